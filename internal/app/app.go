@@ -14,8 +14,11 @@ import (
 	"payment-mutex/pkg/auth"
 	"payment-mutex/pkg/dotenv"
 	"payment-mutex/pkg/hash"
+	"payment-mutex/pkg/idempotency"
 	"payment-mutex/pkg/logger"
+	"payment-mutex/pkg/notifier"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -41,26 +44,302 @@ func Run() {
 
 	hashing := hash.NewHashingPassword()
 
-	repository := repository.NewRepositorys(repository.Deps{
-		MapperRecord: *recordmapper.NewRecordMapper(),
+	repository, err := repository.NewRepositorys(repository.Deps{
+		MapperRecord:  *recordmapper.NewRecordMapper(),
+		StorageDriver: viper.GetString("STORAGE_DRIVER"),
+		StoragePath:   viper.GetString("STORAGE_PATH"),
 	})
 
-	token, err := auth.NewManager(viper.GetString("JWT_SECRET"))
+	if err != nil {
+		log.Fatal("Error creating repositories: ", zap.Error(err))
+	}
+
+	if err := repository.Recover(); err != nil {
+		log.Fatal("Error recovering repositories: ", zap.Error(err))
+	}
+
+	signingAlgorithm := viper.GetString("JWT_SIGNING_ALGORITHM")
+	if signingAlgorithm == "" {
+		signingAlgorithm = auth.AlgorithmRS256
+	}
+
+	token, err := auth.NewManager(signingAlgorithm, viper.GetString("JWT_KEYS_PATH"))
 
 	if err != nil {
 		log.Fatal("Error creating manager: ", zap.Error(err))
 
 	}
 
+	token.WithTTL(
+		time.Duration(viper.GetInt("ACCESS_TOKEN_TTL"))*time.Second,
+		time.Duration(viper.GetInt("REFRESH_TOKEN_TTL"))*time.Second,
+	)
+
+	withdrawHoldTTL := time.Duration(viper.GetInt("WITHDRAW_HOLD_TTL")) * time.Second
+	if withdrawHoldTTL <= 0 {
+		withdrawHoldTTL = 15 * time.Minute
+	}
+
+	authorizationTTL := time.Duration(viper.GetInt("TRANSACTION_AUTHORIZATION_TTL")) * time.Second
+	if authorizationTTL <= 0 {
+		authorizationTTL = 7 * 24 * time.Hour
+	}
+
+	checkoutSessionTTL := time.Duration(viper.GetInt("CHECKOUT_SESSION_TTL")) * time.Second
+	if checkoutSessionTTL <= 0 {
+		checkoutSessionTTL = 30 * time.Minute
+	}
+
+	pinMaxAttempts := viper.GetInt("TRANSACTION_PIN_MAX_ATTEMPTS")
+	if pinMaxAttempts <= 0 {
+		pinMaxAttempts = 3
+	}
+
+	pinLockout := time.Duration(viper.GetInt("TRANSACTION_PIN_LOCKOUT")) * time.Second
+	if pinLockout <= 0 {
+		pinLockout = 30 * time.Minute
+	}
+
+	loginMaxAttempts := viper.GetInt("LOGIN_MAX_ATTEMPTS")
+	if loginMaxAttempts <= 0 {
+		loginMaxAttempts = 5
+	}
+
+	loginLockout := time.Duration(viper.GetInt("LOGIN_LOCKOUT")) * time.Second
+	if loginLockout <= 0 {
+		loginLockout = 15 * time.Minute
+	}
+
+	loginBackoffBase := time.Duration(viper.GetInt("LOGIN_BACKOFF_BASE")) * time.Second
+	if loginBackoffBase <= 0 {
+		loginBackoffBase = time.Second
+	}
+
+	loginBackoffMax := time.Duration(viper.GetInt("LOGIN_BACKOFF_MAX")) * time.Second
+	if loginBackoffMax <= 0 {
+		loginBackoffMax = 5 * time.Minute
+	}
+
+	verificationTTL := time.Duration(viper.GetInt("EMAIL_VERIFICATION_TTL")) * time.Second
+	if verificationTTL <= 0 {
+		verificationTTL = 24 * time.Hour
+	}
+
+	passwordResetTTL := time.Duration(viper.GetInt("PASSWORD_RESET_TTL")) * time.Second
+	if passwordResetTTL <= 0 {
+		passwordResetTTL = time.Hour
+	}
+
+	baseURL := viper.GetString("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://localhost:%s", viper.GetString("PORT"))
+	}
+
+	notifier, err := notifier.New(viper.GetString("NOTIFIER_DRIVER"), viper.GetString("NOTIFIER_PATH"), *log)
+	if err != nil {
+		log.Fatal("Error creating notifier: ", zap.Error(err))
+	}
+
+	apiKeyRotationOverlap := time.Duration(viper.GetInt("MERCHANT_API_KEY_ROTATION_OVERLAP")) * time.Second
+	if apiKeyRotationOverlap <= 0 {
+		apiKeyRotationOverlap = 24 * time.Hour
+	}
+
+	signatureTolerance := time.Duration(viper.GetInt("MERCHANT_SIGNATURE_TOLERANCE")) * time.Second
+	if signatureTolerance <= 0 {
+		signatureTolerance = 5 * time.Minute
+	}
+
+	webhookTimeout := time.Duration(viper.GetInt("WEBHOOK_TIMEOUT")) * time.Second
+	if webhookTimeout <= 0 {
+		webhookTimeout = 10 * time.Second
+	}
+
+	webhookMaxAttempts := viper.GetInt("WEBHOOK_MAX_ATTEMPTS")
+	if webhookMaxAttempts <= 0 {
+		webhookMaxAttempts = 8
+	}
+
+	webhookBackoffBase := time.Duration(viper.GetInt("WEBHOOK_BACKOFF_BASE")) * time.Second
+	if webhookBackoffBase <= 0 {
+		webhookBackoffBase = 30 * time.Second
+	}
+
+	webhookBackoffMax := time.Duration(viper.GetInt("WEBHOOK_BACKOFF_MAX")) * time.Second
+	if webhookBackoffMax <= 0 {
+		webhookBackoffMax = time.Hour
+	}
+
+	settlementCutoffHour := viper.GetInt("SETTLEMENT_CUTOFF_HOUR")
+	if settlementCutoffHour < 0 || settlementCutoffHour > 23 {
+		settlementCutoffHour = 0
+	}
+
+	totpIssuer := viper.GetString("TOTP_ISSUER")
+	if totpIssuer == "" {
+		totpIssuer = "payment-mutex"
+	}
+
 	service := service.NewServices(service.Deps{
-		Repository:     repository,
-		Logger:         *log,
-		Hash:           hashing,
-		Token:          token,
-		MapperResponse: *responseMapper.NewResponseMapper(),
+		Repository:            repository,
+		Logger:                *log,
+		Hash:                  hashing,
+		Token:                 token,
+		MapperResponse:        *responseMapper.NewResponseMapper(),
+		WithdrawHoldTTL:       withdrawHoldTTL,
+		AuthorizationTTL:      authorizationTTL,
+		CheckoutSessionTTL:    checkoutSessionTTL,
+		ApiKeyRotationOverlap: apiKeyRotationOverlap,
+		SignatureTolerance:    signatureTolerance,
+		Pin: service.PinPolicy{
+			Threshold:   viper.GetInt("TRANSACTION_PIN_THRESHOLD"),
+			MaxAttempts: pinMaxAttempts,
+			Lockout:     pinLockout,
+		},
+		Login: service.LoginPolicy{
+			MaxAttempts: loginMaxAttempts,
+			Lockout:     loginLockout,
+			BackoffBase: loginBackoffBase,
+			BackoffMax:  loginBackoffMax,
+		},
+		Account: service.AccountPolicy{
+			BaseURL:         strings.TrimSuffix(baseURL, "/"),
+			VerificationTTL: verificationTTL,
+			ResetTTL:        passwordResetTTL,
+		},
+		Webhook: service.WebhookPolicy{
+			Timeout:     webhookTimeout,
+			MaxAttempts: webhookMaxAttempts,
+			BackoffBase: webhookBackoffBase,
+			BackoffMax:  webhookBackoffMax,
+		},
+		Settlement: service.SettlementPolicy{
+			CutoffHour: settlementCutoffHour,
+		},
+		Notifier:   notifier,
+		TotpIssuer: totpIssuer,
 	})
 
-	myhandler := handler.NewHandler(service)
+	adminEmail := viper.GetString("ADMIN_EMAIL")
+	adminPassword := viper.GetString("ADMIN_PASSWORD")
+
+	// Tidak ada password bawaan, tanpa ADMIN_PASSWORD akun admin tidak dibuat
+	if adminEmail != "" && adminPassword == "" {
+		log.Warn("ADMIN_PASSWORD is not set, skipping admin account creation", zap.String("email", adminEmail))
+	} else if adminEmail != "" {
+		if err := service.Auth.EnsureAdmin(adminEmail, adminPassword); err != nil {
+			log.Fatal("Error creating admin account: ", zap.Error(err))
+		}
+	}
+
+	migrated, err := service.Merchant.MigrateLegacyApiKeys()
+	if err != nil {
+		log.Fatal("Error migrating merchant api keys: ", zap.Error(err))
+	}
+
+	if migrated > 0 {
+		log.Info(fmt.Sprintf("Moved %d merchant api keys to hashed storage", migrated))
+	}
+
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	holdSweepInterval := time.Duration(viper.GetInt("WITHDRAW_HOLD_SWEEP_INTERVAL")) * time.Second
+	if holdSweepInterval <= 0 {
+		holdSweepInterval = time.Minute
+	}
+
+	go runPeriodically(jobCtx, holdSweepInterval, func() {
+		if expired := service.Withdraw.ExpireHolds(); expired > 0 {
+			log.Info(fmt.Sprintf("Released %d expired withdraw holds", expired))
+		}
+	})
+
+	authorizationSweepInterval := time.Duration(viper.GetInt("TRANSACTION_AUTHORIZATION_SWEEP_INTERVAL")) * time.Second
+	if authorizationSweepInterval <= 0 {
+		authorizationSweepInterval = time.Minute
+	}
+
+	go runPeriodically(jobCtx, authorizationSweepInterval, func() {
+		if expired := service.Transaction.ExpireAuthorizations(); expired > 0 {
+			log.Info(fmt.Sprintf("Released %d expired transaction authorizations", expired))
+		}
+	})
+
+	checkoutSweepInterval := time.Duration(viper.GetInt("CHECKOUT_SESSION_SWEEP_INTERVAL")) * time.Second
+	if checkoutSweepInterval <= 0 {
+		checkoutSweepInterval = time.Minute
+	}
+
+	go runPeriodically(jobCtx, checkoutSweepInterval, func() {
+		if expired := service.Checkout.ExpireSessions(); expired > 0 {
+			log.Info(fmt.Sprintf("Expired %d checkout sessions", expired))
+		}
+	})
+
+	webhookSweepInterval := time.Duration(viper.GetInt("WEBHOOK_SWEEP_INTERVAL")) * time.Second
+	if webhookSweepInterval <= 0 {
+		webhookSweepInterval = 10 * time.Second
+	}
+
+	go runPeriodically(jobCtx, webhookSweepInterval, func() {
+		if sent := service.Webhook.DeliverDue(); sent > 0 {
+			log.Info(fmt.Sprintf("Retried %d webhook deliveries", sent))
+		}
+	})
+
+	settlementSweepInterval := time.Duration(viper.GetInt("SETTLEMENT_SWEEP_INTERVAL")) * time.Second
+	if settlementSweepInterval <= 0 {
+		settlementSweepInterval = 5 * time.Minute
+	}
+
+	go runPeriodically(jobCtx, settlementSweepInterval, func() {
+		if paid := service.Settlement.CloseDue(); paid > 0 {
+			log.Info(fmt.Sprintf("Paid out %d settlement batches", paid))
+		}
+	})
+
+	snapshotInterval := time.Duration(viper.GetInt("STORAGE_SNAPSHOT_INTERVAL")) * time.Second
+	if snapshotInterval <= 0 {
+		snapshotInterval = 5 * time.Minute
+	}
+
+	go runPeriodically(jobCtx, snapshotInterval, func() {
+		if err := repository.Snapshot(); err != nil {
+			log.Error("Failed to snapshot repositories", zap.Error(err))
+		}
+	})
+
+	keyRotationInterval := time.Duration(viper.GetInt("JWT_KEY_ROTATION_INTERVAL")) * time.Second
+	if keyRotationInterval <= 0 {
+		keyRotationInterval = 30 * 24 * time.Hour
+	}
+
+	// Umur kunci dicek tiap menit, sehingga jadwal rotasi tetap berjalan setelah restart
+	go runPeriodically(jobCtx, time.Minute, func() {
+		rotated, err := token.RotateIfDue(keyRotationInterval)
+		if err != nil {
+			log.Error("Failed to rotate signing key", zap.Error(err))
+			return
+		}
+
+		if rotated {
+			log.Info("Rotated token signing key")
+		}
+	})
+
+	idempotencyTTL := time.Duration(viper.GetInt("IDEMPOTENCY_KEY_TTL")) * time.Second
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = 24 * time.Hour
+	}
+
+	myhandler := handler.NewHandler(handler.Deps{
+		Services:    service,
+		Idempotency: idempotency.NewStore(idempotencyTTL),
+		Verifier:    &auth.Verifier{Tokens: token, Revocations: service.Auth},
+
+		TrustProxyHeaders: viper.GetBool("TRUST_PROXY_HEADERS"),
+	})
 
 	serve := &http.Server{
 		Addr:         fmt.Sprintf(":%s", viper.GetString("PORT")),
@@ -84,9 +363,19 @@ func Run() {
 
 	<-c
 
+	stopJobs()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	serve.Shutdown(ctx)
+
+	if err := repository.Snapshot(); err != nil {
+		log.Error("Failed to snapshot repositories", zap.Error(err))
+	}
+
+	if err := repository.Close(); err != nil {
+		log.Error("Failed to close repositories", zap.Error(err))
+	}
 	os.Exit(0)
 }
@@ -0,0 +1,21 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// runPeriodically calls job every interval until ctx is cancelled.
+func runPeriodically(ctx context.Context, interval time.Duration, job func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job()
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package record
+
+import "time"
+
+type CheckoutSessionRecord struct {
+	CheckoutSessionID int        `json:"checkout_session_id"`
+	SessionID         string     `json:"session_id"`
+	MerchantID        int        `json:"merchant_id"`
+	Amount            int        `json:"amount"`
+	Reference         string     `json:"reference"`
+	SuccessURL        string     `json:"success_url"`
+	CancelURL         string     `json:"cancel_url"`
+	Status            string     `json:"status"`
+	CardNumber        string     `json:"card_number"`
+	PaymentMethod     string     `json:"payment_method"`
+	TransactionID     int        `json:"transaction_id"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	CompletedAt       *time.Time `json:"completed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package record
+
+import "time"
+
+type FeePlanRecord struct {
+	FeePlanID    int             `json:"fee_plan_id"`
+	Name         string          `json:"name"`
+	DefaultRate  int             `json:"default_rate"`
+	DefaultFixed int             `json:"default_fixed"`
+	Rates        []FeeRateRecord `json:"rates"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+type FeeRateRecord struct {
+	PaymentMethod string `json:"payment_method"`
+	Rate          int    `json:"rate"`
+	Fixed         int    `json:"fixed"`
+}
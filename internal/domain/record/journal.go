@@ -0,0 +1,24 @@
+package record
+
+import "time"
+
+type JournalEntryRecord struct {
+	EntryID     int             `json:"entry_id"`
+	Reference   string          `json:"reference"`
+	ReferenceID int             `json:"reference_id"`
+	Description string          `json:"description"`
+	Postings    []PostingRecord `json:"postings"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type PostingRecord struct {
+	PostingID   int       `json:"posting_id"`
+	EntryID     int       `json:"entry_id"`
+	Account     string    `json:"account"`
+	Debit       int       `json:"debit"`
+	Credit      int       `json:"credit"`
+	Reference   string    `json:"reference"`
+	ReferenceID int       `json:"reference_id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -1,9 +1,26 @@
 package record
 
+import "time"
+
 type MerchantRecord struct {
 	MerchantID int    `json:"merchant_id"`
 	Name       string `json:"name"`
-	ApiKey     string `json:"api_key"`
 	UserID     int    `json:"user_id"`
 	Status     string `json:"status"`
+
+	StatusHistory []MerchantStatusChangeRecord `json:"status_history"`
+
+	SigningSecret    string `json:"signing_secret"`
+	RequireSignature bool   `json:"require_signature"`
+	WebhookSecret    string `json:"webhook_secret"`
+	SettlementMode   string `json:"settlement_mode"`
+	FeePlanID        int    `json:"fee_plan_id"`
+}
+
+type MerchantStatusChangeRecord struct {
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason"`
+	Note      string    `json:"note"`
+	ChangedBy int       `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
 }
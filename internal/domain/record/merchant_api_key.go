@@ -0,0 +1,16 @@
+package record
+
+import "time"
+
+type MerchantApiKeyRecord struct {
+	KeyID      int        `json:"key_id"`
+	MerchantID int        `json:"merchant_id"`
+	Name       string     `json:"name"`
+	Lookup     string     `json:"lookup"`
+	Hash       string     `json:"hash"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
@@ -0,0 +1,13 @@
+package record
+
+import "time"
+
+type RefundRecord struct {
+	RefundID      int       `json:"refund_id"`
+	TransactionID int       `json:"transaction_id"`
+	MerchantID    int       `json:"merchant_id"`
+	CardNumber    string    `json:"card_number"`
+	Amount        int       `json:"amount"`
+	Reason        string    `json:"reason"`
+	RefundTime    time.Time `json:"refund_time"`
+}
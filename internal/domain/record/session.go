@@ -0,0 +1,13 @@
+package record
+
+import "time"
+
+type SessionRecord struct {
+	SessionID     int        `json:"session_id"`
+	UserID        int        `json:"user_id"`
+	Generation    int        `json:"generation"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	RevokedReason string     `json:"revoked_reason"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+package record
+
+import "time"
+
+type SettlementItemRecord struct {
+	ItemID        int       `json:"item_id"`
+	MerchantID    int       `json:"merchant_id"`
+	TransactionID int       `json:"transaction_id"`
+	Type          string    `json:"type"`
+	ReferenceID   int       `json:"reference_id"`
+	Amount        int       `json:"amount"`
+	Fee           int       `json:"fee"`
+	BatchID       int       `json:"batch_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type SettlementBatchRecord struct {
+	BatchID      int        `json:"batch_id"`
+	MerchantID   int        `json:"merchant_id"`
+	CardNumber   string     `json:"card_number"`
+	Cutoff       time.Time  `json:"cutoff"`
+	Status       string     `json:"status"`
+	ItemCount    int        `json:"item_count"`
+	GrossAmount  int        `json:"gross_amount"`
+	RefundAmount int        `json:"refund_amount"`
+	FeeAmount    int        `json:"fee_amount"`
+	NetAmount    int        `json:"net_amount"`
+	Error        string     `json:"error"`
+	CreatedAt    time.Time  `json:"created_at"`
+	PaidAt       *time.Time `json:"paid_at"`
+}
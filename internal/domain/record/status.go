@@ -0,0 +1,8 @@
+package record
+
+import "time"
+
+type StatusChangeRecord struct {
+	Status    string    `json:"status"`
+	ChangedAt time.Time `json:"changed_at"`
+}
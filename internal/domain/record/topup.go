@@ -3,10 +3,12 @@ package record
 import "time"
 
 type TopupRecord struct {
-	TopupID     int       `json:"topup_id"`
-	CardNumber  string    `json:"card_number"`
-	TopupNo     string    `json:"topup_no"`
-	TopupAmount int       `json:"topup_amount"`
-	TopupMethod string    `json:"topup_method"`
-	TopupTime   time.Time `json:"topup_time"`
+	TopupID       int                  `json:"topup_id"`
+	CardNumber    string               `json:"card_number"`
+	TopupNo       string               `json:"topup_no"`
+	TopupAmount   int                  `json:"topup_amount"`
+	TopupMethod   string               `json:"topup_method"`
+	TopupTime     time.Time            `json:"topup_time"`
+	Status        string               `json:"status"`
+	StatusHistory []StatusChangeRecord `json:"status_history"`
 }
@@ -3,9 +3,11 @@ package record
 import "time"
 
 type TransferRecord struct {
-	TransferID     int       `json:"transfer_id"`
-	TransferFrom   string    `json:"transfer_from"`
-	TransferTo     string    `json:"transfer_to"`
-	TransferAmount int       `json:"transfer_amount"`
-	TransferTime   time.Time `json:"transfer_time"`
+	TransferID     int                  `json:"transfer_id"`
+	TransferFrom   string               `json:"transfer_from"`
+	TransferTo     string               `json:"transfer_to"`
+	TransferAmount int                  `json:"transfer_amount"`
+	TransferTime   time.Time            `json:"transfer_time"`
+	Status         string               `json:"status"`
+	StatusHistory  []StatusChangeRecord `json:"status_history"`
 }
@@ -1,10 +1,30 @@
 package record
 
+import "time"
+
 type UserRecord struct {
 	UserID      int     `json:"user_id"`
 	FirstName   string  `json:"firstname"`
 	LastName    string  `json:"lastname"`
 	Email       string  `json:"email"`
 	Password    *string `json:"password"`
+	Role        string  `json:"role"`
 	NocTransfer int     `json:"noc_transfer"`
+
+	Status          string     `json:"status"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+
+	LoginFailedAttempts int        `json:"login_failed_attempts"`
+	LoginLockedUntil    *time.Time `json:"login_locked_until"`
+
+	Pin               *string    `json:"pin"`
+	PinFailedAttempts int        `json:"pin_failed_attempts"`
+	PinLockedUntil    *time.Time `json:"pin_locked_until"`
+
+	TotpSecret         string     `json:"totp_secret"`
+	TotpEnabled        bool       `json:"totp_enabled"`
+	TotpLastStep       int64      `json:"totp_last_step"`
+	TotpFailedAttempts int        `json:"totp_failed_attempts"`
+	TotpLockedUntil    *time.Time `json:"totp_locked_until"`
+	RecoveryCodes      []string   `json:"recovery_codes"`
 }
@@ -0,0 +1,12 @@
+package record
+
+import "time"
+
+type UserTokenRecord struct {
+	TokenID   int        `json:"token_id"`
+	UserID    int        `json:"user_id"`
+	Purpose   string     `json:"purpose"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package record
+
+import "time"
+
+type WebhookEndpointRecord struct {
+	EndpointID int       `json:"endpoint_id"`
+	MerchantID int       `json:"merchant_id"`
+	URL        string    `json:"url"`
+	Events     []string  `json:"events"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryRecord struct {
+	DeliveryID    int                    `json:"delivery_id"`
+	EndpointID    int                    `json:"endpoint_id"`
+	MerchantID    int                    `json:"merchant_id"`
+	EventID       string                 `json:"event_id"`
+	EventType     string                 `json:"event_type"`
+	Payload       string                 `json:"payload"`
+	Status        string                 `json:"status"`
+	Attempts      []WebhookAttemptRecord `json:"attempts"`
+	NextAttemptAt *time.Time             `json:"next_attempt_at"`
+	RedeliveryOf  int                    `json:"redelivery_of"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+type WebhookAttemptRecord struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error"`
+	DurationMs  int64     `json:"duration_ms"`
+}
@@ -0,0 +1,15 @@
+package record
+
+import "time"
+
+type WithdrawHoldRecord struct {
+	HoldID         int       `json:"hold_id"`
+	CardNumber     string    `json:"card_number"`
+	Amount         int       `json:"amount"`
+	CapturedAmount int       `json:"captured_amount"`
+	WithdrawID     int       `json:"withdraw_id"`
+	Status         string    `json:"status"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,72 @@
+package requests
+
+import (
+	"fmt"
+	methodtopup "payment-mutex/pkg/method_topup"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateAuthorizationRequest struct {
+	CardNumber    string `json:"card_number" validate:"required,min=1"`
+	Amount        int    `json:"amount" validate:"required,gt=0"`
+	PaymentMethod string `json:"payment_method" validate:"required"`
+
+	MerchantID *int      `json:"-"`
+	ExpiresAt  time.Time `json:"-"`
+}
+
+// CaptureAuthorizationRequest captures an authorization once. When Amount is
+// zero the whole authorized amount is captured, otherwise the rest is released.
+type CaptureAuthorizationRequest struct {
+	AuthorizationID int `json:"authorization_id" validate:"required"`
+	Amount          int `json:"amount" validate:"gte=0"`
+}
+
+type VoidAuthorizationRequest struct {
+	AuthorizationID int `json:"authorization_id" validate:"required"`
+}
+
+type UpdateAuthorizationStatus struct {
+	AuthorizationID int
+	Status          string
+	CapturedAmount  int
+	TransactionID   int
+}
+
+func (r *CreateAuthorizationRequest) Validate() error {
+	validate := validator.New()
+
+	err := validate.Struct(r)
+
+	if !methodtopup.PaymentMethodValidator(r.PaymentMethod) {
+		return fmt.Errorf("payment method not found")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *CaptureAuthorizationRequest) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *VoidAuthorizationRequest) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	return nil
+}
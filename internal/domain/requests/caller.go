@@ -0,0 +1,14 @@
+package requests
+
+import "payment-mutex/internal/models"
+
+// Caller is the signed in user a service call is made for.
+type Caller struct {
+	UserID int
+	Role   string
+}
+
+// IsAdmin reports whether the caller may act on resources of every user.
+func (c Caller) IsAdmin() bool {
+	return c.Role == models.RoleAdmin
+}
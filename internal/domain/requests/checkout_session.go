@@ -0,0 +1,69 @@
+package requests
+
+import (
+	"fmt"
+	methodtopup "payment-mutex/pkg/method_topup"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CreateCheckoutSessionRequest asks a customer to pay Amount to the merchant.
+// The customer is sent to SuccessURL once the payment went through, and to
+// CancelURL when they leave the payment page without paying.
+type CreateCheckoutSessionRequest struct {
+	Amount     int    `json:"amount" validate:"required,gt=0"`
+	Reference  string `json:"reference" validate:"required,max=100"`
+	SuccessURL string `json:"success_url" validate:"required,http_url"`
+	CancelURL  string `json:"cancel_url" validate:"required,http_url"`
+
+	MerchantID *int      `json:"-"`
+	SessionID  string    `json:"-"`
+	ExpiresAt  time.Time `json:"-"`
+}
+
+// ConfirmCheckoutSessionRequest pays a checkout session from a card of the
+// signed in customer.
+type ConfirmCheckoutSessionRequest struct {
+	SessionID     string `json:"session_id" validate:"required"`
+	CardNumber    string `json:"card_number" validate:"required"`
+	PaymentMethod string `json:"payment_method" validate:"required"`
+	Pin           string `json:"pin" validate:"omitempty,len=6,numeric"`
+}
+
+type UpdateCheckoutSessionStatus struct {
+	CheckoutSessionID int
+	// From is the status the session must still have, the update fails
+	// when it moved on in the meantime. Empty updates whatever the status.
+	From          string
+	Status        string
+	CardNumber    string
+	PaymentMethod string
+	TransactionID int
+}
+
+func (r *CreateCheckoutSessionRequest) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *ConfirmCheckoutSessionRequest) Validate() error {
+	validate := validator.New()
+
+	err := validate.Struct(r)
+
+	if !methodtopup.PaymentMethodValidator(r.PaymentMethod) {
+		return fmt.Errorf("payment method not found")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package requests
+
+import (
+	"fmt"
+	methodtopup "payment-mutex/pkg/method_topup"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type FeeRateRequest struct {
+	PaymentMethod string `json:"payment_method" validate:"required"`
+	Rate          int    `json:"rate" validate:"min=0,max=10000"`
+	Fixed         int    `json:"fixed" validate:"min=0"`
+}
+
+type CreateFeePlanRequest struct {
+	Name         string           `json:"name" validate:"required,max=100"`
+	DefaultRate  int              `json:"default_rate" validate:"min=0,max=10000"`
+	DefaultFixed int              `json:"default_fixed" validate:"min=0"`
+	Rates        []FeeRateRequest `json:"rates" validate:"dive"`
+}
+
+type UpdateFeePlanRequest struct {
+	FeePlanID    int              `json:"fee_plan_id" validate:"required"`
+	Name         string           `json:"name" validate:"required,max=100"`
+	DefaultRate  int              `json:"default_rate" validate:"min=0,max=10000"`
+	DefaultFixed int              `json:"default_fixed" validate:"min=0"`
+	Rates        []FeeRateRequest `json:"rates" validate:"dive"`
+}
+
+// AssignFeePlanRequest charges a merchant by a fee plan. A FeePlanID of zero
+// removes the plan and the merchant pays no fee.
+type AssignFeePlanRequest struct {
+	MerchantID int `json:"merchant_id" validate:"required"`
+	FeePlanID  int `json:"fee_plan_id" validate:"min=0"`
+}
+
+func (r *CreateFeePlanRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return validateFeeRates(r.Rates)
+}
+
+func (r *UpdateFeePlanRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+
+	return validateFeeRates(r.Rates)
+}
+
+func (r *AssignFeePlanRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateFeeRates accepts each known payment method at most once.
+func validateFeeRates(rates []FeeRateRequest) error {
+	seen := make(map[string]bool, len(rates))
+
+	for _, rate := range rates {
+		method := strings.ToLower(rate.PaymentMethod)
+
+		if !methodtopup.PaymentMethodValidator(method) {
+			return fmt.Errorf("payment method %q not found", rate.PaymentMethod)
+		}
+
+		if seen[method] {
+			return fmt.Errorf("payment method %q has more than one rate", rate.PaymentMethod)
+		}
+
+		seen[method] = true
+	}
+
+	return nil
+}
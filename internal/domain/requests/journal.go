@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateJournalEntryRequest struct {
+	Reference   string                 `json:"reference" validate:"required"`
+	ReferenceID int                    `json:"reference_id"`
+	Description string                 `json:"description"`
+	Postings    []CreatePostingRequest `json:"postings" validate:"required,min=2,dive"`
+}
+
+type CreatePostingRequest struct {
+	Account string `json:"account" validate:"required"`
+	Debit   int    `json:"debit" validate:"gte=0"`
+	Credit  int    `json:"credit" validate:"gte=0"`
+}
+
+func (r *CreateJournalEntryRequest) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	totalDebit := 0
+	totalCredit := 0
+
+	for _, posting := range r.Postings {
+		if posting.Debit > 0 && posting.Credit > 0 {
+			return errors.New("posting cannot have both debit and credit")
+		}
+
+		totalDebit += posting.Debit
+		totalCredit += posting.Credit
+	}
+
+	if totalDebit != totalCredit {
+		return errors.New("journal entry is not balanced")
+	}
+
+	return nil
+}
@@ -2,16 +2,70 @@ package requests
 
 import "github.com/go-playground/validator/v10"
 
+// CreateMerchantRequest opens a merchant for the caller. Only admins may
+// open one for another user with UserID.
 type CreateMerchantRequest struct {
 	Name   string `json:"name"`
 	UserID int    `json:"user_id"`
 }
 
+// UpdateMerchantRequest changes the details of a merchant. Its status only
+// moves through the review workflow, and its owner only changes through
+// UpdateMerchantOwnerRequest.
 type UpdateMerchantRequest struct {
 	MerchantID int    `json:"merchant_id"`
 	Name       string `json:"name"`
-	UserID     int    `json:"user_id"`
-	Status     string `json:"status"`
+}
+
+// UpdateMerchantOwnerRequest hands a merchant to another user. Its API keys,
+// webhooks and settlement payouts go with it.
+type UpdateMerchantOwnerRequest struct {
+	MerchantID int `json:"merchant_id" validate:"required"`
+	UserID     int `json:"user_id" validate:"required"`
+}
+
+// UpdateMerchantStatusRequest moves a merchant to Status and records the
+// change in its history.
+type UpdateMerchantStatusRequest struct {
+	MerchantID int
+	Status     string
+	Reason     string
+	Note       string
+	ChangedBy  int
+}
+
+// ChangeMerchantStatusRequest asks for a step of the review workflow. Which
+// reasons are accepted depends on the step.
+type ChangeMerchantStatusRequest struct {
+	MerchantID int    `json:"merchant_id" validate:"required"`
+	Reason     string `json:"reason"`
+	Note       string `json:"note" validate:"max=500"`
+}
+
+type UpdateMerchantSigningRequest struct {
+	MerchantID       int
+	SigningSecret    string
+	RequireSignature bool
+}
+
+type RotateSigningSecretRequest struct {
+	MerchantID int `json:"merchant_id" validate:"required"`
+}
+
+type RequireSignatureRequest struct {
+	MerchantID int  `json:"merchant_id" validate:"required"`
+	Required   bool `json:"required"`
+}
+
+// SignedRequest is what a merchant request is signed over, together with the
+// signature headers it carried.
+type SignedRequest struct {
+	Method    string
+	Path      string
+	Body      []byte
+	Timestamp string
+	Nonce     string
+	Signature string
 }
 
 func (r CreateMerchantRequest) Validate() error {
@@ -37,3 +91,51 @@ func (r UpdateMerchantRequest) Validate() error {
 
 	return nil
 }
+
+func (r UpdateMerchantOwnerRequest) Validate() error {
+	validate := validator.New()
+
+	err := validate.Struct(r)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r RotateSigningSecretRequest) Validate() error {
+	validate := validator.New()
+
+	err := validate.Struct(r)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r RequireSignatureRequest) Validate() error {
+	validate := validator.New()
+
+	err := validate.Struct(r)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r ChangeMerchantStatusRequest) Validate() error {
+	validate := validator.New()
+
+	err := validate.Struct(r)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
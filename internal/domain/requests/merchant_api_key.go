@@ -0,0 +1,55 @@
+package requests
+
+import "github.com/go-playground/validator/v10"
+
+type CreateMerchantApiKeyRequest struct {
+	MerchantID int
+	Name       string
+	Lookup     string
+	Hash       string
+	Scopes     []string
+}
+
+type CreateApiKeyRequest struct {
+	MerchantID int      `json:"merchant_id" validate:"required"`
+	Name       string   `json:"name" validate:"required,max=64"`
+	Scopes     []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=charge refund read"`
+}
+
+// RotateApiKeyRequest replaces a key with a new one. The old key keeps working
+// for OverlapSeconds, or the configured overlap when it is zero.
+type RotateApiKeyRequest struct {
+	KeyID          int `json:"key_id" validate:"required"`
+	OverlapSeconds int `json:"overlap_seconds" validate:"gte=0"`
+}
+
+type RevokeApiKeyRequest struct {
+	KeyID int `json:"key_id" validate:"required"`
+}
+
+func (r *CreateApiKeyRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *RotateApiKeyRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *RevokeApiKeyRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package requests
+
+import "github.com/go-playground/validator/v10"
+
+// SetPinRequest sets the first transaction PIN of the signed in user. The
+// account password is asked for so a stolen access token alone cannot set
+// one.
+type SetPinRequest struct {
+	Password   string `json:"password" validate:"required"`
+	Pin        string `json:"pin" validate:"required,len=6,numeric"`
+	ConfirmPin string `json:"confirm_pin" validate:"required,eqfield=Pin"`
+}
+
+type ChangePinRequest struct {
+	OldPin     string `json:"old_pin" validate:"required,len=6,numeric"`
+	Pin        string `json:"pin" validate:"required,len=6,numeric,nefield=OldPin"`
+	ConfirmPin string `json:"confirm_pin" validate:"required,eqfield=Pin"`
+}
+
+func (r *SetPinRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ChangePinRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package requests
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// CreateRefundRequest refunds part or all of a transaction. When Amount is
+// zero the whole remaining amount of the transaction is refunded.
+type CreateRefundRequest struct {
+	TransactionID int    `json:"transaction_id" validate:"required"`
+	Amount        int    `json:"amount" validate:"gte=0"`
+	Reason        string `json:"reason" validate:"max=255"`
+
+	MerchantID *int   `json:"-"`
+	CardNumber string `json:"-"`
+}
+
+func (r *CreateRefundRequest) Validate() error {
+	validate := validator.New()
+
+	err := validate.Struct(r)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
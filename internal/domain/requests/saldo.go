@@ -9,7 +9,7 @@ import (
 
 type CreateSaldoRequest struct {
 	CardNumber   string `json:"card_number" validate:"required"`
-	TotalBalance int    `json:"total_balance" validate:"required"`
+	TotalBalance int    `json:"total_balance" validate:"min=0"`
 }
 
 type UpdateSaldoRequest struct {
@@ -23,6 +23,18 @@ type UpdateSaldoBalance struct {
 	TotalBalance int    `json:"total_balance" validate:"required,min=50000"`
 }
 
+type UpdateSaldoBalances struct {
+	Changes []SaldoBalanceChange `json:"changes" validate:"required,min=1,dive"`
+}
+
+// SaldoBalanceChange moves the ledger balance of a card by Amount and the held
+// part of it by Held. Both are signed.
+type SaldoBalanceChange struct {
+	CardNumber string `json:"card_number" validate:"required,min=1"`
+	Amount     int    `json:"amount"`
+	Held       int    `json:"held"`
+}
+
 type UpdateSaldoWithdraw struct {
 	CardNumber     string     `json:"card_number" validate:"required,min=1"`
 	TotalBalance   int        `json:"total_balance" validate:"required,min=50000"`
@@ -56,6 +68,21 @@ func (r *UpdateSaldoBalance) Validate() error {
 	return nil
 }
 
+func (r *UpdateSaldoBalances) Validate() error {
+	validate := validator.New()
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	for _, change := range r.Changes {
+		if change.Amount == 0 && change.Held == 0 {
+			return errors.New("balance change must move the balance or the held amount")
+		}
+	}
+
+	return nil
+}
+
 func (r *UpdateSaldoWithdraw) Validate() error {
 	validate := validator.New()
 	if err := validate.Struct(r); err != nil {
@@ -0,0 +1,23 @@
+package requests
+
+import "github.com/go-playground/validator/v10"
+
+type CreateSessionRequest struct {
+	UserID int
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (r *RefreshTokenRequest) Validate() error {
+	validate := validator.New()
+
+	err := validate.Struct(r)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateSettlementItemRequest struct {
+	MerchantID    int
+	TransactionID int
+	Type          string
+	ReferenceID   int
+	Amount        int
+	Fee           int
+}
+
+type CreateSettlementBatchRequest struct {
+	MerchantID   int
+	CardNumber   string
+	Cutoff       time.Time
+	ItemCount    int
+	GrossAmount  int
+	RefundAmount int
+	FeeAmount    int
+	NetAmount    int
+}
+
+type UpdateSettlementBatchStatus struct {
+	BatchID int
+	Status  string
+	Error   string
+}
+
+type UpdateSettlementModeRequest struct {
+	MerchantID int    `json:"merchant_id" validate:"required"`
+	Mode       string `json:"mode" validate:"required,oneof=instant batch"`
+}
+
+// CloseSettlementRequest closes the open items of a merchant right away
+// instead of waiting for the daily cutoff.
+type CloseSettlementRequest struct {
+	MerchantID int `json:"merchant_id" validate:"required"`
+}
+
+func (r *UpdateSettlementModeRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *CloseSettlementRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package requests
+
+import "github.com/go-playground/validator/v10"
+
+type UpdateStatusRequest struct {
+	ID     int    `json:"id" validate:"required"`
+	Status string `json:"status" validate:"required,oneof=pending processing succeeded failed reversed"`
+}
+
+func (r *UpdateStatusRequest) Validate() error {
+	validate := validator.New()
+
+	err := validate.Struct(r)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
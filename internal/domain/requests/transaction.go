@@ -10,10 +10,11 @@ import (
 
 type CreateTransactionRequest struct {
 	CardNumber      string    `json:"card_number"`
-	Amount          int       `json:"amount"`
+	Amount          int       `json:"amount" validate:"required,gt=0"`
 	PaymentMethod   string    `json:"payment_method"`
 	MerchantID      *int      `json:"merchant_id"`
 	TransactionTime time.Time `json:"transaction_time"`
+	Fee             int       `json:"-"`
 }
 
 func (r *CreateTransactionRequest) Validate() error {
@@ -35,11 +36,12 @@ func (r *CreateTransactionRequest) Validate() error {
 type UpdateTransactionRequest struct {
 	TransactionID int    `json:"transaction_id"`
 	CardNumber    string `json:"card_number"`
-	Amount        int    `json:"amount"`
+	Amount        int    `json:"amount" validate:"required,gt=0"`
 	PaymentMethod string `json:"payment_method"`
 
 	MerchantID      *int      `json:"merchant_id"`
 	TransactionTime time.Time `json:"transaction_time"`
+	Fee             int       `json:"-"`
 }
 
 func (r *UpdateTransactionRequest) Validate() error {
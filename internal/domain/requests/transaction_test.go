@@ -0,0 +1,27 @@
+package requests
+
+import "testing"
+
+func TestTransactionAmountValidation(t *testing.T) {
+	tests := []struct {
+		amount int
+		ok     bool
+	}{
+		{1, true},
+		{50000, true},
+		{0, false},
+		{-100, false},
+	}
+
+	for _, tt := range tests {
+		create := CreateTransactionRequest{CardNumber: "4111", Amount: tt.amount, PaymentMethod: "dana"}
+		if err := create.Validate(); (err == nil) != tt.ok {
+			t.Errorf("create with amount %d: %v, want valid = %v", tt.amount, err, tt.ok)
+		}
+
+		update := UpdateTransactionRequest{TransactionID: 1, CardNumber: "4111", Amount: tt.amount, PaymentMethod: "dana"}
+		if err := update.Validate(); (err == nil) != tt.ok {
+			t.Errorf("update with amount %d: %v, want valid = %v", tt.amount, err, tt.ok)
+		}
+	}
+}
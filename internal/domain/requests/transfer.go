@@ -10,6 +10,7 @@ type CreateTransferRequest struct {
 	TransferFrom   string `json:"transfer_from" validate:"required"`
 	TransferTo     string `json:"transfer_to" validate:"required,min=1"`
 	TransferAmount int    `json:"transfer_amount" validate:"required,min=50000"`
+	Pin            string `json:"pin" validate:"omitempty,len=6,numeric"`
 }
 
 type UpdateTransferRequest struct {
@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type UpdateUserTotpRequest struct {
+	UserID         int
+	Secret         string
+	Enabled        bool
+	LastStep       int64
+	FailedAttempts int
+	LockedUntil    *time.Time
+	RecoveryCodes  []string
+}
+
+type ConfirmTotpRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// VerifyLoginRequest finishes a login that asked for a second factor. Code is
+// either the current code of the authenticator app or an unused recovery code.
+type VerifyLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+func (r *ConfirmTotpRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *VerifyLoginRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
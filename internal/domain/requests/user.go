@@ -8,6 +8,9 @@ type CreateUserRequest struct {
 	Email           string `json:"email" validate:"required,email"`
 	Password        string `json:"password" validate:"required,min=6"`
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
+	Role            string `json:"role" validate:"omitempty,oneof=admin customer merchant_owner"`
+	// Status is set by the service, accounts created without one are active.
+	Status string `json:"-"`
 }
 
 type UpdateUserRequest struct {
@@ -19,6 +22,15 @@ type UpdateUserRequest struct {
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
 }
 
+type UpdateUserRoleRequest struct {
+	UserID int    `json:"user_id" validate:"required,min=1"`
+	Role   string `json:"role" validate:"required,oneof=admin customer merchant_owner"`
+}
+
+type UnlockUserRequest struct {
+	UserID int `json:"user_id" validate:"required,min=1"`
+}
+
 func (r *CreateUserRequest) Validate() error {
 	validate := validator.New()
 	err := validate.Struct(r)
@@ -36,3 +48,21 @@ func (r *UpdateUserRequest) Validate() error {
 	}
 	return nil
 }
+
+func (r *UpdateUserRoleRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *UnlockUserRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateWebhookEndpointRequest struct {
+	MerchantID int
+	URL        string
+	Events     []string
+}
+
+type UpdateWebhookEndpointRequest struct {
+	EndpointID int
+	URL        string
+	Events     []string
+	Active     bool
+}
+
+type CreateWebhookDeliveryRequest struct {
+	EndpointID   int
+	MerchantID   int
+	EventID      string
+	EventType    string
+	Payload      string
+	RedeliveryOf int
+}
+
+// RecordWebhookAttemptRequest adds an attempt to a delivery and moves it to
+// Status. NextAttemptAt is nil once no further attempt is planned.
+type RecordWebhookAttemptRequest struct {
+	DeliveryID    int
+	AttemptedAt   time.Time
+	StatusCode    int
+	Error         string
+	Duration      time.Duration
+	Status        string
+	NextAttemptAt *time.Time
+}
+
+type CreateWebhookRequest struct {
+	MerchantID int      `json:"merchant_id" validate:"required"`
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	Events     []string `json:"events" validate:"required,min=1,unique,dive,oneof=transaction.created transaction.updated transaction.status_changed transaction.refunded authorization.created authorization.captured authorization.voided settlement.paid"`
+}
+
+type UpdateWebhookRequest struct {
+	EndpointID int      `json:"endpoint_id" validate:"required"`
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	Events     []string `json:"events" validate:"required,min=1,unique,dive,oneof=transaction.created transaction.updated transaction.status_changed transaction.refunded authorization.created authorization.captured authorization.voided settlement.paid"`
+	Active     bool     `json:"active"`
+}
+
+type RotateWebhookSecretRequest struct {
+	MerchantID int `json:"merchant_id" validate:"required"`
+}
+
+type RedeliverWebhookRequest struct {
+	DeliveryID int `json:"delivery_id" validate:"required"`
+}
+
+func (r *CreateWebhookRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *UpdateWebhookRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *RotateWebhookSecretRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *RedeliverWebhookRequest) Validate() error {
+	validate := validator.New()
+	err := validate.Struct(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
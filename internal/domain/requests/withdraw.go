@@ -11,6 +11,7 @@ type CreateWithdrawRequest struct {
 	CardNumber     string    `json:"card_number" validate:"required,min=1"`
 	WithdrawAmount int       `json:"withdraw_amount" validate:"required,min=50000"`
 	WithdrawTime   time.Time `json:"withdraw_time" validate:"required"`
+	Pin            string    `json:"pin" validate:"omitempty,len=6,numeric"`
 }
 
 type UpdateWithdrawRequest struct {
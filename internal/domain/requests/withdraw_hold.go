@@ -0,0 +1,68 @@
+package requests
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateWithdrawHoldRequest struct {
+	CardNumber string `json:"card_number" validate:"required,min=1"`
+	Amount     int    `json:"amount" validate:"required,min=50000"`
+	Pin        string `json:"pin" validate:"omitempty,len=6,numeric"`
+
+	ExpiresAt time.Time `json:"-"`
+}
+
+// CaptureWithdrawHoldRequest captures a hold. When Amount is zero the whole
+// held amount is withdrawn, otherwise the rest of the hold is released.
+type CaptureWithdrawHoldRequest struct {
+	HoldID int `json:"hold_id" validate:"required"`
+	Amount int `json:"amount" validate:"gte=0"`
+}
+
+type VoidWithdrawHoldRequest struct {
+	HoldID int `json:"hold_id" validate:"required"`
+}
+
+type UpdateWithdrawHoldStatus struct {
+	HoldID         int
+	Status         string
+	CapturedAmount int
+	WithdrawID     int
+}
+
+func (r *CreateWithdrawHoldRequest) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	if r.Amount < 50000 {
+		return errors.New("hold amount must be at least 50,000")
+	}
+
+	return nil
+}
+
+func (r *CaptureWithdrawHoldRequest) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *VoidWithdrawHoldRequest) Validate() error {
+	validate := validator.New()
+
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package response
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// LoginResponse carries the tokens of a finished login, or a challenge token
+// when the account uses two-factor authentication and a code is still needed.
+type LoginResponse struct {
+	*TokenResponse
+	MfaRequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
+}
+
+type TotpEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
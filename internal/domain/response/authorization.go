@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+type AuthorizationResponse struct {
+	ID             int       `json:"id"`
+	CardNumber     string    `json:"card_number"`
+	Amount         int       `json:"amount"`
+	CapturedAmount int       `json:"captured_amount"`
+	PaymentMethod  string    `json:"payment_method"`
+	TransactionID  *int      `json:"transaction_id"`
+	Status         string    `json:"status"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
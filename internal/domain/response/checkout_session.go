@@ -0,0 +1,21 @@
+package response
+
+import "time"
+
+type CheckoutSessionResponse struct {
+	ID            string     `json:"id"`
+	MerchantID    int        `json:"merchant_id"`
+	MerchantName  string     `json:"merchant_name,omitempty"`
+	Amount        int        `json:"amount"`
+	Reference     string     `json:"reference"`
+	SuccessURL    string     `json:"success_url"`
+	CancelURL     string     `json:"cancel_url"`
+	Status        string     `json:"status"`
+	CardNumber    string     `json:"card_number,omitempty"`
+	PaymentMethod string     `json:"payment_method,omitempty"`
+	TransactionID *int       `json:"transaction_id"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
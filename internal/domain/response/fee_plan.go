@@ -0,0 +1,32 @@
+package response
+
+import "time"
+
+type FeePlanResponse struct {
+	ID           int                `json:"id"`
+	Name         string             `json:"name"`
+	DefaultRate  int                `json:"default_rate"`
+	DefaultFixed int                `json:"default_fixed"`
+	Rates        []*FeeRateResponse `json:"rates"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+type FeeRateResponse struct {
+	PaymentMethod string `json:"payment_method"`
+	Rate          int    `json:"rate"`
+	Fixed         int    `json:"fixed"`
+}
+
+// FeePreviewResponse is the fee a merchant would pay on a payment, without
+// making it.
+type FeePreviewResponse struct {
+	MerchantID    int    `json:"merchant_id"`
+	FeePlanID     int    `json:"fee_plan_id"`
+	PaymentMethod string `json:"payment_method"`
+	Amount        int    `json:"amount"`
+	Rate          int    `json:"rate"`
+	Fixed         int    `json:"fixed"`
+	Fee           int    `json:"fee"`
+	NetAmount     int    `json:"net_amount"`
+}
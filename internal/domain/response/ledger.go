@@ -0,0 +1,22 @@
+package response
+
+import "time"
+
+type PostingResponse struct {
+	ID          int       `json:"id"`
+	EntryID     int       `json:"entry_id"`
+	Account     string    `json:"account"`
+	Debit       int       `json:"debit"`
+	Credit      int       `json:"credit"`
+	Reference   string    `json:"reference"`
+	ReferenceID int       `json:"reference_id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type LedgerBalanceResponse struct {
+	CardNumber      string `json:"card_number"`
+	JournalBalance  int    `json:"journal_balance"`
+	PreviousBalance int    `json:"previous_balance"`
+	Corrected       bool   `json:"corrected"`
+}
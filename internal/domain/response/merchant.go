@@ -1,8 +1,34 @@
 package response
 
+import "time"
+
 type MerchantResponse struct {
 	ID     int    `json:"id"`
 	Name   string `json:"name"`
-	ApiKey string `json:"api_key"`
 	Status string `json:"status"`
+	// RequireSignature tells whether unsigned requests of the merchant are
+	// refused.
+	RequireSignature bool `json:"require_signature"`
+	// SettlementMode tells whether the merchant is paid per transaction or in
+	// daily batches.
+	SettlementMode string `json:"settlement_mode"`
+	// FeePlanID is the fee plan charged on payments, zero when there is none.
+	FeePlanID int `json:"fee_plan_id"`
+	// ApiKey is only set when the merchant is created, the key is not
+	// stored in a form that can be shown again.
+	ApiKey string `json:"api_key,omitempty"`
+}
+
+type SigningSecretResponse struct {
+	MerchantID       int    `json:"merchant_id"`
+	SigningSecret    string `json:"signing_secret"`
+	RequireSignature bool   `json:"require_signature"`
+}
+
+type MerchantStatusChangeResponse struct {
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason"`
+	Note      string    `json:"note,omitempty"`
+	ChangedBy int       `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
 }
@@ -0,0 +1,18 @@
+package response
+
+import "time"
+
+type MerchantApiKeyResponse struct {
+	ID         int        `json:"id"`
+	MerchantID int        `json:"merchant_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	// ApiKey is only set when the key is created, it cannot be shown again.
+	ApiKey string `json:"api_key,omitempty"`
+}
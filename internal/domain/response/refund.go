@@ -0,0 +1,13 @@
+package response
+
+import "time"
+
+type RefundResponse struct {
+	ID              int       `json:"id"`
+	TransactionID   int       `json:"transaction_id"`
+	CardNumber      string    `json:"card_number"`
+	Amount          int       `json:"amount"`
+	RemainingAmount int       `json:"remaining_amount"`
+	Reason          string    `json:"reason"`
+	RefundTime      time.Time `json:"refund_time"`
+}
@@ -6,6 +6,8 @@ type SaldoResponse struct {
 	ID             int        `json:"id"`
 	CardNumber     string     `json:"card_number"`
 	TotalBalance   int        `json:"total_balance"`
+	HeldBalance    int        `json:"held_balance"`
+	Available      int        `json:"available_balance"`
 	WithdrawAmount *int       `json:"withdraw_amount"`
 	WithdrawTime   *time.Time `json:"withdraw_time"`
 }
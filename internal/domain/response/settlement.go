@@ -0,0 +1,45 @@
+package response
+
+import "time"
+
+type SettlementBatchResponse struct {
+	ID           int        `json:"id"`
+	MerchantID   int        `json:"merchant_id"`
+	CardNumber   string     `json:"card_number"`
+	Cutoff       time.Time  `json:"cutoff"`
+	Status       string     `json:"status"`
+	ItemCount    int        `json:"item_count"`
+	GrossAmount  int        `json:"gross_amount"`
+	RefundAmount int        `json:"refund_amount"`
+	FeeAmount    int        `json:"fee_amount"`
+	NetAmount    int        `json:"net_amount"`
+	Error        string     `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	PaidAt       *time.Time `json:"paid_at"`
+	// Items is only filled when a single batch is asked for.
+	Items []*SettlementItemResponse `json:"items,omitempty"`
+}
+
+type SettlementItemResponse struct {
+	ID            int       `json:"id"`
+	TransactionID int       `json:"transaction_id"`
+	Type          string    `json:"type"`
+	ReferenceID   int       `json:"reference_id"`
+	Amount        int       `json:"amount"`
+	Fee           int       `json:"fee"`
+	NetAmount     int       `json:"net_amount"`
+	BatchID       *int      `json:"batch_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PendingSettlementResponse is what a merchant has accrued and not been paid
+// out yet.
+type PendingSettlementResponse struct {
+	MerchantID     int                       `json:"merchant_id"`
+	SettlementMode string                    `json:"settlement_mode"`
+	PendingBalance int                       `json:"pending_balance"`
+	FeeAmount      int                       `json:"fee_amount"`
+	NetAmount      int                       `json:"net_amount"`
+	NextCutoff     time.Time                 `json:"next_cutoff"`
+	Items          []*SettlementItemResponse `json:"items"`
+}
@@ -0,0 +1,8 @@
+package response
+
+import "time"
+
+type StatusChangeResponse struct {
+	Status    string    `json:"status"`
+	ChangedAt time.Time `json:"changed_at"`
+}
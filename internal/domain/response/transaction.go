@@ -3,10 +3,13 @@ package response
 import "time"
 
 type TransactionResponse struct {
-	ID              int       `json:"id"`
-	UserID          int       `json:"user_id"`
-	CardNumber      string    `json:"card_number"`
-	Amount          int       `json:"amount"`
-	PaymentMethod   string    `json:"payment_method"`
-	TransactionTime time.Time `json:"transaction_time"`
+	ID              int                    `json:"id"`
+	UserID          int                    `json:"user_id"`
+	CardNumber      string                 `json:"card_number"`
+	Amount          int                    `json:"amount"`
+	Fee             int                    `json:"fee"`
+	PaymentMethod   string                 `json:"payment_method"`
+	TransactionTime time.Time              `json:"transaction_time"`
+	Status          string                 `json:"status"`
+	StatusHistory   []StatusChangeResponse `json:"status_history"`
 }
@@ -1,9 +1,13 @@
 package response
 
 type UserResponse struct {
-	ID          int    `json:"user_id"`
-	FirstName   string `json:"firstname"`
-	LastName    string `json:"lastname"`
-	Email       string `json:"email"`
-	NocTransfer int    `json:"noc_transfer"`
+	ID               int    `json:"user_id"`
+	FirstName        string `json:"firstname"`
+	LastName         string `json:"lastname"`
+	Email            string `json:"email"`
+	Role             string `json:"role"`
+	Status           string `json:"status"`
+	NocTransfer      int    `json:"noc_transfer"`
+	HasPin           bool   `json:"has_pin"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
 }
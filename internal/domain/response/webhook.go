@@ -0,0 +1,54 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent is the body of every webhook sent to a merchant.
+type WebhookEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	MerchantID int       `json:"merchant_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	Data       any       `json:"data"`
+}
+
+type WebhookEndpointResponse struct {
+	ID         int       `json:"id"`
+	MerchantID int       `json:"merchant_id"`
+	URL        string    `json:"url"`
+	Events     []string  `json:"events"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// WebhookSecret is set when registering the endpoint created the
+	// merchant's first webhook secret.
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID            int                       `json:"id"`
+	EndpointID    int                       `json:"endpoint_id"`
+	EventID       string                    `json:"event_id"`
+	EventType     string                    `json:"event_type"`
+	Payload       json.RawMessage           `json:"payload"`
+	Status        string                    `json:"status"`
+	Attempts      []*WebhookAttemptResponse `json:"attempts"`
+	NextAttemptAt *time.Time                `json:"next_attempt_at"`
+	RedeliveryOf  *int                      `json:"redelivery_of"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	UpdatedAt     time.Time                 `json:"updated_at"`
+}
+
+type WebhookAttemptResponse struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error"`
+	DurationMs  int64     `json:"duration_ms"`
+}
+
+type WebhookSecretResponse struct {
+	MerchantID    int    `json:"merchant_id"`
+	WebhookSecret string `json:"webhook_secret"`
+}
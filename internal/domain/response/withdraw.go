@@ -3,8 +3,10 @@ package response
 import "time"
 
 type WithdrawResponse struct {
-	ID             int       `json:"id"`
-	CardNumber     string    `json:"card_number"`
-	WithdrawAmount int       `json:"withdraw_amount"`
-	WithdrawTime   time.Time `json:"withdraw_time"`
+	ID             int                    `json:"id"`
+	CardNumber     string                 `json:"card_number"`
+	WithdrawAmount int                    `json:"withdraw_amount"`
+	WithdrawTime   time.Time              `json:"withdraw_time"`
+	Status         string                 `json:"status"`
+	StatusHistory  []StatusChangeResponse `json:"status_history"`
 }
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+)
+
+func (h *handler) verifyEmail(w http.ResponseWriter, r *http.Request) {
+	var verify requests.VerifyEmailRequest
+
+	// GET untuk link di email, POST untuk klien yang mengirim token sendiri
+	switch r.Method {
+	case http.MethodGet:
+		verify.Token = r.URL.Query().Get("token")
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&verify); err != nil {
+			res := response.ErrorResponse{
+				Status:  "error",
+				Message: "Error invalid request",
+			}
+			response.ResponseError(w, res)
+			return
+		}
+	default:
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := verify.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Account.VerifyEmail(verify)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) resendVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var resend requests.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&resend); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := resend.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Account.ResendVerification(resend)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) forgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var forgot requests.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&forgot); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := forgot.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Account.ForgotPassword(forgot)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) resetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var reset requests.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&reset); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := reset.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Account.ResetPassword(reset)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
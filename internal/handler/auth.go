@@ -6,11 +6,21 @@ import (
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
 	"payment-mutex/internal/middleware"
+	"payment-mutex/pkg/auth"
 )
 
 func (h *handler) initAuthGroup(prefix string, router *http.ServeMux) {
 	router.Handle(prefix+"/login", middleware.Middleware(http.HandlerFunc(h.login)))
+	router.Handle(prefix+"/login/verify", middleware.Middleware(http.HandlerFunc(h.verifyLogin)))
 	router.Handle(prefix+"/register", middleware.Middleware(http.HandlerFunc(h.register)))
+	router.Handle(prefix+"/verify-email", middleware.Middleware(http.HandlerFunc(h.verifyEmail)))
+	router.Handle(prefix+"/resend-verification", middleware.Middleware(http.HandlerFunc(h.resendVerification)))
+	router.Handle(prefix+"/forgot-password", middleware.Middleware(http.HandlerFunc(h.forgotPassword)))
+	router.Handle(prefix+"/reset-password", middleware.Middleware(http.HandlerFunc(h.resetPassword)))
+	router.Handle(prefix+"/refresh", middleware.Middleware(http.HandlerFunc(h.refresh)))
+	router.Handle(prefix+"/logout", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.logout), h.verifier))
+	router.Handle(prefix+"/2fa/enroll", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.enrollTotp), allRoles...), h.verifier))
+	router.Handle(prefix+"/2fa/confirm", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.confirmTotp), allRoles...), h.verifier))
 }
 
 func (h *handler) login(w http.ResponseWriter, r *http.Request) {
@@ -42,13 +52,9 @@ func (h *handler) login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.services.Auth.Login(&login)
-	if err != nil {
-		res := response.ErrorResponse{
-			Status:  "error",
-			Message: "Error login user",
-		}
-		response.ResponseError(w, res)
+	res, errRes := h.services.Auth.Login(&login, h.clientIP(r))
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
 		return
 	}
 
@@ -96,3 +102,60 @@ func (h *handler) register(w http.ResponseWriter, r *http.Request) {
 
 	response.ResponseMessage(w, *res)
 }
+
+func (h *handler) refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var refresh requests.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&refresh); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := refresh.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Auth.Refresh(refresh)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Auth.Logout(callerFromRequest(r), auth.GetContextSessionId(r))
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
@@ -11,11 +11,11 @@ import (
 )
 
 func (h *handler) initCardGroup(prefix string, router *http.ServeMux) {
-	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindAllCard)))
-	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindByIdCard)))
-	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.CreateCard)))
-	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.UpdateCard)))
-	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.DeleteCard)))
+	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllCard), adminRoles...), h.verifier))
+	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindByIdCard), allRoles...), h.verifier))
+	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.CreateCard), allRoles...), h.verifier))
+	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateCard), allRoles...), h.verifier))
+	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.DeleteCard), adminRoles...), h.verifier))
 }
 
 func (h *handler) FindAllCard(w http.ResponseWriter, r *http.Request) {
@@ -74,7 +74,7 @@ func (h *handler) FindByIdCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, errRes := h.services.Card.FindById(id)
+	res, errRes := h.services.Card.FindById(callerFromRequest(r), id)
 
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
@@ -117,7 +117,7 @@ func (h *handler) CreateCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.services.Card.Create(createCard)
+	res, err := h.services.Card.Create(callerFromRequest(r), createCard)
 
 	if err != nil {
 		res := response.ErrorResponse{
@@ -163,7 +163,7 @@ func (h *handler) UpdateCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.services.Card.Update(updateCard)
+	res, err := h.services.Card.Update(callerFromRequest(r), updateCard)
 
 	if err != nil {
 		res := response.ErrorResponse{
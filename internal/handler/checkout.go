@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/middleware"
+	"payment-mutex/internal/models"
+)
+
+func (h *handler) initCheckoutGroup(prefix string, router *http.ServeMux) {
+	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(middleware.MerchantMiddleware(middleware.MerchantSignatureMiddleware(http.HandlerFunc(h.CreateCheckoutSession), h.services.Merchant), h.services.Merchant, models.ApiKeyScopeCharge), h.idempotency), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/merchant_find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MerchantMiddleware(middleware.MerchantSignatureMiddleware(http.HandlerFunc(h.FindByIdMerchantCheckoutSession), h.services.Merchant), h.services.Merchant, models.ApiKeyScopeRead), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindByIdCheckoutSession), allRoles...), h.verifier))
+	router.Handle(prefix+"/confirm", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(http.HandlerFunc(h.ConfirmCheckoutSession), h.idempotency), allRoles...), h.verifier))
+}
+
+func (h *handler) CreateCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey{}).(int)
+	if !ok || merchantID == 0 {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var createSession requests.CreateCheckoutSessionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&createSession); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := createSession.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Checkout.Create(merchantID, createSession)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) FindByIdMerchantCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey{}).(int)
+	if !ok || merchantID == 0 {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Checkout session id is required",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Checkout.FindByMerchant(merchantID, id)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) FindByIdCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Checkout session id is required",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Checkout.Find(callerFromRequest(r), id)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) ConfirmCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var confirmSession requests.ConfirmCheckoutSessionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&confirmSession); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := confirmSession.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Checkout.Confirm(callerFromRequest(r), confirmSession)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"strconv"
+)
+
+func (h *handler) FindAllFeePlans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.FeePlan.FindAll()
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) FindFeePlanByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.FeePlan.FindByID(id)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) CreateFeePlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var createFeePlan requests.CreateFeePlanRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&createFeePlan); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := createFeePlan.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.FeePlan.Create(createFeePlan)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) UpdateFeePlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var updateFeePlan requests.UpdateFeePlanRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&updateFeePlan); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := updateFeePlan.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.FeePlan.Update(updateFeePlan)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) DeleteFeePlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid ID format",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.FeePlan.Delete(id)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) AssignMerchantFeePlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var assignFeePlan requests.AssignFeePlanRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&assignFeePlan); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := assignFeePlan.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.FeePlan.Assign(assignFeePlan)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) PreviewMerchantFee(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, err := strconv.Atoi(r.URL.Query().Get("merchant_id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert merchant_id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	amount, err := strconv.Atoi(r.URL.Query().Get("amount"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert amount",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.FeePlan.Preview(callerFromRequest(r), merchantID, amount, r.URL.Query().Get("payment_method"))
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
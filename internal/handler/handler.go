@@ -1,17 +1,49 @@
 package handler
 
 import (
+	"net"
 	"net/http"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/models"
 	"payment-mutex/internal/service"
+	"payment-mutex/pkg/auth"
+	"payment-mutex/pkg/idempotency"
+	"strconv"
+	"strings"
 )
 
+// Roles allowed on a route. Balance adjustments, corrections and user
+// management are admin only, merchant side operations need a merchant owner
+// and the rest is open to every signed in user.
+var (
+	adminRoles    = []string{models.RoleAdmin}
+	merchantRoles = []string{models.RoleAdmin, models.RoleMerchantOwner}
+	allRoles      = []string{models.RoleAdmin, models.RoleMerchantOwner, models.RoleCustomer}
+)
+
+type Deps struct {
+	Services    *service.Services
+	Idempotency *idempotency.Store
+	Verifier    *auth.Verifier
+	// TrustProxyHeaders takes the client address from X-Forwarded-For. Only
+	// turn it on behind a proxy that sets the header, clients can fake it.
+	TrustProxyHeaders bool
+}
+
 type handler struct {
-	services *service.Services
+	services          *service.Services
+	idempotency       *idempotency.Store
+	verifier          *auth.Verifier
+	trustProxyHeaders bool
 }
 
-func NewHandler(services *service.Services) *handler {
+func NewHandler(deps Deps) *handler {
 	return &handler{
-		services: services,
+		services:    deps.Services,
+		idempotency: deps.Idempotency,
+		verifier:    deps.Verifier,
+
+		trustProxyHeaders: deps.TrustProxyHeaders,
 	}
 }
 
@@ -31,13 +63,46 @@ func (h *handler) Init() *http.ServeMux {
 
 func (h *handler) InitApi(r *http.ServeMux) {
 	h.initAuthGroup("/auth", r)
+	h.initWellKnownGroup("/.well-known", r)
 	h.initSaldoGroup("/saldo", r)
 	h.initTopupGroup("/topup", r)
 	h.initTransferGroup("/transfer", r)
 	h.InitWithdrawGroup("/withdraw", r)
 	h.initUserGroup("/user", r)
+	h.initPinGroup("/pin", r)
 	h.initTransactionGroup("/transaction", r)
+	h.initCheckoutGroup("/checkout", r)
 	h.initCardGroup("/card", r)
 	h.InitMerchantGroup("/merchant", r)
 	h.initDashboardGroup("/dashboard", r)
+	h.initLedgerGroup("/ledger", r)
+}
+
+// callerFromRequest returns the user the auth middleware signed the request
+// in as.
+func callerFromRequest(r *http.Request) requests.Caller {
+	userID, _ := strconv.Atoi(auth.GetContextUserId(r))
+
+	return requests.Caller{
+		UserID: userID,
+		Role:   auth.GetContextRole(r),
+	}
+}
+
+// clientIP returns the address the request came from.
+func (h *handler) clientIP(r *http.Request) string {
+	if h.trustProxyHeaders {
+		// Alamat pertama adalah klien, sisanya proxy yang dilewati
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
 }
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/middleware"
+	"strconv"
+)
+
+func (h *handler) initLedgerGroup(prefix string, router *http.ServeMux) {
+	router.Handle(prefix+"/postings", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindPostingsLedger), adminRoles...), h.verifier))
+	router.Handle(prefix+"/recompute", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.RecomputeBalanceLedger), adminRoles...), h.verifier))
+}
+
+func (h *handler) FindPostingsLedger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	cardNumber := r.URL.Query().Get("card_number")
+	if cardNumber == "" {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Card number is required",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 10
+	}
+
+	res, errRes := h.services.Ledger.FindPostingsByCardNumber(cardNumber, page, pageSize)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) RecomputeBalanceLedger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	cardNumber := r.URL.Query().Get("card_number")
+	if cardNumber == "" {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Card number is required",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Ledger.RecomputeBalance(cardNumber)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
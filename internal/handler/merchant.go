@@ -11,12 +11,44 @@ import (
 )
 
 func (h *handler) InitMerchantGroup(prefix string, router *http.ServeMux) {
-	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindAllMerchant)))
-	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindMerchantByID)))
-	router.Handle(prefix+"/find_by_name", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindMerchantByName)))
-	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.CreateMerchant)))
-	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.UpdateMerchant)))
-	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.DeleteMerchant)))
+	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllMerchant), allRoles...), h.verifier))
+	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindMerchantByID), allRoles...), h.verifier))
+	router.Handle(prefix+"/find_by_name", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindMerchantByName), allRoles...), h.verifier))
+	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.CreateMerchant), allRoles...), h.verifier))
+	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateMerchant), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/update_owner", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateMerchantOwner), adminRoles...), h.verifier))
+	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.DeleteMerchant), adminRoles...), h.verifier))
+	router.Handle(prefix+"/submit", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.SubmitMerchant), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/approve", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.ApproveMerchant), adminRoles...), h.verifier))
+	router.Handle(prefix+"/reject", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.RejectMerchant), adminRoles...), h.verifier))
+	router.Handle(prefix+"/suspend", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.SuspendMerchant), adminRoles...), h.verifier))
+	router.Handle(prefix+"/close", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.CloseMerchant), adminRoles...), h.verifier))
+	router.Handle(prefix+"/status_history", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindMerchantStatusHistory), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/api_keys/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllMerchantApiKeys), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/api_keys/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.CreateMerchantApiKey), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/api_keys/rotate", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.RotateMerchantApiKey), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/api_keys/revoke", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.RevokeMerchantApiKey), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/signing_secret/rotate", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.RotateMerchantSigningSecret), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/signing_secret/require", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.RequireMerchantSignature), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/webhooks/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllMerchantWebhooks), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/webhooks/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.CreateMerchantWebhook), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/webhooks/update", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateMerchantWebhook), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/webhooks/delete", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.DeleteMerchantWebhook), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/webhooks/rotate_secret", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.RotateMerchantWebhookSecret), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/webhooks/deliveries", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindMerchantWebhookDeliveries), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/webhooks/redeliver", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.RedeliverMerchantWebhook), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/settlements/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllMerchantSettlements), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/settlements/find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindMerchantSettlementByID), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/settlements/pending", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindMerchantPendingSettlement), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/settlements/mode", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateMerchantSettlementMode), adminRoles...), h.verifier))
+	router.Handle(prefix+"/settlements/close", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.CloseMerchantSettlement), adminRoles...), h.verifier))
+	router.Handle(prefix+"/fee_plans/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllFeePlans), adminRoles...), h.verifier))
+	router.Handle(prefix+"/fee_plans/find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindFeePlanByID), adminRoles...), h.verifier))
+	router.Handle(prefix+"/fee_plans/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.CreateFeePlan), adminRoles...), h.verifier))
+	router.Handle(prefix+"/fee_plans/update", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateFeePlan), adminRoles...), h.verifier))
+	router.Handle(prefix+"/fee_plans/delete", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.DeleteFeePlan), adminRoles...), h.verifier))
+	router.Handle(prefix+"/fee_plans/assign", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.AssignMerchantFeePlan), adminRoles...), h.verifier))
+	router.Handle(prefix+"/fees/preview", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.PreviewMerchantFee), merchantRoles...), h.verifier))
 
 }
 
@@ -44,7 +76,7 @@ func (h *handler) FindAllMerchant(w http.ResponseWriter, r *http.Request) {
 
 	res, errRes := h.services.Merchant.FindAll(page, pageSize, search)
 
-	if err != nil {
+	if errRes != nil {
 
 		response.ResponseError(w, *errRes)
 		return
@@ -142,7 +174,7 @@ func (h *handler) CreateMerchant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.services.Merchant.Create(req)
+	res, err := h.services.Merchant.Create(callerFromRequest(r), req)
 	if err != nil {
 		response.ResponseError(w, *err)
 		return
@@ -173,7 +205,45 @@ func (h *handler) UpdateMerchant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.services.Merchant.Update(req)
+	res, err := h.services.Merchant.Update(callerFromRequest(r), req)
+	if err != nil {
+		response.ResponseError(w, *err)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) UpdateMerchantOwner(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var req requests.UpdateMerchantOwnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid input",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, err := h.services.Merchant.UpdateOwner(req)
 	if err != nil {
 		response.ResponseError(w, *err)
 		return
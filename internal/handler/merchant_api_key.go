@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"strconv"
+)
+
+func (h *handler) FindAllMerchantApiKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, err := strconv.Atoi(r.URL.Query().Get("merchant_id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert merchant_id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Merchant.FindApiKeys(callerFromRequest(r), merchantID)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) CreateMerchantApiKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var createApiKey requests.CreateApiKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&createApiKey); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := createApiKey.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Merchant.CreateApiKey(callerFromRequest(r), createApiKey)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) RotateMerchantApiKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var rotateApiKey requests.RotateApiKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&rotateApiKey); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := rotateApiKey.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Merchant.RotateApiKey(callerFromRequest(r), rotateApiKey)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) RevokeMerchantApiKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var revokeApiKey requests.RevokeApiKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&revokeApiKey); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := revokeApiKey.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Merchant.RevokeApiKey(callerFromRequest(r), revokeApiKey)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
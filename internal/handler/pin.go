@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/middleware"
+)
+
+func (h *handler) initPinGroup(prefix string, router *http.ServeMux) {
+	router.Handle(prefix+"/set", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.SetPin), allRoles...), h.verifier))
+	router.Handle(prefix+"/change", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.ChangePin), allRoles...), h.verifier))
+}
+
+func (h *handler) SetPin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var setPin requests.SetPinRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&setPin); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := setPin.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Pin.SetPin(callerFromRequest(r), setPin)
+
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) ChangePin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var changePin requests.ChangePinRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&changePin); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := changePin.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Pin.ChangePin(callerFromRequest(r), changePin)
+
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
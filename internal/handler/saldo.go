@@ -6,16 +6,18 @@ import (
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
 	"payment-mutex/internal/middleware"
+	"payment-mutex/internal/models"
+	"payment-mutex/pkg/auth"
 	"strconv"
 )
 
 func (h *handler) initSaldoGroup(prefix string, router *http.ServeMux) {
-	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindAllSaldo)))
-	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindByIdSaldo)))
+	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllSaldo), adminRoles...), h.verifier))
+	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindByIdSaldo), allRoles...), h.verifier))
 
-	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.CreateSaldo)))
-	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.UpdateSaldo)))
-	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.DeleteSaldo)))
+	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.CreateSaldo), allRoles...), h.verifier))
+	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateSaldo), adminRoles...), h.verifier))
+	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.DeleteSaldo), adminRoles...), h.verifier))
 }
 
 func (h *handler) FindAllSaldo(w http.ResponseWriter, r *http.Request) {
@@ -71,7 +73,7 @@ func (h *handler) FindByIdSaldo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiRes, errRes := h.services.Saldo.FindById(id)
+	apiRes, errRes := h.services.Saldo.FindById(callerFromRequest(r), id)
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
 		return
@@ -110,7 +112,17 @@ func (h *handler) CreateSaldo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiRes, errRes := h.services.Saldo.Create(createSaldo)
+	// Saldo awal adalah penyesuaian saldo, jadi hanya admin yang boleh mengisinya
+	if createSaldo.TotalBalance != 0 && auth.GetContextRole(r) != models.RoleAdmin {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Only admins can open a saldo with a balance",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	apiRes, errRes := h.services.Saldo.Create(callerFromRequest(r), createSaldo)
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
 		return
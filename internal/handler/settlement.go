@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"strconv"
+)
+
+func (h *handler) FindAllMerchantSettlements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, err := strconv.Atoi(r.URL.Query().Get("merchant_id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert merchant_id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 10
+	}
+
+	res, errRes := h.services.Settlement.FindBatches(callerFromRequest(r), merchantID, page, pageSize)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) FindMerchantSettlementByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Settlement.FindBatch(callerFromRequest(r), id)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) FindMerchantPendingSettlement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, err := strconv.Atoi(r.URL.Query().Get("merchant_id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert merchant_id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Settlement.FindPending(callerFromRequest(r), merchantID)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) UpdateMerchantSettlementMode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var updateMode requests.UpdateSettlementModeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&updateMode); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := updateMode.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Settlement.UpdateMode(updateMode)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) CloseMerchantSettlement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var closeSettlement requests.CloseSettlementRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&closeSettlement); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := closeSettlement.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Settlement.Close(closeSettlement)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
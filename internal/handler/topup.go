@@ -10,12 +10,13 @@ import (
 )
 
 func (h *handler) initTopupGroup(prefix string, router *http.ServeMux) {
-	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindAllTopup)))
-	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindByIdTopup)))
+	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllTopup), adminRoles...), h.verifier))
+	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindByIdTopup), allRoles...), h.verifier))
 
-	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.CreateTopup)))
-	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.UpdateTopup)))
-	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.DeleteTopup)))
+	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(http.HandlerFunc(h.CreateTopup), h.idempotency), allRoles...), h.verifier))
+	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateTopup), adminRoles...), h.verifier))
+	router.Handle(prefix+"/update_status", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateStatusTopup), adminRoles...), h.verifier))
+	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.DeleteTopup), adminRoles...), h.verifier))
 }
 
 func (h *handler) FindAllTopup(w http.ResponseWriter, r *http.Request) {
@@ -39,8 +40,9 @@ func (h *handler) FindAllTopup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	search := r.URL.Query().Get("search")
+	status := r.URL.Query().Get("status")
 
-	res, errRes := h.services.Topup.FindAll(page, pageSize, search)
+	res, errRes := h.services.Topup.FindAll(page, pageSize, search, status)
 
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
@@ -72,7 +74,7 @@ func (h *handler) FindByIdTopup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, errRes := h.services.Topup.FindById(id)
+	res, errRes := h.services.Topup.FindById(callerFromRequest(r), id)
 
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
@@ -110,7 +112,7 @@ func (h *handler) CreateTopup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, errRes := h.services.Topup.Create(createTopup)
+	res, errRes := h.services.Topup.Create(callerFromRequest(r), createTopup)
 
 	if errRes != nil {
 
@@ -173,6 +175,57 @@ func (h *handler) UpdateTopup(w http.ResponseWriter, r *http.Request) {
 	response.ResponseMessage(w, *res)
 }
 
+func (h *handler) UpdateStatusTopup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var updateStatus requests.UpdateStatusRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&updateStatus); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	updateStatus.ID = id
+
+	if err := updateStatus.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Topup.UpdateStatus(updateStatus)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
 func (h *handler) DeleteTopup(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		res := response.ErrorResponse{
@@ -6,15 +6,22 @@ import (
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
 	"payment-mutex/internal/middleware"
+	"payment-mutex/internal/models"
 	"strconv"
 )
 
 func (h *handler) initTransactionGroup(prefix string, router *http.ServeMux) {
-	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindAllTransaction)))
-	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindByIdTransaction)))
-	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(middleware.MerchantMiddleware(http.HandlerFunc(h.CreateTransaction), h.services.Merchant)))
-	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(middleware.MerchantMiddleware(http.HandlerFunc(h.UpdateTransaction), h.services.Merchant)))
-	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.DeleteTransaction)))
+	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllTransaction), adminRoles...), h.verifier))
+	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindByIdTransaction), allRoles...), h.verifier))
+	router.Handle(prefix+"/merchant_find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MerchantMiddleware(middleware.MerchantSignatureMiddleware(http.HandlerFunc(h.FindByIdMerchantTransaction), h.services.Merchant), h.services.Merchant, models.ApiKeyScopeRead), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(middleware.MerchantMiddleware(middleware.MerchantSignatureMiddleware(http.HandlerFunc(h.CreateTransaction), h.services.Merchant), h.services.Merchant, models.ApiKeyScopeCharge), h.idempotency), allRoles...), h.verifier))
+	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MerchantMiddleware(middleware.MerchantSignatureMiddleware(http.HandlerFunc(h.UpdateTransaction), h.services.Merchant), h.services.Merchant, models.ApiKeyScopeCharge), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/refund", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(middleware.MerchantMiddleware(middleware.MerchantSignatureMiddleware(http.HandlerFunc(h.RefundTransaction), h.services.Merchant), h.services.Merchant, models.ApiKeyScopeRefund), h.idempotency), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/authorize", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(middleware.MerchantMiddleware(middleware.MerchantSignatureMiddleware(http.HandlerFunc(h.AuthorizeTransaction), h.services.Merchant), h.services.Merchant, models.ApiKeyScopeCharge), h.idempotency), allRoles...), h.verifier))
+	router.Handle(prefix+"/capture", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(middleware.MerchantMiddleware(middleware.MerchantSignatureMiddleware(http.HandlerFunc(h.CaptureTransaction), h.services.Merchant), h.services.Merchant, models.ApiKeyScopeCharge), h.idempotency), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/void", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MerchantMiddleware(middleware.MerchantSignatureMiddleware(http.HandlerFunc(h.VoidTransaction), h.services.Merchant), h.services.Merchant, models.ApiKeyScopeCharge), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/update_status", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MerchantMiddleware(middleware.MerchantSignatureMiddleware(http.HandlerFunc(h.UpdateStatusTransaction), h.services.Merchant), h.services.Merchant, models.ApiKeyScopeCharge), merchantRoles...), h.verifier))
+	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.DeleteTransaction), adminRoles...), h.verifier))
 }
 
 func (h *handler) FindAllTransaction(w http.ResponseWriter, r *http.Request) {
@@ -38,8 +45,9 @@ func (h *handler) FindAllTransaction(w http.ResponseWriter, r *http.Request) {
 	}
 
 	search := r.URL.Query().Get("search")
+	status := r.URL.Query().Get("status")
 
-	res, errRes := h.services.Transaction.FindAll(page, pageSize, search)
+	res, errRes := h.services.Transaction.FindAll(page, pageSize, search, status)
 
 	if errRes != nil {
 
@@ -71,7 +79,46 @@ func (h *handler) FindByIdTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, errRes := h.services.Transaction.FindById(id)
+	res, errRes := h.services.Transaction.FindById(callerFromRequest(r), id)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) FindByIdMerchantTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey{}).(int)
+	if !ok || merchantID == 0 {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Transaction.FindByMerchant(merchantID, id)
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
 		return
@@ -90,9 +137,9 @@ func (h *handler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiKey, ok := r.Context().Value(middleware.ApiKeyKey{}).(string)
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey{}).(int)
 
-	if !ok || apiKey == "" {
+	if !ok || merchantID == 0 {
 		res := response.ErrorResponse{
 			Status:  "error",
 			Message: "Unauthorized",
@@ -121,7 +168,7 @@ func (h *handler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, errRes := h.services.Transaction.Create(apiKey, createTransaction)
+	res, errRes := h.services.Transaction.Create(merchantID, createTransaction)
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
 		return
@@ -140,8 +187,8 @@ func (h *handler) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiKey, ok := r.Context().Value(middleware.ApiKeyKey{}).(string)
-	if !ok || apiKey == "" {
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey{}).(int)
+	if !ok || merchantID == 0 {
 		res := response.ErrorResponse{
 			Status:  "error",
 			Message: "Unauthorized",
@@ -170,7 +217,264 @@ func (h *handler) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, errRes := h.services.Transaction.Update(apiKey, updateTransaction)
+	res, errRes := h.services.Transaction.Update(merchantID, updateTransaction)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) RefundTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey{}).(int)
+	if !ok || merchantID == 0 {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var createRefund requests.CreateRefundRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&createRefund); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := createRefund.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Transaction.Refund(merchantID, createRefund)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) AuthorizeTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey{}).(int)
+	if !ok || merchantID == 0 {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var createAuthorization requests.CreateAuthorizationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&createAuthorization); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := createAuthorization.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Transaction.Authorize(merchantID, createAuthorization)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) CaptureTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey{}).(int)
+	if !ok || merchantID == 0 {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var captureAuthorization requests.CaptureAuthorizationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&captureAuthorization); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := captureAuthorization.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Transaction.Capture(merchantID, captureAuthorization)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) VoidTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey{}).(int)
+	if !ok || merchantID == 0 {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var voidAuthorization requests.VoidAuthorizationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&voidAuthorization); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := voidAuthorization.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Transaction.Void(merchantID, voidAuthorization)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) UpdateStatusTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	merchantID, ok := r.Context().Value(middleware.MerchantIDKey{}).(int)
+	if !ok || merchantID == 0 {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var updateStatus requests.UpdateStatusRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&updateStatus); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	updateStatus.ID = id
+
+	if err := updateStatus.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Transaction.UpdateStatus(merchantID, updateStatus)
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
 		return
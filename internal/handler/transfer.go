@@ -10,11 +10,12 @@ import (
 )
 
 func (h *handler) initTransferGroup(prefix string, router *http.ServeMux) {
-	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindAllTransfer)))
-	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindByIdTransfer)))
-	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.CreateTransfer)))
-	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.UpdateTransfer)))
-	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.DeleteTransfer)))
+	router.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllTransfer), adminRoles...), h.verifier))
+	router.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindByIdTransfer), allRoles...), h.verifier))
+	router.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(http.HandlerFunc(h.CreateTransfer), h.idempotency), allRoles...), h.verifier))
+	router.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateTransfer), adminRoles...), h.verifier))
+	router.Handle(prefix+"/update_status", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateStatusTransfer), adminRoles...), h.verifier))
+	router.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.DeleteTransfer), adminRoles...), h.verifier))
 }
 
 func (h *handler) FindAllTransfer(w http.ResponseWriter, r *http.Request) {
@@ -38,8 +39,9 @@ func (h *handler) FindAllTransfer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	search := r.URL.Query().Get("search")
+	status := r.URL.Query().Get("status")
 
-	res, errRes := h.services.Transfer.FindAll(page, pageSize, search)
+	res, errRes := h.services.Transfer.FindAll(page, pageSize, search, status)
 
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
@@ -70,7 +72,7 @@ func (h *handler) FindByIdTransfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, errRes := h.services.Transfer.FindById(id)
+	res, errRes := h.services.Transfer.FindById(callerFromRequest(r), id)
 
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
@@ -110,7 +112,7 @@ func (h *handler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, errRes := h.services.Transfer.Create(createTransfer)
+	res, errRes := h.services.Transfer.Create(callerFromRequest(r), createTransfer)
 
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
@@ -173,6 +175,57 @@ func (h *handler) UpdateTransfer(w http.ResponseWriter, r *http.Request) {
 	response.ResponseMessage(w, *res)
 }
 
+func (h *handler) UpdateStatusTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var updateStatus requests.UpdateStatusRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&updateStatus); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	updateStatus.ID = id
+
+	if err := updateStatus.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Transfer.UpdateStatus(updateStatus)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
 func (h *handler) DeleteTransfer(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		res := response.ErrorResponse{
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/middleware"
+)
+
+func (h *handler) initWellKnownGroup(prefix string, router *http.ServeMux) {
+	router.Handle(prefix+"/jwks.json", middleware.Middleware(http.HandlerFunc(h.jwks)))
+}
+
+// jwks serves the public keys tokens are signed with, so other services can
+// verify our tokens without calling us.
+func (h *handler) jwks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	response.ResponseMessage(w, h.verifier.Tokens.JWKS())
+}
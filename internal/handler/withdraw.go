@@ -10,11 +10,15 @@ import (
 )
 
 func (h *handler) InitWithdrawGroup(prefix string, r *http.ServeMux) {
-	r.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindAllWithdraw)))
-	r.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.FindByIdWithdraw)))
-	r.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.CreateWithdraw)))
-	r.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.UpdateWithdraw)))
-	r.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(http.HandlerFunc(h.DeleteWithdraw)))
+	r.Handle(prefix+"/find_all", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindAllWithdraw), adminRoles...), h.verifier))
+	r.Handle(prefix+"/find_by_id", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.FindByIdWithdraw), allRoles...), h.verifier))
+	r.Handle(prefix+"/create", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(http.HandlerFunc(h.CreateWithdraw), h.idempotency), allRoles...), h.verifier))
+	r.Handle(prefix+"/update", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateWithdraw), adminRoles...), h.verifier))
+	r.Handle(prefix+"/update_status", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.UpdateStatusWithdraw), adminRoles...), h.verifier))
+	r.Handle(prefix+"/hold", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(http.HandlerFunc(h.HoldWithdraw), h.idempotency), allRoles...), h.verifier))
+	r.Handle(prefix+"/capture", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(middleware.MiddlewareIdempotency(http.HandlerFunc(h.CaptureWithdraw), h.idempotency), allRoles...), h.verifier))
+	r.Handle(prefix+"/void", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.VoidWithdraw), allRoles...), h.verifier))
+	r.Handle(prefix+"/delete", middleware.MiddlewareAuthAndCors(middleware.MiddlewareRole(http.HandlerFunc(h.DeleteWithdraw), adminRoles...), h.verifier))
 }
 
 func (h *handler) FindAllWithdraw(w http.ResponseWriter, r *http.Request) {
@@ -34,8 +38,9 @@ func (h *handler) FindAllWithdraw(w http.ResponseWriter, r *http.Request) {
 	}
 
 	search := r.URL.Query().Get("search")
+	status := r.URL.Query().Get("status")
 
-	res, errRes := h.services.Withdraw.FindAll(page, pageSize, search)
+	res, errRes := h.services.Withdraw.FindAll(page, pageSize, search, status)
 
 	if errRes != nil {
 		res := response.ErrorResponse{
@@ -72,7 +77,7 @@ func (h *handler) FindByIdWithdraw(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, errRes := h.services.Withdraw.FindById(id)
+	res, errRes := h.services.Withdraw.FindById(callerFromRequest(r), id)
 
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
@@ -112,7 +117,7 @@ func (h *handler) CreateWithdraw(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, errRes := h.services.Withdraw.Create(request)
+	res, errRes := h.services.Withdraw.Create(callerFromRequest(r), request)
 
 	if errRes != nil {
 		response.ResponseError(w, *errRes)
@@ -175,6 +180,174 @@ func (h *handler) UpdateWithdraw(w http.ResponseWriter, r *http.Request) {
 	response.ResponseMessage(w, *res)
 }
 
+func (h *handler) UpdateStatusWithdraw(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error convert id",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var updateStatus requests.UpdateStatusRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&updateStatus); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	updateStatus.ID = id
+
+	if err := updateStatus.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Withdraw.UpdateStatus(updateStatus)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) HoldWithdraw(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var createHold requests.CreateWithdrawHoldRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&createHold); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := createHold.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Withdraw.Hold(callerFromRequest(r), createHold)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) CaptureWithdraw(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var captureHold requests.CaptureWithdrawHoldRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&captureHold); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := captureHold.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Withdraw.Capture(callerFromRequest(r), captureHold)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
+func (h *handler) VoidWithdraw(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Method Not Allowed",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	var voidHold requests.VoidWithdrawHoldRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&voidHold); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	if err := voidHold.Validate(); err != nil {
+		res := response.ErrorResponse{
+			Status:  "error",
+			Message: "Error invalid validate request",
+		}
+		response.ResponseError(w, res)
+		return
+	}
+
+	res, errRes := h.services.Withdraw.Void(callerFromRequest(r), voidHold)
+	if errRes != nil {
+		response.ResponseError(w, *errRes)
+		return
+	}
+
+	response.ResponseMessage(w, *res)
+}
+
 func (h *handler) DeleteWithdraw(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		res := response.ErrorResponse{
@@ -0,0 +1,40 @@
+package ledger
+
+import (
+	"strconv"
+	"strings"
+)
+
+// System accounts sit on the other side of every movement that enters or
+// leaves the card accounts, so each journal entry stays balanced.
+const (
+	AccountTopupFunding       = "system:topup_funding"
+	AccountWithdrawalPayout   = "system:withdrawal_payout"
+	AccountMerchantSettlement = "system:merchant_settlement"
+	AccountBalanceAdjustment  = "system:balance_adjustment"
+	// AccountFeeRevenue collects the fees the platform keeps.
+	AccountFeeRevenue = "system:fee_revenue"
+)
+
+const (
+	cardAccountPrefix            = "card:"
+	merchantPendingAccountPrefix = "merchant_pending:"
+)
+
+func CardAccount(cardNumber string) string {
+	return cardAccountPrefix + cardNumber
+}
+
+func CardNumberFromAccount(account string) (string, bool) {
+	if !strings.HasPrefix(account, cardAccountPrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(account, cardAccountPrefix), true
+}
+
+// MerchantPendingAccount holds the proceeds of a merchant settled in batches
+// until a batch pays them out to its card.
+func MerchantPendingAccount(merchantID int) string {
+	return merchantPendingAccountPrefix + strconv.Itoa(merchantID)
+}
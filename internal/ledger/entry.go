@@ -0,0 +1,213 @@
+package ledger
+
+import (
+	"payment-mutex/internal/domain/requests"
+	"strconv"
+)
+
+// Card accounts hold what the platform owes the card holder, so a credit
+// raises the card balance and a debit lowers it.
+
+const (
+	ReferenceTopup       = "topup"
+	ReferenceWithdraw    = "withdraw"
+	ReferenceTransfer    = "transfer"
+	ReferenceTransaction = "transaction"
+	ReferenceRefund      = "refund"
+	ReferenceAdjustment  = "adjustment"
+	ReferenceSettlement  = "settlement"
+)
+
+func TopupEntry(cardNumber string, amount int, topupID int) requests.CreateJournalEntryRequest {
+	return requests.CreateJournalEntryRequest{
+		Reference:   ReferenceTopup,
+		ReferenceID: topupID,
+		Description: "topup to card " + cardNumber,
+		Postings: []requests.CreatePostingRequest{
+			{Account: AccountTopupFunding, Debit: amount},
+			{Account: CardAccount(cardNumber), Credit: amount},
+		},
+	}
+}
+
+func WithdrawEntry(cardNumber string, amount int, withdrawID int) requests.CreateJournalEntryRequest {
+	return requests.CreateJournalEntryRequest{
+		Reference:   ReferenceWithdraw,
+		ReferenceID: withdrawID,
+		Description: "withdrawal from card " + cardNumber,
+		Postings: []requests.CreatePostingRequest{
+			{Account: CardAccount(cardNumber), Debit: amount},
+			{Account: AccountWithdrawalPayout, Credit: amount},
+		},
+	}
+}
+
+func TransferEntry(from string, to string, amount int, transferID int) requests.CreateJournalEntryRequest {
+	return requests.CreateJournalEntryRequest{
+		Reference:   ReferenceTransfer,
+		ReferenceID: transferID,
+		Description: "transfer from card " + from + " to card " + to,
+		Postings: []requests.CreatePostingRequest{
+			{Account: CardAccount(from), Debit: amount},
+			{Account: CardAccount(to), Credit: amount},
+		},
+	}
+}
+
+// PaymentEntry moves amount from the customer card to the merchant card
+// through the settlement account. The fee part of amount goes to the platform
+// instead of the merchant.
+func PaymentEntry(cardNumber string, merchantCardNumber string, amount int, fee int, transactionID int) requests.CreateJournalEntryRequest {
+	entry := requests.CreateJournalEntryRequest{
+		Reference:   ReferenceTransaction,
+		ReferenceID: transactionID,
+		Description: "payment from card " + cardNumber + " to merchant card " + merchantCardNumber,
+		Postings: []requests.CreatePostingRequest{
+			{Account: CardAccount(cardNumber), Debit: amount},
+			{Account: AccountMerchantSettlement, Credit: amount},
+			{Account: AccountMerchantSettlement, Debit: amount},
+		},
+	}
+
+	if amount > fee {
+		entry.Postings = append(entry.Postings, requests.CreatePostingRequest{Account: CardAccount(merchantCardNumber), Credit: amount - fee})
+	}
+
+	if fee > 0 {
+		entry.Postings = append(entry.Postings, requests.CreatePostingRequest{Account: AccountFeeRevenue, Credit: fee})
+	}
+
+	return entry
+}
+
+// RefundEntry returns funds from the merchant card to the customer card
+// through the settlement account, mirroring PaymentEntry. The fee of the
+// payment is not returned, so the merchant card pays the whole amount.
+func RefundEntry(cardNumber string, merchantCardNumber string, amount int, refundID int) requests.CreateJournalEntryRequest {
+	return requests.CreateJournalEntryRequest{
+		Reference:   ReferenceRefund,
+		ReferenceID: refundID,
+		Description: "refund from merchant card " + merchantCardNumber + " to card " + cardNumber,
+		Postings: []requests.CreatePostingRequest{
+			{Account: CardAccount(merchantCardNumber), Debit: amount},
+			{Account: AccountMerchantSettlement, Credit: amount},
+			{Account: AccountMerchantSettlement, Debit: amount},
+			{Account: CardAccount(cardNumber), Credit: amount},
+		},
+	}
+}
+
+// DeferredPaymentEntry is PaymentEntry for a merchant settled in batches: the
+// funds go to its pending balance instead of its card.
+func DeferredPaymentEntry(cardNumber string, merchantID int, amount int, transactionID int) requests.CreateJournalEntryRequest {
+	return requests.CreateJournalEntryRequest{
+		Reference:   ReferenceTransaction,
+		ReferenceID: transactionID,
+		Description: "payment from card " + cardNumber + " to pending balance of merchant " + strconv.Itoa(merchantID),
+		Postings: []requests.CreatePostingRequest{
+			{Account: CardAccount(cardNumber), Debit: amount},
+			{Account: AccountMerchantSettlement, Credit: amount},
+			{Account: AccountMerchantSettlement, Debit: amount},
+			{Account: MerchantPendingAccount(merchantID), Credit: amount},
+		},
+	}
+}
+
+// DeferredRefundEntry returns funds from the pending balance of a merchant
+// settled in batches to the customer card, mirroring DeferredPaymentEntry.
+func DeferredRefundEntry(cardNumber string, merchantID int, amount int, refundID int) requests.CreateJournalEntryRequest {
+	return requests.CreateJournalEntryRequest{
+		Reference:   ReferenceRefund,
+		ReferenceID: refundID,
+		Description: "refund from pending balance of merchant " + strconv.Itoa(merchantID) + " to card " + cardNumber,
+		Postings: []requests.CreatePostingRequest{
+			{Account: MerchantPendingAccount(merchantID), Debit: amount},
+			{Account: AccountMerchantSettlement, Credit: amount},
+			{Account: AccountMerchantSettlement, Debit: amount},
+			{Account: CardAccount(cardNumber), Credit: amount},
+		},
+	}
+}
+
+// SettlementPayoutEntry pays a settlement batch out of the pending balance of
+// the merchant: net to its card and fee to the platform. Both may be zero but
+// not at the same time.
+func SettlementPayoutEntry(merchantID int, cardNumber string, net int, fee int, batchID int) requests.CreateJournalEntryRequest {
+	entry := requests.CreateJournalEntryRequest{
+		Reference:   ReferenceSettlement,
+		ReferenceID: batchID,
+		Description: "settlement payout from pending balance of merchant " + strconv.Itoa(merchantID) + " to card " + cardNumber,
+		Postings: []requests.CreatePostingRequest{
+			{Account: MerchantPendingAccount(merchantID), Debit: net + fee},
+		},
+	}
+
+	if net > 0 {
+		entry.Postings = append(entry.Postings, requests.CreatePostingRequest{Account: CardAccount(cardNumber), Credit: net})
+	}
+
+	if fee > 0 {
+		entry.Postings = append(entry.Postings, requests.CreatePostingRequest{Account: AccountFeeRevenue, Credit: fee})
+	}
+
+	return entry
+}
+
+// AdjustmentEntry moves a card balance by amount, which may be negative,
+// against the balance adjustment account.
+func AdjustmentEntry(cardNumber string, amount int, saldoID int) requests.CreateJournalEntryRequest {
+	entry := requests.CreateJournalEntryRequest{
+		Reference:   ReferenceAdjustment,
+		ReferenceID: saldoID,
+		Description: "balance adjustment for card " + cardNumber,
+		Postings: []requests.CreatePostingRequest{
+			{Account: AccountBalanceAdjustment, Debit: amount},
+			{Account: CardAccount(cardNumber), Credit: amount},
+		},
+	}
+
+	if amount < 0 {
+		entry.Postings = []requests.CreatePostingRequest{
+			{Account: CardAccount(cardNumber), Debit: -amount},
+			{Account: AccountBalanceAdjustment, Credit: -amount},
+		}
+	}
+
+	return entry
+}
+
+// Reverse returns an entry that undoes every posting of entry.
+func Reverse(entry requests.CreateJournalEntryRequest) requests.CreateJournalEntryRequest {
+	reversed := requests.CreateJournalEntryRequest{
+		Reference:   entry.Reference,
+		ReferenceID: entry.ReferenceID,
+		Description: "reversal of " + entry.Description,
+		Postings:    make([]requests.CreatePostingRequest, 0, len(entry.Postings)),
+	}
+
+	for _, posting := range entry.Postings {
+		reversed.Postings = append(reversed.Postings, requests.CreatePostingRequest{
+			Account: posting.Account,
+			Debit:   posting.Credit,
+			Credit:  posting.Debit,
+		})
+	}
+
+	return reversed
+}
+
+// Combine merges the postings of several entries into a single entry so they
+// are applied to the card balances together.
+func Combine(reference string, referenceID int, description string, entries ...requests.CreateJournalEntryRequest) requests.CreateJournalEntryRequest {
+	combined := requests.CreateJournalEntryRequest{
+		Reference:   reference,
+		ReferenceID: referenceID,
+		Description: description,
+	}
+
+	for _, entry := range entries {
+		combined.Postings = append(combined.Postings, entry.Postings...)
+	}
+
+	return combined
+}
@@ -0,0 +1,254 @@
+package ledger
+
+import (
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/repository"
+	"sync"
+)
+
+// Ledger is the only writer of card balances. Every movement is recorded as a
+// balanced journal entry and Saldo.TotalBalance is kept as a projection of the
+// card account postings.
+type Ledger struct {
+	mu      sync.Mutex
+	journal repository.JournalRepository
+	saldo   repository.SaldoRepository
+	units   repository.Units
+}
+
+func NewLedger(journal repository.JournalRepository, saldo repository.SaldoRepository, units repository.Units) *Ledger {
+	return &Ledger{
+		journal: journal,
+		saldo:   saldo,
+		units:   units,
+	}
+}
+
+func (l *Ledger) Post(entry requests.CreateJournalEntryRequest) (*record.JournalEntryRecord, error) {
+	return l.post(l.Begin(), entry, nil)
+}
+
+// PostWith posts entry together with the calls already staged on unit, so
+// either all of them are applied or none is.
+func (l *Ledger) PostWith(unit *repository.UnitOfWork, entry requests.CreateJournalEntryRequest) (*record.JournalEntryRecord, error) {
+	return l.post(unit, entry, nil)
+}
+
+// Hold reserves amount on a card so debits can no longer use it. A hold does
+// not move money and is not written to the journal.
+func (l *Ledger) Hold(cardNumber string, amount int) error {
+	return l.adjustHeld(cardNumber, amount)
+}
+
+// Release returns a held amount to the available balance of a card.
+func (l *Ledger) Release(cardNumber string, amount int) error {
+	return l.adjustHeld(cardNumber, -amount)
+}
+
+// ReleaseWith is Release with the calls already staged on unit applied
+// together with it.
+func (l *Ledger) ReleaseWith(unit *repository.UnitOfWork, cardNumber string, amount int) error {
+	if amount == 0 {
+		unit.Rollback()
+		return fmt.Errorf("held amount must not be zero")
+	}
+
+	unit.Saldo.UpdateBalances(requests.UpdateSaldoBalances{
+		Changes: []requests.SaldoBalanceChange{
+			{CardNumber: cardNumber, Held: -amount},
+		},
+	})
+
+	return l.Commit(unit)
+}
+
+// Capture releases heldAmount on cardNumber and posts entry in the same step,
+// so the captured funds are never available to another debit in between.
+func (l *Ledger) Capture(cardNumber string, heldAmount int, entry requests.CreateJournalEntryRequest) (*record.JournalEntryRecord, error) {
+	return l.CaptureWith(l.Begin(), cardNumber, heldAmount, entry)
+}
+
+// CaptureWith is Capture with the calls already staged on unit applied
+// together with it.
+func (l *Ledger) CaptureWith(unit *repository.UnitOfWork, cardNumber string, heldAmount int, entry requests.CreateJournalEntryRequest) (*record.JournalEntryRecord, error) {
+	return l.post(unit, entry, []requests.SaldoBalanceChange{
+		{CardNumber: cardNumber, Held: -heldAmount},
+	})
+}
+
+func (l *Ledger) adjustHeld(cardNumber string, amount int) error {
+	if amount == 0 {
+		return fmt.Errorf("held amount must not be zero")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err := l.saldo.UpdateBalances(requests.UpdateSaldoBalances{
+		Changes: []requests.SaldoBalanceChange{
+			{CardNumber: cardNumber, Held: amount},
+		},
+	})
+
+	return err
+}
+
+// Begin starts a unit of work for posting entries together with the records
+// they belong to.
+func (l *Ledger) Begin() *repository.UnitOfWork {
+	return l.units.Begin()
+}
+
+// Stage adds entry to unit, so the balance change and the journal entry are
+// applied by Commit together with whatever else the unit holds. The returned
+// value carries the journal entry once the unit is committed.
+func (l *Ledger) Stage(unit *repository.UnitOfWork, entry requests.CreateJournalEntryRequest) (*repository.Pending[*record.JournalEntryRecord], error) {
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+
+	return l.stage(unit, entry, nil), nil
+}
+
+// Commit applies a unit of work that holds ledger entries. Units that move
+// balances must be committed here rather than directly, so they are
+// serialized with every other ledger write.
+func (l *Ledger) Commit(unit *repository.UnitOfWork) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return unit.Commit()
+}
+
+func (l *Ledger) stage(unit *repository.UnitOfWork, entry requests.CreateJournalEntryRequest, holdChanges []requests.SaldoBalanceChange) *repository.Pending[*record.JournalEntryRecord] {
+	changes := append(projectCardChanges(entry), holdChanges...)
+
+	if len(changes) > 0 {
+		unit.Saldo.UpdateBalances(requests.UpdateSaldoBalances{Changes: changes})
+	}
+
+	return unit.Journal.Create(entry)
+}
+
+func (l *Ledger) post(unit *repository.UnitOfWork, entry requests.CreateJournalEntryRequest, holdChanges []requests.SaldoBalanceChange) (*record.JournalEntryRecord, error) {
+	if err := entry.Validate(); err != nil {
+		unit.Rollback()
+		return nil, err
+	}
+
+	journalEntry := l.stage(unit, entry, holdChanges)
+
+	if err := l.Commit(unit); err != nil {
+		return nil, err
+	}
+
+	return journalEntry.Value(), nil
+}
+
+// Posted reports whether money was moved for the record with referenceID of
+// the kind reference. Only such records can be reversed.
+func (l *Ledger) Posted(reference string, referenceID int) (bool, error) {
+	return l.journal.ExistsByReference(reference, referenceID)
+}
+
+func (l *Ledger) Balance(cardNumber string) (int, error) {
+	totalDebit, totalCredit, err := l.journal.TotalsByAccount(CardAccount(cardNumber))
+	if err != nil {
+		return 0, err
+	}
+
+	return totalCredit - totalDebit, nil
+}
+
+// PendingBalance returns what a merchant settled in batches has accrued and
+// not been paid out yet. It is negative when refunds exceed the payments.
+func (l *Ledger) PendingBalance(merchantID int) (int, error) {
+	totalDebit, totalCredit, err := l.journal.TotalsByAccount(MerchantPendingAccount(merchantID))
+	if err != nil {
+		return 0, err
+	}
+
+	return totalCredit - totalDebit, nil
+}
+
+// SetBalance posts the adjustment that brings the balance of the card of
+// saldoID to balance and returns it. The difference is worked out under the
+// ledger lock, so a posting made at the same time is never overwritten. Nothing
+// is posted when the balance already matches.
+func (l *Ledger) SetBalance(saldoID int, balance int) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	saldo, err := l.saldo.Read(saldoID)
+	if err != nil {
+		return 0, err
+	}
+
+	adjustment := balance - saldo.TotalBalance
+	if adjustment == 0 {
+		return 0, nil
+	}
+
+	entry := AdjustmentEntry(saldo.CardNumber, adjustment, saldo.SaldoID)
+	if err := entry.Validate(); err != nil {
+		return 0, err
+	}
+
+	unit := l.Begin()
+	l.stage(unit, entry, nil)
+
+	if err := unit.Commit(); err != nil {
+		return 0, err
+	}
+
+	return adjustment, nil
+}
+
+// Recompute rebuilds the Saldo projection of a card from the journal and
+// returns the journal balance together with the balance it replaced.
+func (l *Ledger) Recompute(cardNumber string) (int, int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	saldo, err := l.saldo.ReadByCardNumber(cardNumber)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	journalBalance, err := l.Balance(cardNumber)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if journalBalance != saldo.TotalBalance {
+		if _, err := l.saldo.UpdateBalances(requests.UpdateSaldoBalances{
+			Changes: []requests.SaldoBalanceChange{
+				{CardNumber: cardNumber, Amount: journalBalance - saldo.TotalBalance},
+			},
+		}); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return journalBalance, saldo.TotalBalance, nil
+}
+
+func projectCardChanges(entry requests.CreateJournalEntryRequest) []requests.SaldoBalanceChange {
+	changes := make([]requests.SaldoBalanceChange, 0, len(entry.Postings))
+
+	for _, posting := range entry.Postings {
+		cardNumber, ok := CardNumberFromAccount(posting.Account)
+		if !ok {
+			continue
+		}
+
+		changes = append(changes, requests.SaldoBalanceChange{
+			CardNumber: cardNumber,
+			Amount:     posting.Credit - posting.Debit,
+		})
+	}
+
+	return changes
+}
@@ -0,0 +1,268 @@
+package ledger
+
+import (
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/repository"
+	"sync"
+	"testing"
+)
+
+// newTestLedger returns a ledger on in-memory repositories with an empty
+// saldo for card A (saldo 1) and card B (saldo 2).
+func newTestLedger(t *testing.T) (*Ledger, *repository.Repositories) {
+	t.Helper()
+
+	r, err := repository.NewRepositorys(repository.Deps{MapperRecord: *recordmapper.NewRecordMapper(), StorageDriver: repository.StorageDriverMemory})
+	if err != nil {
+		t.Fatalf("open repositories: %v", err)
+	}
+
+	for _, card := range []string{"A", "B"} {
+		if _, err := r.Saldo.Create(requests.CreateSaldoRequest{CardNumber: card}); err != nil {
+			t.Fatalf("create saldo %s: %v", card, err)
+		}
+	}
+
+	return NewLedger(r.Journal, r.Saldo, r), r
+}
+
+// checkInvariants fails t unless every saldo matches the journal, no held
+// balance is negative or larger than the balance, and the journal as a
+// whole is balanced.
+func checkInvariants(t *testing.T, l *Ledger, r *repository.Repositories) {
+	t.Helper()
+
+	sum := 0
+
+	for _, card := range []string{"A", "B"} {
+		saldo, err := r.Saldo.ReadByCardNumber(card)
+		if err != nil {
+			t.Fatalf("read saldo %s: %v", card, err)
+		}
+
+		balance, err := l.Balance(card)
+		if err != nil {
+			t.Fatalf("balance of %s: %v", card, err)
+		}
+
+		if saldo.TotalBalance != balance {
+			t.Errorf("saldo of %s is %d, journal says %d", card, saldo.TotalBalance, balance)
+		}
+
+		if saldo.HeldBalance < 0 || saldo.HeldBalance > saldo.TotalBalance {
+			t.Errorf("held balance of %s is %d with a balance of %d", card, saldo.HeldBalance, saldo.TotalBalance)
+		}
+
+		sum += balance
+	}
+
+	for _, account := range []string{AccountTopupFunding, AccountWithdrawalPayout, AccountBalanceAdjustment} {
+		debit, credit, err := r.Journal.TotalsByAccount(account)
+		if err != nil {
+			t.Fatalf("totals of %s: %v", account, err)
+		}
+
+		sum += credit - debit
+	}
+
+	if sum != 0 {
+		t.Errorf("journal is off by %d", sum)
+	}
+}
+
+func TestLedgerKeepsBalances(t *testing.T) {
+	l, r := newTestLedger(t)
+
+	post := func(entry requests.CreateJournalEntryRequest) func() error {
+		return func() error {
+			_, err := l.Post(entry)
+			return err
+		}
+	}
+
+	// Setiap langkah berjalan di atas hasil langkah sebelumnya
+	steps := []struct {
+		name         string
+		do           func() error
+		wantErr      bool
+		wantA, wantB int
+		heldA, heldB int
+	}{
+		{
+			name:  "topup",
+			do:    post(TopupEntry("A", 100000, 1)),
+			wantA: 100000,
+		},
+		{
+			name:  "transfer",
+			do:    post(TransferEntry("A", "B", 30000, 1)),
+			wantA: 70000, wantB: 30000,
+		},
+		{
+			name:    "withdraw more than the balance",
+			do:      post(WithdrawEntry("B", 50000, 1)),
+			wantErr: true,
+			wantA:   70000, wantB: 30000,
+		},
+		{
+			name:    "unbalanced entry",
+			do:      post(requests.CreateJournalEntryRequest{Reference: ReferenceTopup, ReferenceID: 2, Postings: []requests.CreatePostingRequest{{Account: CardAccount("A"), Credit: 10}}}),
+			wantErr: true,
+			wantA:   70000, wantB: 30000,
+		},
+		{
+			name:  "hold",
+			do:    func() error { return l.Hold("A", 60000) },
+			wantA: 70000, wantB: 30000,
+			heldA: 60000,
+		},
+		{
+			name:    "transfer of held funds",
+			do:      post(TransferEntry("A", "B", 20000, 2)),
+			wantErr: true,
+			wantA:   70000, wantB: 30000,
+			heldA: 60000,
+		},
+		{
+			name:    "hold more than is available",
+			do:      func() error { return l.Hold("A", 20000) },
+			wantErr: true,
+			wantA:   70000, wantB: 30000,
+			heldA: 60000,
+		},
+		{
+			name: "capture",
+			do: func() error {
+				_, err := l.Capture("A", 60000, WithdrawEntry("A", 60000, 2))
+				return err
+			},
+			wantA: 10000, wantB: 30000,
+		},
+		{
+			name:    "release without a hold",
+			do:      func() error { return l.Release("B", 5000) },
+			wantErr: true,
+			wantA:   10000, wantB: 30000,
+		},
+		{
+			name:  "hold on the other card",
+			do:    func() error { return l.Hold("B", 10000) },
+			wantA: 10000, wantB: 30000,
+			heldB: 10000,
+		},
+		{
+			name:  "release with a unit",
+			do:    func() error { return l.ReleaseWith(l.Begin(), "B", 10000) },
+			wantA: 10000, wantB: 30000,
+		},
+		{
+			name:    "release with a unit without a hold",
+			do:      func() error { return l.ReleaseWith(l.Begin(), "B", 10000) },
+			wantErr: true,
+			wantA:   10000, wantB: 30000,
+		},
+		{
+			name: "set balance",
+			do: func() error {
+				_, err := l.SetBalance(2, 50000)
+				return err
+			},
+			wantA: 10000, wantB: 50000,
+		},
+		{
+			name: "set balance lower",
+			do: func() error {
+				_, err := l.SetBalance(2, 5000)
+				return err
+			},
+			wantA: 10000, wantB: 5000,
+		},
+		{
+			name:  "withdraw everything",
+			do:    post(WithdrawEntry("A", 10000, 3)),
+			wantB: 5000,
+		},
+	}
+
+	for _, step := range steps {
+		err := step.do()
+		if (err != nil) != step.wantErr {
+			t.Fatalf("%s: %v, want error = %v", step.name, err, step.wantErr)
+		}
+
+		checkInvariants(t, l, r)
+
+		for _, want := range []struct {
+			card          string
+			balance, held int
+		}{
+			{"A", step.wantA, step.heldA},
+			{"B", step.wantB, step.heldB},
+		} {
+			saldo, err := r.Saldo.ReadByCardNumber(want.card)
+			if err != nil {
+				t.Fatalf("read saldo %s: %v", want.card, err)
+			}
+
+			if saldo.TotalBalance != want.balance || saldo.HeldBalance != want.held {
+				t.Errorf("after %s: %s has %d with %d held, want %d with %d held", step.name, want.card, saldo.TotalBalance, saldo.HeldBalance, want.balance, want.held)
+			}
+		}
+	}
+}
+
+func TestLedgerSetBalanceReportsAdjustment(t *testing.T) {
+	l, r := newTestLedger(t)
+
+	tests := []struct {
+		balance int
+		want    int
+	}{
+		{100, 100},
+		{100, 0},
+		{40, -60},
+		{0, -40},
+	}
+
+	for _, tt := range tests {
+		got, err := l.SetBalance(1, tt.balance)
+		if err != nil {
+			t.Fatalf("set balance to %d: %v", tt.balance, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("set balance to %d: adjustment %d, want %d", tt.balance, got, tt.want)
+		}
+
+		checkInvariants(t, l, r)
+	}
+}
+
+func TestLedgerParallelPostsKeepBalances(t *testing.T) {
+	l, r := newTestLedger(t)
+
+	if _, err := l.Post(TopupEntry("A", 1000, 1)); err != nil {
+		t.Fatalf("topup: %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	// Hanya 10 transfer yang muat di saldo, sisanya harus ditolak
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func(id int) {
+			defer wg.Done()
+			l.Post(TransferEntry("A", "B", 100, id))
+		}(i + 1)
+	}
+
+	wg.Wait()
+
+	checkInvariants(t, l, r)
+
+	if balance, _ := l.Balance("B"); balance != 1000 {
+		t.Errorf("balance of B = %d, want 1000", balance)
+	}
+}
@@ -0,0 +1,37 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type authorizationRecordMapper struct {
+}
+
+func NewAuthorizationRecordMapper() *authorizationRecordMapper {
+	return &authorizationRecordMapper{}
+}
+
+func (s *authorizationRecordMapper) ToAuthorizationRecord(authorization models.Authorization) *record.AuthorizationRecord {
+	return &record.AuthorizationRecord{
+		AuthorizationID: authorization.AuthorizationID,
+		MerchantID:      authorization.MerchantID,
+		CardNumber:      authorization.CardNumber,
+		Amount:          authorization.Amount,
+		CapturedAmount:  authorization.CapturedAmount,
+		PaymentMethod:   authorization.PaymentMethod,
+		TransactionID:   authorization.TransactionID,
+		Status:          authorization.Status,
+		ExpiresAt:       authorization.ExpiresAt,
+		CreatedAt:       authorization.CreatedAt,
+		UpdatedAt:       authorization.UpdatedAt,
+	}
+}
+
+func (s *authorizationRecordMapper) ToAuthorizationsRecord(authorizations []models.Authorization) []*record.AuthorizationRecord {
+	var authorizationRecords []*record.AuthorizationRecord
+	for _, authorization := range authorizations {
+		authorizationRecords = append(authorizationRecords, s.ToAuthorizationRecord(authorization))
+	}
+	return authorizationRecords
+}
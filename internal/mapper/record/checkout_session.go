@@ -0,0 +1,41 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type checkoutSessionRecordMapper struct {
+}
+
+func NewCheckoutSessionRecordMapper() *checkoutSessionRecordMapper {
+	return &checkoutSessionRecordMapper{}
+}
+
+func (s *checkoutSessionRecordMapper) ToCheckoutSessionRecord(session models.CheckoutSession) *record.CheckoutSessionRecord {
+	return &record.CheckoutSessionRecord{
+		CheckoutSessionID: session.CheckoutSessionID,
+		SessionID:         session.SessionID,
+		MerchantID:        session.MerchantID,
+		Amount:            session.Amount,
+		Reference:         session.Reference,
+		SuccessURL:        session.SuccessURL,
+		CancelURL:         session.CancelURL,
+		Status:            session.Status,
+		CardNumber:        session.CardNumber,
+		PaymentMethod:     session.PaymentMethod,
+		TransactionID:     session.TransactionID,
+		ExpiresAt:         session.ExpiresAt,
+		CompletedAt:       session.CompletedAt,
+		CreatedAt:         session.CreatedAt,
+		UpdatedAt:         session.UpdatedAt,
+	}
+}
+
+func (s *checkoutSessionRecordMapper) ToCheckoutSessionsRecord(sessions []models.CheckoutSession) []*record.CheckoutSessionRecord {
+	var sessionRecords []*record.CheckoutSessionRecord
+	for _, session := range sessions {
+		sessionRecords = append(sessionRecords, s.ToCheckoutSessionRecord(session))
+	}
+	return sessionRecords
+}
@@ -0,0 +1,45 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type feePlanRecordMapper struct {
+}
+
+func NewFeePlanRecordMapper() *feePlanRecordMapper {
+	return &feePlanRecordMapper{}
+}
+
+func (s *feePlanRecordMapper) ToFeePlanRecord(plan models.FeePlan) *record.FeePlanRecord {
+	rates := make([]record.FeeRateRecord, 0, len(plan.Rates))
+
+	for _, rate := range plan.Rates {
+		rates = append(rates, record.FeeRateRecord{
+			PaymentMethod: rate.PaymentMethod,
+			Rate:          rate.Rate,
+			Fixed:         rate.Fixed,
+		})
+	}
+
+	return &record.FeePlanRecord{
+		FeePlanID:    plan.FeePlanID,
+		Name:         plan.Name,
+		DefaultRate:  plan.DefaultRate,
+		DefaultFixed: plan.DefaultFixed,
+		Rates:        rates,
+		CreatedAt:    plan.CreatedAt,
+		UpdatedAt:    plan.UpdatedAt,
+	}
+}
+
+func (s *feePlanRecordMapper) ToFeePlansRecord(plans []models.FeePlan) []*record.FeePlanRecord {
+	var records []*record.FeePlanRecord
+
+	for _, plan := range plans {
+		records = append(records, s.ToFeePlanRecord(plan))
+	}
+
+	return records
+}
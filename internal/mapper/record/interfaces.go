@@ -44,3 +44,61 @@ type MerchantRecordMapping interface {
 	ToMerchantRecord(merchant models.Merchant) *record.MerchantRecord
 	ToMerchantsRecord(merchants []models.Merchant) []*record.MerchantRecord
 }
+
+type JournalRecordMapping interface {
+	ToJournalEntryRecord(entry models.JournalEntry) *record.JournalEntryRecord
+	ToJournalEntriesRecord(entries []models.JournalEntry) []*record.JournalEntryRecord
+	ToPostingRecord(entry models.JournalEntry, posting models.Posting) *record.PostingRecord
+}
+
+type RefundRecordMapping interface {
+	ToRefundRecord(refund models.Refund) *record.RefundRecord
+	ToRefundsRecord(refunds []models.Refund) []*record.RefundRecord
+}
+
+type WithdrawHoldRecordMapping interface {
+	ToWithdrawHoldRecord(hold models.WithdrawHold) *record.WithdrawHoldRecord
+	ToWithdrawHoldsRecord(holds []models.WithdrawHold) []*record.WithdrawHoldRecord
+}
+
+type AuthorizationRecordMapping interface {
+	ToAuthorizationRecord(authorization models.Authorization) *record.AuthorizationRecord
+	ToAuthorizationsRecord(authorizations []models.Authorization) []*record.AuthorizationRecord
+}
+
+type SessionRecordMapping interface {
+	ToSessionRecord(session models.Session) *record.SessionRecord
+}
+
+type UserTokenRecordMapping interface {
+	ToUserTokenRecord(token models.UserToken) *record.UserTokenRecord
+}
+
+type MerchantApiKeyRecordMapping interface {
+	ToMerchantApiKeyRecord(key models.MerchantApiKey) *record.MerchantApiKeyRecord
+	ToMerchantApiKeysRecord(keys []models.MerchantApiKey) []*record.MerchantApiKeyRecord
+}
+
+type WebhookRecordMapping interface {
+	ToWebhookEndpointRecord(endpoint models.WebhookEndpoint) *record.WebhookEndpointRecord
+	ToWebhookEndpointsRecord(endpoints []models.WebhookEndpoint) []*record.WebhookEndpointRecord
+	ToWebhookDeliveryRecord(delivery models.WebhookDelivery) *record.WebhookDeliveryRecord
+	ToWebhookDeliveriesRecord(deliveries []models.WebhookDelivery) []*record.WebhookDeliveryRecord
+}
+
+type SettlementRecordMapping interface {
+	ToSettlementItemRecord(item models.SettlementItem) *record.SettlementItemRecord
+	ToSettlementItemsRecord(items []models.SettlementItem) []*record.SettlementItemRecord
+	ToSettlementBatchRecord(batch models.SettlementBatch) *record.SettlementBatchRecord
+	ToSettlementBatchesRecord(batches []models.SettlementBatch) []*record.SettlementBatchRecord
+}
+
+type FeePlanRecordMapping interface {
+	ToFeePlanRecord(plan models.FeePlan) *record.FeePlanRecord
+	ToFeePlansRecord(plans []models.FeePlan) []*record.FeePlanRecord
+}
+
+type CheckoutSessionRecordMapping interface {
+	ToCheckoutSessionRecord(session models.CheckoutSession) *record.CheckoutSessionRecord
+	ToCheckoutSessionsRecord(sessions []models.CheckoutSession) []*record.CheckoutSessionRecord
+}
@@ -0,0 +1,54 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type journalRecordMapper struct {
+}
+
+func NewJournalRecordMapper() *journalRecordMapper {
+	return &journalRecordMapper{}
+}
+
+func (m *journalRecordMapper) ToJournalEntryRecord(entry models.JournalEntry) *record.JournalEntryRecord {
+	postings := make([]record.PostingRecord, 0, len(entry.Postings))
+
+	for _, posting := range entry.Postings {
+		postings = append(postings, *m.ToPostingRecord(entry, posting))
+	}
+
+	return &record.JournalEntryRecord{
+		EntryID:     entry.EntryID,
+		Reference:   entry.Reference,
+		ReferenceID: entry.ReferenceID,
+		Description: entry.Description,
+		Postings:    postings,
+		CreatedAt:   entry.CreatedAt,
+	}
+}
+
+func (m *journalRecordMapper) ToJournalEntriesRecord(entries []models.JournalEntry) []*record.JournalEntryRecord {
+	var records []*record.JournalEntryRecord
+
+	for _, entry := range entries {
+		records = append(records, m.ToJournalEntryRecord(entry))
+	}
+
+	return records
+}
+
+func (m *journalRecordMapper) ToPostingRecord(entry models.JournalEntry, posting models.Posting) *record.PostingRecord {
+	return &record.PostingRecord{
+		PostingID:   posting.PostingID,
+		EntryID:     entry.EntryID,
+		Account:     posting.Account,
+		Debit:       posting.Debit,
+		Credit:      posting.Credit,
+		Reference:   entry.Reference,
+		ReferenceID: entry.ReferenceID,
+		Description: entry.Description,
+		CreatedAt:   entry.CreatedAt,
+	}
+}
@@ -1,25 +1,47 @@
 package recordmapper
 
 type RecordMapper struct {
-	UserRecordMapper        UserRecordMapping
-	SaldoRecordMapper       SaldoRecordMapping
-	TopupRecordMapper       TopupRecordMapping
-	TransferRecordMapper    TransferRecordMapping
-	WithdrawRecordMapper    WithdrawRecordMapping
-	CardRecordMapper        CardRecordMapping
-	TransactionRecordMapper TransactionRecordMapping
-	MerchantRecordMapper    MerchantRecordMapping
+	UserRecordMapper            UserRecordMapping
+	SaldoRecordMapper           SaldoRecordMapping
+	TopupRecordMapper           TopupRecordMapping
+	TransferRecordMapper        TransferRecordMapping
+	WithdrawRecordMapper        WithdrawRecordMapping
+	CardRecordMapper            CardRecordMapping
+	TransactionRecordMapper     TransactionRecordMapping
+	MerchantRecordMapper        MerchantRecordMapping
+	JournalRecordMapper         JournalRecordMapping
+	RefundRecordMapper          RefundRecordMapping
+	WithdrawHoldRecordMapper    WithdrawHoldRecordMapping
+	AuthorizationRecordMapper   AuthorizationRecordMapping
+	SessionRecordMapper         SessionRecordMapping
+	UserTokenRecordMapper       UserTokenRecordMapping
+	MerchantApiKeyRecordMapper  MerchantApiKeyRecordMapping
+	WebhookRecordMapper         WebhookRecordMapping
+	SettlementRecordMapper      SettlementRecordMapping
+	FeePlanRecordMapper         FeePlanRecordMapping
+	CheckoutSessionRecordMapper CheckoutSessionRecordMapping
 }
 
 func NewRecordMapper() *RecordMapper {
 	return &RecordMapper{
-		UserRecordMapper:        NewUserRecordMapper(),
-		SaldoRecordMapper:       NewSaldoRecordMapper(),
-		TopupRecordMapper:       NewTopupRecordMapper(),
-		TransferRecordMapper:    NewTransferRecordMapper(),
-		WithdrawRecordMapper:    NewWithdrawRecordMapper(),
-		CardRecordMapper:        NewCardRecordMapper(),
-		TransactionRecordMapper: NewTransactionRecordMapper(),
-		MerchantRecordMapper:    NewMerchantRecordMapper(),
+		UserRecordMapper:            NewUserRecordMapper(),
+		SaldoRecordMapper:           NewSaldoRecordMapper(),
+		TopupRecordMapper:           NewTopupRecordMapper(),
+		TransferRecordMapper:        NewTransferRecordMapper(),
+		WithdrawRecordMapper:        NewWithdrawRecordMapper(),
+		CardRecordMapper:            NewCardRecordMapper(),
+		TransactionRecordMapper:     NewTransactionRecordMapper(),
+		MerchantRecordMapper:        NewMerchantRecordMapper(),
+		JournalRecordMapper:         NewJournalRecordMapper(),
+		RefundRecordMapper:          NewRefundRecordMapper(),
+		WithdrawHoldRecordMapper:    NewWithdrawHoldRecordMapper(),
+		AuthorizationRecordMapper:   NewAuthorizationRecordMapper(),
+		SessionRecordMapper:         NewSessionRecordMapper(),
+		UserTokenRecordMapper:       NewUserTokenRecordMapper(),
+		MerchantApiKeyRecordMapper:  NewMerchantApiKeyRecordMapper(),
+		WebhookRecordMapper:         NewWebhookRecordMapper(),
+		SettlementRecordMapper:      NewSettlementRecordMapper(),
+		FeePlanRecordMapper:         NewFeePlanRecordMapper(),
+		CheckoutSessionRecordMapper: NewCheckoutSessionRecordMapper(),
 	}
 }
@@ -13,12 +13,36 @@ func NewMerchantRecordMapper() *merchantRecordMapper {
 }
 
 func (m *merchantRecordMapper) ToMerchantRecord(merchant models.Merchant) *record.MerchantRecord {
+	settlementMode := merchant.SettlementMode
+	if settlementMode == "" {
+		settlementMode = models.SettlementModeInstant
+	}
+
+	history := make([]record.MerchantStatusChangeRecord, 0, len(merchant.StatusHistory))
+
+	for _, change := range merchant.StatusHistory {
+		history = append(history, record.MerchantStatusChangeRecord{
+			Status:    change.Status,
+			Reason:    change.Reason,
+			Note:      change.Note,
+			ChangedBy: change.ChangedBy,
+			ChangedAt: change.ChangedAt,
+		})
+	}
+
 	return &record.MerchantRecord{
 		MerchantID: merchant.MerchantID,
 		Name:       merchant.Name,
-		ApiKey:     merchant.ApiKey,
 		UserID:     merchant.UserID,
 		Status:     merchant.Status,
+
+		StatusHistory: history,
+
+		SigningSecret:    merchant.SigningSecret,
+		RequireSignature: merchant.RequireSignature,
+		WebhookSecret:    merchant.WebhookSecret,
+		SettlementMode:   settlementMode,
+		FeePlanID:        merchant.FeePlanID,
 	}
 }
 
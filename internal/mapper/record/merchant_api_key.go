@@ -0,0 +1,38 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type merchantApiKeyRecordMapper struct {
+}
+
+func NewMerchantApiKeyRecordMapper() *merchantApiKeyRecordMapper {
+	return &merchantApiKeyRecordMapper{}
+}
+
+func (s *merchantApiKeyRecordMapper) ToMerchantApiKeyRecord(key models.MerchantApiKey) *record.MerchantApiKeyRecord {
+	return &record.MerchantApiKeyRecord{
+		KeyID:      key.KeyID,
+		MerchantID: key.MerchantID,
+		Name:       key.Name,
+		Lookup:     key.Lookup,
+		Hash:       key.Hash,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+func (s *merchantApiKeyRecordMapper) ToMerchantApiKeysRecord(keys []models.MerchantApiKey) []*record.MerchantApiKeyRecord {
+	var records []*record.MerchantApiKeyRecord
+
+	for _, key := range keys {
+		records = append(records, s.ToMerchantApiKeyRecord(key))
+	}
+
+	return records
+}
@@ -0,0 +1,33 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type refundRecordMapper struct {
+}
+
+func NewRefundRecordMapper() *refundRecordMapper {
+	return &refundRecordMapper{}
+}
+
+func (s *refundRecordMapper) ToRefundRecord(refund models.Refund) *record.RefundRecord {
+	return &record.RefundRecord{
+		RefundID:      refund.RefundID,
+		TransactionID: refund.TransactionID,
+		MerchantID:    refund.MerchantID,
+		CardNumber:    refund.CardNumber,
+		Amount:        refund.Amount,
+		Reason:        refund.Reason,
+		RefundTime:    refund.RefundTime,
+	}
+}
+
+func (s *refundRecordMapper) ToRefundsRecord(refunds []models.Refund) []*record.RefundRecord {
+	var refundRecords []*record.RefundRecord
+	for _, refund := range refunds {
+		refundRecords = append(refundRecords, s.ToRefundRecord(refund))
+	}
+	return refundRecords
+}
@@ -17,6 +17,7 @@ func (s *saldoRecordMapper) ToSaldoRecord(saldo models.Saldo) *record.SaldoRecor
 		SaldoID:        saldo.SaldoID,
 		CardNumber:     saldo.CardNumber,
 		TotalBalance:   saldo.TotalBalance,
+		HeldBalance:    saldo.HeldBalance,
 		WithdrawAmount: saldo.WithdrawAmount,
 		WithdrawTime:   saldo.WithdrawTime,
 	}
@@ -0,0 +1,25 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type sessionRecordMapper struct {
+}
+
+func NewSessionRecordMapper() *sessionRecordMapper {
+	return &sessionRecordMapper{}
+}
+
+func (s *sessionRecordMapper) ToSessionRecord(session models.Session) *record.SessionRecord {
+	return &record.SessionRecord{
+		SessionID:     session.SessionID,
+		UserID:        session.UserID,
+		Generation:    session.Generation,
+		RevokedAt:     session.RevokedAt,
+		RevokedReason: session.RevokedReason,
+		CreatedAt:     session.CreatedAt,
+		UpdatedAt:     session.UpdatedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type settlementRecordMapper struct {
+}
+
+func NewSettlementRecordMapper() *settlementRecordMapper {
+	return &settlementRecordMapper{}
+}
+
+func (s *settlementRecordMapper) ToSettlementItemRecord(item models.SettlementItem) *record.SettlementItemRecord {
+	return &record.SettlementItemRecord{
+		ItemID:        item.ItemID,
+		MerchantID:    item.MerchantID,
+		TransactionID: item.TransactionID,
+		Type:          item.Type,
+		ReferenceID:   item.ReferenceID,
+		Amount:        item.Amount,
+		Fee:           item.Fee,
+		BatchID:       item.BatchID,
+		CreatedAt:     item.CreatedAt,
+	}
+}
+
+func (s *settlementRecordMapper) ToSettlementItemsRecord(items []models.SettlementItem) []*record.SettlementItemRecord {
+	var records []*record.SettlementItemRecord
+
+	for _, item := range items {
+		records = append(records, s.ToSettlementItemRecord(item))
+	}
+
+	return records
+}
+
+func (s *settlementRecordMapper) ToSettlementBatchRecord(batch models.SettlementBatch) *record.SettlementBatchRecord {
+	return &record.SettlementBatchRecord{
+		BatchID:      batch.BatchID,
+		MerchantID:   batch.MerchantID,
+		CardNumber:   batch.CardNumber,
+		Cutoff:       batch.Cutoff,
+		Status:       batch.Status,
+		ItemCount:    batch.ItemCount,
+		GrossAmount:  batch.GrossAmount,
+		RefundAmount: batch.RefundAmount,
+		FeeAmount:    batch.FeeAmount,
+		NetAmount:    batch.NetAmount,
+		Error:        batch.Error,
+		CreatedAt:    batch.CreatedAt,
+		PaidAt:       batch.PaidAt,
+	}
+}
+
+func (s *settlementRecordMapper) ToSettlementBatchesRecord(batches []models.SettlementBatch) []*record.SettlementBatchRecord {
+	var records []*record.SettlementBatchRecord
+
+	for _, batch := range batches {
+		records = append(records, s.ToSettlementBatchRecord(batch))
+	}
+
+	return records
+}
@@ -0,0 +1,19 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+func toStatusHistoryRecord(history []models.StatusChange) []record.StatusChangeRecord {
+	records := make([]record.StatusChangeRecord, 0, len(history))
+
+	for _, change := range history {
+		records = append(records, record.StatusChangeRecord{
+			Status:    change.Status,
+			ChangedAt: change.ChangedAt,
+		})
+	}
+
+	return records
+}
@@ -14,12 +14,14 @@ func NewTopupRecordMapper() *topupRecordMapper {
 
 func (t *topupRecordMapper) ToTopupRecord(topup models.Topup) *record.TopupRecord {
 	return &record.TopupRecord{
-		TopupID:     topup.TopupID,
-		CardNumber:  topup.CardNumber,
-		TopupNo:     topup.TopupNo,
-		TopupAmount: topup.TopupAmount,
-		TopupMethod: topup.TopupMethod,
-		TopupTime:   topup.TopupTime,
+		TopupID:       topup.TopupID,
+		CardNumber:    topup.CardNumber,
+		TopupNo:       topup.TopupNo,
+		TopupAmount:   topup.TopupAmount,
+		TopupMethod:   topup.TopupMethod,
+		TopupTime:     topup.TopupTime,
+		Status:        topup.Status,
+		StatusHistory: toStatusHistoryRecord(topup.StatusHistory),
 	}
 }
 
@@ -17,8 +17,12 @@ func (s *transactionRecordMapper) ToTransactionRecord(transfer models.Transactio
 		TransactionID:   transfer.TransactionID,
 		CardNumber:      transfer.CardNumber,
 		Amount:          transfer.Amount,
+		Fee:             transfer.Fee,
 		PaymentMethod:   transfer.PaymentMethod,
+		MerchantID:      transfer.MerchantID,
 		TransactionTime: transfer.TransactionTime,
+		Status:          transfer.Status,
+		StatusHistory:   toStatusHistoryRecord(transfer.StatusHistory),
 	}
 }
 
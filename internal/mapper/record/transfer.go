@@ -19,6 +19,8 @@ func (t *transferRecordMapper) ToTransferRecord(transfer models.Transfer) *recor
 		TransferTo:     transfer.TransferTo,
 		TransferAmount: transfer.TransferAmount,
 		TransferTime:   transfer.TransferTime,
+		Status:         transfer.Status,
+		StatusHistory:  toStatusHistoryRecord(transfer.StatusHistory),
 	}
 }
 
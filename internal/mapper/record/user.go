@@ -19,12 +19,42 @@ func (u *userRecordMapper) ToUserRecord(user models.User) *record.UserRecord {
 		password = &user.Password
 	}
 
+	// Pengguna yang tersimpan sebelum ada verifikasi email dianggap aktif
+	status := user.Status
+	if status == "" {
+		status = models.UserStatusActive
+	}
+
+	var pin *string
+
+	if user.Pin != "" {
+		pin = &user.Pin
+	}
+
 	return &record.UserRecord{
 		UserID:    user.UserID,
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		Email:     user.Email,
 		Password:  password,
+		Role:      user.Role,
+
+		Status:          status,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+
+		LoginFailedAttempts: user.LoginFailedAttempts,
+		LoginLockedUntil:    user.LoginLockedUntil,
+
+		Pin:               pin,
+		PinFailedAttempts: user.PinFailedAttempts,
+		PinLockedUntil:    user.PinLockedUntil,
+
+		TotpSecret:         user.TotpSecret,
+		TotpEnabled:        user.TotpEnabled,
+		TotpLastStep:       user.TotpLastStep,
+		TotpFailedAttempts: user.TotpFailedAttempts,
+		TotpLockedUntil:    user.TotpLockedUntil,
+		RecoveryCodes:      user.RecoveryCodes,
 	}
 }
 
@@ -0,0 +1,24 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type userTokenRecordMapper struct {
+}
+
+func NewUserTokenRecordMapper() *userTokenRecordMapper {
+	return &userTokenRecordMapper{}
+}
+
+func (s *userTokenRecordMapper) ToUserTokenRecord(token models.UserToken) *record.UserTokenRecord {
+	return &record.UserTokenRecord{
+		TokenID:   token.TokenID,
+		UserID:    token.UserID,
+		Purpose:   token.Purpose,
+		ExpiresAt: token.ExpiresAt,
+		UsedAt:    token.UsedAt,
+		CreatedAt: token.CreatedAt,
+	}
+}
@@ -0,0 +1,73 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type webhookRecordMapper struct {
+}
+
+func NewWebhookRecordMapper() *webhookRecordMapper {
+	return &webhookRecordMapper{}
+}
+
+func (s *webhookRecordMapper) ToWebhookEndpointRecord(endpoint models.WebhookEndpoint) *record.WebhookEndpointRecord {
+	return &record.WebhookEndpointRecord{
+		EndpointID: endpoint.EndpointID,
+		MerchantID: endpoint.MerchantID,
+		URL:        endpoint.URL,
+		Events:     endpoint.Events,
+		Active:     endpoint.Active,
+		CreatedAt:  endpoint.CreatedAt,
+		UpdatedAt:  endpoint.UpdatedAt,
+	}
+}
+
+func (s *webhookRecordMapper) ToWebhookEndpointsRecord(endpoints []models.WebhookEndpoint) []*record.WebhookEndpointRecord {
+	var records []*record.WebhookEndpointRecord
+
+	for _, endpoint := range endpoints {
+		records = append(records, s.ToWebhookEndpointRecord(endpoint))
+	}
+
+	return records
+}
+
+func (s *webhookRecordMapper) ToWebhookDeliveryRecord(delivery models.WebhookDelivery) *record.WebhookDeliveryRecord {
+	attempts := make([]record.WebhookAttemptRecord, 0, len(delivery.Attempts))
+
+	for _, attempt := range delivery.Attempts {
+		attempts = append(attempts, record.WebhookAttemptRecord{
+			AttemptedAt: attempt.AttemptedAt,
+			StatusCode:  attempt.StatusCode,
+			Error:       attempt.Error,
+			DurationMs:  attempt.DurationMs,
+		})
+	}
+
+	return &record.WebhookDeliveryRecord{
+		DeliveryID:    delivery.DeliveryID,
+		EndpointID:    delivery.EndpointID,
+		MerchantID:    delivery.MerchantID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		Attempts:      attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		RedeliveryOf:  delivery.RedeliveryOf,
+		CreatedAt:     delivery.CreatedAt,
+		UpdatedAt:     delivery.UpdatedAt,
+	}
+}
+
+func (s *webhookRecordMapper) ToWebhookDeliveriesRecord(deliveries []models.WebhookDelivery) []*record.WebhookDeliveryRecord {
+	var records []*record.WebhookDeliveryRecord
+
+	for _, delivery := range deliveries {
+		records = append(records, s.ToWebhookDeliveryRecord(delivery))
+	}
+
+	return records
+}
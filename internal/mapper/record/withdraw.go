@@ -18,6 +18,8 @@ func (s *withdrawRecordMapper) ToWithdrawRecord(withdraw models.Withdraw) *recor
 		CardNumber:     withdraw.CardNumber,
 		WithdrawAmount: withdraw.WithdrawAmount,
 		WithdrawTime:   withdraw.WithdrawTime,
+		Status:         withdraw.Status,
+		StatusHistory:  toStatusHistoryRecord(withdraw.StatusHistory),
 	}
 }
 
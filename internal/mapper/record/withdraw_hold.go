@@ -0,0 +1,35 @@
+package recordmapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/models"
+)
+
+type withdrawHoldRecordMapper struct {
+}
+
+func NewWithdrawHoldRecordMapper() *withdrawHoldRecordMapper {
+	return &withdrawHoldRecordMapper{}
+}
+
+func (s *withdrawHoldRecordMapper) ToWithdrawHoldRecord(hold models.WithdrawHold) *record.WithdrawHoldRecord {
+	return &record.WithdrawHoldRecord{
+		HoldID:         hold.HoldID,
+		CardNumber:     hold.CardNumber,
+		Amount:         hold.Amount,
+		CapturedAmount: hold.CapturedAmount,
+		WithdrawID:     hold.WithdrawID,
+		Status:         hold.Status,
+		ExpiresAt:      hold.ExpiresAt,
+		CreatedAt:      hold.CreatedAt,
+		UpdatedAt:      hold.UpdatedAt,
+	}
+}
+
+func (s *withdrawHoldRecordMapper) ToWithdrawHoldsRecord(holds []models.WithdrawHold) []*record.WithdrawHoldRecord {
+	var holdRecords []*record.WithdrawHoldRecord
+	for _, hold := range holds {
+		holdRecords = append(holdRecords, s.ToWithdrawHoldRecord(hold))
+	}
+	return holdRecords
+}
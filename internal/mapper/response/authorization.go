@@ -0,0 +1,42 @@
+package responseMapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+)
+
+type authorizationResponseMapper struct {
+}
+
+func NewAuthorizationResponseMapper() *authorizationResponseMapper {
+	return &authorizationResponseMapper{}
+}
+
+func (s *authorizationResponseMapper) ToAuthorizationResponse(authorization record.AuthorizationRecord) *response.AuthorizationResponse {
+	res := &response.AuthorizationResponse{
+		ID:             authorization.AuthorizationID,
+		CardNumber:     authorization.CardNumber,
+		Amount:         authorization.Amount,
+		CapturedAmount: authorization.CapturedAmount,
+		PaymentMethod:  authorization.PaymentMethod,
+		Status:         authorization.Status,
+		ExpiresAt:      authorization.ExpiresAt,
+		CreatedAt:      authorization.CreatedAt,
+		UpdatedAt:      authorization.UpdatedAt,
+	}
+
+	if authorization.TransactionID != 0 {
+		transactionID := authorization.TransactionID
+		res.TransactionID = &transactionID
+	}
+
+	return res
+}
+
+func (s *authorizationResponseMapper) ToAuthorizationsResponse(authorizations []*record.AuthorizationRecord) []*response.AuthorizationResponse {
+	responses := make([]*response.AuthorizationResponse, 0, len(authorizations))
+	for _, authorization := range authorizations {
+		responses = append(responses, s.ToAuthorizationResponse(*authorization))
+	}
+	return responses
+}
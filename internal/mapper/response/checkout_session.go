@@ -0,0 +1,46 @@
+package responseMapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+)
+
+type checkoutSessionResponseMapper struct {
+}
+
+func NewCheckoutSessionResponseMapper() *checkoutSessionResponseMapper {
+	return &checkoutSessionResponseMapper{}
+}
+
+func (s *checkoutSessionResponseMapper) ToCheckoutSessionResponse(session record.CheckoutSessionRecord) *response.CheckoutSessionResponse {
+	res := &response.CheckoutSessionResponse{
+		ID:            session.SessionID,
+		MerchantID:    session.MerchantID,
+		Amount:        session.Amount,
+		Reference:     session.Reference,
+		SuccessURL:    session.SuccessURL,
+		CancelURL:     session.CancelURL,
+		Status:        session.Status,
+		CardNumber:    session.CardNumber,
+		PaymentMethod: session.PaymentMethod,
+		ExpiresAt:     session.ExpiresAt,
+		CompletedAt:   session.CompletedAt,
+		CreatedAt:     session.CreatedAt,
+		UpdatedAt:     session.UpdatedAt,
+	}
+
+	if session.TransactionID != 0 {
+		transactionID := session.TransactionID
+		res.TransactionID = &transactionID
+	}
+
+	return res
+}
+
+func (s *checkoutSessionResponseMapper) ToCheckoutSessionsResponse(sessions []*record.CheckoutSessionRecord) []*response.CheckoutSessionResponse {
+	responses := make([]*response.CheckoutSessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, s.ToCheckoutSessionResponse(*session))
+	}
+	return responses
+}
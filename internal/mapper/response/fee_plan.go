@@ -0,0 +1,45 @@
+package responseMapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+)
+
+type feePlanResponseMapper struct {
+}
+
+func NewFeePlanResponseMapper() *feePlanResponseMapper {
+	return &feePlanResponseMapper{}
+}
+
+func (s *feePlanResponseMapper) ToFeePlanResponse(plan record.FeePlanRecord) *response.FeePlanResponse {
+	rates := make([]*response.FeeRateResponse, 0, len(plan.Rates))
+
+	for _, rate := range plan.Rates {
+		rates = append(rates, &response.FeeRateResponse{
+			PaymentMethod: rate.PaymentMethod,
+			Rate:          rate.Rate,
+			Fixed:         rate.Fixed,
+		})
+	}
+
+	return &response.FeePlanResponse{
+		ID:           plan.FeePlanID,
+		Name:         plan.Name,
+		DefaultRate:  plan.DefaultRate,
+		DefaultFixed: plan.DefaultFixed,
+		Rates:        rates,
+		CreatedAt:    plan.CreatedAt,
+		UpdatedAt:    plan.UpdatedAt,
+	}
+}
+
+func (s *feePlanResponseMapper) ToFeePlansResponse(plans []*record.FeePlanRecord) []*response.FeePlanResponse {
+	responses := make([]*response.FeePlanResponse, 0, len(plans))
+
+	for _, plan := range plans {
+		responses = append(responses, s.ToFeePlanResponse(*plan))
+	}
+
+	return responses
+}
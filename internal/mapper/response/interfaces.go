@@ -43,4 +43,54 @@ type WithdrawResponseMapper interface {
 type MerchantResponseMapper interface {
 	ToMerchantResponse(merchant record.MerchantRecord) *response.MerchantResponse
 	ToMerchantsResponse(merchants []*record.MerchantRecord) []*response.MerchantResponse
+	ToMerchantStatusHistoryResponse(history []record.MerchantStatusChangeRecord) []*response.MerchantStatusChangeResponse
+}
+
+type MerchantApiKeyResponseMapper interface {
+	ToMerchantApiKeyResponse(key record.MerchantApiKeyRecord) *response.MerchantApiKeyResponse
+	ToMerchantApiKeysResponse(keys []*record.MerchantApiKeyRecord) []*response.MerchantApiKeyResponse
+}
+
+type JournalResponseMapper interface {
+	ToPostingResponse(posting record.PostingRecord) *response.PostingResponse
+	ToPostingsResponse(postings []*record.PostingRecord) []*response.PostingResponse
+}
+
+type RefundResponseMapper interface {
+	ToRefundResponse(refund record.RefundRecord) *response.RefundResponse
+	ToRefundsResponse(refunds []*record.RefundRecord) []*response.RefundResponse
+}
+
+type WithdrawHoldResponseMapper interface {
+	ToWithdrawHoldResponse(hold record.WithdrawHoldRecord) *response.WithdrawHoldResponse
+	ToWithdrawHoldsResponse(holds []*record.WithdrawHoldRecord) []*response.WithdrawHoldResponse
+}
+
+type AuthorizationResponseMapper interface {
+	ToAuthorizationResponse(authorization record.AuthorizationRecord) *response.AuthorizationResponse
+	ToAuthorizationsResponse(authorizations []*record.AuthorizationRecord) []*response.AuthorizationResponse
+}
+
+type WebhookResponseMapper interface {
+	ToWebhookEndpointResponse(endpoint record.WebhookEndpointRecord) *response.WebhookEndpointResponse
+	ToWebhookEndpointsResponse(endpoints []*record.WebhookEndpointRecord) []*response.WebhookEndpointResponse
+	ToWebhookDeliveryResponse(delivery record.WebhookDeliveryRecord) *response.WebhookDeliveryResponse
+	ToWebhookDeliveriesResponse(deliveries []*record.WebhookDeliveryRecord) []*response.WebhookDeliveryResponse
+}
+
+type SettlementResponseMapper interface {
+	ToSettlementBatchResponse(batch record.SettlementBatchRecord) *response.SettlementBatchResponse
+	ToSettlementBatchesResponse(batches []*record.SettlementBatchRecord) []*response.SettlementBatchResponse
+	ToSettlementItemResponse(item record.SettlementItemRecord) *response.SettlementItemResponse
+	ToSettlementItemsResponse(items []*record.SettlementItemRecord) []*response.SettlementItemResponse
+}
+
+type FeePlanResponseMapper interface {
+	ToFeePlanResponse(plan record.FeePlanRecord) *response.FeePlanResponse
+	ToFeePlansResponse(plans []*record.FeePlanRecord) []*response.FeePlanResponse
+}
+
+type CheckoutSessionResponseMapper interface {
+	ToCheckoutSessionResponse(session record.CheckoutSessionRecord) *response.CheckoutSessionResponse
+	ToCheckoutSessionsResponse(sessions []*record.CheckoutSessionRecord) []*response.CheckoutSessionResponse
 }
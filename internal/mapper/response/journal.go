@@ -0,0 +1,35 @@
+package responseMapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+)
+
+type journalResponseMapper struct {
+}
+
+func NewJournalResponseMapper() *journalResponseMapper {
+	return &journalResponseMapper{}
+}
+
+func (s *journalResponseMapper) ToPostingResponse(posting record.PostingRecord) *response.PostingResponse {
+	return &response.PostingResponse{
+		ID:          posting.PostingID,
+		EntryID:     posting.EntryID,
+		Account:     posting.Account,
+		Debit:       posting.Debit,
+		Credit:      posting.Credit,
+		Reference:   posting.Reference,
+		ReferenceID: posting.ReferenceID,
+		Description: posting.Description,
+		CreatedAt:   posting.CreatedAt,
+	}
+}
+
+func (s *journalResponseMapper) ToPostingsResponse(postings []*record.PostingRecord) []*response.PostingResponse {
+	responses := make([]*response.PostingResponse, 0, len(postings))
+	for _, posting := range postings {
+		responses = append(responses, s.ToPostingResponse(*posting))
+	}
+	return responses
+}
@@ -1,25 +1,43 @@
 package responseMapper
 
 type ResponseMapper struct {
-	CardResponseMapper        CardResponseMapper
-	SaldoResponseMapper       SaldoResponseMapper
-	TransactionResponseMapper TransactionResponseMapper
-	TransferResponseMapper    TransferResponseMapper
-	TopupResponseMapper       TopupResponseMapper
-	WithdrawResponseMapper    WithdrawResponseMapper
-	UserResponseMapper        UserResponseMapper
-	MerchantResponseMapper    MerchantResponseMapper
+	CardResponseMapper            CardResponseMapper
+	SaldoResponseMapper           SaldoResponseMapper
+	TransactionResponseMapper     TransactionResponseMapper
+	TransferResponseMapper        TransferResponseMapper
+	TopupResponseMapper           TopupResponseMapper
+	WithdrawResponseMapper        WithdrawResponseMapper
+	UserResponseMapper            UserResponseMapper
+	MerchantResponseMapper        MerchantResponseMapper
+	JournalResponseMapper         JournalResponseMapper
+	RefundResponseMapper          RefundResponseMapper
+	WithdrawHoldResponseMapper    WithdrawHoldResponseMapper
+	AuthorizationResponseMapper   AuthorizationResponseMapper
+	MerchantApiKeyResponseMapper  MerchantApiKeyResponseMapper
+	WebhookResponseMapper         WebhookResponseMapper
+	SettlementResponseMapper      SettlementResponseMapper
+	FeePlanResponseMapper         FeePlanResponseMapper
+	CheckoutSessionResponseMapper CheckoutSessionResponseMapper
 }
 
 func NewResponseMapper() *ResponseMapper {
 	return &ResponseMapper{
-		CardResponseMapper:        NewCardResponseMapper(),
-		SaldoResponseMapper:       NewSaldoResponseMapper(),
-		TransactionResponseMapper: NewTransactionResponseMapper(),
-		TransferResponseMapper:    NewTransferResponseMapper(),
-		TopupResponseMapper:       NewTopupResponseMapper(),
-		WithdrawResponseMapper:    NewWithdrawResponseMapper(),
-		UserResponseMapper:        NewUserResponseMapper(),
-		MerchantResponseMapper:    NewMerchantResponseMapper(),
+		CardResponseMapper:            NewCardResponseMapper(),
+		SaldoResponseMapper:           NewSaldoResponseMapper(),
+		TransactionResponseMapper:     NewTransactionResponseMapper(),
+		TransferResponseMapper:        NewTransferResponseMapper(),
+		TopupResponseMapper:           NewTopupResponseMapper(),
+		WithdrawResponseMapper:        NewWithdrawResponseMapper(),
+		UserResponseMapper:            NewUserResponseMapper(),
+		MerchantResponseMapper:        NewMerchantResponseMapper(),
+		JournalResponseMapper:         NewJournalResponseMapper(),
+		RefundResponseMapper:          NewRefundResponseMapper(),
+		WithdrawHoldResponseMapper:    NewWithdrawHoldResponseMapper(),
+		AuthorizationResponseMapper:   NewAuthorizationResponseMapper(),
+		MerchantApiKeyResponseMapper:  NewMerchantApiKeyResponseMapper(),
+		WebhookResponseMapper:         NewWebhookResponseMapper(),
+		SettlementResponseMapper:      NewSettlementResponseMapper(),
+		FeePlanResponseMapper:         NewFeePlanResponseMapper(),
+		CheckoutSessionResponseMapper: NewCheckoutSessionResponseMapper(),
 	}
 }
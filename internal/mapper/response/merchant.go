@@ -17,7 +17,10 @@ func (s *merchantResponseMapper) ToMerchantResponse(merchant record.MerchantReco
 		ID:     merchant.MerchantID,
 		Name:   merchant.Name,
 		Status: merchant.Status,
-		ApiKey: merchant.ApiKey,
+
+		RequireSignature: merchant.RequireSignature,
+		SettlementMode:   merchant.SettlementMode,
+		FeePlanID:        merchant.FeePlanID,
 	}
 }
 
@@ -28,3 +31,19 @@ func (s *merchantResponseMapper) ToMerchantsResponse(merchants []*record.Merchan
 	}
 	return response
 }
+
+func (s *merchantResponseMapper) ToMerchantStatusHistoryResponse(history []record.MerchantStatusChangeRecord) []*response.MerchantStatusChangeResponse {
+	responses := make([]*response.MerchantStatusChangeResponse, 0, len(history))
+
+	for _, change := range history {
+		responses = append(responses, &response.MerchantStatusChangeResponse{
+			Status:    change.Status,
+			Reason:    change.Reason,
+			Note:      change.Note,
+			ChangedBy: change.ChangedBy,
+			ChangedAt: change.ChangedAt,
+		})
+	}
+
+	return responses
+}
@@ -0,0 +1,55 @@
+package responseMapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+	"time"
+)
+
+const (
+	apiKeyStatusActive   = "active"
+	apiKeyStatusExpiring = "expiring"
+	apiKeyStatusExpired  = "expired"
+	apiKeyStatusRevoked  = "revoked"
+)
+
+type merchantApiKeyResponseMapper struct {
+}
+
+func NewMerchantApiKeyResponseMapper() *merchantApiKeyResponseMapper {
+	return &merchantApiKeyResponseMapper{}
+}
+
+func (s *merchantApiKeyResponseMapper) ToMerchantApiKeyResponse(key record.MerchantApiKeyRecord) *response.MerchantApiKeyResponse {
+	status := apiKeyStatusActive
+
+	switch {
+	case key.RevokedAt != nil:
+		status = apiKeyStatusRevoked
+	case key.ExpiresAt != nil && !time.Now().Before(*key.ExpiresAt):
+		status = apiKeyStatusExpired
+	case key.ExpiresAt != nil:
+		status = apiKeyStatusExpiring
+	}
+
+	return &response.MerchantApiKeyResponse{
+		ID:         key.KeyID,
+		MerchantID: key.MerchantID,
+		Name:       key.Name,
+		Prefix:     key.Lookup,
+		Scopes:     key.Scopes,
+		Status:     status,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+func (s *merchantApiKeyResponseMapper) ToMerchantApiKeysResponse(keys []*record.MerchantApiKeyRecord) []*response.MerchantApiKeyResponse {
+	responses := make([]*response.MerchantApiKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, s.ToMerchantApiKeyResponse(*key))
+	}
+	return responses
+}
@@ -0,0 +1,32 @@
+package responseMapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+)
+
+type refundResponseMapper struct {
+}
+
+func NewRefundResponseMapper() *refundResponseMapper {
+	return &refundResponseMapper{}
+}
+
+func (s *refundResponseMapper) ToRefundResponse(refund record.RefundRecord) *response.RefundResponse {
+	return &response.RefundResponse{
+		ID:            refund.RefundID,
+		TransactionID: refund.TransactionID,
+		CardNumber:    refund.CardNumber,
+		Amount:        refund.Amount,
+		Reason:        refund.Reason,
+		RefundTime:    refund.RefundTime,
+	}
+}
+
+func (s *refundResponseMapper) ToRefundsResponse(refunds []*record.RefundRecord) []*response.RefundResponse {
+	responses := make([]*response.RefundResponse, 0, len(refunds))
+	for _, refund := range refunds {
+		responses = append(responses, s.ToRefundResponse(*refund))
+	}
+	return responses
+}
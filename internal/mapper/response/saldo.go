@@ -17,6 +17,8 @@ func (s *saldoResponseMapper) ToSaldoResponse(saldo record.SaldoRecord) *respons
 		ID:             saldo.SaldoID,
 		CardNumber:     saldo.CardNumber,
 		TotalBalance:   saldo.TotalBalance,
+		HeldBalance:    saldo.HeldBalance,
+		Available:      saldo.TotalBalance - saldo.HeldBalance,
 		WithdrawAmount: &saldo.WithdrawAmount,
 		WithdrawTime:   &saldo.WithdrawTime,
 	}
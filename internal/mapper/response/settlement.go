@@ -0,0 +1,67 @@
+package responseMapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+)
+
+type settlementResponseMapper struct {
+}
+
+func NewSettlementResponseMapper() *settlementResponseMapper {
+	return &settlementResponseMapper{}
+}
+
+func (s *settlementResponseMapper) ToSettlementBatchResponse(batch record.SettlementBatchRecord) *response.SettlementBatchResponse {
+	return &response.SettlementBatchResponse{
+		ID:           batch.BatchID,
+		MerchantID:   batch.MerchantID,
+		CardNumber:   batch.CardNumber,
+		Cutoff:       batch.Cutoff,
+		Status:       batch.Status,
+		ItemCount:    batch.ItemCount,
+		GrossAmount:  batch.GrossAmount,
+		RefundAmount: batch.RefundAmount,
+		FeeAmount:    batch.FeeAmount,
+		NetAmount:    batch.NetAmount,
+		Error:        batch.Error,
+		CreatedAt:    batch.CreatedAt,
+		PaidAt:       batch.PaidAt,
+	}
+}
+
+func (s *settlementResponseMapper) ToSettlementBatchesResponse(batches []*record.SettlementBatchRecord) []*response.SettlementBatchResponse {
+	responses := make([]*response.SettlementBatchResponse, 0, len(batches))
+	for _, batch := range batches {
+		responses = append(responses, s.ToSettlementBatchResponse(*batch))
+	}
+	return responses
+}
+
+func (s *settlementResponseMapper) ToSettlementItemResponse(item record.SettlementItemRecord) *response.SettlementItemResponse {
+	res := &response.SettlementItemResponse{
+		ID:            item.ItemID,
+		TransactionID: item.TransactionID,
+		Type:          item.Type,
+		ReferenceID:   item.ReferenceID,
+		Amount:        item.Amount,
+		Fee:           item.Fee,
+		NetAmount:     item.Amount - item.Fee,
+		CreatedAt:     item.CreatedAt,
+	}
+
+	if item.BatchID != 0 {
+		batchID := item.BatchID
+		res.BatchID = &batchID
+	}
+
+	return res
+}
+
+func (s *settlementResponseMapper) ToSettlementItemsResponse(items []*record.SettlementItemRecord) []*response.SettlementItemResponse {
+	responses := make([]*response.SettlementItemResponse, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, s.ToSettlementItemResponse(*item))
+	}
+	return responses
+}
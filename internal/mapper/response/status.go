@@ -0,0 +1,19 @@
+package responseMapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+)
+
+func toStatusHistoryResponse(history []record.StatusChangeRecord) []response.StatusChangeResponse {
+	responses := make([]response.StatusChangeResponse, 0, len(history))
+
+	for _, change := range history {
+		responses = append(responses, response.StatusChangeResponse{
+			Status:    change.Status,
+			ChangedAt: change.ChangedAt,
+		})
+	}
+
+	return responses
+}
@@ -14,12 +14,14 @@ func NewTopupResponseMapper() *topupResponseMapper {
 
 func (s *topupResponseMapper) ToTopupResponse(topup record.TopupRecord) *response.TopupResponse {
 	return &response.TopupResponse{
-		ID:          topup.TopupID,
-		CardNumber:  topup.CardNumber,
-		TopupNo:     topup.TopupNo,
-		TopupAmount: topup.TopupAmount,
-		TopupMethod: topup.TopupMethod,
-		TopupTime:   topup.TopupTime,
+		ID:            topup.TopupID,
+		CardNumber:    topup.CardNumber,
+		TopupNo:       topup.TopupNo,
+		TopupAmount:   topup.TopupAmount,
+		TopupMethod:   topup.TopupMethod,
+		TopupTime:     topup.TopupTime,
+		Status:        topup.Status,
+		StatusHistory: toStatusHistoryResponse(topup.StatusHistory),
 	}
 }
 
@@ -17,8 +17,11 @@ func (s *transactionResponseMapper) ToTransactionResponse(transaction record.Tra
 		ID:              transaction.TransactionID,
 		CardNumber:      transaction.CardNumber,
 		Amount:          transaction.Amount,
+		Fee:             transaction.Fee,
 		PaymentMethod:   transaction.PaymentMethod,
 		TransactionTime: transaction.TransactionTime,
+		Status:          transaction.Status,
+		StatusHistory:   toStatusHistoryResponse(transaction.StatusHistory),
 	}
 }
 
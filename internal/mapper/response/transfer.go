@@ -19,6 +19,8 @@ func (s *transferResponseMapper) ToTransferResponse(transfer record.TransferReco
 		TransferTo:     transfer.TransferTo,
 		TransferAmount: transfer.TransferAmount,
 		TransferTime:   transfer.TransferTime,
+		Status:         transfer.Status,
+		StatusHistory:  toStatusHistoryResponse(transfer.StatusHistory),
 	}
 }
 
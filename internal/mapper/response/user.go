@@ -14,11 +14,15 @@ func NewUserResponseMapper() *userResponseMapper {
 
 func (s *userResponseMapper) ToUserResponse(user record.UserRecord) *response.UserResponse {
 	return &response.UserResponse{
-		ID:          user.UserID,
-		FirstName:   user.FirstName,
-		LastName:    user.LastName,
-		Email:       user.Email,
-		NocTransfer: user.NocTransfer,
+		ID:               user.UserID,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Email:            user.Email,
+		Role:             user.Role,
+		Status:           user.Status,
+		NocTransfer:      user.NocTransfer,
+		HasPin:           user.Pin != nil,
+		TwoFactorEnabled: user.TotpEnabled,
 	}
 }
 
@@ -0,0 +1,74 @@
+package responseMapper
+
+import (
+	"encoding/json"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+)
+
+type webhookResponseMapper struct {
+}
+
+func NewWebhookResponseMapper() *webhookResponseMapper {
+	return &webhookResponseMapper{}
+}
+
+func (s *webhookResponseMapper) ToWebhookEndpointResponse(endpoint record.WebhookEndpointRecord) *response.WebhookEndpointResponse {
+	return &response.WebhookEndpointResponse{
+		ID:         endpoint.EndpointID,
+		MerchantID: endpoint.MerchantID,
+		URL:        endpoint.URL,
+		Events:     endpoint.Events,
+		Active:     endpoint.Active,
+		CreatedAt:  endpoint.CreatedAt,
+		UpdatedAt:  endpoint.UpdatedAt,
+	}
+}
+
+func (s *webhookResponseMapper) ToWebhookEndpointsResponse(endpoints []*record.WebhookEndpointRecord) []*response.WebhookEndpointResponse {
+	responses := make([]*response.WebhookEndpointResponse, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		responses = append(responses, s.ToWebhookEndpointResponse(*endpoint))
+	}
+	return responses
+}
+
+func (s *webhookResponseMapper) ToWebhookDeliveryResponse(delivery record.WebhookDeliveryRecord) *response.WebhookDeliveryResponse {
+	attempts := make([]*response.WebhookAttemptResponse, 0, len(delivery.Attempts))
+	for _, attempt := range delivery.Attempts {
+		attempts = append(attempts, &response.WebhookAttemptResponse{
+			AttemptedAt: attempt.AttemptedAt,
+			StatusCode:  attempt.StatusCode,
+			Error:       attempt.Error,
+			DurationMs:  attempt.DurationMs,
+		})
+	}
+
+	res := &response.WebhookDeliveryResponse{
+		ID:            delivery.DeliveryID,
+		EndpointID:    delivery.EndpointID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Payload:       json.RawMessage(delivery.Payload),
+		Status:        delivery.Status,
+		Attempts:      attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+		UpdatedAt:     delivery.UpdatedAt,
+	}
+
+	if delivery.RedeliveryOf != 0 {
+		redeliveryOf := delivery.RedeliveryOf
+		res.RedeliveryOf = &redeliveryOf
+	}
+
+	return res
+}
+
+func (s *webhookResponseMapper) ToWebhookDeliveriesResponse(deliveries []*record.WebhookDeliveryRecord) []*response.WebhookDeliveryResponse {
+	responses := make([]*response.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		responses = append(responses, s.ToWebhookDeliveryResponse(*delivery))
+	}
+	return responses
+}
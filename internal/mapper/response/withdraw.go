@@ -18,6 +18,8 @@ func (s *withdrawResponseMapper) ToWithdrawResponse(withdraw record.WithdrawReco
 		CardNumber:     withdraw.CardNumber,
 		WithdrawAmount: withdraw.WithdrawAmount,
 		WithdrawTime:   withdraw.WithdrawTime,
+		Status:         withdraw.Status,
+		StatusHistory:  toStatusHistoryResponse(withdraw.StatusHistory),
 	}
 }
 
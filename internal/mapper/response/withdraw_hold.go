@@ -0,0 +1,41 @@
+package responseMapper
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+)
+
+type withdrawHoldResponseMapper struct {
+}
+
+func NewWithdrawHoldResponseMapper() *withdrawHoldResponseMapper {
+	return &withdrawHoldResponseMapper{}
+}
+
+func (s *withdrawHoldResponseMapper) ToWithdrawHoldResponse(hold record.WithdrawHoldRecord) *response.WithdrawHoldResponse {
+	res := &response.WithdrawHoldResponse{
+		ID:             hold.HoldID,
+		CardNumber:     hold.CardNumber,
+		Amount:         hold.Amount,
+		CapturedAmount: hold.CapturedAmount,
+		Status:         hold.Status,
+		ExpiresAt:      hold.ExpiresAt,
+		CreatedAt:      hold.CreatedAt,
+		UpdatedAt:      hold.UpdatedAt,
+	}
+
+	if hold.WithdrawID != 0 {
+		withdrawID := hold.WithdrawID
+		res.WithdrawID = &withdrawID
+	}
+
+	return res
+}
+
+func (s *withdrawHoldResponseMapper) ToWithdrawHoldsResponse(holds []*record.WithdrawHoldRecord) []*response.WithdrawHoldResponse {
+	responses := make([]*response.WithdrawHoldResponse, 0, len(holds))
+	for _, hold := range holds {
+		responses = append(responses, s.ToWithdrawHoldResponse(*hold))
+	}
+	return responses
+}
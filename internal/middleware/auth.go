@@ -6,9 +6,11 @@ import (
 	"payment-mutex/pkg/auth"
 )
 
-func MiddlewareAuth(next http.Handler) http.Handler {
+// MiddlewareAuth lets a request through when verifier accepts its access
+// token.
+func MiddlewareAuth(next http.Handler, verifier *auth.Verifier) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token, err := auth.Authorization(r)
+		claims, err := verifier.Authorization(r)
 
 		if err != nil {
 			res := response.ErrorResponse{
@@ -20,12 +22,14 @@ func MiddlewareAuth(next http.Handler) http.Handler {
 			return
 		}
 
-		r = auth.SetContextUserId(r, token)
+		r = auth.SetContextUserId(r, claims.Subject)
+		r = auth.SetContextRole(r, claims.Role)
+		r = auth.SetContextSessionId(r, claims.SessionID)
 
 		next.ServeHTTP(w, r)
 	})
 }
 
-func MiddlewareAuthAndCors(next http.Handler) http.Handler {
-	return MiddlewareLogging(MiddlewareCors(MiddlewareAuth(next)))
+func MiddlewareAuthAndCors(next http.Handler, verifier *auth.Verifier) http.Handler {
+	return MiddlewareLogging(MiddlewareCors(MiddlewareAuth(next, verifier)))
 }
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"payment-mutex/pkg/auth"
+	"payment-mutex/pkg/idempotency"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *idempotencyRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
+
+// MiddlewareIdempotency replays the stored response for a retried
+// Idempotency-Key. Keys are scoped to the authenticated user and the path, and
+// only successful responses are kept so a failed request can be retried.
+func MiddlewareIdempotency(next http.Handler, store *idempotency.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+
+		if key == "" || r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		scopedKey := auth.GetContextUserId(r) + ":" + r.URL.Path + ":" + key
+		fingerprint := idempotency.Fingerprint([]byte(r.Method), []byte(r.URL.RequestURI()), body)
+
+		stored, err := store.Begin(scopedKey, fingerprint)
+		if errors.Is(err, idempotency.ErrKeyReused) {
+			writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+			return
+		}
+		if errors.Is(err, idempotency.ErrInProgress) {
+			writeError(w, http.StatusConflict, "A request with this Idempotency-Key is still being processed")
+			return
+		}
+
+		if stored != nil {
+			w.Header().Set("Content-Type", stored.ContentType)
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.StatusCode)
+			w.Write(stored.Body)
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: w}
+
+		defer func() {
+			if recorder.statusCode < http.StatusOK || recorder.statusCode >= http.StatusMultipleChoices {
+				store.Release(scopedKey)
+				return
+			}
+
+			store.Complete(scopedKey, idempotency.Response{
+				StatusCode:  recorder.statusCode,
+				ContentType: recorder.Header().Get("Content-Type"),
+				Body:        recorder.body.Bytes(),
+			})
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
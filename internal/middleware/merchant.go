@@ -1,14 +1,21 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"net/http"
+	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/service"
 )
 
-type ApiKeyKey struct{}
+// MerchantIDKey holds the ID of the merchant whose API key authenticated the
+// request.
+type MerchantIDKey struct{}
 
-func MerchantMiddleware(next http.HandlerFunc, merchantService service.MerchantService) http.HandlerFunc {
+// MerchantMiddleware lets a request through when its X-Api-Key is an active
+// key of an active merchant and carries scope.
+func MerchantMiddleware(next http.HandlerFunc, merchantService service.MerchantService, scope string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		apiKey := r.Header.Get("X-Api-Key")
 		if apiKey == "" {
@@ -16,13 +23,54 @@ func MerchantMiddleware(next http.HandlerFunc, merchantService service.MerchantS
 			return
 		}
 
-		_, err := merchantService.FindByApiKey(apiKey)
-		if err != nil {
-			http.Error(w, "Invalid API Key", http.StatusUnauthorized)
+		merchantID, errRes := merchantService.Authenticate(apiKey, scope)
+		if errRes != nil {
+			http.Error(w, errRes.Message, http.StatusUnauthorized)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), ApiKeyKey{}, apiKey)
+		ctx := context.WithValue(r.Context(), MerchantIDKey{}, merchantID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
+
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+)
+
+// MerchantSignatureMiddleware checks the HMAC signature of a merchant request.
+// It runs inside MerchantMiddleware, which names the merchant whose signing
+// secret is used.
+func MerchantSignatureMiddleware(next http.HandlerFunc, merchantService service.MerchantService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		merchantID, ok := r.Context().Value(MerchantIDKey{}).(int)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		errRes := merchantService.VerifySignature(merchantID, requests.SignedRequest{
+			Method:    r.Method,
+			Path:      r.URL.RequestURI(),
+			Body:      body,
+			Timestamp: r.Header.Get(SignatureTimestampHeader),
+			Nonce:     r.Header.Get(SignatureNonceHeader),
+			Signature: r.Header.Get(SignatureHeader),
+		})
+		if errRes != nil {
+			writeError(w, http.StatusUnauthorized, errRes.Message)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
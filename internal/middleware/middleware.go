@@ -1,9 +1,23 @@
 package middleware
 
-import "net/http"
+import (
+	"encoding/json"
+	"net/http"
+	"payment-mutex/internal/domain/response"
+)
 
 func Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		MiddlewareLogging(MiddlewareCors(next)).ServeHTTP(w, r)
 	})
 }
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	json.NewEncoder(w).Encode(response.ErrorResponse{
+		Status:  "error",
+		Message: message,
+	})
+}
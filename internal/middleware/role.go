@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+	"payment-mutex/pkg/auth"
+	"slices"
+)
+
+// MiddlewareRole lets a request through only when the role in its token is
+// one of roles. It must run after MiddlewareAuth.
+func MiddlewareRole(next http.Handler, roles ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(roles, auth.GetContextRole(r)) {
+			writeError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
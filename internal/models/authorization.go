@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	AuthorizationAuthorized = "authorized"
+	AuthorizationCaptured   = "captured"
+	AuthorizationVoided     = "voided"
+	AuthorizationExpired    = "expired"
+)
+
+type Authorization struct {
+	AuthorizationID int       `json:"authorization_id"`
+	MerchantID      int       `json:"merchant_id"`
+	CardNumber      string    `json:"card_number"`
+	Amount          int       `json:"amount"`
+	CapturedAmount  int       `json:"captured_amount"`
+	PaymentMethod   string    `json:"payment_method"`
+	TransactionID   int       `json:"transaction_id"`
+	Status          string    `json:"status"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
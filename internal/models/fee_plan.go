@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// FeePlan is the merchant discount rate charged on payments. Payments made
+// with a method listed in Rates pay that rate, every other method pays the
+// default rate of the plan.
+type FeePlan struct {
+	FeePlanID int    `json:"fee_plan_id"`
+	Name      string `json:"name"`
+	// DefaultRate is in basis points, 150 is 1.5%.
+	DefaultRate  int       `json:"default_rate"`
+	DefaultFixed int       `json:"default_fixed"`
+	Rates        []FeeRate `json:"rates"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// FeeRate is what a payment method pays: Rate basis points of the amount
+// plus Fixed.
+type FeeRate struct {
+	PaymentMethod string `json:"payment_method"`
+	Rate          int    `json:"rate"`
+	Fixed         int    `json:"fixed"`
+}
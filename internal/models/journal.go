@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type JournalEntry struct {
+	EntryID     int       `json:"entry_id"`
+	Reference   string    `json:"reference"`
+	ReferenceID int       `json:"reference_id"`
+	Description string    `json:"description"`
+	Postings    []Posting `json:"postings"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Posting struct {
+	PostingID int    `json:"posting_id"`
+	EntryID   int    `json:"entry_id"`
+	Account   string `json:"account"`
+	Debit     int    `json:"debit"`
+	Credit    int    `json:"credit"`
+}
@@ -1,9 +1,65 @@
 package models
 
+import "time"
+
+// A merchant starts as a draft and is submitted for review by its owner. An
+// admin approves it, which makes its API keys usable, or sends it back to
+// draft. An active merchant can be suspended and approved again, and every
+// merchant can be closed for good.
+const (
+	MerchantStatusDraft         = "draft"
+	MerchantStatusPendingReview = "pending_review"
+	MerchantStatusActive        = "active"
+	MerchantStatusSuspended     = "suspended"
+	MerchantStatusClosed        = "closed"
+)
+
+// Reason codes recorded with each change of merchant status.
+const (
+	MerchantReasonCreated          = "created"
+	MerchantReasonSubmitted        = "submitted"
+	MerchantReasonKybVerified      = "kyb_verified"
+	MerchantReasonKybIncomplete    = "kyb_incomplete"
+	MerchantReasonKybRejected      = "kyb_rejected"
+	MerchantReasonFraudSuspected   = "fraud_suspected"
+	MerchantReasonHighChargebacks  = "high_chargebacks"
+	MerchantReasonComplianceReview = "compliance_review"
+	MerchantReasonReinstated       = "reinstated"
+	MerchantReasonOwnerRequest     = "owner_request"
+	MerchantReasonOther            = "other"
+)
+
 type Merchant struct {
 	MerchantID int    `json:"merchant_id"`
 	Name       string `json:"name"`
-	ApiKey     string `json:"api_key"`
-	UserID     int    `json:"user_id"`
-	Status     string `json:"status"`
+	// ApiKey is the plain text key merchants had before keys were stored
+	// hashed in MerchantApiKey. It is moved there on startup and left empty.
+	ApiKey string `json:"api_key,omitempty"`
+	UserID int    `json:"user_id"`
+	Status string `json:"status"`
+	// StatusHistory is empty for merchants created before the review
+	// workflow, which were made active right away.
+	StatusHistory []MerchantStatusChange `json:"status_history,omitempty"`
+	// SigningSecret signs the requests of the merchant. It is kept in plain
+	// text because verifying an HMAC needs it.
+	SigningSecret string `json:"signing_secret,omitempty"`
+	// RequireSignature refuses merchant requests that are not signed.
+	RequireSignature bool `json:"require_signature"`
+	// WebhookSecret signs the webhooks sent to the merchant.
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+	// SettlementMode is SettlementModeInstant or SettlementModeBatch. Empty
+	// means instant, as it was for merchants created before batches.
+	SettlementMode string `json:"settlement_mode,omitempty"`
+	// FeePlanID is the fee plan charged on the payments of the merchant.
+	// Zero means the merchant pays no fee.
+	FeePlanID int `json:"fee_plan_id,omitempty"`
+}
+
+type MerchantStatusChange struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+	Note   string `json:"note,omitempty"`
+	// ChangedBy is the user who made the change.
+	ChangedBy int       `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
 }
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+const (
+	ApiKeyScopeCharge = "charge"
+	ApiKeyScopeRefund = "refund"
+	ApiKeyScopeRead   = "read"
+)
+
+// MerchantApiKey is one of the keys a merchant calls the transaction API
+// with. Only the hash of the key is kept, next to its first characters so a
+// presented key can be found without comparing it against every hash.
+type MerchantApiKey struct {
+	KeyID      int        `json:"key_id"`
+	MerchantID int        `json:"merchant_id"`
+	Name       string     `json:"name"`
+	Lookup     string     `json:"lookup"`
+	Hash       string     `json:"hash"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	// ExpiresAt is set on a key that was rotated, so it keeps working
+	// while clients move over to its replacement.
+	ExpiresAt *time.Time `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	SessionRevokedLogout = "logout"
+	SessionRevokedReuse  = "refresh_token_reuse"
+	// SessionRevokedPasswordReset signs out every device after the password
+	// was reset, since whoever knew the old one may still be signed in.
+	SessionRevokedPasswordReset = "password_reset"
+)
+
+// Session is one login. Every refresh token issued for it carries the
+// generation it was issued at, and only the token of the current generation
+// can be exchanged for a new pair.
+type Session struct {
+	SessionID     int        `json:"session_id"`
+	UserID        int        `json:"user_id"`
+	Generation    int        `json:"generation"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	RevokedReason string     `json:"revoked_reason"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
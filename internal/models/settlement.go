@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// A merchant in SettlementModeInstant is paid on its card as each
+// transaction succeeds. In SettlementModeBatch the proceeds accrue in a
+// pending balance that is paid out, less fees, once a day.
+const (
+	SettlementModeInstant = "instant"
+	SettlementModeBatch   = "batch"
+)
+
+const (
+	SettlementItemPayment    = "payment"
+	SettlementItemAdjustment = "adjustment"
+	SettlementItemRefund     = "refund"
+	SettlementItemReversal   = "reversal"
+)
+
+const (
+	SettlementBatchPending = "pending"
+	SettlementBatchPaid    = "paid"
+	SettlementBatchFailed  = "failed"
+)
+
+// SettlementItem is one movement of the pending balance of a merchant. Amount
+// is negative for movements that take money back, such as refunds.
+type SettlementItem struct {
+	ItemID        int    `json:"item_id"`
+	MerchantID    int    `json:"merchant_id"`
+	TransactionID int    `json:"transaction_id"`
+	Type          string `json:"type"`
+	// ReferenceID is the refund of a refund item and the transaction
+	// otherwise.
+	ReferenceID int `json:"reference_id"`
+	Amount      int `json:"amount"`
+	Fee         int `json:"fee"`
+	// BatchID is the batch that paid the item out, or zero while it is open.
+	BatchID   int       `json:"batch_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SettlementBatch pays the open items of a merchant created before Cutoff to
+// the merchant card.
+type SettlementBatch struct {
+	BatchID      int        `json:"batch_id"`
+	MerchantID   int        `json:"merchant_id"`
+	CardNumber   string     `json:"card_number"`
+	Cutoff       time.Time  `json:"cutoff"`
+	Status       string     `json:"status"`
+	ItemCount    int        `json:"item_count"`
+	GrossAmount  int        `json:"gross_amount"`
+	RefundAmount int        `json:"refund_amount"`
+	FeeAmount    int        `json:"fee_amount"`
+	NetAmount    int        `json:"net_amount"`
+	Error        string     `json:"error"`
+	CreatedAt    time.Time  `json:"created_at"`
+	PaidAt       *time.Time `json:"paid_at"`
+}
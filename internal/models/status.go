@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusSucceeded  = "succeeded"
+	StatusFailed     = "failed"
+	StatusReversed   = "reversed"
+)
+
+type StatusChange struct {
+	Status    string    `json:"status"`
+	ChangedAt time.Time `json:"changed_at"`
+}
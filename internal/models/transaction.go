@@ -3,10 +3,13 @@ package models
 import "time"
 
 type Transaction struct {
-	TransactionID   int       `json:"transaction_id"`
-	CardNumber      string    `json:"card_number"`
-	Amount          int       `json:"amount"`
-	PaymentMethod   string    `json:"payment_method"`
-	MerchantID      int       `json:"merchant_id"`
-	TransactionTime time.Time `json:"transaction_time"`
+	TransactionID   int            `json:"transaction_id"`
+	CardNumber      string         `json:"card_number"`
+	Amount          int            `json:"amount"`
+	Fee             int            `json:"fee"`
+	PaymentMethod   string         `json:"payment_method"`
+	MerchantID      int            `json:"merchant_id"`
+	TransactionTime time.Time      `json:"transaction_time"`
+	Status          string         `json:"status"`
+	StatusHistory   []StatusChange `json:"status_history"`
 }
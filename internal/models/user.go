@@ -1,9 +1,51 @@
 package models
 
+import "time"
+
+const (
+	RoleAdmin         = "admin"
+	RoleCustomer      = "customer"
+	RoleMerchantOwner = "merchant_owner"
+)
+
+const (
+	// UserStatusPending accounts signed up themselves and cannot log in
+	// until they followed the link sent to their email.
+	UserStatusPending = "pending_verification"
+	UserStatusActive  = "active"
+)
+
 type User struct {
 	UserID    int    `json:"user_id"`
 	FirstName string `json:"firstname"`
 	LastName  string `json:"lastname"`
 	Email     string `json:"email"`
 	Password  string `json:"password"`
+	Role      string `json:"role"`
+	// Status is empty for users stored before email verification existed,
+	// which counts as active.
+	Status          string     `json:"status"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+
+	// LoginLockedUntil is set once too many wrong passwords were entered in
+	// a row, and blocks logins until then or until an admin unlocks it.
+	LoginFailedAttempts int        `json:"login_failed_attempts"`
+	LoginLockedUntil    *time.Time `json:"login_locked_until"`
+
+	// Pin is the hashed transaction PIN. PinLockedUntil is set once too many
+	// wrong PINs were entered in a row.
+	Pin               string     `json:"pin"`
+	PinFailedAttempts int        `json:"pin_failed_attempts"`
+	PinLockedUntil    *time.Time `json:"pin_locked_until"`
+
+	// TotpSecret is the secret of the authenticator app. Login only asks for
+	// a code once TotpEnabled is set by confirming the first one. TotpLastStep
+	// is the last time step a code was accepted for, so a code works once.
+	TotpSecret         string     `json:"totp_secret"`
+	TotpEnabled        bool       `json:"totp_enabled"`
+	TotpLastStep       int64      `json:"totp_last_step"`
+	TotpFailedAttempts int        `json:"totp_failed_attempts"`
+	TotpLockedUntil    *time.Time `json:"totp_locked_until"`
+	// RecoveryCodes are the SHA-256 hashes of the recovery codes not used yet.
+	RecoveryCodes []string `json:"recovery_codes"`
 }
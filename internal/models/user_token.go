@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	UserTokenEmailVerification = "email_verification"
+	UserTokenPasswordReset     = "password_reset"
+)
+
+// UserToken backs a signed link sent to a user by email. The link carries
+// the ID of the token, which can be used once and only until ExpiresAt.
+type UserToken struct {
+	TokenID   int        `json:"token_id"`
+	UserID    int        `json:"user_id"`
+	Purpose   string     `json:"purpose"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
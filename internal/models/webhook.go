@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+const (
+	WebhookEventTransactionCreated       = "transaction.created"
+	WebhookEventTransactionUpdated       = "transaction.updated"
+	WebhookEventTransactionStatusChanged = "transaction.status_changed"
+	WebhookEventTransactionRefunded      = "transaction.refunded"
+	WebhookEventAuthorizationCreated     = "authorization.created"
+	WebhookEventAuthorizationCaptured    = "authorization.captured"
+	WebhookEventAuthorizationVoided      = "authorization.voided"
+	WebhookEventSettlementPaid           = "settlement.paid"
+	WebhookEventCheckoutCompleted        = "checkout.completed"
+	WebhookEventCheckoutExpired          = "checkout.expired"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEndpoint is a URL of a merchant that receives the events it is
+// subscribed to.
+type WebhookEndpoint struct {
+	EndpointID int       `json:"endpoint_id"`
+	MerchantID int       `json:"merchant_id"`
+	URL        string    `json:"url"`
+	Events     []string  `json:"events"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent to one endpoint, with every attempt made
+// to send it.
+type WebhookDelivery struct {
+	DeliveryID    int              `json:"delivery_id"`
+	EndpointID    int              `json:"endpoint_id"`
+	MerchantID    int              `json:"merchant_id"`
+	EventID       string           `json:"event_id"`
+	EventType     string           `json:"event_type"`
+	Payload       string           `json:"payload"`
+	Status        string           `json:"status"`
+	Attempts      []WebhookAttempt `json:"attempts"`
+	NextAttemptAt *time.Time       `json:"next_attempt_at"`
+	// RedeliveryOf is the delivery this one was sent again for, or zero.
+	RedeliveryOf int       `json:"redelivery_of"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type WebhookAttempt struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error"`
+	DurationMs  int64     `json:"duration_ms"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	WithdrawHoldHeld     = "held"
+	WithdrawHoldCaptured = "captured"
+	WithdrawHoldVoided   = "voided"
+	WithdrawHoldExpired  = "expired"
+)
+
+type WithdrawHold struct {
+	HoldID         int       `json:"hold_id"`
+	CardNumber     string    `json:"card_number"`
+	Amount         int       `json:"amount"`
+	CapturedAmount int       `json:"captured_amount"`
+	WithdrawID     int       `json:"withdraw_id"`
+	Status         string    `json:"status"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
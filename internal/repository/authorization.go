@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sync"
+	"time"
+)
+
+type authorizationRepository struct {
+	mu             sync.RWMutex
+	authorizations map[int]models.Authorization
+	nextID         int
+	store          storage
+	undo           *undoLog
+	mapping        recordmapper.AuthorizationRecordMapping
+}
+
+func NewAuthorizationRepository(mapping recordmapper.AuthorizationRecordMapping) *authorizationRepository {
+	return &authorizationRepository{
+		authorizations: make(map[int]models.Authorization),
+		nextID:         1,
+		mapping:        mapping,
+	}
+}
+
+func (ds *authorizationRepository) Read(authorizationID int) (*record.AuthorizationRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	authorization, ok := ds.authorizations[authorizationID]
+	if !ok {
+		return nil, fmt.Errorf("authorization with ID %d not found", authorizationID)
+	}
+
+	return ds.mapping.ToAuthorizationRecord(authorization), nil
+}
+
+func (ds *authorizationRepository) ReadExpired(now time.Time) ([]*record.AuthorizationRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	expired := make([]models.Authorization, 0)
+
+	for _, authorization := range ds.authorizations {
+		if authorization.Status == models.AuthorizationAuthorized && !now.Before(authorization.ExpiresAt) {
+			expired = append(expired, authorization)
+		}
+	}
+
+	return ds.mapping.ToAuthorizationsRecord(expired), nil
+}
+
+func (ds *authorizationRepository) Create(request requests.CreateAuthorizationRequest) (*record.AuthorizationRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	now := time.Now()
+
+	authorization := models.Authorization{
+		AuthorizationID: ds.nextID,
+		MerchantID:      *request.MerchantID,
+		CardNumber:      request.CardNumber,
+		Amount:          request.Amount,
+		PaymentMethod:   request.PaymentMethod,
+		Status:          models.AuthorizationAuthorized,
+		ExpiresAt:       request.ExpiresAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := putItem(ds.authorizations, authorization.AuthorizationID, authorization, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToAuthorizationRecord(authorization), nil
+}
+
+func (ds *authorizationRepository) UpdateStatus(request requests.UpdateAuthorizationStatus) (*record.AuthorizationRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.updateStatus(request)
+}
+
+func (ds *authorizationRepository) updateStatus(request requests.UpdateAuthorizationStatus) (*record.AuthorizationRecord, error) {
+	authorization, ok := ds.authorizations[request.AuthorizationID]
+	if !ok {
+		return nil, fmt.Errorf("authorization with ID %d not found", request.AuthorizationID)
+	}
+
+	authorization.Status = request.Status
+	authorization.CapturedAmount = request.CapturedAmount
+	authorization.TransactionID = request.TransactionID
+	authorization.UpdatedAt = time.Now()
+
+	if err := putItem(ds.authorizations, request.AuthorizationID, authorization, ds.persist, "UpdateStatus"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToAuthorizationRecord(authorization), nil
+}
+
+func (ds *authorizationRepository) Delete(authorizationID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if _, ok := ds.authorizations[authorizationID]; ok {
+		return deleteItem(ds.authorizations, authorizationID, ds.persist, "Delete")
+	}
+
+	return fmt.Errorf("authorization with ID %d not found", authorizationID)
+}
+
+func (ds *authorizationRepository) tableName() string {
+	return "authorizations"
+}
+
+func (ds *authorizationRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *authorizationRepository) currentStorage() storage {
+	return ds.store
+}
+
+func (ds *authorizationRepository) state() tableState[models.Authorization] {
+	return tableState[models.Authorization]{Items: ds.authorizations, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage and, inside a unit of work, to its
+// undo log. It must be called with ds.mu held.
+func (ds *authorizationRepository) persist(c change) error {
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	if ds.store != nil {
+		if err := ds.store.write(c, ds.state()); err != nil {
+			return err
+		}
+	}
+
+	ds.undo.record(ds, c.Before)
+
+	return nil
+}
+
+// revert puts rows back the way a unit of work found them. It must be called
+// with ds.mu held.
+func (ds *authorizationRepository) revert(before map[int]any) error {
+	revertTable(ds.authorizations, before)
+
+	if ds.store == nil {
+		return nil
+	}
+
+	return ds.store.write(change{Table: ds.tableName(), Call: "Rollback", Rows: before, NextID: ds.nextID}, ds.state())
+}
+
+func (ds *authorizationRepository) lock() {
+	ds.mu.Lock()
+}
+
+func (ds *authorizationRepository) unlock() {
+	ds.mu.Unlock()
+}
+
+func (ds *authorizationRepository) useUndoLog(u *undoLog) {
+	ds.undo = u
+}
+
+func (ds *authorizationRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *authorizationRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.authorizations, &ds.nextID)
+}
+
+func (ds *authorizationRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.authorizations, &ds.nextID, nextID)
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"fmt"
 	"payment-mutex/internal/domain/record"
 	"payment-mutex/internal/domain/requests"
@@ -16,6 +17,7 @@ type cardRepository struct {
 	mu      sync.RWMutex
 	cards   map[int]models.Card
 	nextID  int
+	store   storage
 	mapping recordmapper.CardRecordMapping
 }
 
@@ -149,7 +151,10 @@ func (ds *cardRepository) Create(request requests.CreateCardRequest) (*record.Ca
 		CardProvider: request.CardProvider,
 	}
 
-	ds.cards[card.CardID] = card
+	if err := putItem(ds.cards, card.CardID, card, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
 	ds.nextID++
 
 	return ds.mapping.ToCardRecord(card), nil
@@ -171,7 +176,9 @@ func (ds *cardRepository) Update(request requests.UpdateCardRequest) (*record.Ca
 	card.CVV = request.CVV
 	card.CardProvider = request.CardProvider
 
-	ds.cards[card.CardID] = card
+	if err := putItem(ds.cards, card.CardID, card, ds.persist, "Update"); err != nil {
+		return nil, err
+	}
 
 	return ds.mapping.ToCardRecord(card), nil
 }
@@ -182,9 +189,53 @@ func (ds *cardRepository) Delete(cardID int) error {
 	defer ds.mu.Unlock()
 
 	if _, ok := ds.cards[cardID]; ok {
-		delete(ds.cards, cardID)
-		return nil
+		return deleteItem(ds.cards, cardID, ds.persist, "Delete")
 	}
 
 	return fmt.Errorf("card with ID %d not found", cardID)
 }
+
+func (ds *cardRepository) tableName() string {
+	return "cards"
+}
+
+func (ds *cardRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *cardRepository) state() tableState[models.Card] {
+	return tableState[models.Card]{Items: ds.cards, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *cardRepository) persist(c change) error {
+	if ds.store == nil {
+		return nil
+	}
+
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *cardRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *cardRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.cards, &ds.nextID)
+}
+
+func (ds *cardRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.cards, &ds.nextID, nextID)
+}
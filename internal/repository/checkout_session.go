@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sync"
+	"time"
+)
+
+// ErrCheckoutSessionStatusChanged is returned when a session no longer has
+// the status an update expected it to have.
+var ErrCheckoutSessionStatusChanged = errors.New("checkout session status changed")
+
+type checkoutSessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[int]models.CheckoutSession
+	nextID   int
+	store    storage
+	mapping  recordmapper.CheckoutSessionRecordMapping
+}
+
+func NewCheckoutSessionRepository(mapping recordmapper.CheckoutSessionRecordMapping) *checkoutSessionRepository {
+	return &checkoutSessionRepository{
+		sessions: make(map[int]models.CheckoutSession),
+		nextID:   1,
+		mapping:  mapping,
+	}
+}
+
+func (ds *checkoutSessionRepository) ReadBySessionID(sessionID string) (*record.CheckoutSessionRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	for _, session := range ds.sessions {
+		if session.SessionID == sessionID {
+			return ds.mapping.ToCheckoutSessionRecord(session), nil
+		}
+	}
+
+	return nil, fmt.Errorf("checkout session %s not found", sessionID)
+}
+
+func (ds *checkoutSessionRepository) ReadExpired(now time.Time) ([]*record.CheckoutSessionRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	expired := make([]models.CheckoutSession, 0)
+
+	for _, session := range ds.sessions {
+		if session.Status == models.CheckoutSessionOpen && !now.Before(session.ExpiresAt) {
+			expired = append(expired, session)
+		}
+	}
+
+	return ds.mapping.ToCheckoutSessionsRecord(expired), nil
+}
+
+func (ds *checkoutSessionRepository) Create(request requests.CreateCheckoutSessionRequest) (*record.CheckoutSessionRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	now := time.Now()
+
+	session := models.CheckoutSession{
+		CheckoutSessionID: ds.nextID,
+		SessionID:         request.SessionID,
+		MerchantID:        *request.MerchantID,
+		Amount:            request.Amount,
+		Reference:         request.Reference,
+		SuccessURL:        request.SuccessURL,
+		CancelURL:         request.CancelURL,
+		Status:            models.CheckoutSessionOpen,
+		ExpiresAt:         request.ExpiresAt,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := putItem(ds.sessions, session.CheckoutSessionID, session, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToCheckoutSessionRecord(session), nil
+}
+
+// UpdateStatus moves the session to request.Status. When request.From is
+// set, checking and moving happen under one lock, so of two callers moving
+// the session out of the same status only one succeeds and the other sees
+// ErrCheckoutSessionStatusChanged.
+func (ds *checkoutSessionRepository) UpdateStatus(request requests.UpdateCheckoutSessionStatus) (*record.CheckoutSessionRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	session, ok := ds.sessions[request.CheckoutSessionID]
+	if !ok {
+		return nil, fmt.Errorf("checkout session with ID %d not found", request.CheckoutSessionID)
+	}
+
+	if request.From != "" && session.Status != request.From {
+		return nil, ErrCheckoutSessionStatusChanged
+	}
+
+	now := time.Now()
+
+	session.Status = request.Status
+	session.CardNumber = request.CardNumber
+	session.PaymentMethod = request.PaymentMethod
+	session.TransactionID = request.TransactionID
+	session.UpdatedAt = now
+
+	if request.Status == models.CheckoutSessionCompleted {
+		session.CompletedAt = &now
+	}
+
+	if err := putItem(ds.sessions, request.CheckoutSessionID, session, ds.persist, "UpdateStatus"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToCheckoutSessionRecord(session), nil
+}
+
+func (ds *checkoutSessionRepository) tableName() string {
+	return "checkout_sessions"
+}
+
+func (ds *checkoutSessionRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *checkoutSessionRepository) state() tableState[models.CheckoutSession] {
+	return tableState[models.CheckoutSession]{Items: ds.sessions, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *checkoutSessionRepository) persist(c change) error {
+	if ds.store == nil {
+		return nil
+	}
+
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *checkoutSessionRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *checkoutSessionRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.sessions, &ds.nextID)
+}
+
+func (ds *checkoutSessionRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.sessions, &ds.nextID, nextID)
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+type feePlanRepository struct {
+	mu      sync.RWMutex
+	plans   map[int]models.FeePlan
+	nextID  int
+	store   storage
+	mapping recordmapper.FeePlanRecordMapping
+}
+
+func NewFeePlanRepository(mapping recordmapper.FeePlanRecordMapping) *feePlanRepository {
+	return &feePlanRepository{
+		plans:   make(map[int]models.FeePlan),
+		nextID:  1,
+		mapping: mapping,
+	}
+}
+
+func (ds *feePlanRepository) ReadAll() ([]*record.FeePlanRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	plans := make([]models.FeePlan, 0, len(ds.plans))
+
+	for id := 1; id < ds.nextID; id++ {
+		if plan, ok := ds.plans[id]; ok {
+			plans = append(plans, plan)
+		}
+	}
+
+	return ds.mapping.ToFeePlansRecord(plans), nil
+}
+
+func (ds *feePlanRepository) Read(feePlanID int) (*record.FeePlanRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	plan, ok := ds.plans[feePlanID]
+	if !ok {
+		return nil, fmt.Errorf("fee plan with ID %d not found", feePlanID)
+	}
+
+	return ds.mapping.ToFeePlanRecord(plan), nil
+}
+
+func (ds *feePlanRepository) Create(request requests.CreateFeePlanRequest) (*record.FeePlanRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	now := time.Now()
+
+	plan := models.FeePlan{
+		FeePlanID:    ds.nextID,
+		Name:         request.Name,
+		DefaultRate:  request.DefaultRate,
+		DefaultFixed: request.DefaultFixed,
+		Rates:        toFeeRates(request.Rates),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := putItem(ds.plans, plan.FeePlanID, plan, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToFeePlanRecord(plan), nil
+}
+
+func (ds *feePlanRepository) Update(request requests.UpdateFeePlanRequest) (*record.FeePlanRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	plan, ok := ds.plans[request.FeePlanID]
+	if !ok {
+		return nil, fmt.Errorf("fee plan with ID %d not found", request.FeePlanID)
+	}
+
+	plan.Name = request.Name
+	plan.DefaultRate = request.DefaultRate
+	plan.DefaultFixed = request.DefaultFixed
+	plan.Rates = toFeeRates(request.Rates)
+	plan.UpdatedAt = time.Now()
+
+	if err := putItem(ds.plans, plan.FeePlanID, plan, ds.persist, "Update"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToFeePlanRecord(plan), nil
+}
+
+func (ds *feePlanRepository) Delete(feePlanID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if _, ok := ds.plans[feePlanID]; ok {
+		return deleteItem(ds.plans, feePlanID, ds.persist, "Delete")
+	}
+
+	return fmt.Errorf("fee plan with ID %d not found", feePlanID)
+}
+
+// toFeeRates stores payment methods in lower case, the way they are matched.
+func toFeeRates(rates []requests.FeeRateRequest) []models.FeeRate {
+	feeRates := make([]models.FeeRate, 0, len(rates))
+
+	for _, rate := range rates {
+		feeRates = append(feeRates, models.FeeRate{
+			PaymentMethod: strings.ToLower(rate.PaymentMethod),
+			Rate:          rate.Rate,
+			Fixed:         rate.Fixed,
+		})
+	}
+
+	return feeRates
+}
+
+func (ds *feePlanRepository) tableName() string {
+	return "fee_plans"
+}
+
+func (ds *feePlanRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *feePlanRepository) state() tableState[models.FeePlan] {
+	return tableState[models.FeePlan]{Items: ds.plans, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *feePlanRepository) persist(c change) error {
+	if ds.store == nil {
+		return nil
+	}
+
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *feePlanRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *feePlanRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.plans, &ds.nextID)
+}
+
+func (ds *feePlanRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.plans, &ds.nextID, nextID)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"payment-mutex/internal/domain/record"
 	"payment-mutex/internal/domain/requests"
+	"time"
 )
 
 type UserRepository interface {
@@ -11,6 +12,13 @@ type UserRepository interface {
 	ReadByEmail(email string) (*record.UserRecord, error)
 	Create(request requests.CreateUserRequest) (*record.UserRecord, error)
 	Update(request requests.UpdateUserRequest) (*record.UserRecord, error)
+	UpdateRole(request requests.UpdateUserRoleRequest) (*record.UserRecord, error)
+	MarkEmailVerified(userID int) (*record.UserRecord, error)
+	UpdatePassword(userID int, password string) (*record.UserRecord, error)
+	UpdateLoginAttempts(userID int, failedAttempts int, lockedUntil *time.Time) (*record.UserRecord, error)
+	UpdatePin(userID int, pin string) (*record.UserRecord, error)
+	UpdatePinAttempts(userID int, failedAttempts int, lockedUntil *time.Time) (*record.UserRecord, error)
+	UpdateTotp(request requests.UpdateUserTotpRequest) (*record.UserRecord, error)
 	Delete(userID int) error
 }
 
@@ -21,36 +29,40 @@ type SaldoRepository interface {
 	Create(request requests.CreateSaldoRequest) (*record.SaldoRecord, error)
 	Update(request requests.UpdateSaldoRequest) (*record.SaldoRecord, error)
 	UpdateBalance(request requests.UpdateSaldoBalance) (*record.SaldoRecord, error)
+	UpdateBalances(request requests.UpdateSaldoBalances) ([]*record.SaldoRecord, error)
 	UpdateSaldoWithdraw(request requests.UpdateSaldoWithdraw) (*record.SaldoRecord, error)
 	Delete(saldoID int) error
 }
 
 type TopupRepository interface {
-	ReadAll(page int, pageSize int, search string) ([]*record.TopupRecord, int, error)
+	ReadAll(page int, pageSize int, search string, status string) ([]*record.TopupRecord, int, error)
 	Read(topupID int) (*record.TopupRecord, error)
 	CountByDate(date string) (int, error)
 	Create(request requests.CreateTopupRequest) (*record.TopupRecord, error)
 	Update(request requests.UpdateTopupRequest) (*record.TopupRecord, error)
 	UpdateAmount(request requests.UpdateTopupAmount) (*record.TopupRecord, error)
+	UpdateStatus(topupID int, status string) (*record.TopupRecord, error)
 	Delete(topupID int) error
 }
 
 type TransferRepository interface {
-	ReadAll(page int, pageSize int, search string) ([]*record.TransferRecord, int, error)
+	ReadAll(page int, pageSize int, search string, status string) ([]*record.TransferRecord, int, error)
 	Read(transferID int) (*record.TransferRecord, error)
 	CountByDate(date string) (int, error)
 	CountAll() (int, error)
 	Create(request requests.CreateTransferRequest) (*record.TransferRecord, error)
 	Update(request requests.UpdateTransferRequest) (*record.TransferRecord, error)
+	UpdateStatus(transferID int, status string) (*record.TransferRecord, error)
 	Delete(transferID int) error
 }
 
 type WithdrawRepository interface {
-	ReadAll(page int, pageSize int, search string) ([]*record.WithdrawRecord, int, error)
+	ReadAll(page int, pageSize int, search string, status string) ([]*record.WithdrawRecord, int, error)
 	Read(withdrawID int) (*record.WithdrawRecord, error)
 	CountByDate(date string) (int, error)
 	Create(request requests.CreateWithdrawRequest) (*record.WithdrawRecord, error)
 	Update(request requests.UpdateWithdrawRequest) (*record.WithdrawRecord, error)
+	UpdateStatus(withdrawID int, status string) (*record.WithdrawRecord, error)
 	Delete(transferID int) error
 }
 
@@ -66,12 +78,13 @@ type CardRepository interface {
 }
 
 type TransactionRepository interface {
-	ReadAll(page int, pageSize int, search string) ([]*record.TransactionRecord, int, error)
+	ReadAll(page int, pageSize int, search string, status string) ([]*record.TransactionRecord, int, error)
 	CountByDate(date string) (int, error)
 	CountAll() (int, error)
 	Read(transactionID int) (*record.TransactionRecord, error)
 	Create(request requests.CreateTransactionRequest) (*record.TransactionRecord, error)
 	Update(request requests.UpdateTransactionRequest) (*record.TransactionRecord, error)
+	UpdateStatus(transactionID int, status string) (*record.TransactionRecord, error)
 	Delete(transactionID int) error
 }
 
@@ -79,8 +92,117 @@ type MerchantRepository interface {
 	ReadAll(page int, pageSize int, search string) ([]*record.MerchantRecord, int, error)
 	Read(merchantID int) (*record.MerchantRecord, error)
 	ReadByName(name string) (*record.MerchantRecord, error)
-	ReadByApiKey(apiKey string) (*record.MerchantRecord, error)
+	ReadLegacyApiKeys() (map[int]string, error)
+	ClearLegacyApiKey(merchantID int) error
+	UpdateStatus(request requests.UpdateMerchantStatusRequest) (*record.MerchantRecord, error)
+	UpdateSigning(request requests.UpdateMerchantSigningRequest) (*record.MerchantRecord, error)
+	UpdateWebhookSecret(merchantID int, secret string) (*record.MerchantRecord, error)
+	UpdateSettlementMode(merchantID int, mode string) (*record.MerchantRecord, error)
+	UpdateFeePlan(merchantID int, feePlanID int) (*record.MerchantRecord, error)
+	CountByFeePlanID(feePlanID int) (int, error)
 	Create(request requests.CreateMerchantRequest) (*record.MerchantRecord, error)
 	Update(request requests.UpdateMerchantRequest) (*record.MerchantRecord, error)
+	UpdateOwner(request requests.UpdateMerchantOwnerRequest) (*record.MerchantRecord, error)
 	Delete(merchantID int) error
 }
+
+type JournalRepository interface {
+	Read(entryID int) (*record.JournalEntryRecord, error)
+	ReadPostingsByAccount(account string, page int, pageSize int) ([]*record.PostingRecord, int, error)
+	TotalsByAccount(account string) (int, int, error)
+	ExistsByReference(reference string, referenceID int) (bool, error)
+	Create(request requests.CreateJournalEntryRequest) (*record.JournalEntryRecord, error)
+}
+
+type RefundRepository interface {
+	Read(refundID int) (*record.RefundRecord, error)
+	ReadByTransactionID(transactionID int) ([]*record.RefundRecord, error)
+	TotalByTransactionID(transactionID int) (int, error)
+	Create(request requests.CreateRefundRequest) (*record.RefundRecord, error)
+	Delete(refundID int) error
+}
+
+type WithdrawHoldRepository interface {
+	Read(holdID int) (*record.WithdrawHoldRecord, error)
+	ReadExpired(now time.Time) ([]*record.WithdrawHoldRecord, error)
+	Create(request requests.CreateWithdrawHoldRequest) (*record.WithdrawHoldRecord, error)
+	UpdateStatus(request requests.UpdateWithdrawHoldStatus) (*record.WithdrawHoldRecord, error)
+	Delete(holdID int) error
+}
+
+type AuthorizationRepository interface {
+	Read(authorizationID int) (*record.AuthorizationRecord, error)
+	ReadExpired(now time.Time) ([]*record.AuthorizationRecord, error)
+	Create(request requests.CreateAuthorizationRequest) (*record.AuthorizationRecord, error)
+	UpdateStatus(request requests.UpdateAuthorizationStatus) (*record.AuthorizationRecord, error)
+	Delete(authorizationID int) error
+}
+
+type SessionRepository interface {
+	Read(sessionID int) (*record.SessionRecord, error)
+	Create(request requests.CreateSessionRequest) (*record.SessionRecord, error)
+	Rotate(sessionID int, generation int) (*record.SessionRecord, error)
+	Revoke(sessionID int, reason string) (*record.SessionRecord, error)
+	RevokeAll(userID int, reason string) (int, error)
+}
+
+type UserTokenRepository interface {
+	Create(request requests.CreateUserTokenRequest) (*record.UserTokenRecord, error)
+	Consume(tokenID int, purpose string) (*record.UserTokenRecord, error)
+	ConsumeAll(userID int, purpose string) error
+}
+
+type MerchantApiKeyRepository interface {
+	Read(keyID int) (*record.MerchantApiKeyRecord, error)
+	ReadByMerchantID(merchantID int) ([]*record.MerchantApiKeyRecord, error)
+	ReadByLookup(lookup string) (*record.MerchantApiKeyRecord, error)
+	Create(request requests.CreateMerchantApiKeyRequest) (*record.MerchantApiKeyRecord, error)
+	Expire(keyID int, expiresAt time.Time) (*record.MerchantApiKeyRecord, error)
+	Revoke(keyID int) (*record.MerchantApiKeyRecord, error)
+	Touch(keyID int, usedAt time.Time) error
+}
+
+type WebhookEndpointRepository interface {
+	Read(endpointID int) (*record.WebhookEndpointRecord, error)
+	ReadByMerchantID(merchantID int) ([]*record.WebhookEndpointRecord, error)
+	ReadSubscribed(merchantID int, eventType string) ([]*record.WebhookEndpointRecord, error)
+	Create(request requests.CreateWebhookEndpointRequest) (*record.WebhookEndpointRecord, error)
+	Update(request requests.UpdateWebhookEndpointRequest) (*record.WebhookEndpointRecord, error)
+	Delete(endpointID int) error
+}
+
+type WebhookDeliveryRepository interface {
+	Read(deliveryID int) (*record.WebhookDeliveryRecord, error)
+	ReadByMerchantID(merchantID int, endpointID int, page int, pageSize int) ([]*record.WebhookDeliveryRecord, int, error)
+	ReadDue(now time.Time) ([]*record.WebhookDeliveryRecord, error)
+	Create(request requests.CreateWebhookDeliveryRequest) (*record.WebhookDeliveryRecord, error)
+	RecordAttempt(request requests.RecordWebhookAttemptRequest) (*record.WebhookDeliveryRecord, error)
+}
+
+type SettlementItemRepository interface {
+	ReadByBatchID(batchID int) ([]*record.SettlementItemRecord, error)
+	ReadByTransactionID(transactionID int) ([]*record.SettlementItemRecord, error)
+	ReadOpen(merchantID int, cutoff time.Time) ([]*record.SettlementItemRecord, error)
+}
+
+type SettlementBatchRepository interface {
+	Read(batchID int) (*record.SettlementBatchRecord, error)
+	ReadByMerchantID(merchantID int, page int, pageSize int) ([]*record.SettlementBatchRecord, int, error)
+	Create(request requests.CreateSettlementBatchRequest) (*record.SettlementBatchRecord, error)
+	UpdateStatus(request requests.UpdateSettlementBatchStatus) (*record.SettlementBatchRecord, error)
+}
+
+type FeePlanRepository interface {
+	ReadAll() ([]*record.FeePlanRecord, error)
+	Read(feePlanID int) (*record.FeePlanRecord, error)
+	Create(request requests.CreateFeePlanRequest) (*record.FeePlanRecord, error)
+	Update(request requests.UpdateFeePlanRequest) (*record.FeePlanRecord, error)
+	Delete(feePlanID int) error
+}
+
+type CheckoutSessionRepository interface {
+	ReadBySessionID(sessionID string) (*record.CheckoutSessionRecord, error)
+	ReadExpired(now time.Time) ([]*record.CheckoutSessionRecord, error)
+	Create(request requests.CreateCheckoutSessionRequest) (*record.CheckoutSessionRecord, error)
+	UpdateStatus(request requests.UpdateCheckoutSessionStatus) (*record.CheckoutSessionRecord, error)
+}
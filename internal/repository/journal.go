@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+type journalRepository struct {
+	mu            sync.RWMutex
+	entries       []models.JournalEntry
+	nextID        int
+	nextPostingID int
+	store         storage
+	undo          *undoLog
+	mapping       recordmapper.JournalRecordMapping
+}
+
+func NewJournalRepository(mapping recordmapper.JournalRecordMapping) *journalRepository {
+	return &journalRepository{
+		entries:       make([]models.JournalEntry, 0),
+		nextID:        1,
+		nextPostingID: 1,
+		mapping:       mapping,
+	}
+}
+
+// journalState is the stored form of the journal.
+type journalState struct {
+	Entries       []models.JournalEntry `json:"entries"`
+	NextID        int                   `json:"next_id"`
+	NextPostingID int                   `json:"next_posting_id"`
+}
+
+func (ds *journalRepository) Read(entryID int) (*record.JournalEntryRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	for _, entry := range ds.entries {
+		if entry.EntryID == entryID {
+			return ds.mapping.ToJournalEntryRecord(entry), nil
+		}
+	}
+
+	return nil, fmt.Errorf("journal entry with ID %d not found", entryID)
+}
+
+func (ds *journalRepository) ReadPostingsByAccount(account string, page int, pageSize int) ([]*record.PostingRecord, int, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	filteredPostings := make([]*record.PostingRecord, 0)
+
+	for _, entry := range ds.entries {
+		for _, posting := range entry.Postings {
+			if posting.Account == account {
+				filteredPostings = append(filteredPostings, ds.mapping.ToPostingRecord(entry, posting))
+			}
+		}
+	}
+
+	totalRecords := len(filteredPostings)
+
+	start := (page - 1) * pageSize
+	if start >= totalRecords {
+		return nil, totalRecords, nil
+	}
+
+	end := start + pageSize
+	if end > totalRecords {
+		end = totalRecords
+	}
+
+	return filteredPostings[start:end], totalRecords, nil
+}
+
+func (ds *journalRepository) TotalsByAccount(account string) (int, int, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	totalDebit := 0
+	totalCredit := 0
+
+	for _, entry := range ds.entries {
+		for _, posting := range entry.Postings {
+			if posting.Account == account {
+				totalDebit += posting.Debit
+				totalCredit += posting.Credit
+			}
+		}
+	}
+
+	return totalDebit, totalCredit, nil
+}
+
+// ExistsByReference reports whether an entry was posted for the record with
+// referenceID of the kind reference.
+func (ds *journalRepository) ExistsByReference(reference string, referenceID int) (bool, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	for _, entry := range ds.entries {
+		if entry.Reference == reference && entry.ReferenceID == referenceID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (ds *journalRepository) Create(request requests.CreateJournalEntryRequest) (*record.JournalEntryRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.create(request)
+}
+
+func (ds *journalRepository) create(request requests.CreateJournalEntryRequest) (*record.JournalEntryRecord, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	entry := models.JournalEntry{
+		EntryID:     ds.nextID,
+		Reference:   request.Reference,
+		ReferenceID: request.ReferenceID,
+		Description: request.Description,
+		Postings:    make([]models.Posting, 0, len(request.Postings)),
+		CreatedAt:   time.Now(),
+	}
+
+	for _, posting := range request.Postings {
+		entry.Postings = append(entry.Postings, models.Posting{
+			PostingID: ds.nextPostingID,
+			EntryID:   entry.EntryID,
+			Account:   posting.Account,
+			Debit:     posting.Debit,
+			Credit:    posting.Credit,
+		})
+		ds.nextPostingID++
+	}
+
+	ds.entries = append(ds.entries, entry)
+	ds.nextID++
+
+	if err := ds.persist(change{Call: "Create", Rows: map[int]any{entry.EntryID: entry}, Before: map[int]any{entry.EntryID: nil}}); err != nil {
+		ds.entries = ds.entries[:len(ds.entries)-1]
+		ds.nextID--
+		return nil, err
+	}
+
+	return ds.mapping.ToJournalEntryRecord(entry), nil
+}
+
+func (ds *journalRepository) tableName() string {
+	return "journal"
+}
+
+func (ds *journalRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *journalRepository) currentStorage() storage {
+	return ds.store
+}
+
+func (ds *journalRepository) state() journalState {
+	return journalState{Entries: ds.entries, NextID: ds.nextID, NextPostingID: ds.nextPostingID}
+}
+
+// persist hands a change to the storage and, inside a unit of work, to its
+// undo log. It must be called with ds.mu held.
+func (ds *journalRepository) persist(c change) error {
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	if ds.store != nil {
+		if err := ds.store.write(c, ds.state()); err != nil {
+			return err
+		}
+	}
+
+	ds.undo.record(ds, c.Before)
+
+	return nil
+}
+
+// revert drops the entries a unit of work added. Entries are never changed
+// once written, so every row in before is one that did not exist. It must be
+// called with ds.mu held.
+func (ds *journalRepository) revert(before map[int]any) error {
+	for entryID := range before {
+		ds.removeEntry(entryID)
+	}
+
+	if ds.store == nil {
+		return nil
+	}
+
+	return ds.store.write(change{Table: ds.tableName(), Call: "Rollback", Rows: before, NextID: ds.nextID}, ds.state())
+}
+
+func (ds *journalRepository) lock() {
+	ds.mu.Lock()
+}
+
+func (ds *journalRepository) unlock() {
+	ds.mu.Unlock()
+}
+
+func (ds *journalRepository) useUndoLog(u *undoLog) {
+	ds.undo = u
+}
+
+func (ds *journalRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *journalRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	var state journalState
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	ds.entries = make([]models.JournalEntry, 0, len(state.Entries))
+	ds.nextID = max(state.NextID, 1)
+	ds.nextPostingID = max(state.NextPostingID, 1)
+
+	for _, entry := range state.Entries {
+		ds.appendEntry(entry)
+	}
+
+	return nil
+}
+
+// replay appends the entries of a change, or drops them when the change is a
+// rollback. An entry that is already in the journal is left as it is, since
+// entries never change once written.
+func (ds *journalRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	entryIDs := make([]int, 0, len(rows))
+	for id := range rows {
+		entryIDs = append(entryIDs, id)
+	}
+
+	sort.Ints(entryIDs)
+
+	for _, id := range entryIDs {
+		if bytes.Equal(rows[id], []byte("null")) {
+			ds.removeEntry(id)
+			continue
+		}
+
+		if ds.hasEntry(id) {
+			continue
+		}
+
+		var entry models.JournalEntry
+		if err := json.Unmarshal(rows[id], &entry); err != nil {
+			return err
+		}
+
+		ds.appendEntry(entry)
+	}
+
+	ds.nextID = max(ds.nextID, nextID)
+
+	return nil
+}
+
+func (ds *journalRepository) removeEntry(entryID int) {
+	for i, entry := range ds.entries {
+		if entry.EntryID == entryID {
+			ds.entries = append(ds.entries[:i], ds.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+func (ds *journalRepository) hasEntry(entryID int) bool {
+	for _, entry := range ds.entries {
+		if entry.EntryID == entryID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendEntry adds a stored entry and moves the counters past its IDs. It
+// must be called with ds.mu held.
+func (ds *journalRepository) appendEntry(entry models.JournalEntry) {
+	ds.entries = append(ds.entries, entry)
+	ds.nextID = max(ds.nextID, entry.EntryID+1)
+
+	for _, posting := range entry.Postings {
+		ds.nextPostingID = max(ds.nextPostingID, posting.PostingID+1)
+	}
+}
@@ -1,20 +1,22 @@
 package repository
 
 import (
+	"encoding/json"
 	"fmt"
 	"payment-mutex/internal/domain/record"
 	"payment-mutex/internal/domain/requests"
 	recordmapper "payment-mutex/internal/mapper/record"
 	"payment-mutex/internal/models"
-	"payment-mutex/pkg/apikey"
 	"strings"
 	"sync"
+	"time"
 )
 
 type merchantRepository struct {
 	mu        sync.RWMutex
 	merchants map[int]models.Merchant
 	nextID    int
+	store     storage
 	mapping   recordmapper.MerchantRecordMapping
 }
 
@@ -35,7 +37,6 @@ func (ds *merchantRepository) ReadAll(page int, pageSize int, search string) ([]
 	for _, merchant := range ds.merchants {
 		if search == "" ||
 			strings.Contains(strings.ToLower(merchant.Name), strings.ToLower(search)) ||
-			strings.Contains(strings.ToLower(merchant.ApiKey), strings.ToLower(search)) ||
 			strings.Contains(strings.ToLower(merchant.Status), strings.ToLower(search)) {
 			filteredMerchants = append(filteredMerchants, merchant)
 		}
@@ -84,18 +85,51 @@ func (ds *merchantRepository) ReadByName(name string) (*record.MerchantRecord, e
 	return nil, fmt.Errorf("merchant not found")
 }
 
-func (ds *merchantRepository) ReadByApiKey(apiKey string) (*record.MerchantRecord, error) {
+// CountByFeePlanID returns how many merchants are charged by the fee plan.
+func (ds *merchantRepository) CountByFeePlanID(feePlanID int) (int, error) {
 	ds.mu.RLock()
-
 	defer ds.mu.RUnlock()
 
+	count := 0
+
 	for _, merchant := range ds.merchants {
-		if merchant.ApiKey == apiKey {
-			return ds.mapping.ToMerchantRecord(merchant), nil
+		if merchant.FeePlanID == feePlanID {
+			count++
 		}
 	}
 
-	return nil, fmt.Errorf("merchant not found")
+	return count, nil
+}
+
+// ReadLegacyApiKeys returns the plain text keys still stored with merchants,
+// by merchant ID.
+func (ds *merchantRepository) ReadLegacyApiKeys() (map[int]string, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	keys := make(map[int]string)
+
+	for id, merchant := range ds.merchants {
+		if merchant.ApiKey != "" {
+			keys[id] = merchant.ApiKey
+		}
+	}
+
+	return keys, nil
+}
+
+func (ds *merchantRepository) ClearLegacyApiKey(merchantID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	merchant, ok := ds.merchants[merchantID]
+	if !ok {
+		return fmt.Errorf("merchant with id %d not found", merchantID)
+	}
+
+	merchant.ApiKey = ""
+
+	return putItem(ds.merchants, merchantID, merchant, ds.persist, "ClearLegacyApiKey")
 }
 
 func (ds *merchantRepository) Create(request requests.CreateMerchantRequest) (*record.MerchantRecord, error) {
@@ -104,13 +138,20 @@ func (ds *merchantRepository) Create(request requests.CreateMerchantRequest) (*r
 
 	merchant := models.Merchant{
 		MerchantID: ds.nextID,
-		ApiKey:     apikey.GenerateApiKey(),
 		Name:       request.Name,
 		UserID:     request.UserID,
-		Status:     "active",
+		Status:     models.MerchantStatusDraft,
+		StatusHistory: []models.MerchantStatusChange{
+			{Status: models.MerchantStatusDraft, Reason: models.MerchantReasonCreated, ChangedBy: request.UserID, ChangedAt: time.Now()},
+		},
+
+		SettlementMode: models.SettlementModeInstant,
+	}
+
+	if err := putItem(ds.merchants, merchant.MerchantID, merchant, ds.persist, "Create"); err != nil {
+		return nil, err
 	}
 
-	ds.merchants[merchant.MerchantID] = merchant
 	ds.nextID++
 
 	return ds.mapping.ToMerchantRecord(merchant), nil
@@ -128,13 +169,129 @@ func (ds *merchantRepository) Update(request requests.UpdateMerchantRequest) (*r
 	}
 
 	merchant.Name = request.Name
+
+	if err := putItem(ds.merchants, request.MerchantID, merchant, ds.persist, "Update"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToMerchantRecord(merchant), nil
+
+}
+
+func (ds *merchantRepository) UpdateOwner(request requests.UpdateMerchantOwnerRequest) (*record.MerchantRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	merchant, ok := ds.merchants[request.MerchantID]
+	if !ok {
+		return nil, fmt.Errorf("merchant with id %d not found", request.MerchantID)
+	}
+
 	merchant.UserID = request.UserID
+
+	if err := putItem(ds.merchants, request.MerchantID, merchant, ds.persist, "UpdateOwner"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToMerchantRecord(merchant), nil
+}
+
+func (ds *merchantRepository) UpdateStatus(request requests.UpdateMerchantStatusRequest) (*record.MerchantRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	merchant, ok := ds.merchants[request.MerchantID]
+	if !ok {
+		return nil, fmt.Errorf("merchant with id %d not found", request.MerchantID)
+	}
+
 	merchant.Status = request.Status
+	merchant.StatusHistory = append(merchant.StatusHistory, models.MerchantStatusChange{
+		Status:    request.Status,
+		Reason:    request.Reason,
+		Note:      request.Note,
+		ChangedBy: request.ChangedBy,
+		ChangedAt: time.Now(),
+	})
+
+	if err := putItem(ds.merchants, request.MerchantID, merchant, ds.persist, "UpdateStatus"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToMerchantRecord(merchant), nil
+}
+
+func (ds *merchantRepository) UpdateSigning(request requests.UpdateMerchantSigningRequest) (*record.MerchantRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	merchant, ok := ds.merchants[request.MerchantID]
+	if !ok {
+		return nil, fmt.Errorf("merchant with id %d not found", request.MerchantID)
+	}
 
-	ds.merchants[request.MerchantID] = merchant
+	merchant.SigningSecret = request.SigningSecret
+	merchant.RequireSignature = request.RequireSignature
+
+	if err := putItem(ds.merchants, request.MerchantID, merchant, ds.persist, "UpdateSigning"); err != nil {
+		return nil, err
+	}
 
 	return ds.mapping.ToMerchantRecord(merchant), nil
+}
+
+func (ds *merchantRepository) UpdateWebhookSecret(merchantID int, secret string) (*record.MerchantRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	merchant, ok := ds.merchants[merchantID]
+	if !ok {
+		return nil, fmt.Errorf("merchant with id %d not found", merchantID)
+	}
+
+	merchant.WebhookSecret = secret
+
+	if err := putItem(ds.merchants, merchantID, merchant, ds.persist, "UpdateWebhookSecret"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToMerchantRecord(merchant), nil
+}
+
+func (ds *merchantRepository) UpdateSettlementMode(merchantID int, mode string) (*record.MerchantRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	merchant, ok := ds.merchants[merchantID]
+	if !ok {
+		return nil, fmt.Errorf("merchant with id %d not found", merchantID)
+	}
+
+	merchant.SettlementMode = mode
+
+	if err := putItem(ds.merchants, merchantID, merchant, ds.persist, "UpdateSettlementMode"); err != nil {
+		return nil, err
+	}
 
+	return ds.mapping.ToMerchantRecord(merchant), nil
+}
+
+func (ds *merchantRepository) UpdateFeePlan(merchantID int, feePlanID int) (*record.MerchantRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	merchant, ok := ds.merchants[merchantID]
+	if !ok {
+		return nil, fmt.Errorf("merchant with id %d not found", merchantID)
+	}
+
+	merchant.FeePlanID = feePlanID
+
+	if err := putItem(ds.merchants, merchantID, merchant, ds.persist, "UpdateFeePlan"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToMerchantRecord(merchant), nil
 }
 
 func (ds *merchantRepository) Delete(merchantID int) error {
@@ -142,10 +299,53 @@ func (ds *merchantRepository) Delete(merchantID int) error {
 	defer ds.mu.Unlock()
 
 	if _, ok := ds.merchants[merchantID]; ok {
-		delete(ds.merchants, merchantID)
+		return deleteItem(ds.merchants, merchantID, ds.persist, "Delete")
+	}
 
+	return fmt.Errorf("merchant with id %d not found", merchantID)
+}
+
+func (ds *merchantRepository) tableName() string {
+	return "merchants"
+}
+
+func (ds *merchantRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *merchantRepository) state() tableState[models.Merchant] {
+	return tableState[models.Merchant]{Items: ds.merchants, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *merchantRepository) persist(c change) error {
+	if ds.store == nil {
 		return nil
 	}
 
-	return fmt.Errorf("merchant with id %d not found", merchantID)
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *merchantRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *merchantRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.merchants, &ds.nextID)
+}
+
+func (ds *merchantRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.merchants, &ds.nextID, nextID)
 }
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sync"
+	"time"
+)
+
+// lastUsedResolution is how stale the last used time of a key may get. Keys
+// are used on every merchant call, writing each use would turn reads into
+// writes.
+const lastUsedResolution = time.Minute
+
+type merchantApiKeyRepository struct {
+	mu     sync.RWMutex
+	keys   map[int]models.MerchantApiKey
+	nextID int
+	// byLookup finds a key by the part stored in plain text.
+	byLookup map[string]int
+	store    storage
+	mapping  recordmapper.MerchantApiKeyRecordMapping
+}
+
+func NewMerchantApiKeyRepository(mapping recordmapper.MerchantApiKeyRecordMapping) *merchantApiKeyRepository {
+	return &merchantApiKeyRepository{
+		keys:     make(map[int]models.MerchantApiKey),
+		nextID:   1,
+		byLookup: make(map[string]int),
+		mapping:  mapping,
+	}
+}
+
+func (ds *merchantApiKeyRepository) Read(keyID int) (*record.MerchantApiKeyRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	key, ok := ds.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("api key with ID %d not found", keyID)
+	}
+
+	return ds.mapping.ToMerchantApiKeyRecord(key), nil
+}
+
+func (ds *merchantApiKeyRepository) ReadByMerchantID(merchantID int) ([]*record.MerchantApiKeyRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	keys := make([]models.MerchantApiKey, 0)
+
+	for id := 1; id < ds.nextID; id++ {
+		if key, ok := ds.keys[id]; ok && key.MerchantID == merchantID {
+			keys = append(keys, key)
+		}
+	}
+
+	return ds.mapping.ToMerchantApiKeysRecord(keys), nil
+}
+
+func (ds *merchantApiKeyRepository) ReadByLookup(lookup string) (*record.MerchantApiKeyRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	id, ok := ds.byLookup[lookup]
+	if !ok {
+		return nil, fmt.Errorf("api key not found")
+	}
+
+	return ds.mapping.ToMerchantApiKeyRecord(ds.keys[id]), nil
+}
+
+func (ds *merchantApiKeyRepository) Create(request requests.CreateMerchantApiKeyRequest) (*record.MerchantApiKeyRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if _, ok := ds.byLookup[request.Lookup]; ok {
+		return nil, fmt.Errorf("api key lookup already in use")
+	}
+
+	key := models.MerchantApiKey{
+		KeyID:      ds.nextID,
+		MerchantID: request.MerchantID,
+		Name:       request.Name,
+		Lookup:     request.Lookup,
+		Hash:       request.Hash,
+		Scopes:     request.Scopes,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := putItem(ds.keys, key.KeyID, key, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.byLookup[key.Lookup] = key.KeyID
+	ds.nextID++
+
+	return ds.mapping.ToMerchantApiKeyRecord(key), nil
+}
+
+// Expire makes the key stop working at expiresAt. A key that already expires
+// earlier keeps its time.
+func (ds *merchantApiKeyRepository) Expire(keyID int, expiresAt time.Time) (*record.MerchantApiKeyRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	key, ok := ds.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("api key with ID %d not found", keyID)
+	}
+
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(expiresAt) {
+		return ds.mapping.ToMerchantApiKeyRecord(key), nil
+	}
+
+	key.ExpiresAt = &expiresAt
+
+	if err := putItem(ds.keys, keyID, key, ds.persist, "Expire"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToMerchantApiKeyRecord(key), nil
+}
+
+func (ds *merchantApiKeyRepository) Revoke(keyID int) (*record.MerchantApiKeyRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	key, ok := ds.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("api key with ID %d not found", keyID)
+	}
+
+	if key.RevokedAt != nil {
+		return ds.mapping.ToMerchantApiKeyRecord(key), nil
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+
+	if err := putItem(ds.keys, keyID, key, ds.persist, "Revoke"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToMerchantApiKeyRecord(key), nil
+}
+
+// Touch records that the key was used at usedAt. Uses closer than
+// lastUsedResolution to the recorded one are not written.
+func (ds *merchantApiKeyRepository) Touch(keyID int, usedAt time.Time) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	key, ok := ds.keys[keyID]
+	if !ok {
+		return fmt.Errorf("api key with ID %d not found", keyID)
+	}
+
+	if key.LastUsedAt != nil && usedAt.Sub(*key.LastUsedAt) < lastUsedResolution {
+		return nil
+	}
+
+	key.LastUsedAt = &usedAt
+
+	return putItem(ds.keys, keyID, key, ds.persist, "Touch")
+}
+
+// reindex rebuilds byLookup. It must be called with ds.mu held.
+func (ds *merchantApiKeyRepository) reindex() {
+	ds.byLookup = make(map[string]int, len(ds.keys))
+
+	for id, key := range ds.keys {
+		ds.byLookup[key.Lookup] = id
+	}
+}
+
+func (ds *merchantApiKeyRepository) tableName() string {
+	return "merchant_api_keys"
+}
+
+func (ds *merchantApiKeyRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *merchantApiKeyRepository) state() tableState[models.MerchantApiKey] {
+	return tableState[models.MerchantApiKey]{Items: ds.keys, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *merchantApiKeyRepository) persist(c change) error {
+	if ds.store == nil {
+		return nil
+	}
+
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *merchantApiKeyRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *merchantApiKeyRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if err := restoreTable(data, &ds.keys, &ds.nextID); err != nil {
+		return err
+	}
+
+	ds.reindex()
+
+	return nil
+}
+
+func (ds *merchantApiKeyRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if err := replayTable(rows, ds.keys, &ds.nextID, nextID); err != nil {
+		return err
+	}
+
+	ds.reindex()
+
+	return nil
+}
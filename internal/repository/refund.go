@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+type refundRepository struct {
+	mu      sync.RWMutex
+	refunds map[int]models.Refund
+	nextID  int
+	store   storage
+	mapping recordmapper.RefundRecordMapping
+}
+
+func NewRefundRepository(mapping recordmapper.RefundRecordMapping) *refundRepository {
+	return &refundRepository{
+		refunds: make(map[int]models.Refund),
+		nextID:  1,
+		mapping: mapping,
+	}
+}
+
+func (ds *refundRepository) Read(refundID int) (*record.RefundRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	refund, ok := ds.refunds[refundID]
+	if !ok {
+		return nil, fmt.Errorf("refund with ID %d not found", refundID)
+	}
+
+	return ds.mapping.ToRefundRecord(refund), nil
+}
+
+func (ds *refundRepository) ReadByTransactionID(transactionID int) ([]*record.RefundRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	refunds := make([]models.Refund, 0)
+
+	for _, refund := range ds.refunds {
+		if refund.TransactionID == transactionID {
+			refunds = append(refunds, refund)
+		}
+	}
+
+	sort.Slice(refunds, func(i, j int) bool {
+		return refunds[i].RefundID < refunds[j].RefundID
+	})
+
+	return ds.mapping.ToRefundsRecord(refunds), nil
+}
+
+func (ds *refundRepository) TotalByTransactionID(transactionID int) (int, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	total := 0
+
+	for _, refund := range ds.refunds {
+		if refund.TransactionID == transactionID {
+			total += refund.Amount
+		}
+	}
+
+	return total, nil
+}
+
+func (ds *refundRepository) Create(request requests.CreateRefundRequest) (*record.RefundRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if request.MerchantID == nil {
+		return nil, fmt.Errorf("merchant ID is required for refund")
+	}
+
+	refund := models.Refund{
+		RefundID:      ds.nextID,
+		TransactionID: request.TransactionID,
+		MerchantID:    *request.MerchantID,
+		CardNumber:    request.CardNumber,
+		Amount:        request.Amount,
+		Reason:        request.Reason,
+		RefundTime:    time.Now(),
+	}
+
+	if err := putItem(ds.refunds, refund.RefundID, refund, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToRefundRecord(refund), nil
+}
+
+func (ds *refundRepository) Delete(refundID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if _, ok := ds.refunds[refundID]; ok {
+		return deleteItem(ds.refunds, refundID, ds.persist, "Delete")
+	}
+
+	return fmt.Errorf("refund with ID %d not found", refundID)
+}
+
+func (ds *refundRepository) tableName() string {
+	return "refunds"
+}
+
+func (ds *refundRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *refundRepository) state() tableState[models.Refund] {
+	return tableState[models.Refund]{Items: ds.refunds, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *refundRepository) persist(c change) error {
+	if ds.store == nil {
+		return nil
+	}
+
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *refundRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *refundRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.refunds, &ds.nextID)
+}
+
+func (ds *refundRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.refunds, &ds.nextID, nextID)
+}
@@ -1,31 +1,181 @@
 package repository
 
-import recordmapper "payment-mutex/internal/mapper/record"
+import (
+	"fmt"
+	"os"
+	recordmapper "payment-mutex/internal/mapper/record"
+)
 
 type Repositories struct {
-	User        UserRepository
-	Saldo       SaldoRepository
-	Topup       TopupRepository
-	Transfer    TransferRepository
-	Withdraw    WithdrawRepository
-	Card        CardRepository
-	Transaction TransactionRepository
-	Merchant    MerchantRepository
+	User            UserRepository
+	Saldo           SaldoRepository
+	Topup           TopupRepository
+	Transfer        TransferRepository
+	Withdraw        WithdrawRepository
+	Card            CardRepository
+	Transaction     TransactionRepository
+	Merchant        MerchantRepository
+	Journal         JournalRepository
+	Refund          RefundRepository
+	WithdrawHold    WithdrawHoldRepository
+	Authorization   AuthorizationRepository
+	Session         SessionRepository
+	UserToken       UserTokenRepository
+	MerchantApiKey  MerchantApiKeyRepository
+	WebhookEndpoint WebhookEndpointRepository
+	WebhookDelivery WebhookDeliveryRepository
+	SettlementItem  SettlementItemRepository
+	SettlementBatch SettlementBatchRepository
+	FeePlan         FeePlanRepository
+	CheckoutSession CheckoutSessionRepository
+
+	units unitTables
+	wal   *writeAheadLog
 }
 
 type Deps struct {
 	MapperRecord recordmapper.RecordMapper
+
+	// StorageDriver picks where the repositories keep their data. It is
+	// StorageDriverMemory when empty.
+	StorageDriver string
+	// StoragePath is the directory used by StorageDriverFile and
+	// StorageDriverWAL.
+	StoragePath string
 }
 
-func NewRepositorys(deps Deps) *Repositories {
-	return &Repositories{
-		User:        NewUserRepository(deps.MapperRecord.UserRecordMapper),
-		Saldo:       NewSaldoRepository(deps.MapperRecord.SaldoRecordMapper),
-		Topup:       NewTopupRepository(deps.MapperRecord.TopupRecordMapper),
-		Transfer:    NewTransferRepository(deps.MapperRecord.TransferRecordMapper),
-		Withdraw:    NewWithdrawRepository(deps.MapperRecord.WithdrawRecordMapper),
-		Card:        NewCardRepository(deps.MapperRecord.CardRecordMapper),
-		Transaction: NewTransactionRepository(deps.MapperRecord.TransactionRecordMapper),
-		Merchant:    NewMerchantRepository(deps.MapperRecord.MerchantRecordMapper),
+func NewRepositorys(deps Deps) (*Repositories, error) {
+	user := NewUserRepository(deps.MapperRecord.UserRecordMapper)
+	saldo := NewSaldoRepository(deps.MapperRecord.SaldoRecordMapper)
+	topup := NewTopupRepository(deps.MapperRecord.TopupRecordMapper)
+	transfer := NewTransferRepository(deps.MapperRecord.TransferRecordMapper)
+	withdraw := NewWithdrawRepository(deps.MapperRecord.WithdrawRecordMapper)
+	card := NewCardRepository(deps.MapperRecord.CardRecordMapper)
+	transaction := NewTransactionRepository(deps.MapperRecord.TransactionRecordMapper)
+	merchant := NewMerchantRepository(deps.MapperRecord.MerchantRecordMapper)
+	journal := NewJournalRepository(deps.MapperRecord.JournalRecordMapper)
+	refund := NewRefundRepository(deps.MapperRecord.RefundRecordMapper)
+	withdrawHold := NewWithdrawHoldRepository(deps.MapperRecord.WithdrawHoldRecordMapper)
+	authorization := NewAuthorizationRepository(deps.MapperRecord.AuthorizationRecordMapper)
+	session := NewSessionRepository(deps.MapperRecord.SessionRecordMapper)
+	userToken := NewUserTokenRepository(deps.MapperRecord.UserTokenRecordMapper)
+	merchantApiKey := NewMerchantApiKeyRepository(deps.MapperRecord.MerchantApiKeyRecordMapper)
+	webhookEndpoint := NewWebhookEndpointRepository(deps.MapperRecord.WebhookRecordMapper)
+	webhookDelivery := NewWebhookDeliveryRepository(deps.MapperRecord.WebhookRecordMapper)
+	settlementItem := NewSettlementItemRepository(deps.MapperRecord.SettlementRecordMapper)
+	settlementBatch := NewSettlementBatchRepository(deps.MapperRecord.SettlementRecordMapper)
+	feePlan := NewFeePlanRepository(deps.MapperRecord.FeePlanRecordMapper)
+	checkoutSession := NewCheckoutSessionRepository(deps.MapperRecord.CheckoutSessionRecordMapper)
+
+	repositories := &Repositories{
+		User:            user,
+		Saldo:           saldo,
+		Topup:           topup,
+		Transfer:        transfer,
+		Withdraw:        withdraw,
+		Card:            card,
+		Transaction:     transaction,
+		Merchant:        merchant,
+		Journal:         journal,
+		Refund:          refund,
+		WithdrawHold:    withdrawHold,
+		Authorization:   authorization,
+		Session:         session,
+		UserToken:       userToken,
+		MerchantApiKey:  merchantApiKey,
+		WebhookEndpoint: webhookEndpoint,
+		WebhookDelivery: webhookDelivery,
+		SettlementItem:  settlementItem,
+		SettlementBatch: settlementBatch,
+		FeePlan:         feePlan,
+		CheckoutSession: checkoutSession,
+		units: unitTables{
+			saldo:       saldo,
+			journal:     journal,
+			topup:       topup,
+			transfer:    transfer,
+			transaction: transaction,
+			withdraw:    withdraw,
+
+			withdrawHold:    withdrawHold,
+			authorization:   authorization,
+			settlementItem:  settlementItem,
+			settlementBatch: settlementBatch,
+		},
 	}
+
+	// The journal is stored too, because balances are rebuilt from it.
+	tables := []table{user, saldo, topup, transfer, withdraw, card, transaction, merchant, journal, refund, withdrawHold, authorization, session, userToken, merchantApiKey, webhookEndpoint, webhookDelivery, settlementItem, settlementBatch, feePlan, checkoutSession}
+
+	switch deps.StorageDriver {
+	case "", StorageDriverMemory:
+		return repositories, nil
+	case StorageDriverFile:
+		if err := requireStoragePath(deps); err != nil {
+			return nil, err
+		}
+
+		if err := os.MkdirAll(deps.StoragePath, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create storage path: %w", err)
+		}
+
+		if err := openFileStores(deps.StoragePath, tables); err != nil {
+			return nil, err
+		}
+
+		return repositories, nil
+	case StorageDriverWAL:
+		if err := requireStoragePath(deps); err != nil {
+			return nil, err
+		}
+
+		wal, err := newWriteAheadLog(deps.StoragePath, tables)
+		if err != nil {
+			return nil, err
+		}
+
+		repositories.wal = wal
+
+		return repositories, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", deps.StorageDriver)
+	}
+}
+
+func requireStoragePath(deps Deps) error {
+	if deps.StoragePath == "" {
+		return fmt.Errorf("storage path is required for the %s driver", deps.StorageDriver)
+	}
+
+	return nil
+}
+
+// Recover brings the repositories back to the last committed state when
+// they run on the write-ahead log. It must be called once before the
+// repositories are used. Other drivers have nothing to recover.
+func (r *Repositories) Recover() error {
+	if r.wal == nil {
+		return nil
+	}
+
+	return r.wal.recover()
+}
+
+// Snapshot writes the full state to disk so the write-ahead log can be
+// trimmed. It does nothing for other drivers.
+func (r *Repositories) Snapshot() error {
+	if r.wal == nil {
+		return nil
+	}
+
+	return r.wal.snapshot()
+}
+
+// Close releases the files held by the storage driver.
+func (r *Repositories) Close() error {
+	if r.wal == nil {
+		return nil
+	}
+
+	return r.wal.close()
 }
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"payment-mutex/internal/domain/record"
 	"payment-mutex/internal/domain/requests"
@@ -10,10 +12,14 @@ import (
 	"sync"
 )
 
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
 type saldoRepository struct {
 	mu      sync.RWMutex
 	saldos  map[int]models.Saldo
 	nextID  int
+	store   storage
+	undo    *undoLog
 	mapping recordmapper.SaldoRecordMapping
 }
 
@@ -92,7 +98,10 @@ func (ds *saldoRepository) Create(request requests.CreateSaldoRequest) (*record.
 		TotalBalance: request.TotalBalance,
 	}
 
-	ds.saldos[saldo.SaldoID] = saldo
+	if err := putItem(ds.saldos, saldo.SaldoID, saldo, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
 	ds.nextID++
 
 	return ds.mapping.ToSaldoRecord(saldo), nil
@@ -110,7 +119,9 @@ func (ds *saldoRepository) Update(request requests.UpdateSaldoRequest) (*record.
 	saldo.CardNumber = request.CardNumber
 	saldo.TotalBalance = request.TotalBalance
 
-	ds.saldos[request.SaldoID] = saldo
+	if err := putItem(ds.saldos, request.SaldoID, saldo, ds.persist, "Update"); err != nil {
+		return nil, err
+	}
 
 	return ds.mapping.ToSaldoRecord(saldo), nil
 }
@@ -124,7 +135,9 @@ func (ds *saldoRepository) UpdateBalance(request requests.UpdateSaldoBalance) (*
 			updatedSaldo := saldo
 			updatedSaldo.TotalBalance = request.TotalBalance
 
-			ds.saldos[id] = updatedSaldo
+			if err := putItem(ds.saldos, id, updatedSaldo, ds.persist, "UpdateBalance"); err != nil {
+				return nil, err
+			}
 
 			return ds.mapping.ToSaldoRecord(saldo), nil
 		}
@@ -133,40 +146,204 @@ func (ds *saldoRepository) UpdateBalance(request requests.UpdateSaldoBalance) (*
 	return nil, fmt.Errorf("saldo for user ID %s not found", request.CardNumber)
 }
 
+func (ds *saldoRepository) UpdateBalances(request requests.UpdateSaldoBalances) ([]*record.SaldoRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.updateBalances(request)
+}
+
+func (ds *saldoRepository) updateBalances(request requests.UpdateSaldoBalances) ([]*record.SaldoRecord, error) {
+	updatedSaldos, err := ds.applyBalanceChanges(request.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToSaldosRecord(updatedSaldos), nil
+}
+
+// UpdateSaldoWithdraw records the latest withdrawal on the saldo. The balance
+// itself is moved by the ledger through UpdateBalances.
 func (ds *saldoRepository) UpdateSaldoWithdraw(request requests.UpdateSaldoWithdraw) (*record.SaldoRecord, error) {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 
-	for id, saldo := range ds.saldos {
-		if saldo.CardNumber == request.CardNumber {
-			updatedSaldo := saldo
-			if request.WithdrawAmount != nil {
-				if *request.WithdrawAmount > updatedSaldo.TotalBalance {
-					return nil, fmt.Errorf("insufficient balance")
-				}
-				updatedSaldo.WithdrawAmount = *request.WithdrawAmount
-				updatedSaldo.TotalBalance -= *request.WithdrawAmount
-			}
+	id, ok := ds.findIDByCardNumber(request.CardNumber)
+	if !ok {
+		return nil, fmt.Errorf("saldo for user ID %s not found", request.CardNumber)
+	}
 
-			if request.WithdrawTime != nil {
-				updatedSaldo.WithdrawTime = *request.WithdrawTime
-			}
+	updatedSaldo := ds.saldos[id]
+
+	if request.WithdrawAmount != nil {
+		updatedSaldo.WithdrawAmount = *request.WithdrawAmount
+	}
 
-			ds.saldos[id] = updatedSaldo
+	if request.WithdrawTime != nil {
+		updatedSaldo.WithdrawTime = *request.WithdrawTime
+	}
 
-			return ds.mapping.ToSaldoRecord(saldo), nil
+	if err := putItem(ds.saldos, id, updatedSaldo, ds.persist, "UpdateSaldoWithdraw"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToSaldoRecord(updatedSaldo), nil
+}
+
+// applyBalanceChanges stages every change before writing any of them, so the
+// caller sees either all legs applied or none. A card whose balance goes down
+// or whose held amount goes up must keep a non-negative available balance.
+// It must be called with ds.mu held.
+func (ds *saldoRepository) applyBalanceChanges(changes []requests.SaldoBalanceChange) ([]models.Saldo, error) {
+	staged := make(map[int]models.Saldo, len(changes))
+	order := make([]int, 0, len(changes))
+	reducesAvailable := make(map[int]bool, len(changes))
+
+	for _, change := range changes {
+		id, ok := ds.findIDByCardNumber(change.CardNumber)
+		if !ok {
+			return nil, fmt.Errorf("saldo for user ID %s not found", change.CardNumber)
+		}
+
+		saldo, ok := staged[id]
+		if !ok {
+			saldo = ds.saldos[id]
+			order = append(order, id)
+		}
+
+		saldo.TotalBalance += change.Amount
+		saldo.HeldBalance += change.Held
+		staged[id] = saldo
+
+		if change.Amount < 0 || change.Held > 0 {
+			reducesAvailable[id] = true
 		}
 	}
 
-	return nil, fmt.Errorf("saldo for user ID %s not found", request.CardNumber)
+	for _, id := range order {
+		saldo := staged[id]
+
+		if saldo.HeldBalance < 0 {
+			return nil, fmt.Errorf("held balance of card number %s cannot be negative", saldo.CardNumber)
+		}
+
+		if saldo.TotalBalance < 0 || (reducesAvailable[id] && saldo.TotalBalance-saldo.HeldBalance < 0) {
+			return nil, fmt.Errorf("%w for card number %s", ErrInsufficientBalance, saldo.CardNumber)
+		}
+	}
+
+	before := make(map[int]any, len(order))
+	rows := make(map[int]any, len(order))
+	updatedSaldos := make([]models.Saldo, 0, len(order))
+
+	for _, id := range order {
+		before[id] = ds.saldos[id]
+		ds.saldos[id] = staged[id]
+		rows[id] = staged[id]
+		updatedSaldos = append(updatedSaldos, staged[id])
+	}
+
+	if err := ds.persist(change{Call: "UpdateBalances", Rows: rows, Before: before}); err != nil {
+		revertTable(ds.saldos, before)
+		return nil, err
+	}
+
+	return updatedSaldos, nil
+}
+
+func (ds *saldoRepository) findIDByCardNumber(cardNumber string) (int, bool) {
+	for id, saldo := range ds.saldos {
+		if saldo.CardNumber == cardNumber {
+			return id, true
+		}
+	}
+
+	return 0, false
 }
 
 func (ds *saldoRepository) Delete(saldoID int) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 	if _, ok := ds.saldos[saldoID]; ok {
-		delete(ds.saldos, saldoID)
-		return nil
+		return deleteItem(ds.saldos, saldoID, ds.persist, "Delete")
 	}
 	return fmt.Errorf("saldo with ID %d not found", saldoID)
 }
+
+func (ds *saldoRepository) tableName() string {
+	return "saldos"
+}
+
+func (ds *saldoRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *saldoRepository) currentStorage() storage {
+	return ds.store
+}
+
+func (ds *saldoRepository) state() tableState[models.Saldo] {
+	return tableState[models.Saldo]{Items: ds.saldos, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage and, inside a unit of work, to its
+// undo log. It must be called with ds.mu held.
+func (ds *saldoRepository) persist(c change) error {
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	if ds.store != nil {
+		if err := ds.store.write(c, ds.state()); err != nil {
+			return err
+		}
+	}
+
+	ds.undo.record(ds, c.Before)
+
+	return nil
+}
+
+// revert puts rows back the way a unit of work found them. It must be called
+// with ds.mu held.
+func (ds *saldoRepository) revert(before map[int]any) error {
+	revertTable(ds.saldos, before)
+
+	if ds.store == nil {
+		return nil
+	}
+
+	return ds.store.write(change{Table: ds.tableName(), Call: "Rollback", Rows: before, NextID: ds.nextID}, ds.state())
+}
+
+func (ds *saldoRepository) lock() {
+	ds.mu.Lock()
+}
+
+func (ds *saldoRepository) unlock() {
+	ds.mu.Unlock()
+}
+
+func (ds *saldoRepository) useUndoLog(u *undoLog) {
+	ds.undo = u
+}
+
+func (ds *saldoRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *saldoRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.saldos, &ds.nextID)
+}
+
+func (ds *saldoRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.saldos, &ds.nextID, nextID)
+}
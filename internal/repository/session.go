@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSessionRevoked is returned when a revoked session is refreshed.
+	ErrSessionRevoked = errors.New("session revoked")
+	// ErrRefreshTokenReused is returned when a refresh token of an earlier
+	// generation is presented, which means it was used before.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+type sessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[int]models.Session
+	nextID   int
+	store    storage
+	mapping  recordmapper.SessionRecordMapping
+}
+
+func NewSessionRepository(mapping recordmapper.SessionRecordMapping) *sessionRepository {
+	return &sessionRepository{
+		sessions: make(map[int]models.Session),
+		nextID:   1,
+		mapping:  mapping,
+	}
+}
+
+func (ds *sessionRepository) Read(sessionID int) (*record.SessionRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	session, ok := ds.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session with ID %d not found", sessionID)
+	}
+
+	return ds.mapping.ToSessionRecord(session), nil
+}
+
+func (ds *sessionRepository) Create(request requests.CreateSessionRequest) (*record.SessionRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	now := time.Now()
+
+	session := models.Session{
+		SessionID:  ds.nextID,
+		UserID:     request.UserID,
+		Generation: 1,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := putItem(ds.sessions, session.SessionID, session, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToSessionRecord(session), nil
+}
+
+// Rotate moves the session to its next generation when generation is the
+// current one. Checking and moving happen under one lock, so of two refreshes
+// with the same token only one succeeds and the other sees
+// ErrRefreshTokenReused.
+func (ds *sessionRepository) Rotate(sessionID int, generation int) (*record.SessionRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	session, ok := ds.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session with ID %d not found", sessionID)
+	}
+
+	if session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+
+	if generation != session.Generation {
+		return nil, ErrRefreshTokenReused
+	}
+
+	session.Generation++
+	session.UpdatedAt = time.Now()
+
+	if err := putItem(ds.sessions, sessionID, session, ds.persist, "Rotate"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToSessionRecord(session), nil
+}
+
+// Revoke ends the session. Revoking a session twice keeps the first reason.
+func (ds *sessionRepository) Revoke(sessionID int, reason string) (*record.SessionRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	session, ok := ds.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session with ID %d not found", sessionID)
+	}
+
+	if session.RevokedAt != nil {
+		return ds.mapping.ToSessionRecord(session), nil
+	}
+
+	now := time.Now()
+
+	session.RevokedAt = &now
+	session.RevokedReason = reason
+	session.UpdatedAt = now
+
+	if err := putItem(ds.sessions, sessionID, session, ds.persist, "Revoke"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToSessionRecord(session), nil
+}
+
+// RevokeAll ends every session of the user that is still active and returns
+// how many it ended.
+func (ds *sessionRepository) RevokeAll(userID int, reason string) (int, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	now := time.Now()
+	revoked := 0
+
+	for id, session := range ds.sessions {
+		if session.UserID != userID || session.RevokedAt != nil {
+			continue
+		}
+
+		session.RevokedAt = &now
+		session.RevokedReason = reason
+		session.UpdatedAt = now
+
+		if err := putItem(ds.sessions, id, session, ds.persist, "RevokeAll"); err != nil {
+			return revoked, err
+		}
+
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+func (ds *sessionRepository) tableName() string {
+	return "sessions"
+}
+
+func (ds *sessionRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *sessionRepository) state() tableState[models.Session] {
+	return tableState[models.Session]{Items: ds.sessions, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *sessionRepository) persist(c change) error {
+	if ds.store == nil {
+		return nil
+	}
+
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *sessionRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *sessionRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.sessions, &ds.nextID)
+}
+
+func (ds *sessionRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.sessions, &ds.nextID, nextID)
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sync"
+	"time"
+)
+
+type settlementBatchRepository struct {
+	mu      sync.RWMutex
+	batches map[int]models.SettlementBatch
+	nextID  int
+	store   storage
+	undo    *undoLog
+	mapping recordmapper.SettlementRecordMapping
+}
+
+func NewSettlementBatchRepository(mapping recordmapper.SettlementRecordMapping) *settlementBatchRepository {
+	return &settlementBatchRepository{
+		batches: make(map[int]models.SettlementBatch),
+		nextID:  1,
+		mapping: mapping,
+	}
+}
+
+func (ds *settlementBatchRepository) Read(batchID int) (*record.SettlementBatchRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	batch, ok := ds.batches[batchID]
+	if !ok {
+		return nil, fmt.Errorf("settlement batch with ID %d not found", batchID)
+	}
+
+	return ds.mapping.ToSettlementBatchRecord(batch), nil
+}
+
+// ReadByMerchantID returns the batches of the merchant newest first.
+func (ds *settlementBatchRepository) ReadByMerchantID(merchantID int, page int, pageSize int) ([]*record.SettlementBatchRecord, int, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	filteredBatches := make([]models.SettlementBatch, 0)
+
+	for id := ds.nextID - 1; id > 0; id-- {
+		if batch, ok := ds.batches[id]; ok && batch.MerchantID == merchantID {
+			filteredBatches = append(filteredBatches, batch)
+		}
+	}
+
+	totalRecords := len(filteredBatches)
+
+	start := (page - 1) * pageSize
+	if start >= totalRecords {
+		return nil, totalRecords, nil
+	}
+
+	end := start + pageSize
+	if end > totalRecords {
+		end = totalRecords
+	}
+
+	return ds.mapping.ToSettlementBatchesRecord(filteredBatches[start:end]), totalRecords, nil
+}
+
+func (ds *settlementBatchRepository) Create(request requests.CreateSettlementBatchRequest) (*record.SettlementBatchRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	batch := models.SettlementBatch{
+		BatchID:      ds.nextID,
+		MerchantID:   request.MerchantID,
+		CardNumber:   request.CardNumber,
+		Cutoff:       request.Cutoff,
+		Status:       models.SettlementBatchPending,
+		ItemCount:    request.ItemCount,
+		GrossAmount:  request.GrossAmount,
+		RefundAmount: request.RefundAmount,
+		FeeAmount:    request.FeeAmount,
+		NetAmount:    request.NetAmount,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := putItem(ds.batches, batch.BatchID, batch, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToSettlementBatchRecord(batch), nil
+}
+
+func (ds *settlementBatchRepository) UpdateStatus(request requests.UpdateSettlementBatchStatus) (*record.SettlementBatchRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.updateStatus(request)
+}
+
+func (ds *settlementBatchRepository) updateStatus(request requests.UpdateSettlementBatchStatus) (*record.SettlementBatchRecord, error) {
+	batch, ok := ds.batches[request.BatchID]
+	if !ok {
+		return nil, fmt.Errorf("settlement batch with ID %d not found", request.BatchID)
+	}
+
+	batch.Status = request.Status
+	batch.Error = request.Error
+
+	if request.Status == models.SettlementBatchPaid {
+		now := time.Now()
+		batch.PaidAt = &now
+	}
+
+	if err := putItem(ds.batches, batch.BatchID, batch, ds.persist, "UpdateStatus"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToSettlementBatchRecord(batch), nil
+}
+
+func (ds *settlementBatchRepository) tableName() string {
+	return "settlement_batches"
+}
+
+func (ds *settlementBatchRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *settlementBatchRepository) currentStorage() storage {
+	return ds.store
+}
+
+func (ds *settlementBatchRepository) state() tableState[models.SettlementBatch] {
+	return tableState[models.SettlementBatch]{Items: ds.batches, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage and, inside a unit of work, to its
+// undo log. It must be called with ds.mu held.
+func (ds *settlementBatchRepository) persist(c change) error {
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	if ds.store != nil {
+		if err := ds.store.write(c, ds.state()); err != nil {
+			return err
+		}
+	}
+
+	ds.undo.record(ds, c.Before)
+
+	return nil
+}
+
+// revert puts rows back the way a unit of work found them. It must be called
+// with ds.mu held.
+func (ds *settlementBatchRepository) revert(before map[int]any) error {
+	revertTable(ds.batches, before)
+
+	if ds.store == nil {
+		return nil
+	}
+
+	return ds.store.write(change{Table: ds.tableName(), Call: "Rollback", Rows: before, NextID: ds.nextID}, ds.state())
+}
+
+func (ds *settlementBatchRepository) lock() {
+	ds.mu.Lock()
+}
+
+func (ds *settlementBatchRepository) unlock() {
+	ds.mu.Unlock()
+}
+
+func (ds *settlementBatchRepository) useUndoLog(u *undoLog) {
+	ds.undo = u
+}
+
+func (ds *settlementBatchRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *settlementBatchRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.batches, &ds.nextID)
+}
+
+func (ds *settlementBatchRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.batches, &ds.nextID, nextID)
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sync"
+	"time"
+)
+
+type settlementItemRepository struct {
+	mu      sync.RWMutex
+	items   map[int]models.SettlementItem
+	nextID  int
+	store   storage
+	undo    *undoLog
+	mapping recordmapper.SettlementRecordMapping
+}
+
+func NewSettlementItemRepository(mapping recordmapper.SettlementRecordMapping) *settlementItemRepository {
+	return &settlementItemRepository{
+		items:   make(map[int]models.SettlementItem),
+		nextID:  1,
+		mapping: mapping,
+	}
+}
+
+func (ds *settlementItemRepository) ReadByBatchID(batchID int) ([]*record.SettlementItemRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	items := make([]models.SettlementItem, 0)
+
+	for id := 1; id < ds.nextID; id++ {
+		if item, ok := ds.items[id]; ok && item.BatchID == batchID {
+			items = append(items, item)
+		}
+	}
+
+	return ds.mapping.ToSettlementItemsRecord(items), nil
+}
+
+// ReadByTransactionID returns every item of the transaction, open or paid.
+func (ds *settlementItemRepository) ReadByTransactionID(transactionID int) ([]*record.SettlementItemRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	items := make([]models.SettlementItem, 0)
+
+	for id := 1; id < ds.nextID; id++ {
+		if item, ok := ds.items[id]; ok && item.TransactionID == transactionID {
+			items = append(items, item)
+		}
+	}
+
+	return ds.mapping.ToSettlementItemsRecord(items), nil
+}
+
+// ReadOpen returns the items not paid out yet that were created before
+// cutoff, oldest first. merchantID zero returns the items of every merchant.
+func (ds *settlementItemRepository) ReadOpen(merchantID int, cutoff time.Time) ([]*record.SettlementItemRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	items := make([]models.SettlementItem, 0)
+
+	for id := 1; id < ds.nextID; id++ {
+		item, ok := ds.items[id]
+		if !ok || item.BatchID != 0 || !item.CreatedAt.Before(cutoff) {
+			continue
+		}
+
+		if merchantID == 0 || item.MerchantID == merchantID {
+			items = append(items, item)
+		}
+	}
+
+	return ds.mapping.ToSettlementItemsRecord(items), nil
+}
+
+func (ds *settlementItemRepository) create(request requests.CreateSettlementItemRequest) (*record.SettlementItemRecord, error) {
+	item := models.SettlementItem{
+		ItemID:        ds.nextID,
+		MerchantID:    request.MerchantID,
+		TransactionID: request.TransactionID,
+		Type:          request.Type,
+		ReferenceID:   request.ReferenceID,
+		Amount:        request.Amount,
+		Fee:           request.Fee,
+		CreatedAt:     time.Now(),
+	}
+
+	if err := putItem(ds.items, item.ItemID, item, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToSettlementItemRecord(item), nil
+}
+
+// assignBatch marks the items as paid out by batchID. Items that are missing
+// or already in a batch are an error, so an item is never paid twice.
+func (ds *settlementItemRepository) assignBatch(itemIDs []int, batchID int) ([]*record.SettlementItemRecord, error) {
+	assigned := make([]models.SettlementItem, 0, len(itemIDs))
+
+	for _, itemID := range itemIDs {
+		item, ok := ds.items[itemID]
+		if !ok {
+			return nil, fmt.Errorf("settlement item with ID %d not found", itemID)
+		}
+
+		if item.BatchID != 0 {
+			return nil, fmt.Errorf("settlement item with ID %d is already in batch %d", itemID, item.BatchID)
+		}
+
+		item.BatchID = batchID
+
+		if err := putItem(ds.items, itemID, item, ds.persist, "AssignBatch"); err != nil {
+			return nil, err
+		}
+
+		assigned = append(assigned, item)
+	}
+
+	return ds.mapping.ToSettlementItemsRecord(assigned), nil
+}
+
+func (ds *settlementItemRepository) tableName() string {
+	return "settlement_items"
+}
+
+func (ds *settlementItemRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *settlementItemRepository) currentStorage() storage {
+	return ds.store
+}
+
+func (ds *settlementItemRepository) state() tableState[models.SettlementItem] {
+	return tableState[models.SettlementItem]{Items: ds.items, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage and, inside a unit of work, to its
+// undo log. It must be called with ds.mu held.
+func (ds *settlementItemRepository) persist(c change) error {
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	if ds.store != nil {
+		if err := ds.store.write(c, ds.state()); err != nil {
+			return err
+		}
+	}
+
+	ds.undo.record(ds, c.Before)
+
+	return nil
+}
+
+// revert puts rows back the way a unit of work found them. It must be called
+// with ds.mu held.
+func (ds *settlementItemRepository) revert(before map[int]any) error {
+	revertTable(ds.items, before)
+
+	if ds.store == nil {
+		return nil
+	}
+
+	return ds.store.write(change{Table: ds.tableName(), Call: "Rollback", Rows: before, NextID: ds.nextID}, ds.state())
+}
+
+func (ds *settlementItemRepository) lock() {
+	ds.mu.Lock()
+}
+
+func (ds *settlementItemRepository) unlock() {
+	ds.mu.Unlock()
+}
+
+func (ds *settlementItemRepository) useUndoLog(u *undoLog) {
+	ds.undo = u
+}
+
+func (ds *settlementItemRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *settlementItemRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.items, &ds.nextID)
+}
+
+func (ds *settlementItemRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.items, &ds.nextID, nextID)
+}
@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"payment-mutex/pkg/atomicfile"
+	"sync"
+)
+
+const (
+	StorageDriverMemory = "memory"
+	StorageDriverFile   = "file"
+	StorageDriverWAL    = "wal"
+)
+
+// change describes one mutating repository call. Rows holds the state of
+// every row the call touched after it ran, keyed by ID, and Before the state
+// of the same rows before it ran; a nil row means the row did not exist.
+type change struct {
+	Table  string
+	Call   string
+	Rows   map[int]any
+	Before map[int]any
+	NextID int
+}
+
+// storage receives every change a repository makes, together with the full
+// state of that repository. It is called with the repository lock held, and
+// an error makes the repository undo the change in memory.
+type storage interface {
+	write(c change, state any) error
+}
+
+// groupStorage is storage that can take the writes of a unit of work in one
+// step, so a crash leaves either all or none of them on disk.
+type groupStorage interface {
+	storage
+	writeGroup(writes []unitWrite) error
+}
+
+// table is implemented by every repository so a storage driver can load and
+// save it without knowing its row type.
+type table interface {
+	tableName() string
+	useStorage(s storage)
+	// snapshot encodes the full state of the repository.
+	snapshot() ([]byte, error)
+	// restore replaces the state of the repository with one produced by
+	// snapshot.
+	restore(data []byte) error
+	// replay applies the rows of a change written earlier.
+	replay(rows map[int]json.RawMessage, nextID int) error
+}
+
+// fileUnitFile holds the tables written by a unit of work until every one of
+// them is on disk.
+const fileUnitFile = "pending_unit.json"
+
+// fileStore keeps the whole state of one repository in a JSON file and
+// rewrites it on every change.
+type fileStore struct {
+	path   string
+	driver *fileDriver
+}
+
+// fileDriver is shared by the file stores of one directory. A unit of work
+// touches several files, which cannot be replaced together, so its tables
+// are first written to one intent file. Once that is on disk the unit counts
+// as written: the table files are replaced one by one and the intent file is
+// removed, and a crash in between is finished from the intent file on the
+// next start.
+type fileDriver struct {
+	mu  sync.Mutex
+	dir string
+
+	// pending holds the tables of an intent file that is still on disk,
+	// keyed by file path.
+	pending map[string][]byte
+}
+
+func newFileStore(driver *fileDriver, name string) *fileStore {
+	return &fileStore{path: filepath.Join(driver.dir, name+".json"), driver: driver}
+}
+
+// openFileStores finishes a unit of work a crash interrupted, then loads
+// every table from dir and makes it write back there.
+func openFileStores(dir string, tables []table) error {
+	driver := &fileDriver{dir: dir}
+
+	if err := driver.recover(); err != nil {
+		return err
+	}
+
+	for _, t := range tables {
+		store := newFileStore(driver, t.tableName())
+
+		data, err := store.read()
+		if err != nil {
+			return err
+		}
+
+		if data != nil {
+			if err := t.restore(data); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", store.path, err)
+			}
+		}
+
+		t.useStorage(store)
+	}
+
+	return nil
+}
+
+func (s *fileStore) write(c change, state any) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", s.path, err)
+	}
+
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+
+	if err := s.driver.finish(); err != nil {
+		return err
+	}
+
+	return atomicfile.Write(s.path, data)
+}
+
+// writeGroup writes the tables of a unit of work through the intent file.
+// Every write must belong to a file store of the same driver.
+func (s *fileStore) writeGroup(writes []unitWrite) error {
+	tables := make(map[string][]byte, len(writes))
+
+	// Satu tabel bisa ditulis berkali-kali dalam satu unit, yang terakhir berisi state lengkapnya
+	for _, w := range writes {
+		store, ok := w.store.(*fileStore)
+		if !ok || store.driver != s.driver {
+			return fmt.Errorf("cannot write %s together with %s", w.change.Table, s.path)
+		}
+
+		data, err := json.Marshal(w.state)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", store.path, err)
+		}
+
+		tables[store.path] = data
+	}
+
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+
+	if err := s.driver.finish(); err != nil {
+		return err
+	}
+
+	if err := s.driver.writeIntent(tables); err != nil {
+		return err
+	}
+
+	// Unit sudah tercatat di file intent; tabel yang gagal ditulis diselesaikan pada penulisan berikutnya
+	s.driver.pending = tables
+	s.driver.finish()
+
+	return nil
+}
+
+func (d *fileDriver) intentPath() string {
+	return filepath.Join(d.dir, fileUnitFile)
+}
+
+// writeIntent stores tables, keyed by file name, in the intent file. It
+// must be called with d.mu held.
+func (d *fileDriver) writeIntent(tables map[string][]byte) error {
+	intent := make(map[string]json.RawMessage, len(tables))
+	for path, data := range tables {
+		intent[filepath.Base(path)] = data
+	}
+
+	data, err := json.Marshal(intent)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", fileUnitFile, err)
+	}
+
+	return atomicfile.Write(d.intentPath(), data)
+}
+
+// finish writes the tables of the pending intent file and removes it. Until
+// that succeeds no other write goes through, because the intent file would
+// otherwise put older rows back on the next start. It must be called with
+// d.mu held.
+func (d *fileDriver) finish() error {
+	if d.pending == nil {
+		return nil
+	}
+
+	for path, data := range d.pending {
+		if err := atomicfile.Write(path, data); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Remove(d.intentPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", fileUnitFile, err)
+	}
+
+	d.pending = nil
+
+	return nil
+}
+
+// recover finishes the unit of work left in the intent file, if any.
+func (d *fileDriver) recover() error {
+	data, err := os.ReadFile(d.intentPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", fileUnitFile, err)
+	}
+
+	var intent map[string]json.RawMessage
+	if err := json.Unmarshal(data, &intent); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", fileUnitFile, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pending = make(map[string][]byte, len(intent))
+	for name, table := range intent {
+		d.pending[filepath.Join(d.dir, name)] = table
+	}
+
+	return d.finish()
+}
+
+// read returns the stored state, or nil when nothing was stored yet.
+func (s *fileStore) read() ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+
+	return data, nil
+}
+
+// tableState is the stored form of a repository that keeps its rows in a map
+// keyed by ID.
+type tableState[T any] struct {
+	Items  map[int]T `json:"items"`
+	NextID int       `json:"next_id"`
+}
+
+// restoreTable replaces items and nextID with the decoded state. nextID never
+// ends up at or below an ID that is already taken, even if the state was
+// written before the counter moved.
+func restoreTable[T any](data []byte, items *map[int]T, nextID *int) error {
+	var state tableState[T]
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	if state.Items == nil {
+		state.Items = make(map[int]T)
+	}
+
+	if state.NextID < 1 {
+		state.NextID = 1
+	}
+
+	for id := range state.Items {
+		if id >= state.NextID {
+			state.NextID = id + 1
+		}
+	}
+
+	*items = state.Items
+	*nextID = state.NextID
+
+	return nil
+}
+
+// replayTable writes the rows of a change back into items. Replaying the same
+// change twice gives the same result.
+func replayTable[T any](rows map[int]json.RawMessage, items map[int]T, nextID *int, changeNextID int) error {
+	for id, raw := range rows {
+		if bytes.Equal(raw, []byte("null")) {
+			delete(items, id)
+			continue
+		}
+
+		var item T
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return err
+		}
+
+		items[id] = item
+
+		if id >= *nextID {
+			*nextID = id + 1
+		}
+	}
+
+	if changeNextID > *nextID {
+		*nextID = changeNextID
+	}
+
+	return nil
+}
+
+// putItem sets items[id] and hands the change to persist. When persist fails
+// the previous row is put back so memory never holds a change the storage
+// does not.
+func putItem[T any](items map[int]T, id int, item T, persist func(c change) error, call string) error {
+	previous, existed := items[id]
+	items[id] = item
+
+	before := map[int]any{id: nil}
+	if existed {
+		before[id] = previous
+	}
+
+	if err := persist(change{Call: call, Rows: map[int]any{id: item}, Before: before}); err != nil {
+		if existed {
+			items[id] = previous
+		} else {
+			delete(items, id)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// deleteItem removes items[id] and hands the change to persist, restoring the
+// row when persist fails.
+func deleteItem[T any](items map[int]T, id int, persist func(c change) error, call string) error {
+	previous, existed := items[id]
+	if !existed {
+		return nil
+	}
+
+	delete(items, id)
+
+	if err := persist(change{Call: call, Rows: map[int]any{id: nil}, Before: map[int]any{id: previous}}); err != nil {
+		items[id] = previous
+		return err
+	}
+
+	return nil
+}
+
+// revertTable puts the rows of before back into items. It cannot fail, so a
+// rollback always restores memory even when the storage refuses the write.
+func revertTable[T any](items map[int]T, before map[int]any) {
+	for id, row := range before {
+		if row == nil {
+			delete(items, id)
+			continue
+		}
+
+		items[id] = row.(T)
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"fmt"
 	"payment-mutex/internal/domain/record"
 	"payment-mutex/internal/domain/requests"
@@ -16,6 +17,8 @@ type topupRepository struct {
 	mu      sync.RWMutex
 	topups  map[int]models.Topup
 	nextID  int
+	store   storage
+	undo    *undoLog
 	mapping recordmapper.TopupRecordMapping
 }
 
@@ -27,13 +30,17 @@ func NewTopupRepository(mapping recordmapper.TopupRecordMapping) *topupRepositor
 	}
 }
 
-func (ds *topupRepository) ReadAll(page int, pageSize int, search string) ([]*record.TopupRecord, int, error) {
+func (ds *topupRepository) ReadAll(page int, pageSize int, search string, status string) ([]*record.TopupRecord, int, error) {
 	ds.mu.RLock()
 	defer ds.mu.RUnlock()
 
 	filteredTopups := make([]models.Topup, 0)
 
 	for _, topup := range ds.topups {
+		if status != "" && topup.Status != status {
+			continue
+		}
+
 		if search == "" ||
 			strings.Contains(strings.ToLower(topup.CardNumber), strings.ToLower(search)) ||
 			strings.Contains(strings.ToLower(topup.TopupNo), strings.ToLower(search)) ||
@@ -97,9 +104,15 @@ func (ds *topupRepository) Create(request requests.CreateTopupRequest) (*record.
 		TopupAmount: request.TopupAmount,
 		TopupMethod: request.TopupMethod,
 		TopupTime:   time.Now(),
+		Status:      models.StatusPending,
+		StatusHistory: []models.StatusChange{
+			{Status: models.StatusPending, ChangedAt: time.Now()},
+		},
 	}
 
-	ds.topups[topup.TopupID] = topup
+	if err := putItem(ds.topups, topup.TopupID, topup, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
 
 	ds.nextID++
 
@@ -121,16 +134,21 @@ func (ds *topupRepository) Update(request requests.UpdateTopupRequest) (*record.
 	topup.TopupAmount = request.TopupAmount
 	topup.TopupMethod = request.TopupMethod
 	topup.TopupTime = time.Now()
-	ds.topups[request.TopupID] = topup
+	if err := putItem(ds.topups, request.TopupID, topup, ds.persist, "Update"); err != nil {
+		return nil, err
+	}
 
 	return ds.mapping.ToTopupRecord(topup), nil
 }
 
 func (ds *topupRepository) UpdateAmount(request requests.UpdateTopupAmount) (*record.TopupRecord, error) {
 	ds.mu.Lock()
-
 	defer ds.mu.Unlock()
 
+	return ds.updateAmount(request)
+}
+
+func (ds *topupRepository) updateAmount(request requests.UpdateTopupAmount) (*record.TopupRecord, error) {
 	topup, exists := ds.topups[request.TopupID]
 
 	if !exists {
@@ -139,7 +157,9 @@ func (ds *topupRepository) UpdateAmount(request requests.UpdateTopupAmount) (*re
 
 	topup.TopupAmount = request.TopupAmount
 
-	ds.topups[request.TopupID] = topup
+	if err := putItem(ds.topups, request.TopupID, topup, ds.persist, "UpdateAmount"); err != nil {
+		return nil, err
+	}
 
 	return ds.mapping.ToTopupRecord(topup), nil
 }
@@ -149,9 +169,112 @@ func (ds *topupRepository) Delete(topupID int) error {
 	defer ds.mu.Unlock()
 
 	if _, ok := ds.topups[topupID]; ok {
-		delete(ds.topups, topupID)
-		return nil
+		return deleteItem(ds.topups, topupID, ds.persist, "Delete")
 	}
 
 	return fmt.Errorf("topup with ID %d not found", topupID)
 }
+
+func (ds *topupRepository) UpdateStatus(topupID int, status string) (*record.TopupRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.updateStatus(topupID, status)
+}
+
+func (ds *topupRepository) updateStatus(topupID int, status string) (*record.TopupRecord, error) {
+	topup, ok := ds.topups[topupID]
+	if !ok {
+		return nil, fmt.Errorf("topup with ID %d not found", topupID)
+	}
+
+	topup.Status = status
+	topup.StatusHistory = append(topup.StatusHistory, models.StatusChange{
+		Status:    status,
+		ChangedAt: time.Now(),
+	})
+
+	if err := putItem(ds.topups, topupID, topup, ds.persist, "UpdateStatus"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToTopupRecord(topup), nil
+}
+
+func (ds *topupRepository) tableName() string {
+	return "topups"
+}
+
+func (ds *topupRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *topupRepository) currentStorage() storage {
+	return ds.store
+}
+
+func (ds *topupRepository) state() tableState[models.Topup] {
+	return tableState[models.Topup]{Items: ds.topups, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage and, inside a unit of work, to its
+// undo log. It must be called with ds.mu held.
+func (ds *topupRepository) persist(c change) error {
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	if ds.store != nil {
+		if err := ds.store.write(c, ds.state()); err != nil {
+			return err
+		}
+	}
+
+	ds.undo.record(ds, c.Before)
+
+	return nil
+}
+
+// revert puts rows back the way a unit of work found them. It must be called
+// with ds.mu held.
+func (ds *topupRepository) revert(before map[int]any) error {
+	revertTable(ds.topups, before)
+
+	if ds.store == nil {
+		return nil
+	}
+
+	return ds.store.write(change{Table: ds.tableName(), Call: "Rollback", Rows: before, NextID: ds.nextID}, ds.state())
+}
+
+func (ds *topupRepository) lock() {
+	ds.mu.Lock()
+}
+
+func (ds *topupRepository) unlock() {
+	ds.mu.Unlock()
+}
+
+func (ds *topupRepository) useUndoLog(u *undoLog) {
+	ds.undo = u
+}
+
+func (ds *topupRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *topupRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.topups, &ds.nextID)
+}
+
+func (ds *topupRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.topups, &ds.nextID, nextID)
+}
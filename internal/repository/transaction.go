@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"fmt"
 	"payment-mutex/internal/domain/record"
 	"payment-mutex/internal/domain/requests"
@@ -8,12 +9,15 @@ import (
 	"payment-mutex/internal/models"
 	"strings"
 	"sync"
+	"time"
 )
 
 type transactionRepository struct {
 	mu           sync.RWMutex
 	transactions map[int]models.Transaction
 	nextID       int
+	store        storage
+	undo         *undoLog
 	mapping      recordmapper.TransactionRecordMapping
 }
 
@@ -25,13 +29,17 @@ func NewTransactionRepository(mapping recordmapper.TransactionRecordMapping) *tr
 	}
 }
 
-func (ds *transactionRepository) ReadAll(page int, pageSize int, search string) ([]*record.TransactionRecord, int, error) {
+func (ds *transactionRepository) ReadAll(page int, pageSize int, search string, status string) ([]*record.TransactionRecord, int, error) {
 	ds.mu.RLock()
 	defer ds.mu.RUnlock()
 
 	filteredTransactions := make([]models.Transaction, 0)
 
 	for _, transaction := range ds.transactions {
+		if status != "" && transaction.Status != status {
+			continue
+		}
+
 		if search == "" ||
 			strings.Contains(strings.ToLower(transaction.CardNumber), strings.ToLower(search)) ||
 			strings.Contains(strings.ToLower(transaction.PaymentMethod), strings.ToLower(search)) {
@@ -96,12 +104,20 @@ func (ds *transactionRepository) Create(request requests.CreateTransactionReques
 		TransactionID:   ds.nextID,
 		CardNumber:      request.CardNumber,
 		Amount:          request.Amount,
+		Fee:             request.Fee,
 		PaymentMethod:   request.PaymentMethod,
 		MerchantID:      *request.MerchantID,
 		TransactionTime: request.TransactionTime,
+		Status:          models.StatusPending,
+		StatusHistory: []models.StatusChange{
+			{Status: models.StatusPending, ChangedAt: time.Now()},
+		},
+	}
+
+	if err := putItem(ds.transactions, transaction.TransactionID, transaction, ds.persist, "Create"); err != nil {
+		return nil, err
 	}
 
-	ds.transactions[transaction.TransactionID] = transaction
 	ds.nextID++
 
 	return ds.mapping.ToTransactionRecord(transaction), nil
@@ -111,6 +127,10 @@ func (ds *transactionRepository) Update(request requests.UpdateTransactionReques
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 
+	return ds.update(request)
+}
+
+func (ds *transactionRepository) update(request requests.UpdateTransactionRequest) (*record.TransactionRecord, error) {
 	transaction, ok := ds.transactions[request.TransactionID]
 	if !ok {
 		return nil, fmt.Errorf("transaction with ID %d not found", request.TransactionID)
@@ -118,11 +138,14 @@ func (ds *transactionRepository) Update(request requests.UpdateTransactionReques
 
 	transaction.CardNumber = request.CardNumber
 	transaction.Amount = request.Amount
+	transaction.Fee = request.Fee
 	transaction.MerchantID = *request.MerchantID
 	transaction.PaymentMethod = request.PaymentMethod
 	transaction.TransactionTime = request.TransactionTime
 
-	ds.transactions[transaction.TransactionID] = transaction
+	if err := putItem(ds.transactions, transaction.TransactionID, transaction, ds.persist, "Update"); err != nil {
+		return nil, err
+	}
 
 	return ds.mapping.ToTransactionRecord(transaction), nil
 }
@@ -133,9 +156,125 @@ func (ds *transactionRepository) Delete(transactionID int) error {
 	defer ds.mu.Unlock()
 
 	if _, ok := ds.transactions[transactionID]; ok {
-		delete(ds.transactions, transactionID)
-		return nil
+		return deleteItem(ds.transactions, transactionID, ds.persist, "Delete")
 	}
 
 	return fmt.Errorf("transaction with ID %d not found", transactionID)
 }
+
+func (ds *transactionRepository) UpdateStatus(transactionID int, status string) (*record.TransactionRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.updateStatus(transactionID, status)
+}
+
+func (ds *transactionRepository) updateStatusFrom(transactionID int, from string, status string) (*record.TransactionRecord, error) {
+	transaction, ok := ds.transactions[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("transaction with ID %d not found", transactionID)
+	}
+
+	if transaction.Status != from {
+		return nil, ErrStatusChanged
+	}
+
+	return ds.updateStatus(transactionID, status)
+}
+
+func (ds *transactionRepository) updateStatus(transactionID int, status string) (*record.TransactionRecord, error) {
+	transaction, ok := ds.transactions[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("transaction with ID %d not found", transactionID)
+	}
+
+	transaction.Status = status
+	transaction.StatusHistory = append(transaction.StatusHistory, models.StatusChange{
+		Status:    status,
+		ChangedAt: time.Now(),
+	})
+
+	if err := putItem(ds.transactions, transactionID, transaction, ds.persist, "UpdateStatus"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToTransactionRecord(transaction), nil
+}
+
+func (ds *transactionRepository) tableName() string {
+	return "transactions"
+}
+
+func (ds *transactionRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *transactionRepository) currentStorage() storage {
+	return ds.store
+}
+
+func (ds *transactionRepository) state() tableState[models.Transaction] {
+	return tableState[models.Transaction]{Items: ds.transactions, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage and, inside a unit of work, to its
+// undo log. It must be called with ds.mu held.
+func (ds *transactionRepository) persist(c change) error {
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	if ds.store != nil {
+		if err := ds.store.write(c, ds.state()); err != nil {
+			return err
+		}
+	}
+
+	ds.undo.record(ds, c.Before)
+
+	return nil
+}
+
+// revert puts rows back the way a unit of work found them. It must be called
+// with ds.mu held.
+func (ds *transactionRepository) revert(before map[int]any) error {
+	revertTable(ds.transactions, before)
+
+	if ds.store == nil {
+		return nil
+	}
+
+	return ds.store.write(change{Table: ds.tableName(), Call: "Rollback", Rows: before, NextID: ds.nextID}, ds.state())
+}
+
+func (ds *transactionRepository) lock() {
+	ds.mu.Lock()
+}
+
+func (ds *transactionRepository) unlock() {
+	ds.mu.Unlock()
+}
+
+func (ds *transactionRepository) useUndoLog(u *undoLog) {
+	ds.undo = u
+}
+
+func (ds *transactionRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *transactionRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.transactions, &ds.nextID)
+}
+
+func (ds *transactionRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.transactions, &ds.nextID, nextID)
+}
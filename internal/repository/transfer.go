@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"fmt"
 	"payment-mutex/internal/domain/record"
 	"payment-mutex/internal/domain/requests"
@@ -16,6 +17,8 @@ type transferRepository struct {
 	mu        sync.RWMutex
 	transfers map[int]models.Transfer
 	nextID    int
+	store     storage
+	undo      *undoLog
 	mapping   recordmapper.TransferRecordMapping
 }
 
@@ -27,13 +30,17 @@ func NewTransferRepository(mapping recordmapper.TransferRecordMapping) *transfer
 	}
 }
 
-func (ds *transferRepository) ReadAll(page int, pageSize int, search string) ([]*record.TransferRecord, int, error) {
+func (ds *transferRepository) ReadAll(page int, pageSize int, search string, status string) ([]*record.TransferRecord, int, error) {
 	ds.mu.RLock()
 	defer ds.mu.RUnlock()
 
 	filteredTransfers := make([]models.Transfer, 0)
 
 	for _, transfer := range ds.transfers {
+		if status != "" && transfer.Status != status {
+			continue
+		}
+
 		if search == "" ||
 			strings.Contains(strings.ToLower(transfer.TransferFrom), strings.ToLower(search)) ||
 			strings.Contains(strings.ToLower(transfer.TransferTo), strings.ToLower(search)) {
@@ -99,9 +106,15 @@ func (ds *transferRepository) Create(request requests.CreateTransferRequest) (*r
 		TransferTo:     request.TransferTo,
 		TransferAmount: request.TransferAmount,
 		TransferTime:   time.Now(),
+		Status:         models.StatusPending,
+		StatusHistory: []models.StatusChange{
+			{Status: models.StatusPending, ChangedAt: time.Now()},
+		},
 	}
 
-	ds.transfers[transfer.TransferID] = transfer
+	if err := putItem(ds.transfers, transfer.TransferID, transfer, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
 
 	ds.nextID++
 
@@ -112,6 +125,10 @@ func (ds *transferRepository) Update(request requests.UpdateTransferRequest) (*r
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 
+	return ds.update(request)
+}
+
+func (ds *transferRepository) update(request requests.UpdateTransferRequest) (*record.TransferRecord, error) {
 	transfer, exists := ds.transfers[request.TransferID]
 
 	if !exists {
@@ -122,7 +139,9 @@ func (ds *transferRepository) Update(request requests.UpdateTransferRequest) (*r
 	transfer.TransferTo = request.TransferTo
 	transfer.TransferAmount = request.TransferAmount
 	transfer.TransferTime = time.Now()
-	ds.transfers[request.TransferID] = transfer
+	if err := putItem(ds.transfers, request.TransferID, transfer, ds.persist, "Update"); err != nil {
+		return nil, err
+	}
 
 	return ds.mapping.ToTransferRecord(transfer), nil
 }
@@ -140,7 +159,9 @@ func (ds *transferRepository) UpdateAmount(request requests.UpdateTransferAmount
 	transfer.TransferAmount = request.TransferAmount
 	transfer.TransferTime = time.Now()
 
-	ds.transfers[request.TransferID] = transfer
+	if err := putItem(ds.transfers, request.TransferID, transfer, ds.persist, "UpdateAmount"); err != nil {
+		return nil, err
+	}
 
 	return ds.mapping.ToTransferRecord(transfer), nil
 
@@ -151,9 +172,125 @@ func (ds *transferRepository) Delete(transferID int) error {
 	defer ds.mu.Unlock()
 
 	if _, ok := ds.transfers[transferID]; ok {
-		delete(ds.transfers, transferID)
-		return nil
+		return deleteItem(ds.transfers, transferID, ds.persist, "Delete")
 	}
 
 	return fmt.Errorf("transfer with ID %d not found", transferID)
 }
+
+func (ds *transferRepository) UpdateStatus(transferID int, status string) (*record.TransferRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.updateStatus(transferID, status)
+}
+
+func (ds *transferRepository) updateStatusFrom(transferID int, from string, status string) (*record.TransferRecord, error) {
+	transfer, ok := ds.transfers[transferID]
+	if !ok {
+		return nil, fmt.Errorf("transfer with ID %d not found", transferID)
+	}
+
+	if transfer.Status != from {
+		return nil, ErrStatusChanged
+	}
+
+	return ds.updateStatus(transferID, status)
+}
+
+func (ds *transferRepository) updateStatus(transferID int, status string) (*record.TransferRecord, error) {
+	transfer, ok := ds.transfers[transferID]
+	if !ok {
+		return nil, fmt.Errorf("transfer with ID %d not found", transferID)
+	}
+
+	transfer.Status = status
+	transfer.StatusHistory = append(transfer.StatusHistory, models.StatusChange{
+		Status:    status,
+		ChangedAt: time.Now(),
+	})
+
+	if err := putItem(ds.transfers, transferID, transfer, ds.persist, "UpdateStatus"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToTransferRecord(transfer), nil
+}
+
+func (ds *transferRepository) tableName() string {
+	return "transfers"
+}
+
+func (ds *transferRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *transferRepository) currentStorage() storage {
+	return ds.store
+}
+
+func (ds *transferRepository) state() tableState[models.Transfer] {
+	return tableState[models.Transfer]{Items: ds.transfers, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage and, inside a unit of work, to its
+// undo log. It must be called with ds.mu held.
+func (ds *transferRepository) persist(c change) error {
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	if ds.store != nil {
+		if err := ds.store.write(c, ds.state()); err != nil {
+			return err
+		}
+	}
+
+	ds.undo.record(ds, c.Before)
+
+	return nil
+}
+
+// revert puts rows back the way a unit of work found them. It must be called
+// with ds.mu held.
+func (ds *transferRepository) revert(before map[int]any) error {
+	revertTable(ds.transfers, before)
+
+	if ds.store == nil {
+		return nil
+	}
+
+	return ds.store.write(change{Table: ds.tableName(), Call: "Rollback", Rows: before, NextID: ds.nextID}, ds.state())
+}
+
+func (ds *transferRepository) lock() {
+	ds.mu.Lock()
+}
+
+func (ds *transferRepository) unlock() {
+	ds.mu.Unlock()
+}
+
+func (ds *transferRepository) useUndoLog(u *undoLog) {
+	ds.undo = u
+}
+
+func (ds *transferRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *transferRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.transfers, &ds.nextID)
+}
+
+func (ds *transferRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.transfers, &ds.nextID, nextID)
+}
@@ -0,0 +1,475 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+)
+
+var (
+	// ErrUnitOfWorkDone is returned when a unit of work is used after it was
+	// committed or rolled back.
+	ErrUnitOfWorkDone = errors.New("unit of work already finished")
+	// ErrStatusChanged is returned when a staged status update finds the row
+	// no longer in the status it was staged from.
+	ErrStatusChanged = errors.New("status changed")
+)
+
+// unitTable is a repository that can take part in a unit of work.
+type unitTable interface {
+	lock()
+	unlock()
+	// useUndoLog makes every change the repository persists land in u as
+	// well, until it is called again with nil.
+	useUndoLog(u *undoLog)
+	// revert puts back rows recorded in an undo log.
+	revert(before map[int]any) error
+	useStorage(s storage)
+	currentStorage() storage
+}
+
+// unitWrite is a storage write a unit of work holds back until commit.
+type unitWrite struct {
+	store  storage
+	change change
+	state  any
+}
+
+// unitBuffer collects the storage writes of a unit of work, so they reach
+// the storage as one group once every staged call succeeded.
+type unitBuffer struct {
+	writes []unitWrite
+}
+
+// bufferedStorage stands in for the storage of a repository while a unit of
+// work runs on it.
+type bufferedStorage struct {
+	buffer *unitBuffer
+	store  storage
+}
+
+func (s *bufferedStorage) write(c change, state any) error {
+	s.buffer.writes = append(s.buffer.writes, unitWrite{store: s.store, change: c, state: state})
+	return nil
+}
+
+// flush hands the buffered writes to the storage. Storage that can write a
+// group does so in one step; any other storage gets the writes one by one.
+func (b *unitBuffer) flush() error {
+	if len(b.writes) == 0 {
+		return nil
+	}
+
+	if group, ok := b.writes[0].store.(groupStorage); ok {
+		return group.writeGroup(b.writes)
+	}
+
+	for _, w := range b.writes {
+		if err := w.store.write(w.change, w.state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type undoStep struct {
+	table  unitTable
+	before map[int]any
+}
+
+// undoLog collects the rows a unit of work changed, as they were before it
+// changed them. A nil undoLog records nothing, which is the case for calls
+// made outside a unit of work.
+type undoLog struct {
+	steps []undoStep
+}
+
+func (u *undoLog) record(t unitTable, before map[int]any) {
+	if u == nil || len(before) == 0 {
+		return
+	}
+
+	u.steps = append(u.steps, undoStep{table: t, before: before})
+}
+
+// rollback reverts the recorded steps newest first, so a row changed twice
+// ends up the way it was before the first change. Memory is always restored;
+// the returned error only reports storage that refused a rollback write.
+func (u *undoLog) rollback() error {
+	var errs []error
+
+	for i := len(u.steps) - 1; i >= 0; i-- {
+		step := u.steps[i]
+
+		if err := step.table.revert(step.before); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	u.steps = nil
+
+	return errors.Join(errs...)
+}
+
+// unitTables are the repositories a unit of work can stage calls on, in the
+// order their locks are taken.
+type unitTables struct {
+	saldo       *saldoRepository
+	journal     *journalRepository
+	topup       *topupRepository
+	transfer    *transferRepository
+	transaction *transactionRepository
+	withdraw    *withdrawRepository
+
+	withdrawHold    *withdrawHoldRepository
+	authorization   *authorizationRepository
+	settlementItem  *settlementItemRepository
+	settlementBatch *settlementBatchRepository
+}
+
+// UnitOfWork stages calls on the saldo, journal, topup, transfer,
+// transaction, withdraw, withdraw hold, authorization and settlement
+// repositories and applies them together: Commit either applies every staged
+// call or, when one of them fails, leaves all of them undone. Nothing touches
+// the repositories before Commit, so a unit of work that is never committed
+// changes nothing.
+//
+// The results of staged calls are returned as Pending values that are filled
+// in by a successful Commit.
+type UnitOfWork struct {
+	Saldo       *StagedSaldo
+	Journal     *StagedJournal
+	Topup       *StagedTopup
+	Transfer    *StagedTransfer
+	Transaction *StagedTransaction
+	Withdraw    *StagedWithdraw
+
+	WithdrawHold    *StagedWithdrawHold
+	Authorization   *StagedAuthorization
+	SettlementItem  *StagedSettlementItem
+	SettlementBatch *StagedSettlementBatch
+
+	tables  []unitTable
+	touched map[unitTable]bool
+	calls   []func() error
+	done    bool
+}
+
+// Units starts units of work. It is implemented by Repositories.
+type Units interface {
+	Begin() *UnitOfWork
+}
+
+// Begin starts an empty unit of work.
+func (r *Repositories) Begin() *UnitOfWork {
+	t := r.units
+
+	u := &UnitOfWork{
+		tables:  []unitTable{t.saldo, t.journal, t.topup, t.transfer, t.transaction, t.withdraw, t.withdrawHold, t.authorization, t.settlementItem, t.settlementBatch},
+		touched: make(map[unitTable]bool),
+	}
+
+	u.Saldo = &StagedSaldo{unit: u, repository: t.saldo}
+	u.Journal = &StagedJournal{unit: u, repository: t.journal}
+	u.Topup = &StagedTopup{unit: u, repository: t.topup}
+	u.Transfer = &StagedTransfer{unit: u, repository: t.transfer}
+	u.Transaction = &StagedTransaction{unit: u, repository: t.transaction}
+	u.Withdraw = &StagedWithdraw{unit: u, repository: t.withdraw}
+	u.WithdrawHold = &StagedWithdrawHold{unit: u, repository: t.withdrawHold}
+	u.Authorization = &StagedAuthorization{unit: u, repository: t.authorization}
+	u.SettlementItem = &StagedSettlementItem{unit: u, repository: t.settlementItem}
+	u.SettlementBatch = &StagedSettlementBatch{unit: u, repository: t.settlementBatch}
+
+	return u
+}
+
+// Commit runs the staged calls in the order they were staged while holding
+// the locks of every repository involved, so no other caller sees a partial
+// result. The storage only receives the changes once every call succeeded,
+// as one group, so a crash in the middle of a commit leaves none of them on
+// disk. When a call or the storage write fails the changes made in memory
+// are reverted and the error is returned.
+func (u *UnitOfWork) Commit() error {
+	if u.done {
+		return ErrUnitOfWorkDone
+	}
+
+	u.done = true
+
+	undo := &undoLog{}
+	buffer := &unitBuffer{}
+	locked := make([]unitTable, 0, len(u.touched))
+	stores := make(map[unitTable]storage, len(u.touched))
+
+	for _, t := range u.tables {
+		if !u.touched[t] {
+			continue
+		}
+
+		t.lock()
+		t.useUndoLog(undo)
+
+		// Tanpa storage (driver memory) tidak ada yang perlu ditahan
+		if store := t.currentStorage(); store != nil {
+			stores[t] = store
+			t.useStorage(&bufferedStorage{buffer: buffer, store: store})
+		}
+
+		locked = append(locked, t)
+	}
+
+	defer func() {
+		for i := len(locked) - 1; i >= 0; i-- {
+			if store, ok := stores[locked[i]]; ok {
+				locked[i].useStorage(store)
+			}
+
+			locked[i].useUndoLog(nil)
+			locked[i].unlock()
+		}
+	}()
+
+	err := u.run()
+	if err == nil {
+		err = buffer.flush()
+	}
+
+	if err != nil {
+		// Tulisan rollback ikut tertahan di buffer dan dibuang, storage tidak pernah melihat unit ini
+		if rollbackErr := undo.rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (u *UnitOfWork) run() error {
+	for _, call := range u.calls {
+		if err := call(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rollback drops the staged calls without running them.
+func (u *UnitOfWork) Rollback() {
+	u.done = true
+	u.calls = nil
+}
+
+// Pending is the result of a staged call.
+type Pending[T any] struct {
+	value T
+}
+
+// Value returns the result of the call, or the zero value when the unit of
+// work has not been committed successfully.
+func (p *Pending[T]) Value() T {
+	return p.value
+}
+
+func stage[T any](u *UnitOfWork, t unitTable, call func() (T, error)) *Pending[T] {
+	pending := &Pending[T]{}
+
+	u.touched[t] = true
+	u.calls = append(u.calls, func() error {
+		value, err := call()
+		if err != nil {
+			return err
+		}
+
+		pending.value = value
+
+		return nil
+	})
+
+	return pending
+}
+
+// StagedSaldo stages saldo repository calls on a unit of work.
+type StagedSaldo struct {
+	unit       *UnitOfWork
+	repository *saldoRepository
+}
+
+func (s *StagedSaldo) UpdateBalances(request requests.UpdateSaldoBalances) *Pending[[]*record.SaldoRecord] {
+	return stage(s.unit, s.repository, func() ([]*record.SaldoRecord, error) {
+		return s.repository.updateBalances(request)
+	})
+}
+
+// StagedJournal stages journal repository calls on a unit of work.
+type StagedJournal struct {
+	unit       *UnitOfWork
+	repository *journalRepository
+}
+
+func (s *StagedJournal) Create(request requests.CreateJournalEntryRequest) *Pending[*record.JournalEntryRecord] {
+	return stage(s.unit, s.repository, func() (*record.JournalEntryRecord, error) {
+		return s.repository.create(request)
+	})
+}
+
+// StagedTopup stages topup repository calls on a unit of work.
+type StagedTopup struct {
+	unit       *UnitOfWork
+	repository *topupRepository
+}
+
+func (s *StagedTopup) UpdateAmount(request requests.UpdateTopupAmount) *Pending[*record.TopupRecord] {
+	return stage(s.unit, s.repository, func() (*record.TopupRecord, error) {
+		return s.repository.updateAmount(request)
+	})
+}
+
+func (s *StagedTopup) UpdateStatus(topupID int, status string) *Pending[*record.TopupRecord] {
+	return stage(s.unit, s.repository, func() (*record.TopupRecord, error) {
+		return s.repository.updateStatus(topupID, status)
+	})
+}
+
+// StagedTransfer stages transfer repository calls on a unit of work.
+type StagedTransfer struct {
+	unit       *UnitOfWork
+	repository *transferRepository
+}
+
+func (s *StagedTransfer) Update(request requests.UpdateTransferRequest) *Pending[*record.TransferRecord] {
+	return stage(s.unit, s.repository, func() (*record.TransferRecord, error) {
+		return s.repository.update(request)
+	})
+}
+
+func (s *StagedTransfer) UpdateStatus(transferID int, status string) *Pending[*record.TransferRecord] {
+	return stage(s.unit, s.repository, func() (*record.TransferRecord, error) {
+		return s.repository.updateStatus(transferID, status)
+	})
+}
+
+// UpdateStatusFrom moves the row from status from to status. The unit fails
+// with ErrStatusChanged when the row has another status by the time it is
+// committed.
+func (s *StagedTransfer) UpdateStatusFrom(transferID int, from string, status string) *Pending[*record.TransferRecord] {
+	return stage(s.unit, s.repository, func() (*record.TransferRecord, error) {
+		return s.repository.updateStatusFrom(transferID, from, status)
+	})
+}
+
+// StagedTransaction stages transaction repository calls on a unit of work.
+type StagedTransaction struct {
+	unit       *UnitOfWork
+	repository *transactionRepository
+}
+
+func (s *StagedTransaction) Update(request requests.UpdateTransactionRequest) *Pending[*record.TransactionRecord] {
+	return stage(s.unit, s.repository, func() (*record.TransactionRecord, error) {
+		return s.repository.update(request)
+	})
+}
+
+func (s *StagedTransaction) UpdateStatus(transactionID int, status string) *Pending[*record.TransactionRecord] {
+	return stage(s.unit, s.repository, func() (*record.TransactionRecord, error) {
+		return s.repository.updateStatus(transactionID, status)
+	})
+}
+
+// UpdateStatusFrom moves the row from status from to status. The unit fails
+// with ErrStatusChanged when the row has another status by the time it is
+// committed.
+func (s *StagedTransaction) UpdateStatusFrom(transactionID int, from string, status string) *Pending[*record.TransactionRecord] {
+	return stage(s.unit, s.repository, func() (*record.TransactionRecord, error) {
+		return s.repository.updateStatusFrom(transactionID, from, status)
+	})
+}
+
+// StagedWithdraw stages withdraw repository calls on a unit of work.
+type StagedWithdraw struct {
+	unit       *UnitOfWork
+	repository *withdrawRepository
+}
+
+func (s *StagedWithdraw) Update(request requests.UpdateWithdrawRequest) *Pending[*record.WithdrawRecord] {
+	return stage(s.unit, s.repository, func() (*record.WithdrawRecord, error) {
+		return s.repository.update(request)
+	})
+}
+
+func (s *StagedWithdraw) UpdateStatus(withdrawID int, status string) *Pending[*record.WithdrawRecord] {
+	return stage(s.unit, s.repository, func() (*record.WithdrawRecord, error) {
+		return s.repository.updateStatus(withdrawID, status)
+	})
+}
+
+// UpdateStatusFrom moves the row from status from to status. The unit fails
+// with ErrStatusChanged when the row has another status by the time it is
+// committed.
+func (s *StagedWithdraw) UpdateStatusFrom(withdrawID int, from string, status string) *Pending[*record.WithdrawRecord] {
+	return stage(s.unit, s.repository, func() (*record.WithdrawRecord, error) {
+		return s.repository.updateStatusFrom(withdrawID, from, status)
+	})
+}
+
+// StagedWithdrawHold stages withdraw hold repository calls on a unit of
+// work.
+type StagedWithdrawHold struct {
+	unit       *UnitOfWork
+	repository *withdrawHoldRepository
+}
+
+func (s *StagedWithdrawHold) UpdateStatus(request requests.UpdateWithdrawHoldStatus) *Pending[*record.WithdrawHoldRecord] {
+	return stage(s.unit, s.repository, func() (*record.WithdrawHoldRecord, error) {
+		return s.repository.updateStatus(request)
+	})
+}
+
+// StagedAuthorization stages authorization repository calls on a unit of
+// work.
+type StagedAuthorization struct {
+	unit       *UnitOfWork
+	repository *authorizationRepository
+}
+
+func (s *StagedAuthorization) UpdateStatus(request requests.UpdateAuthorizationStatus) *Pending[*record.AuthorizationRecord] {
+	return stage(s.unit, s.repository, func() (*record.AuthorizationRecord, error) {
+		return s.repository.updateStatus(request)
+	})
+}
+
+// StagedSettlementItem stages settlement item repository calls on a unit of
+// work.
+type StagedSettlementItem struct {
+	unit       *UnitOfWork
+	repository *settlementItemRepository
+}
+
+func (s *StagedSettlementItem) Create(request requests.CreateSettlementItemRequest) *Pending[*record.SettlementItemRecord] {
+	return stage(s.unit, s.repository, func() (*record.SettlementItemRecord, error) {
+		return s.repository.create(request)
+	})
+}
+
+func (s *StagedSettlementItem) AssignBatch(itemIDs []int, batchID int) *Pending[[]*record.SettlementItemRecord] {
+	return stage(s.unit, s.repository, func() ([]*record.SettlementItemRecord, error) {
+		return s.repository.assignBatch(itemIDs, batchID)
+	})
+}
+
+// StagedSettlementBatch stages settlement batch repository calls on a unit of
+// work.
+type StagedSettlementBatch struct {
+	unit       *UnitOfWork
+	repository *settlementBatchRepository
+}
+
+func (s *StagedSettlementBatch) UpdateStatus(request requests.UpdateSettlementBatchStatus) *Pending[*record.SettlementBatchRecord] {
+	return stage(s.unit, s.repository, func() (*record.SettlementBatchRecord, error) {
+		return s.repository.updateStatus(request)
+	})
+}
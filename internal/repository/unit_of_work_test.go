@@ -0,0 +1,368 @@
+package repository
+
+import (
+	"errors"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"testing"
+)
+
+// newTestRepositories returns repositories on driver in dir, recovered and
+// ready to use, with a saldo of zero for cards A and B when they are new.
+func newTestRepositories(t *testing.T, driver string, dir string) *Repositories {
+	t.Helper()
+
+	r, err := NewRepositorys(Deps{MapperRecord: *recordmapper.NewRecordMapper(), StorageDriver: driver, StoragePath: dir})
+	if err != nil {
+		t.Fatalf("open repositories: %v", err)
+	}
+
+	if err := r.Recover(); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	t.Cleanup(func() { r.Close() })
+
+	for _, card := range []string{"A", "B"} {
+		if _, err := r.Saldo.ReadByCardNumber(card); err == nil {
+			continue
+		}
+
+		if _, err := r.Saldo.Create(requests.CreateSaldoRequest{CardNumber: card}); err != nil {
+			t.Fatalf("create saldo %s: %v", card, err)
+		}
+	}
+
+	return r
+}
+
+func testEntry(card string, amount int) requests.CreateJournalEntryRequest {
+	return requests.CreateJournalEntryRequest{
+		Reference:   "test",
+		ReferenceID: amount,
+		Postings: []requests.CreatePostingRequest{
+			{Account: "system:test", Debit: amount},
+			{Account: "card:" + card, Credit: amount},
+		},
+	}
+}
+
+func balanceChange(card string, amount int) requests.UpdateSaldoBalances {
+	return requests.UpdateSaldoBalances{Changes: []requests.SaldoBalanceChange{{CardNumber: card, Amount: amount}}}
+}
+
+func balanceOf(t *testing.T, r *Repositories, card string) int {
+	t.Helper()
+
+	saldo, err := r.Saldo.ReadByCardNumber(card)
+	if err != nil {
+		t.Fatalf("read saldo %s: %v", card, err)
+	}
+
+	return saldo.TotalBalance
+}
+
+func TestUnitOfWorkCommit(t *testing.T) {
+	tests := []struct {
+		name     string
+		stage    func(u *UnitOfWork)
+		fails    bool
+		wantErr  error
+		wantA    int
+		wantB    int
+		journals int
+	}{
+		{
+			name: "applies every call",
+			stage: func(u *UnitOfWork) {
+				u.Journal.Create(testEntry("A", 100))
+				u.Saldo.UpdateBalances(balanceChange("A", 100))
+			},
+			wantA:    100,
+			journals: 1,
+		},
+		{
+			name: "undoes earlier calls when a later one fails",
+			stage: func(u *UnitOfWork) {
+				u.Journal.Create(testEntry("A", 100))
+				u.Saldo.UpdateBalances(balanceChange("A", 100))
+				u.Saldo.UpdateBalances(balanceChange("B", -500))
+			},
+			fails:   true,
+			wantErr: ErrInsufficientBalance,
+		},
+		{
+			name: "puts a row changed twice back the way it was",
+			stage: func(u *UnitOfWork) {
+				u.Saldo.UpdateBalances(balanceChange("A", 100))
+				u.Saldo.UpdateBalances(balanceChange("A", 50))
+				u.Saldo.UpdateBalances(balanceChange("A", -500))
+			},
+			fails:   true,
+			wantErr: ErrInsufficientBalance,
+		},
+		{
+			name: "undoes a created row when a later call fails",
+			stage: func(u *UnitOfWork) {
+				u.Journal.Create(testEntry("A", 100))
+				u.Journal.Create(requests.CreateJournalEntryRequest{Reference: "unbalanced"})
+			},
+			fails: true,
+		},
+	}
+
+	for _, driver := range []string{StorageDriverMemory, StorageDriverFile, StorageDriverWAL} {
+		for _, tt := range tests {
+			t.Run(driver+"/"+tt.name, func(t *testing.T) {
+				dir := t.TempDir()
+				r := newTestRepositories(t, driver, dir)
+
+				u := r.Begin()
+				tt.stage(u)
+				err := u.Commit()
+
+				if (err != nil) != tt.fails {
+					t.Fatalf("commit: %v, want failure = %v", err, tt.fails)
+				}
+
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("commit: %v, want %v", err, tt.wantErr)
+				}
+
+				check := func(r *Repositories) {
+					t.Helper()
+
+					if a, b := balanceOf(t, r, "A"), balanceOf(t, r, "B"); a != tt.wantA || b != tt.wantB {
+						t.Errorf("balances A=%d B=%d, want A=%d B=%d", a, b, tt.wantA, tt.wantB)
+					}
+
+					_, err := r.Journal.Read(1)
+					if found := err == nil; found != (tt.journals > 0) {
+						t.Errorf("journal entry found = %v, want %v", found, tt.journals > 0)
+					}
+				}
+
+				check(r)
+
+				if driver == StorageDriverMemory {
+					return
+				}
+
+				// Storage harus berisi hal yang sama dengan memori setelah dibuka ulang
+				r.Close()
+				check(newTestRepositories(t, driver, dir))
+			})
+		}
+	}
+}
+
+func TestUnitOfWorkPendingValues(t *testing.T) {
+	r := newTestRepositories(t, StorageDriverMemory, "")
+
+	u := r.Begin()
+	entry := u.Journal.Create(testEntry("A", 100))
+	saldos := u.Saldo.UpdateBalances(balanceChange("A", 100))
+
+	if entry.Value() != nil || saldos.Value() != nil {
+		t.Fatal("pending values are set before commit")
+	}
+
+	if err := u.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	if entry.Value() == nil || entry.Value().EntryID != 1 {
+		t.Errorf("journal entry = %+v, want entry 1", entry.Value())
+	}
+
+	if len(saldos.Value()) != 1 || saldos.Value()[0].TotalBalance != 100 {
+		t.Errorf("saldos = %+v, want A at 100", saldos.Value())
+	}
+}
+
+func TestUnitOfWorkFinishes(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(u *UnitOfWork) error
+		want   int
+	}{
+		{
+			name:   "commit",
+			finish: func(u *UnitOfWork) error { return u.Commit() },
+			want:   100,
+		},
+		{
+			name: "rollback",
+			finish: func(u *UnitOfWork) error {
+				u.Rollback()
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRepositories(t, StorageDriverMemory, "")
+
+			u := r.Begin()
+			u.Saldo.UpdateBalances(balanceChange("A", 100))
+
+			if err := tt.finish(u); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+
+			if err := u.Commit(); !errors.Is(err, ErrUnitOfWorkDone) {
+				t.Errorf("commit after %s: %v, want %v", tt.name, err, ErrUnitOfWorkDone)
+			}
+
+			if got := balanceOf(t, r, "A"); got != tt.want {
+				t.Errorf("balance of A = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitOfWorkUpdateStatusFrom(t *testing.T) {
+	merchantID := 1
+
+	// Setiap tabel punya baris yang sedang processing
+	tables := []struct {
+		name   string
+		create func(r *Repositories) (int, error)
+		set    func(r *Repositories, id int, status string) error
+		stage  func(u *UnitOfWork, id int)
+		status func(r *Repositories, id int) (string, error)
+	}{
+		{
+			name: "transaction",
+			create: func(r *Repositories) (int, error) {
+				row, err := r.Transaction.Create(requests.CreateTransactionRequest{CardNumber: "A", Amount: 100, MerchantID: &merchantID})
+				if err != nil {
+					return 0, err
+				}
+				return row.TransactionID, nil
+			},
+			set: func(r *Repositories, id int, status string) error {
+				_, err := r.Transaction.UpdateStatus(id, status)
+				return err
+			},
+			stage: func(u *UnitOfWork, id int) {
+				u.Transaction.UpdateStatusFrom(id, models.StatusProcessing, models.StatusSucceeded)
+			},
+			status: func(r *Repositories, id int) (string, error) {
+				row, err := r.Transaction.Read(id)
+				if err != nil {
+					return "", err
+				}
+				return row.Status, nil
+			},
+		},
+		{
+			name: "transfer",
+			create: func(r *Repositories) (int, error) {
+				row, err := r.Transfer.Create(requests.CreateTransferRequest{TransferFrom: "A", TransferTo: "B", TransferAmount: 100})
+				if err != nil {
+					return 0, err
+				}
+				return row.TransferID, nil
+			},
+			set: func(r *Repositories, id int, status string) error {
+				_, err := r.Transfer.UpdateStatus(id, status)
+				return err
+			},
+			stage: func(u *UnitOfWork, id int) {
+				u.Transfer.UpdateStatusFrom(id, models.StatusProcessing, models.StatusSucceeded)
+			},
+			status: func(r *Repositories, id int) (string, error) {
+				row, err := r.Transfer.Read(id)
+				if err != nil {
+					return "", err
+				}
+				return row.Status, nil
+			},
+		},
+		{
+			name: "withdraw",
+			create: func(r *Repositories) (int, error) {
+				row, err := r.Withdraw.Create(requests.CreateWithdrawRequest{CardNumber: "A", WithdrawAmount: 100})
+				if err != nil {
+					return 0, err
+				}
+				return row.WithdrawID, nil
+			},
+			set: func(r *Repositories, id int, status string) error {
+				_, err := r.Withdraw.UpdateStatus(id, status)
+				return err
+			},
+			stage: func(u *UnitOfWork, id int) {
+				u.Withdraw.UpdateStatusFrom(id, models.StatusProcessing, models.StatusSucceeded)
+			},
+			status: func(r *Repositories, id int) (string, error) {
+				row, err := r.Withdraw.Read(id)
+				if err != nil {
+					return "", err
+				}
+				return row.Status, nil
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		// changedTo is the status an admin sets while the row is processed,
+		// empty leaves it processing.
+		changedTo  string
+		wantErr    error
+		wantStatus string
+		wantA      int
+	}{
+		{"still processing", "", nil, models.StatusSucceeded, 100},
+		{"failed in between", models.StatusFailed, ErrStatusChanged, models.StatusFailed, 0},
+	}
+
+	for _, table := range tables {
+		for _, tt := range tests {
+			t.Run(table.name+"/"+tt.name, func(t *testing.T) {
+				r := newTestRepositories(t, StorageDriverMemory, "")
+
+				id, err := table.create(r)
+				if err != nil {
+					t.Fatalf("create: %v", err)
+				}
+
+				if err := table.set(r, id, models.StatusProcessing); err != nil {
+					t.Fatalf("mark processing: %v", err)
+				}
+
+				u := r.Begin()
+				table.stage(u, id)
+				u.Journal.Create(testEntry("A", 100))
+				u.Saldo.UpdateBalances(balanceChange("A", 100))
+
+				if tt.changedTo != "" {
+					if err := table.set(r, id, tt.changedTo); err != nil {
+						t.Fatalf("change status: %v", err)
+					}
+				}
+
+				if err := u.Commit(); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("commit: %v, want %v", err, tt.wantErr)
+				}
+
+				status, err := table.status(r, id)
+				if err != nil {
+					t.Fatalf("read status: %v", err)
+				}
+
+				if status != tt.wantStatus {
+					t.Errorf("status %q, want %q", status, tt.wantStatus)
+				}
+
+				if got := balanceOf(t, r, "A"); got != tt.wantA {
+					t.Errorf("balance of A = %d, want %d", got, tt.wantA)
+				}
+			})
+		}
+	}
+}
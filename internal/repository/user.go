@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"fmt"
 	"payment-mutex/internal/domain/record"
 	"payment-mutex/internal/domain/requests"
@@ -8,12 +9,14 @@ import (
 	"payment-mutex/internal/models"
 	"strings"
 	"sync"
+	"time"
 )
 
 type userRepository struct {
 	mu      sync.RWMutex
 	users   map[int]models.User
 	nextID  int
+	store   storage
 	mapping recordmapper.UserRecordMapping
 }
 
@@ -93,16 +96,32 @@ func (ds *userRepository) Create(request requests.CreateUserRequest) (*record.Us
 		}
 	}
 
+	// Pengguna tanpa role terdaftar sebagai customer
+	role := request.Role
+	if role == "" {
+		role = models.RoleCustomer
+	}
+
+	status := request.Status
+	if status == "" {
+		status = models.UserStatusActive
+	}
+
 	user := models.User{
 		UserID:    ds.nextID,
 		Email:     request.Email,
 		FirstName: request.FirstName,
 		LastName:  request.LastName,
 		Password:  request.Password,
+		Role:      role,
+		Status:    status,
 	}
 
 	user.UserID = ds.nextID
-	ds.users[user.UserID] = user
+	if err := putItem(ds.users, user.UserID, user, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
 	ds.nextID++
 
 	return ds.mapping.ToUserRecord(user), nil
@@ -123,19 +142,218 @@ func (ds *userRepository) Update(request requests.UpdateUserRequest) (*record.Us
 	user.LastName = request.LastName
 	user.Password = request.Password
 
-	ds.users[request.UserID] = user
+	if err := putItem(ds.users, request.UserID, user, ds.persist, "Update"); err != nil {
+		return nil, err
+	}
 
 	return ds.mapping.ToUserRecord(user), fmt.Errorf("user with ID %d not found", request.UserID)
 }
 
+func (ds *userRepository) UpdateRole(request requests.UpdateUserRoleRequest) (*record.UserRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	user, ok := ds.users[request.UserID]
+
+	if !ok {
+		return nil, fmt.Errorf("user with id %d not found", request.UserID)
+	}
+
+	user.Role = request.Role
+
+	if err := putItem(ds.users, request.UserID, user, ds.persist, "UpdateRole"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToUserRecord(user), nil
+}
+
+// MarkEmailVerified activates a pending account.
+func (ds *userRepository) MarkEmailVerified(userID int) (*record.UserRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	user, ok := ds.users[userID]
+
+	if !ok {
+		return nil, fmt.Errorf("user with id %d not found", userID)
+	}
+
+	now := time.Now()
+
+	user.Status = models.UserStatusActive
+	user.EmailVerifiedAt = &now
+
+	if err := putItem(ds.users, userID, user, ds.persist, "MarkEmailVerified"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToUserRecord(user), nil
+}
+
+// UpdatePassword replaces the hashed password and clears a login lockout,
+// since the lockout protected the old password.
+func (ds *userRepository) UpdatePassword(userID int, password string) (*record.UserRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	user, ok := ds.users[userID]
+
+	if !ok {
+		return nil, fmt.Errorf("user with id %d not found", userID)
+	}
+
+	user.Password = password
+	user.LoginFailedAttempts = 0
+	user.LoginLockedUntil = nil
+
+	if err := putItem(ds.users, userID, user, ds.persist, "UpdatePassword"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToUserRecord(user), nil
+}
+
+func (ds *userRepository) UpdateLoginAttempts(userID int, failedAttempts int, lockedUntil *time.Time) (*record.UserRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	user, ok := ds.users[userID]
+
+	if !ok {
+		return nil, fmt.Errorf("user with id %d not found", userID)
+	}
+
+	user.LoginFailedAttempts = failedAttempts
+	user.LoginLockedUntil = lockedUntil
+
+	if err := putItem(ds.users, userID, user, ds.persist, "UpdateLoginAttempts"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToUserRecord(user), nil
+}
+
+// UpdatePin replaces the hashed transaction PIN and clears any failed
+// attempts and lockout.
+func (ds *userRepository) UpdatePin(userID int, pin string) (*record.UserRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	user, ok := ds.users[userID]
+
+	if !ok {
+		return nil, fmt.Errorf("user with id %d not found", userID)
+	}
+
+	user.Pin = pin
+	user.PinFailedAttempts = 0
+	user.PinLockedUntil = nil
+
+	if err := putItem(ds.users, userID, user, ds.persist, "UpdatePin"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToUserRecord(user), nil
+}
+
+func (ds *userRepository) UpdatePinAttempts(userID int, failedAttempts int, lockedUntil *time.Time) (*record.UserRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	user, ok := ds.users[userID]
+
+	if !ok {
+		return nil, fmt.Errorf("user with id %d not found", userID)
+	}
+
+	user.PinFailedAttempts = failedAttempts
+	user.PinLockedUntil = lockedUntil
+
+	if err := putItem(ds.users, userID, user, ds.persist, "UpdatePinAttempts"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToUserRecord(user), nil
+}
+
+// UpdateTotp replaces the two-factor state of a user as a whole.
+func (ds *userRepository) UpdateTotp(request requests.UpdateUserTotpRequest) (*record.UserRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	user, ok := ds.users[request.UserID]
+
+	if !ok {
+		return nil, fmt.Errorf("user with id %d not found", request.UserID)
+	}
+
+	user.TotpSecret = request.Secret
+	user.TotpEnabled = request.Enabled
+	user.TotpLastStep = request.LastStep
+	user.TotpFailedAttempts = request.FailedAttempts
+	user.TotpLockedUntil = request.LockedUntil
+	user.RecoveryCodes = request.RecoveryCodes
+
+	if err := putItem(ds.users, request.UserID, user, ds.persist, "UpdateTotp"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToUserRecord(user), nil
+}
+
 func (ds *userRepository) Delete(userID int) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 
 	if _, ok := ds.users[userID]; ok {
-		delete(ds.users, userID)
-		return nil
+		return deleteItem(ds.users, userID, ds.persist, "Delete")
 	}
 
 	return nil
 }
+
+func (ds *userRepository) tableName() string {
+	return "users"
+}
+
+func (ds *userRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *userRepository) state() tableState[models.User] {
+	return tableState[models.User]{Items: ds.users, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *userRepository) persist(c change) error {
+	if ds.store == nil {
+		return nil
+	}
+
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *userRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *userRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.users, &ds.nextID)
+}
+
+func (ds *userRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.users, &ds.nextID, nextID)
+}
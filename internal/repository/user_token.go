@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUserTokenUsed is returned when a token is consumed a second time.
+	ErrUserTokenUsed = errors.New("token already used")
+	// ErrUserTokenExpired is returned when a token is consumed after it
+	// expired.
+	ErrUserTokenExpired = errors.New("token expired")
+)
+
+type userTokenRepository struct {
+	mu      sync.RWMutex
+	tokens  map[int]models.UserToken
+	nextID  int
+	store   storage
+	mapping recordmapper.UserTokenRecordMapping
+}
+
+func NewUserTokenRepository(mapping recordmapper.UserTokenRecordMapping) *userTokenRepository {
+	return &userTokenRepository{
+		tokens:  make(map[int]models.UserToken),
+		nextID:  1,
+		mapping: mapping,
+	}
+}
+
+func (ds *userTokenRepository) Create(request requests.CreateUserTokenRequest) (*record.UserTokenRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	token := models.UserToken{
+		TokenID:   ds.nextID,
+		UserID:    request.UserID,
+		Purpose:   request.Purpose,
+		ExpiresAt: request.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if err := putItem(ds.tokens, token.TokenID, token, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToUserTokenRecord(token), nil
+}
+
+// Consume marks the token used when it is for purpose, unused and not
+// expired. Checking and marking happen under one lock, so of two requests
+// with the same token only one succeeds.
+func (ds *userTokenRepository) Consume(tokenID int, purpose string) (*record.UserTokenRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	token, ok := ds.tokens[tokenID]
+	if !ok || token.Purpose != purpose {
+		return nil, fmt.Errorf("token with ID %d not found", tokenID)
+	}
+
+	if token.UsedAt != nil {
+		return nil, ErrUserTokenUsed
+	}
+
+	now := time.Now()
+
+	if !now.Before(token.ExpiresAt) {
+		return nil, ErrUserTokenExpired
+	}
+
+	token.UsedAt = &now
+
+	if err := putItem(ds.tokens, tokenID, token, ds.persist, "Consume"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToUserTokenRecord(token), nil
+}
+
+// ConsumeAll marks every unused token of the user for purpose used, so links
+// sent earlier stop working.
+func (ds *userTokenRepository) ConsumeAll(userID int, purpose string) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	now := time.Now()
+
+	for id, token := range ds.tokens {
+		if token.UserID != userID || token.Purpose != purpose || token.UsedAt != nil {
+			continue
+		}
+
+		token.UsedAt = &now
+
+		if err := putItem(ds.tokens, id, token, ds.persist, "ConsumeAll"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ds *userTokenRepository) tableName() string {
+	return "user_tokens"
+}
+
+func (ds *userTokenRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *userTokenRepository) state() tableState[models.UserToken] {
+	return tableState[models.UserToken]{Items: ds.tokens, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *userTokenRepository) persist(c change) error {
+	if ds.store == nil {
+		return nil
+	}
+
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *userTokenRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *userTokenRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.tokens, &ds.nextID)
+}
+
+func (ds *userTokenRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.tokens, &ds.nextID, nextID)
+}
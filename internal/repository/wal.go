@@ -0,0 +1,432 @@
+package repository
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"payment-mutex/pkg/atomicfile"
+	"sort"
+	"sync"
+)
+
+const walSnapshotFile = "snapshot.json"
+
+// walChange is one repository change as it is logged.
+type walChange struct {
+	Table  string                  `json:"table"`
+	Call   string                  `json:"call"`
+	Rows   map[int]json.RawMessage `json:"rows"`
+	NextID int                     `json:"next_id"`
+}
+
+// walRecord is one line of the write-ahead log. It holds either a single
+// change or, in Unit, every change of a unit of work, so a unit is logged and
+// replayed as a whole.
+type walRecord struct {
+	Seq uint64 `json:"seq"`
+	walChange
+	Unit []walChange `json:"unit,omitempty"`
+}
+
+// walSnapshot holds the state of every table as of record Seq.
+type walSnapshot struct {
+	Seq    uint64                     `json:"seq"`
+	Tables map[string]json.RawMessage `json:"tables"`
+}
+
+// writeAheadLog keeps the in-memory repositories durable. Every change, or
+// every unit of work, is appended to the current log segment as one record
+// and synced before the call returns.
+// A snapshot starts a new segment, writes every table and then drops the
+// older segments, so recovery loads the snapshot and replays what was logged
+// after it.
+type writeAheadLog struct {
+	mu     sync.Mutex
+	dir    string
+	tables []table
+	byName map[string]table
+
+	file    *os.File
+	offset  int64
+	seq     uint64
+	segment string
+
+	// snapshotMu keeps snapshots from overlapping.
+	snapshotMu sync.Mutex
+}
+
+func newWriteAheadLog(dir string, tables []table) (*writeAheadLog, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create storage path: %w", err)
+	}
+
+	w := &writeAheadLog{
+		dir:    dir,
+		tables: tables,
+		byName: make(map[string]table, len(tables)),
+	}
+
+	for _, t := range tables {
+		w.byName[t.tableName()] = t
+		t.useStorage(w)
+	}
+
+	return w, nil
+}
+
+func (w *writeAheadLog) write(c change, state any) error {
+	logged, err := encodeWALChange(c)
+	if err != nil {
+		return err
+	}
+
+	return w.append(walRecord{walChange: logged})
+}
+
+// writeGroup logs the changes of a unit of work as one record.
+func (w *writeAheadLog) writeGroup(writes []unitWrite) error {
+	rec := walRecord{Unit: make([]walChange, 0, len(writes))}
+
+	for _, write := range writes {
+		logged, err := encodeWALChange(write.change)
+		if err != nil {
+			return err
+		}
+
+		rec.Unit = append(rec.Unit, logged)
+	}
+
+	return w.append(rec)
+}
+
+func encodeWALChange(c change) (walChange, error) {
+	logged := walChange{
+		Table:  c.Table,
+		Call:   c.Call,
+		Rows:   make(map[int]json.RawMessage, len(c.Rows)),
+		NextID: c.NextID,
+	}
+
+	for id, row := range c.Rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return walChange{}, fmt.Errorf("failed to encode %s row %d: %w", c.Table, id, err)
+		}
+
+		logged.Rows[id] = data
+	}
+
+	return logged, nil
+}
+
+// append numbers rec, writes it to the current segment and syncs it.
+func (w *writeAheadLog) append(rec walRecord) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return errors.New("write-ahead log is not open, recover must run first")
+	}
+
+	rec.Seq = w.seq + 1
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode log record: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	if _, err := w.file.Write(line); err != nil {
+		w.discardTail()
+		return fmt.Errorf("failed to append to %s: %w", w.segment, err)
+	}
+
+	if err := w.file.Sync(); err != nil {
+		w.discardTail()
+		return fmt.Errorf("failed to sync %s: %w", w.segment, err)
+	}
+
+	w.seq = rec.Seq
+	w.offset += int64(len(line))
+
+	return nil
+}
+
+// discardTail cuts off whatever a failed append left behind, so the next
+// record does not land after a torn line. It must be called with w.mu held.
+func (w *writeAheadLog) discardTail() {
+	if err := w.file.Truncate(w.offset); err == nil {
+		w.file.Seek(w.offset, io.SeekStart)
+	}
+}
+
+// recover loads the latest snapshot, replays every record logged after it
+// and opens a new segment for writing. A torn record at the end of the last
+// segment, left by a crash in the middle of an append, is dropped. It must run
+// before the repositories are used; until then every write fails.
+func (w *writeAheadLog) recover() error {
+	w.snapshotMu.Lock()
+	defer w.snapshotMu.Unlock()
+
+	w.mu.Lock()
+	open := w.file != nil
+	w.mu.Unlock()
+
+	if open {
+		return errors.New("write-ahead log is already open")
+	}
+
+	snapshot, err := w.readSnapshot()
+	if err != nil {
+		return err
+	}
+
+	for name, data := range snapshot.Tables {
+		t, ok := w.byName[name]
+		if !ok {
+			return fmt.Errorf("snapshot has unknown table %q", name)
+		}
+
+		if err := t.restore(data); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", name, err)
+		}
+	}
+
+	segments, err := w.segments()
+	if err != nil {
+		return err
+	}
+
+	seq := snapshot.Seq
+
+	for i, segment := range segments {
+		last, err := w.replaySegment(segment, snapshot.Seq, i == len(segments)-1)
+		if err != nil {
+			return err
+		}
+
+		if last > seq {
+			seq = last
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seq = seq
+
+	return w.openSegment()
+}
+
+func (w *writeAheadLog) readSnapshot() (*walSnapshot, error) {
+	snapshot := &walSnapshot{}
+
+	data, err := os.ReadFile(filepath.Join(w.dir, walSnapshotFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return snapshot, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", walSnapshotFile, err)
+	}
+
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", walSnapshotFile, err)
+	}
+
+	return snapshot, nil
+}
+
+// replaySegment applies the records of one segment that come after the
+// snapshot and returns the highest sequence number it saw.
+func (w *writeAheadLog) replaySegment(path string, after uint64, last bool) (uint64, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	var seq uint64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return seq, nil
+		}
+
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		var rec walRecord
+		complete := err == nil
+
+		if !complete || json.Unmarshal(line, &rec) != nil {
+			if !last || complete {
+				return 0, fmt.Errorf("corrupt record in %s at offset %d", path, offset)
+			}
+
+			if err := file.Truncate(offset); err != nil {
+				return 0, fmt.Errorf("failed to drop torn record in %s: %w", path, err)
+			}
+
+			return seq, nil
+		}
+
+		offset += int64(len(line))
+
+		if rec.Seq > seq {
+			seq = rec.Seq
+		}
+
+		if rec.Seq <= after {
+			continue
+		}
+
+		changes := rec.Unit
+		if len(changes) == 0 {
+			changes = []walChange{rec.walChange}
+		}
+
+		for _, c := range changes {
+			t, ok := w.byName[c.Table]
+			if !ok {
+				return 0, fmt.Errorf("record %d in %s has unknown table %q", rec.Seq, path, c.Table)
+			}
+
+			if err := t.replay(c.Rows, c.NextID); err != nil {
+				return 0, fmt.Errorf("failed to replay record %d (%s.%s): %w", rec.Seq, c.Table, c.Call, err)
+			}
+		}
+	}
+}
+
+// segments lists the log segments oldest first.
+func (w *writeAheadLog) segments() ([]string, error) {
+	segments, err := filepath.Glob(filepath.Join(w.dir, "wal-*.log"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(segments)
+
+	return segments, nil
+}
+
+// openSegment starts a new segment for records after w.seq. It must be
+// called with w.mu held.
+func (w *writeAheadLog) openSegment() error {
+	path := filepath.Join(w.dir, fmt.Sprintf("wal-%020d.log", w.seq+1))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	if w.file != nil {
+		w.file.Close()
+	}
+
+	w.file = file
+	w.offset = info.Size()
+	w.segment = path
+
+	return nil
+}
+
+// snapshot writes every table to disk and drops the segments it covers.
+// Writers only wait for the segment switch, not for the tables to be
+// written. Records logged while the tables are read may show up in the
+// snapshot as well as in the new segment, which is fine because replaying a
+// record twice gives the same state.
+func (w *writeAheadLog) snapshot() error {
+	w.snapshotMu.Lock()
+	defer w.snapshotMu.Unlock()
+
+	w.mu.Lock()
+
+	if w.file == nil {
+		w.mu.Unlock()
+		return errors.New("write-ahead log is not open, recover must run first")
+	}
+
+	seq := w.seq
+
+	if err := w.openSegment(); err != nil {
+		w.mu.Unlock()
+		return err
+	}
+
+	active := w.segment
+
+	w.mu.Unlock()
+
+	snapshot := walSnapshot{
+		Seq:    seq,
+		Tables: make(map[string]json.RawMessage, len(w.tables)),
+	}
+
+	for _, t := range w.tables {
+		data, err := t.snapshot()
+		if err != nil {
+			return fmt.Errorf("failed to snapshot %s: %w", t.tableName(), err)
+		}
+
+		snapshot.Tables[t.tableName()] = data
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	if err := atomicfile.Write(filepath.Join(w.dir, walSnapshotFile), data); err != nil {
+		return err
+	}
+
+	segments, err := w.segments()
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if segment >= active {
+			break
+		}
+
+		if err := os.Remove(segment); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", segment, err)
+		}
+	}
+
+	return nil
+}
+
+func (w *writeAheadLog) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+
+	return err
+}
@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"testing"
+)
+
+// lastSegment returns the path and content of the newest log segment that
+// has records in it.
+func lastSegment(t *testing.T, dir string) (string, []byte) {
+	t.Helper()
+
+	segments, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	if err != nil {
+		t.Fatalf("list segments: %v", err)
+	}
+
+	for i := len(segments) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(segments[i])
+		if err != nil {
+			t.Fatalf("read %s: %v", segments[i], err)
+		}
+
+		if len(data) > 0 {
+			return segments[i], data
+		}
+	}
+
+	t.Fatal("no segment has records")
+	return "", nil
+}
+
+// lastLineAt returns the offset where the last record of data starts.
+func lastLineAt(data []byte) int {
+	return bytes.LastIndexByte(data[:len(data)-1], '\n') + 1
+}
+
+func TestWALRecover(t *testing.T) {
+	tests := []struct {
+		name string
+		// crash changes the log on disk the way a crash would have left it.
+		crash     func(t *testing.T, dir string)
+		wantErr   bool
+		wantA     int
+		wantEntry bool
+	}{
+		{
+			name:      "replays every record",
+			crash:     func(t *testing.T, dir string) {},
+			wantA:     150,
+			wantEntry: true,
+		},
+		{
+			name: "drops a torn last record",
+			crash: func(t *testing.T, dir string) {
+				path, data := lastSegment(t, dir)
+				at := lastLineAt(data)
+
+				if err := os.WriteFile(path, data[:at+(len(data)-at)/2], 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantA:     100,
+			wantEntry: true,
+		},
+		{
+			name: "drops a last record with no line end",
+			crash: func(t *testing.T, dir string) {
+				path, data := lastSegment(t, dir)
+
+				if err := os.WriteFile(path, data[:len(data)-1], 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantA:     100,
+			wantEntry: true,
+		},
+		{
+			name: "fails on a corrupt record before the last one",
+			crash: func(t *testing.T, dir string) {
+				path, data := lastSegment(t, dir)
+				corrupt := append([]byte("{not json}\n"), data[bytes.IndexByte(data, '\n')+1:]...)
+
+				if err := os.WriteFile(path, corrupt, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			r := newTestRepositories(t, StorageDriverWAL, dir)
+
+			u := r.Begin()
+			u.Journal.Create(testEntry("A", 100))
+			u.Saldo.UpdateBalances(balanceChange("A", 100))
+
+			if err := u.Commit(); err != nil {
+				t.Fatalf("commit: %v", err)
+			}
+
+			if _, err := r.Saldo.UpdateBalances(balanceChange("A", 50)); err != nil {
+				t.Fatalf("update balance: %v", err)
+			}
+
+			r.Close()
+			tt.crash(t, dir)
+
+			r, err := NewRepositorys(Deps{MapperRecord: *recordmapper.NewRecordMapper(), StorageDriver: StorageDriverWAL, StoragePath: dir})
+			if err != nil {
+				t.Fatalf("open repositories: %v", err)
+			}
+
+			t.Cleanup(func() { r.Close() })
+
+			err = r.Recover()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("recover: %v, want error = %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := balanceOf(t, r, "A"); got != tt.wantA {
+				t.Errorf("balance of A = %d, want %d", got, tt.wantA)
+			}
+
+			if _, err := r.Journal.Read(1); (err == nil) != tt.wantEntry {
+				t.Errorf("journal entry found = %v, want %v", err == nil, tt.wantEntry)
+			}
+
+			// Log yang sudah dipulihkan harus bisa ditulis dan dipulihkan lagi
+			if _, err := r.Saldo.UpdateBalances(balanceChange("B", 10)); err != nil {
+				t.Fatalf("update balance after recover: %v", err)
+			}
+
+			r.Close()
+
+			r = newTestRepositories(t, StorageDriverWAL, dir)
+			if a, b := balanceOf(t, r, "A"), balanceOf(t, r, "B"); a != tt.wantA || b != 10 {
+				t.Errorf("after a second recover A=%d B=%d, want A=%d B=10", a, b, tt.wantA)
+			}
+		})
+	}
+}
+
+func TestWALUnitIsReplayedWhole(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRepositories(t, StorageDriverWAL, dir)
+
+	u := r.Begin()
+	u.Journal.Create(testEntry("A", 100))
+	u.Saldo.UpdateBalances(balanceChange("A", 100))
+
+	if err := u.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	r.Close()
+
+	path, data := lastSegment(t, dir)
+	at := lastLineAt(data)
+
+	var rec walRecord
+	if err := json.Unmarshal(data[at:], &rec); err != nil {
+		t.Fatalf("decode last record: %v", err)
+	}
+
+	if len(rec.Unit) != 2 {
+		t.Fatalf("last record holds %d changes, want the 2 of the unit", len(rec.Unit))
+	}
+
+	// Unit yang terpotong di tengah tidak boleh diterapkan sebagian
+	if err := os.WriteFile(path, data[:at+(len(data)-at)/2], 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r = newTestRepositories(t, StorageDriverWAL, dir)
+
+	if got := balanceOf(t, r, "A"); got != 0 {
+		t.Errorf("balance of A = %d, want 0", got)
+	}
+
+	if _, err := r.Journal.Read(1); err == nil {
+		t.Error("journal entry of a torn unit was replayed")
+	}
+}
+
+func TestWALFailedUnitIsNotLogged(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRepositories(t, StorageDriverWAL, dir)
+	_, before := lastSegment(t, dir)
+
+	u := r.Begin()
+	u.Journal.Create(testEntry("A", 100))
+	u.Saldo.UpdateBalances(balanceChange("B", -500))
+
+	if err := u.Commit(); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("commit: %v, want %v", err, ErrInsufficientBalance)
+	}
+
+	if _, after := lastSegment(t, dir); !bytes.Equal(before, after) {
+		t.Errorf("failed unit added %d bytes to the log", len(after)-len(before))
+	}
+}
+
+func TestWALSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRepositories(t, StorageDriverWAL, dir)
+
+	if _, err := r.Saldo.UpdateBalances(balanceChange("A", 100)); err != nil {
+		t.Fatalf("update balance: %v", err)
+	}
+
+	if err := r.Snapshot(); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+
+	if _, err := r.Saldo.UpdateBalances(balanceChange("B", 20)); err != nil {
+		t.Fatalf("update balance: %v", err)
+	}
+
+	segments, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	if err != nil {
+		t.Fatalf("list segments: %v", err)
+	}
+
+	if len(segments) != 1 {
+		t.Errorf("%d segments after a snapshot, want 1", len(segments))
+	}
+
+	r.Close()
+	r = newTestRepositories(t, StorageDriverWAL, dir)
+
+	if a, b := balanceOf(t, r, "A"), balanceOf(t, r, "B"); a != 100 || b != 20 {
+		t.Errorf("after recover A=%d B=%d, want A=100 B=20", a, b)
+	}
+}
+
+func TestFileDriverFinishesInterruptedUnit(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRepositories(t, StorageDriverFile, dir)
+
+	names := []string{"saldos.json", "journal.json"}
+	before := make(map[string][]byte, len(names))
+
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("read %s: %v", name, err)
+		}
+
+		before[name] = data
+	}
+
+	u := r.Begin()
+	u.Journal.Create(testEntry("A", 100))
+	u.Saldo.UpdateBalances(balanceChange("A", 100))
+
+	if err := u.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, fileUnitFile)); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("intent file left after commit: %v", err)
+	}
+
+	// Susun ulang keadaan crash: intent sudah tertulis, tabel masih yang lama
+	intent := make(map[string]json.RawMessage, len(names))
+
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+
+		intent[name] = data
+
+		if before[name] == nil {
+			err = os.Remove(path)
+		} else {
+			err = os.WriteFile(path, before[name], 0o600)
+		}
+
+		if err != nil {
+			t.Fatalf("restore %s: %v", name, err)
+		}
+	}
+
+	data, err := json.Marshal(intent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, fileUnitFile), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r = newTestRepositories(t, StorageDriverFile, dir)
+
+	if got := balanceOf(t, r, "A"); got != 100 {
+		t.Errorf("balance of A = %d, want 100", got)
+	}
+
+	if _, err := r.Journal.Read(1); err != nil {
+		t.Errorf("journal entry of the unit: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, fileUnitFile)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("intent file left after recover: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sync"
+	"time"
+)
+
+type webhookDeliveryRepository struct {
+	mu         sync.RWMutex
+	deliveries map[int]models.WebhookDelivery
+	nextID     int
+	store      storage
+	mapping    recordmapper.WebhookRecordMapping
+}
+
+func NewWebhookDeliveryRepository(mapping recordmapper.WebhookRecordMapping) *webhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		deliveries: make(map[int]models.WebhookDelivery),
+		nextID:     1,
+		mapping:    mapping,
+	}
+}
+
+func (ds *webhookDeliveryRepository) Read(deliveryID int) (*record.WebhookDeliveryRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	delivery, ok := ds.deliveries[deliveryID]
+	if !ok {
+		return nil, fmt.Errorf("webhook delivery with ID %d not found", deliveryID)
+	}
+
+	return ds.mapping.ToWebhookDeliveryRecord(delivery), nil
+}
+
+// ReadByMerchantID returns the deliveries of the merchant newest first, only
+// those to endpointID when it is not zero.
+func (ds *webhookDeliveryRepository) ReadByMerchantID(merchantID int, endpointID int, page int, pageSize int) ([]*record.WebhookDeliveryRecord, int, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	filteredDeliveries := make([]models.WebhookDelivery, 0)
+
+	for id := ds.nextID - 1; id > 0; id-- {
+		delivery, ok := ds.deliveries[id]
+		if !ok || delivery.MerchantID != merchantID || (endpointID != 0 && delivery.EndpointID != endpointID) {
+			continue
+		}
+
+		filteredDeliveries = append(filteredDeliveries, delivery)
+	}
+
+	totalRecords := len(filteredDeliveries)
+
+	start := (page - 1) * pageSize
+	if start >= totalRecords {
+		return nil, totalRecords, nil
+	}
+
+	end := start + pageSize
+	if end > totalRecords {
+		end = totalRecords
+	}
+
+	return ds.mapping.ToWebhookDeliveriesRecord(filteredDeliveries[start:end]), totalRecords, nil
+}
+
+// ReadDue returns the pending deliveries whose next attempt is due at now.
+func (ds *webhookDeliveryRepository) ReadDue(now time.Time) ([]*record.WebhookDeliveryRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	due := make([]models.WebhookDelivery, 0)
+
+	for id := 1; id < ds.nextID; id++ {
+		delivery, ok := ds.deliveries[id]
+		if ok && delivery.Status == models.WebhookDeliveryPending && delivery.NextAttemptAt != nil && !now.Before(*delivery.NextAttemptAt) {
+			due = append(due, delivery)
+		}
+	}
+
+	return ds.mapping.ToWebhookDeliveriesRecord(due), nil
+}
+
+func (ds *webhookDeliveryRepository) Create(request requests.CreateWebhookDeliveryRequest) (*record.WebhookDeliveryRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	now := time.Now()
+
+	delivery := models.WebhookDelivery{
+		DeliveryID:    ds.nextID,
+		EndpointID:    request.EndpointID,
+		MerchantID:    request.MerchantID,
+		EventID:       request.EventID,
+		EventType:     request.EventType,
+		Payload:       request.Payload,
+		Status:        models.WebhookDeliveryPending,
+		Attempts:      []models.WebhookAttempt{},
+		NextAttemptAt: &now,
+		RedeliveryOf:  request.RedeliveryOf,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := putItem(ds.deliveries, delivery.DeliveryID, delivery, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToWebhookDeliveryRecord(delivery), nil
+}
+
+func (ds *webhookDeliveryRepository) RecordAttempt(request requests.RecordWebhookAttemptRequest) (*record.WebhookDeliveryRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	delivery, ok := ds.deliveries[request.DeliveryID]
+	if !ok {
+		return nil, fmt.Errorf("webhook delivery with ID %d not found", request.DeliveryID)
+	}
+
+	attempts := make([]models.WebhookAttempt, 0, len(delivery.Attempts)+1)
+	attempts = append(attempts, delivery.Attempts...)
+	attempts = append(attempts, models.WebhookAttempt{
+		AttemptedAt: request.AttemptedAt,
+		StatusCode:  request.StatusCode,
+		Error:       request.Error,
+		DurationMs:  request.Duration.Milliseconds(),
+	})
+
+	delivery.Attempts = attempts
+	delivery.Status = request.Status
+	delivery.NextAttemptAt = request.NextAttemptAt
+	delivery.UpdatedAt = time.Now()
+
+	if err := putItem(ds.deliveries, delivery.DeliveryID, delivery, ds.persist, "RecordAttempt"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToWebhookDeliveryRecord(delivery), nil
+}
+
+func (ds *webhookDeliveryRepository) tableName() string {
+	return "webhook_deliveries"
+}
+
+func (ds *webhookDeliveryRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *webhookDeliveryRepository) state() tableState[models.WebhookDelivery] {
+	return tableState[models.WebhookDelivery]{Items: ds.deliveries, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *webhookDeliveryRepository) persist(c change) error {
+	if ds.store == nil {
+		return nil
+	}
+
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *webhookDeliveryRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *webhookDeliveryRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.deliveries, &ds.nextID)
+}
+
+func (ds *webhookDeliveryRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.deliveries, &ds.nextID, nextID)
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+type webhookEndpointRepository struct {
+	mu        sync.RWMutex
+	endpoints map[int]models.WebhookEndpoint
+	nextID    int
+	store     storage
+	mapping   recordmapper.WebhookRecordMapping
+}
+
+func NewWebhookEndpointRepository(mapping recordmapper.WebhookRecordMapping) *webhookEndpointRepository {
+	return &webhookEndpointRepository{
+		endpoints: make(map[int]models.WebhookEndpoint),
+		nextID:    1,
+		mapping:   mapping,
+	}
+}
+
+func (ds *webhookEndpointRepository) Read(endpointID int) (*record.WebhookEndpointRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	endpoint, ok := ds.endpoints[endpointID]
+	if !ok {
+		return nil, fmt.Errorf("webhook endpoint with ID %d not found", endpointID)
+	}
+
+	return ds.mapping.ToWebhookEndpointRecord(endpoint), nil
+}
+
+func (ds *webhookEndpointRepository) ReadByMerchantID(merchantID int) ([]*record.WebhookEndpointRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	endpoints := make([]models.WebhookEndpoint, 0)
+
+	for id := 1; id < ds.nextID; id++ {
+		if endpoint, ok := ds.endpoints[id]; ok && endpoint.MerchantID == merchantID {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	return ds.mapping.ToWebhookEndpointsRecord(endpoints), nil
+}
+
+// ReadSubscribed returns the active endpoints of the merchant that receive
+// eventType.
+func (ds *webhookEndpointRepository) ReadSubscribed(merchantID int, eventType string) ([]*record.WebhookEndpointRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	endpoints := make([]models.WebhookEndpoint, 0)
+
+	for id := 1; id < ds.nextID; id++ {
+		endpoint, ok := ds.endpoints[id]
+		if ok && endpoint.MerchantID == merchantID && endpoint.Active && slices.Contains(endpoint.Events, eventType) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	return ds.mapping.ToWebhookEndpointsRecord(endpoints), nil
+}
+
+func (ds *webhookEndpointRepository) Create(request requests.CreateWebhookEndpointRequest) (*record.WebhookEndpointRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	now := time.Now()
+
+	endpoint := models.WebhookEndpoint{
+		EndpointID: ds.nextID,
+		MerchantID: request.MerchantID,
+		URL:        request.URL,
+		Events:     request.Events,
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := putItem(ds.endpoints, endpoint.EndpointID, endpoint, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToWebhookEndpointRecord(endpoint), nil
+}
+
+func (ds *webhookEndpointRepository) Update(request requests.UpdateWebhookEndpointRequest) (*record.WebhookEndpointRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	endpoint, ok := ds.endpoints[request.EndpointID]
+	if !ok {
+		return nil, fmt.Errorf("webhook endpoint with ID %d not found", request.EndpointID)
+	}
+
+	endpoint.URL = request.URL
+	endpoint.Events = request.Events
+	endpoint.Active = request.Active
+	endpoint.UpdatedAt = time.Now()
+
+	if err := putItem(ds.endpoints, endpoint.EndpointID, endpoint, ds.persist, "Update"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToWebhookEndpointRecord(endpoint), nil
+}
+
+func (ds *webhookEndpointRepository) Delete(endpointID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if _, ok := ds.endpoints[endpointID]; ok {
+		return deleteItem(ds.endpoints, endpointID, ds.persist, "Delete")
+	}
+
+	return fmt.Errorf("webhook endpoint with ID %d not found", endpointID)
+}
+
+func (ds *webhookEndpointRepository) tableName() string {
+	return "webhook_endpoints"
+}
+
+func (ds *webhookEndpointRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *webhookEndpointRepository) state() tableState[models.WebhookEndpoint] {
+	return tableState[models.WebhookEndpoint]{Items: ds.endpoints, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage. It must be called with ds.mu held.
+func (ds *webhookEndpointRepository) persist(c change) error {
+	if ds.store == nil {
+		return nil
+	}
+
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	return ds.store.write(c, ds.state())
+}
+
+func (ds *webhookEndpointRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *webhookEndpointRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.endpoints, &ds.nextID)
+}
+
+func (ds *webhookEndpointRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.endpoints, &ds.nextID, nextID)
+}
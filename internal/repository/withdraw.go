@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"fmt"
 	"payment-mutex/internal/domain/record"
 	"payment-mutex/internal/domain/requests"
@@ -8,12 +9,15 @@ import (
 	"payment-mutex/internal/models"
 	"strings"
 	"sync"
+	"time"
 )
 
 type withdrawRepository struct {
 	mu       sync.RWMutex
 	withdraw map[int]models.Withdraw
 	nextID   int
+	store    storage
+	undo     *undoLog
 	mapping  recordmapper.WithdrawRecordMapping
 }
 
@@ -25,13 +29,17 @@ func NewWithdrawRepository(mapping recordmapper.WithdrawRecordMapping) *withdraw
 	}
 }
 
-func (ds *withdrawRepository) ReadAll(page int, pageSize int, search string) ([]*record.WithdrawRecord, int, error) {
+func (ds *withdrawRepository) ReadAll(page int, pageSize int, search string, status string) ([]*record.WithdrawRecord, int, error) {
 	ds.mu.RLock()
 	defer ds.mu.RUnlock()
 
 	filteredWithdraws := make([]models.Withdraw, 0)
 
 	for _, withdraw := range ds.withdraw {
+		if status != "" && withdraw.Status != status {
+			continue
+		}
+
 		if search == "" ||
 			strings.Contains(strings.ToLower(withdraw.CardNumber), strings.ToLower(search)) {
 			filteredWithdraws = append(filteredWithdraws, withdraw)
@@ -91,10 +99,16 @@ func (ds *withdrawRepository) Create(request requests.CreateWithdrawRequest) (*r
 		CardNumber:     request.CardNumber,
 		WithdrawAmount: request.WithdrawAmount,
 		WithdrawTime:   request.WithdrawTime,
+		Status:         models.StatusPending,
+		StatusHistory: []models.StatusChange{
+			{Status: models.StatusPending, ChangedAt: time.Now()},
+		},
 	}
 
 	withdraw.WithdrawID = ds.nextID
-	ds.withdraw[withdraw.WithdrawID] = withdraw
+	if err := putItem(ds.withdraw, withdraw.WithdrawID, withdraw, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
 
 	ds.nextID++
 
@@ -105,6 +119,10 @@ func (ds *withdrawRepository) Update(request requests.UpdateWithdrawRequest) (*r
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 
+	return ds.update(request)
+}
+
+func (ds *withdrawRepository) update(request requests.UpdateWithdrawRequest) (*record.WithdrawRecord, error) {
 	withdraw, exists := ds.withdraw[request.WithdrawID]
 
 	if !exists {
@@ -115,7 +133,9 @@ func (ds *withdrawRepository) Update(request requests.UpdateWithdrawRequest) (*r
 	withdraw.WithdrawAmount = request.WithdrawAmount
 	withdraw.WithdrawTime = request.WithdrawTime
 
-	ds.withdraw[request.WithdrawID] = withdraw
+	if err := putItem(ds.withdraw, request.WithdrawID, withdraw, ds.persist, "Update"); err != nil {
+		return nil, err
+	}
 
 	return ds.mapping.ToWithdrawRecord(withdraw), nil
 }
@@ -125,9 +145,125 @@ func (ds *withdrawRepository) Delete(withdrawID int) error {
 	defer ds.mu.Unlock()
 
 	if _, ok := ds.withdraw[withdrawID]; ok {
-		delete(ds.withdraw, withdrawID)
-		return nil
+		return deleteItem(ds.withdraw, withdrawID, ds.persist, "Delete")
 	}
 
 	return fmt.Errorf("withdraw with ID %d not found", withdrawID)
 }
+
+func (ds *withdrawRepository) UpdateStatus(withdrawID int, status string) (*record.WithdrawRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.updateStatus(withdrawID, status)
+}
+
+func (ds *withdrawRepository) updateStatusFrom(withdrawID int, from string, status string) (*record.WithdrawRecord, error) {
+	withdraw, ok := ds.withdraw[withdrawID]
+	if !ok {
+		return nil, fmt.Errorf("withdraw with ID %d not found", withdrawID)
+	}
+
+	if withdraw.Status != from {
+		return nil, ErrStatusChanged
+	}
+
+	return ds.updateStatus(withdrawID, status)
+}
+
+func (ds *withdrawRepository) updateStatus(withdrawID int, status string) (*record.WithdrawRecord, error) {
+	withdraw, ok := ds.withdraw[withdrawID]
+	if !ok {
+		return nil, fmt.Errorf("withdraw with ID %d not found", withdrawID)
+	}
+
+	withdraw.Status = status
+	withdraw.StatusHistory = append(withdraw.StatusHistory, models.StatusChange{
+		Status:    status,
+		ChangedAt: time.Now(),
+	})
+
+	if err := putItem(ds.withdraw, withdrawID, withdraw, ds.persist, "UpdateStatus"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToWithdrawRecord(withdraw), nil
+}
+
+func (ds *withdrawRepository) tableName() string {
+	return "withdraws"
+}
+
+func (ds *withdrawRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *withdrawRepository) currentStorage() storage {
+	return ds.store
+}
+
+func (ds *withdrawRepository) state() tableState[models.Withdraw] {
+	return tableState[models.Withdraw]{Items: ds.withdraw, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage and, inside a unit of work, to its
+// undo log. It must be called with ds.mu held.
+func (ds *withdrawRepository) persist(c change) error {
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	if ds.store != nil {
+		if err := ds.store.write(c, ds.state()); err != nil {
+			return err
+		}
+	}
+
+	ds.undo.record(ds, c.Before)
+
+	return nil
+}
+
+// revert puts rows back the way a unit of work found them. It must be called
+// with ds.mu held.
+func (ds *withdrawRepository) revert(before map[int]any) error {
+	revertTable(ds.withdraw, before)
+
+	if ds.store == nil {
+		return nil
+	}
+
+	return ds.store.write(change{Table: ds.tableName(), Call: "Rollback", Rows: before, NextID: ds.nextID}, ds.state())
+}
+
+func (ds *withdrawRepository) lock() {
+	ds.mu.Lock()
+}
+
+func (ds *withdrawRepository) unlock() {
+	ds.mu.Unlock()
+}
+
+func (ds *withdrawRepository) useUndoLog(u *undoLog) {
+	ds.undo = u
+}
+
+func (ds *withdrawRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *withdrawRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.withdraw, &ds.nextID)
+}
+
+func (ds *withdrawRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.withdraw, &ds.nextID, nextID)
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/models"
+	"sync"
+	"time"
+)
+
+type withdrawHoldRepository struct {
+	mu      sync.RWMutex
+	holds   map[int]models.WithdrawHold
+	nextID  int
+	store   storage
+	undo    *undoLog
+	mapping recordmapper.WithdrawHoldRecordMapping
+}
+
+func NewWithdrawHoldRepository(mapping recordmapper.WithdrawHoldRecordMapping) *withdrawHoldRepository {
+	return &withdrawHoldRepository{
+		holds:   make(map[int]models.WithdrawHold),
+		nextID:  1,
+		mapping: mapping,
+	}
+}
+
+func (ds *withdrawHoldRepository) Read(holdID int) (*record.WithdrawHoldRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	hold, ok := ds.holds[holdID]
+	if !ok {
+		return nil, fmt.Errorf("withdraw hold with ID %d not found", holdID)
+	}
+
+	return ds.mapping.ToWithdrawHoldRecord(hold), nil
+}
+
+func (ds *withdrawHoldRepository) ReadExpired(now time.Time) ([]*record.WithdrawHoldRecord, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	expired := make([]models.WithdrawHold, 0)
+
+	for _, hold := range ds.holds {
+		if hold.Status == models.WithdrawHoldHeld && !now.Before(hold.ExpiresAt) {
+			expired = append(expired, hold)
+		}
+	}
+
+	return ds.mapping.ToWithdrawHoldsRecord(expired), nil
+}
+
+func (ds *withdrawHoldRepository) Create(request requests.CreateWithdrawHoldRequest) (*record.WithdrawHoldRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	now := time.Now()
+
+	hold := models.WithdrawHold{
+		HoldID:     ds.nextID,
+		CardNumber: request.CardNumber,
+		Amount:     request.Amount,
+		Status:     models.WithdrawHoldHeld,
+		ExpiresAt:  request.ExpiresAt,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := putItem(ds.holds, hold.HoldID, hold, ds.persist, "Create"); err != nil {
+		return nil, err
+	}
+
+	ds.nextID++
+
+	return ds.mapping.ToWithdrawHoldRecord(hold), nil
+}
+
+func (ds *withdrawHoldRepository) UpdateStatus(request requests.UpdateWithdrawHoldStatus) (*record.WithdrawHoldRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.updateStatus(request)
+}
+
+func (ds *withdrawHoldRepository) updateStatus(request requests.UpdateWithdrawHoldStatus) (*record.WithdrawHoldRecord, error) {
+	hold, ok := ds.holds[request.HoldID]
+	if !ok {
+		return nil, fmt.Errorf("withdraw hold with ID %d not found", request.HoldID)
+	}
+
+	hold.Status = request.Status
+	hold.CapturedAmount = request.CapturedAmount
+	hold.WithdrawID = request.WithdrawID
+	hold.UpdatedAt = time.Now()
+
+	if err := putItem(ds.holds, request.HoldID, hold, ds.persist, "UpdateStatus"); err != nil {
+		return nil, err
+	}
+
+	return ds.mapping.ToWithdrawHoldRecord(hold), nil
+}
+
+func (ds *withdrawHoldRepository) Delete(holdID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if _, ok := ds.holds[holdID]; ok {
+		return deleteItem(ds.holds, holdID, ds.persist, "Delete")
+	}
+
+	return fmt.Errorf("withdraw hold with ID %d not found", holdID)
+}
+
+func (ds *withdrawHoldRepository) tableName() string {
+	return "withdraw_holds"
+}
+
+func (ds *withdrawHoldRepository) useStorage(s storage) {
+	ds.store = s
+}
+
+func (ds *withdrawHoldRepository) currentStorage() storage {
+	return ds.store
+}
+
+func (ds *withdrawHoldRepository) state() tableState[models.WithdrawHold] {
+	return tableState[models.WithdrawHold]{Items: ds.holds, NextID: ds.nextID}
+}
+
+// persist hands a change to the storage and, inside a unit of work, to its
+// undo log. It must be called with ds.mu held.
+func (ds *withdrawHoldRepository) persist(c change) error {
+	c.Table = ds.tableName()
+	c.NextID = ds.nextID
+
+	if ds.store != nil {
+		if err := ds.store.write(c, ds.state()); err != nil {
+			return err
+		}
+	}
+
+	ds.undo.record(ds, c.Before)
+
+	return nil
+}
+
+// revert puts rows back the way a unit of work found them. It must be called
+// with ds.mu held.
+func (ds *withdrawHoldRepository) revert(before map[int]any) error {
+	revertTable(ds.holds, before)
+
+	if ds.store == nil {
+		return nil
+	}
+
+	return ds.store.write(change{Table: ds.tableName(), Call: "Rollback", Rows: before, NextID: ds.nextID}, ds.state())
+}
+
+func (ds *withdrawHoldRepository) lock() {
+	ds.mu.Lock()
+}
+
+func (ds *withdrawHoldRepository) unlock() {
+	ds.mu.Unlock()
+}
+
+func (ds *withdrawHoldRepository) useUndoLog(u *undoLog) {
+	ds.undo = u
+}
+
+func (ds *withdrawHoldRepository) snapshot() ([]byte, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return json.Marshal(ds.state())
+}
+
+func (ds *withdrawHoldRepository) restore(data []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return restoreTable(data, &ds.holds, &ds.nextID)
+}
+
+func (ds *withdrawHoldRepository) replay(rows map[int]json.RawMessage, nextID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return replayTable(rows, ds.holds, &ds.nextID, nextID)
+}
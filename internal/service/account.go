@@ -0,0 +1,272 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/models"
+	"payment-mutex/internal/repository"
+	"payment-mutex/pkg/auth"
+	"payment-mutex/pkg/hash"
+	"payment-mutex/pkg/logger"
+	"payment-mutex/pkg/notifier"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AccountPolicy configures the links sent for email verification and
+// password reset.
+type AccountPolicy struct {
+	// BaseURL is where this API is reached from a mail client.
+	BaseURL         string
+	VerificationTTL time.Duration
+	ResetTTL        time.Duration
+}
+
+type accountService struct {
+	hash                hash.Hashing
+	userRepository      repository.UserRepository
+	userTokenRepository repository.UserTokenRepository
+	sessionRepository   repository.SessionRepository
+	token               auth.TokenManager
+	notifier            notifier.Notifier
+	logger              logger.Logger
+	policy              AccountPolicy
+}
+
+func NewAccountService(
+	hash hash.Hashing,
+	userRepository repository.UserRepository,
+	userTokenRepository repository.UserTokenRepository,
+	sessionRepository repository.SessionRepository,
+	token auth.TokenManager,
+	notifier notifier.Notifier,
+	logger logger.Logger,
+	policy AccountPolicy,
+) *accountService {
+	return &accountService{
+		hash:                hash,
+		userRepository:      userRepository,
+		userTokenRepository: userTokenRepository,
+		sessionRepository:   sessionRepository,
+		token:               token,
+		notifier:            notifier,
+		logger:              logger,
+		policy:              policy,
+	}
+}
+
+// VerifyEmail activates the account the verification link was sent for.
+func (s *accountService) VerifyEmail(request requests.VerifyEmailRequest) (*response.ApiResponse[string], *response.ErrorResponse) {
+	token, errRes := s.consumeLink(request.Token, models.UserTokenEmailVerification, auth.TokenTypeEmailVerification)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	if _, err := s.userRepository.MarkEmailVerified(token.UserID); err != nil {
+		s.logger.Error("failed to verify email", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to verify email",
+		}
+	}
+
+	return &response.ApiResponse[string]{
+		Status:  "success",
+		Message: "Email verified successfully",
+		Data:    "Your account is active, you can login now",
+	}, nil
+}
+
+// ResendVerification sends a new verification link to an account that is
+// still pending. Links sent before stop working. The answer is the same for
+// every email so it does not tell which ones are registered.
+func (s *accountService) ResendVerification(request requests.ResendVerificationRequest) (*response.ApiResponse[string], *response.ErrorResponse) {
+	user, err := s.userRepository.ReadByEmail(request.Email)
+	if err == nil && user.Status == models.UserStatusPending {
+		if err := s.userTokenRepository.ConsumeAll(user.UserID, models.UserTokenEmailVerification); err != nil {
+			s.logger.Error("failed to invalidate verification links", zap.Error(err))
+		}
+
+		if err := s.SendVerification(user); err != nil {
+			s.logger.Error("failed to send verification email", zap.Error(err))
+		}
+	}
+
+	return &response.ApiResponse[string]{
+		Status:  "success",
+		Message: "Verification email sent",
+		Data:    "If the account is waiting for verification, a new link has been sent to " + request.Email,
+	}, nil
+}
+
+// ForgotPassword sends a password reset link. Like ResendVerification it
+// answers the same whether the email is registered or not.
+func (s *accountService) ForgotPassword(request requests.ForgotPasswordRequest) (*response.ApiResponse[string], *response.ErrorResponse) {
+	user, err := s.userRepository.ReadByEmail(request.Email)
+	if err != nil {
+		s.logger.SecurityEvent("password_reset_unknown_email", zap.String("email", request.Email))
+	} else {
+		if err := s.sendPasswordReset(user); err != nil {
+			s.logger.Error("failed to send password reset email", zap.Error(err))
+		} else {
+			s.logger.SecurityEvent("password_reset_requested", zap.Int("user_id", user.UserID))
+		}
+	}
+
+	return &response.ApiResponse[string]{
+		Status:  "success",
+		Message: "Password reset email sent",
+		Data:    "If an account exists for " + request.Email + ", a password reset link has been sent",
+	}, nil
+}
+
+// ResetPassword sets a new password with the token of a reset link. Every
+// other reset link of the user stops working and every session is revoked.
+func (s *accountService) ResetPassword(request requests.ResetPasswordRequest) (*response.ApiResponse[string], *response.ErrorResponse) {
+	token, errRes := s.consumeLink(request.Token, models.UserTokenPasswordReset, auth.TokenTypePasswordReset)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	hashed, err := s.hash.HashPassword(request.Password)
+	if err != nil {
+		s.logger.Error("failed to hash password", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to reset password",
+		}
+	}
+
+	user, err := s.userRepository.UpdatePassword(token.UserID, hashed)
+	if err != nil {
+		s.logger.Error("failed to reset password", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to reset password",
+		}
+	}
+
+	if err := s.userTokenRepository.ConsumeAll(user.UserID, models.UserTokenPasswordReset); err != nil {
+		s.logger.Error("failed to invalidate password reset links", zap.Error(err))
+	}
+
+	// Link reset dikirim ke email, jadi sekaligus membuktikan email-nya
+	if user.Status == models.UserStatusPending {
+		if _, err := s.userRepository.MarkEmailVerified(user.UserID); err != nil {
+			s.logger.Error("failed to verify email", zap.Error(err))
+		}
+	}
+
+	revoked, err := s.sessionRepository.RevokeAll(user.UserID, models.SessionRevokedPasswordReset)
+	if err != nil {
+		s.logger.Error("failed to revoke sessions", zap.Error(err))
+	}
+
+	s.logger.SecurityEvent("password_reset", zap.Int("user_id", user.UserID), zap.Int("revoked_sessions", revoked))
+
+	return &response.ApiResponse[string]{
+		Status:  "success",
+		Message: "Password reset successfully",
+		Data:    "Your password has been changed, please login again",
+	}, nil
+}
+
+// SendVerification mails a new verification link to user.
+func (s *accountService) SendVerification(user *record.UserRecord) error {
+	token, err := s.newLink(user, models.UserTokenEmailVerification, auth.TokenTypeEmailVerification, s.policy.VerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.policy.BaseURL + "/auth/verify-email?token=" + url.QueryEscape(token)
+
+	return s.notifier.Send(notifier.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below within %s to activate your account:\n\n%s\n",
+			user.FirstName, readableDuration(s.policy.VerificationTTL), link),
+	})
+}
+
+func (s *accountService) sendPasswordReset(user *record.UserRecord) error {
+	token, err := s.newLink(user, models.UserTokenPasswordReset, auth.TokenTypePasswordReset, s.policy.ResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.notifier.Send(notifier.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. To choose a new password, send the token below to %s/auth/reset-password within %s:\n\n%s\n\nIf this was not you, ignore this email.\n",
+			user.FirstName, s.policy.BaseURL, readableDuration(s.policy.ResetTTL), token),
+	})
+}
+
+// newLink stores a single use token and signs a link token naming it.
+func (s *accountService) newLink(user *record.UserRecord, purpose string, tokenType string, ttl time.Duration) (string, error) {
+	stored, err := s.userTokenRepository.Create(requests.CreateUserTokenRequest{
+		UserID:    user.UserID,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return s.token.NewLinkToken(user.UserID, tokenType, stored.TokenID, ttl)
+}
+
+// consumeLink checks the signature of a link token and uses up the token it
+// names.
+func (s *accountService) consumeLink(linkToken string, purpose string, tokenType string) (*record.UserTokenRecord, *response.ErrorResponse) {
+	invalid := &response.ErrorResponse{
+		Status:  "error",
+		Message: "Invalid or expired token",
+	}
+
+	claims, err := s.token.ValidateLinkToken(linkToken, tokenType)
+	if err != nil {
+		s.logger.Error("invalid link token", zap.String("purpose", purpose), zap.Error(err))
+		return nil, invalid
+	}
+
+	tokenID, err := strconv.Atoi(claims.ID)
+	if err != nil {
+		return nil, invalid
+	}
+
+	token, err := s.userTokenRepository.Consume(tokenID, purpose)
+	if errors.Is(err, repository.ErrUserTokenUsed) {
+		s.logger.SecurityEvent("link_token_reused", zap.String("purpose", purpose), zap.Int("token_id", tokenID))
+		return nil, invalid
+	}
+
+	if err != nil || strconv.Itoa(token.UserID) != claims.Subject {
+		s.logger.Error("failed to consume link token", zap.String("purpose", purpose), zap.Error(err))
+		return nil, invalid
+	}
+
+	return token, nil
+}
+
+// readableDuration writes d the way an email would, like "24 hours".
+func readableDuration(d time.Duration) string {
+	count, unit := int(math.Ceil(d.Minutes())), "minute"
+
+	if d >= time.Hour && d%time.Hour == 0 {
+		count, unit = int(d.Hours()), "hour"
+	}
+
+	if count == 1 {
+		return "1 " + unit
+	}
+
+	return fmt.Sprintf("%d %ss", count, unit)
+}
@@ -1,35 +1,55 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"payment-mutex/internal/domain/record"
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
 	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/models"
 	"payment-mutex/internal/repository"
 	"payment-mutex/pkg/auth"
 	"payment-mutex/pkg/hash"
 	"payment-mutex/pkg/logger"
+	"strconv"
+	"sync"
 
 	"go.uber.org/zap"
 )
 
 type authService struct {
-	hash       hash.Hashing
-	repository repository.UserRepository
-	token      auth.TokenManager
-	logger     logger.Logger
-	mapper     responseMapper.UserResponseMapper
+	hash              hash.Hashing
+	repository        repository.UserRepository
+	sessionRepository repository.SessionRepository
+	guard             *loginGuard
+	account           *accountService
+	token             auth.TokenManager
+	logger            logger.Logger
+	mapper            responseMapper.UserResponseMapper
+	totpIssuer        string
+
+	// mu menjaga agar hitungan kode 2FA salah dan kode pemulihan yang
+	// terpakai tidak saling menimpa
+	mu sync.Mutex
 }
 
-func NewAuthService(hash hash.Hashing, repository repository.UserRepository, token auth.TokenManager, logger logger.Logger, mapper responseMapper.UserResponseMapper) *authService {
+func NewAuthService(hash hash.Hashing, repository repository.UserRepository, sessionRepository repository.SessionRepository, guard *loginGuard, account *accountService, token auth.TokenManager, logger logger.Logger, mapper responseMapper.UserResponseMapper, totpIssuer string) *authService {
 	return &authService{
-		hash:       hash,
-		repository: repository,
-		token:      token,
-		logger:     logger,
-		mapper:     mapper,
+		hash:              hash,
+		repository:        repository,
+		sessionRepository: sessionRepository,
+		guard:             guard,
+		account:           account,
+		token:             token,
+		logger:            logger,
+		mapper:            mapper,
+		totpIssuer:        totpIssuer,
 	}
 }
 
+// RegisterUser creates a pending account and mails it a verification link.
+// The account can log in once the link was opened.
 func (s *authService) RegisterUser(request *requests.RegisterRequest) (*response.ApiResponse[response.UserResponse], *response.ErrorResponse) {
 	hashing, err := s.hash.HashPassword(request.Password)
 
@@ -44,10 +64,9 @@ func (s *authService) RegisterUser(request *requests.RegisterRequest) (*response
 		Email:           request.Email,
 		Password:        hashing,
 		ConfirmPassword: request.ConfirmPassword,
+		Status:          models.UserStatusPending,
 	})
 
-	res.Password = nil
-
 	if err != nil {
 		s.logger.Error("Error creating user: ", zap.Error(err))
 		return nil, &response.ErrorResponse{
@@ -55,48 +74,262 @@ func (s *authService) RegisterUser(request *requests.RegisterRequest) (*response
 			Message: "Error creating user",
 		}
 	}
+
+	res.Password = nil
+
+	// Gagal kirim tidak membatalkan pendaftaran, link bisa diminta ulang
+	if err := s.account.SendVerification(res); err != nil {
+		s.logger.Error("failed to send verification email", zap.Error(err))
+	}
+
 	so := s.mapper.ToUserResponse(*res)
 
 	return &response.ApiResponse[response.UserResponse]{
 		Status:  "success",
-		Message: "register success",
+		Message: "register success, check your email to verify the account",
 		Data:    *so,
 	}, nil
 }
 
-func (s *authService) Login(request *requests.AuthRequest) (*response.ApiResponse[string], error) {
+// Login checks the password. Accounts with two-factor authentication get a
+// challenge token instead of tokens, to be exchanged at VerifyLogin together
+// with a code. Failed attempts are slowed down per email and per clientIP,
+// and lock the account after too many in a row.
+func (s *authService) Login(request *requests.AuthRequest, clientIP string) (*response.ApiResponse[*response.LoginResponse], *response.ErrorResponse) {
+	// Email yang tidak terdaftar tetap dihitung agar tidak bisa ditebak bebas
 	res, err := s.repository.ReadByEmail(request.Email)
 	if err != nil {
-		s.logger.Error("failed login: ", zap.Error(err))
-		return nil, err
+		res = nil
+	}
+
+	if errRes := s.guard.check(request.Email, clientIP, res); errRes != nil {
+		return nil, errRes
+	}
+
+	if res == nil || res.Password == nil || s.hash.ComparePassword(*res.Password, request.Password) != nil {
+		s.guard.fail(request.Email, clientIP, res)
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid email or password",
+		}
+	}
+
+	s.guard.succeed(request.Email, res)
+
+	if res.Status == models.UserStatusPending {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Email address is not verified, check your inbox for the verification link",
+		}
+	}
+
+	if res.TotpEnabled {
+		challenge, err := s.token.NewChallengeToken(res.UserID)
+		if err != nil {
+			s.logger.Error("failed create challenge token: ", zap.Error(err))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Error login user",
+			}
+		}
+
+		return &response.ApiResponse[*response.LoginResponse]{
+			Status:  "success",
+			Message: "two-factor code required",
+			Data: &response.LoginResponse{
+				MfaRequired:    true,
+				ChallengeToken: challenge,
+			},
+		}, nil
+	}
+
+	tokens, err := s.startSession(res)
+
+	if err != nil {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Error login user",
+		}
 	}
 
-	err = s.hash.ComparePassword(*res.Password, request.Password)
+	return &response.ApiResponse[*response.LoginResponse]{
+		Status:  "success",
+		Message: "login success",
+		Data:    &response.LoginResponse{TokenResponse: tokens},
+	}, nil
+
+}
 
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+// The old refresh token stops working. Presenting it again is treated as a
+// sign that it was stolen and revokes the whole session, so whoever holds the
+// newer token is signed out as well.
+func (s *authService) Refresh(request requests.RefreshTokenRequest) (*response.ApiResponse[*response.TokenResponse], *response.ErrorResponse) {
+	claims, err := s.token.ValidateRefreshToken(request.RefreshToken)
 	if err != nil {
-		s.logger.Error("Error comparing password: ", zap.Error(err))
+		s.logger.Error("invalid refresh token", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid refresh token",
+		}
+	}
+
+	session, err := s.sessionRepository.Rotate(claims.SessionID, claims.Generation)
+	if errors.Is(err, repository.ErrRefreshTokenReused) {
+		s.logger.SecurityEvent("refresh_token_reused", zap.Int("session_id", claims.SessionID), zap.String("user_id", claims.Subject))
+
+		if _, revokeErr := s.sessionRepository.Revoke(claims.SessionID, models.SessionRevokedReuse); revokeErr != nil {
+			s.logger.Error("failed to revoke session", zap.Error(revokeErr))
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid refresh token",
+		}
 	}
 
-	token, err := s.createJwt(int(res.UserID))
+	if err != nil {
+		s.logger.Error("failed to rotate session", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid refresh token",
+		}
+	}
 
+	// Role dibaca ulang sehingga perubahan role berlaku sejak refresh berikutnya
+	user, err := s.repository.Read(session.UserID)
 	if err != nil {
-		s.logger.Error("failed create jwt token: ", zap.Error(err))
-		return nil, err
+		s.logger.Error("failed to find user of session", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid refresh token",
+		}
+	}
+
+	tokens, err := s.createTokens(user, session)
+	if err != nil {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to refresh token",
+		}
+	}
+
+	return &response.ApiResponse[*response.TokenResponse]{
+		Status:  "success",
+		Message: "refresh success",
+		Data:    tokens,
+	}, nil
+}
+
+// Logout revokes the session of the access token the caller signed in with,
+// which invalidates its access token and every refresh token issued for it.
+func (s *authService) Logout(caller requests.Caller, sessionID int) (*response.ApiResponse[string], *response.ErrorResponse) {
+	session, err := s.sessionRepository.Read(sessionID)
+	if err != nil || session.UserID != caller.UserID {
+		s.logger.Error("failed to find session", zap.Int("session_id", sessionID), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Session not found",
+		}
+	}
+
+	if _, err := s.sessionRepository.Revoke(sessionID, models.SessionRevokedLogout); err != nil {
+		s.logger.Error("failed to revoke session", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to logout",
+		}
 	}
 
 	return &response.ApiResponse[string]{
 		Status:  "success",
-		Message: "login success",
-		Data:    token,
+		Message: "logout success",
+		Data:    "Session " + strconv.Itoa(sessionID) + " has been revoked",
 	}, nil
+}
+
+// IsRevoked reports whether the session an access token belongs to was
+// revoked. Tokens of unknown sessions count as revoked.
+func (s *authService) IsRevoked(claims *auth.Claims) bool {
+	session, err := s.sessionRepository.Read(claims.SessionID)
+	if err != nil {
+		return true
+	}
 
+	return session.RevokedAt != nil || strconv.Itoa(session.UserID) != claims.Subject
 }
 
-func (s *authService) createJwt(id int) (string, error) {
-	token, err := s.token.NewJwtToken(id)
+// EnsureAdmin makes sure an admin account exists for email. A missing account
+// is created with password. An account that already exists is never
+// promoted, since whoever registered it may not be the operator; it has to be
+// an admin already.
+func (s *authService) EnsureAdmin(email string, password string) error {
+	existing, err := s.repository.ReadByEmail(email)
+	if err == nil {
+		if existing.Role == models.RoleAdmin {
+			return nil
+		}
+
+		return fmt.Errorf("account %s already exists and is not an admin", email)
+	}
+
+	if password == "" {
+		return errors.New("admin password is required to create the admin account")
+	}
+
+	hashing, err := s.hash.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repository.Create(requests.CreateUserRequest{
+		FirstName: "Admin",
+		LastName:  "Admin",
+		Email:     email,
+		Password:  hashing,
+		Role:      models.RoleAdmin,
+	})
+
+	return err
+}
+
+// startSession opens a session for a signed in user and issues its tokens.
+func (s *authService) startSession(user *record.UserRecord) (*response.TokenResponse, error) {
+	// Setiap login membuka sesi baru yang menjadi induk semua refresh token-nya
+	session, err := s.sessionRepository.Create(requests.CreateSessionRequest{UserID: user.UserID})
+	if err != nil {
+		s.logger.Error("failed create session: ", zap.Error(err))
+		return nil, err
+	}
+
+	return s.createTokens(user, session)
+}
+
+// createTokens issues an access token and a refresh token for the current
+// generation of session.
+func (s *authService) createTokens(user *record.UserRecord, session *record.SessionRecord) (*response.TokenResponse, error) {
+	// Pengguna yang tersimpan sebelum ada role dianggap customer
+	role := user.Role
+	if role == "" {
+		role = models.RoleCustomer
+	}
+
+	accessToken, err := s.token.NewJwtToken(user.UserID, role, session.SessionID)
 	if err != nil {
 		s.logger.Error("failed create jwt token: ", zap.Error(err))
-		return "", err
+		return nil, err
 	}
-	return token, nil
+
+	refreshToken, err := s.token.NewRefreshToken(user.UserID, session.SessionID, session.Generation)
+	if err != nil {
+		s.logger.Error("failed create refresh token: ", zap.Error(err))
+		return nil, err
+	}
+
+	return &response.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.token.AccessTokenTTL().Seconds()),
+	}, nil
 }
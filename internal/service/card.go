@@ -79,7 +79,7 @@ func (s *cardService) FindAll(page int, pageSize int, search string) (*response.
 	}, nil
 }
 
-func (s *cardService) FindById(cardID int) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse) {
+func (s *cardService) FindById(caller requests.Caller, cardID int) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse) {
 	card, err := s.cardRepository.Read(cardID)
 	if err != nil {
 		s.logger.Error("failed find card by id", zap.Error(err))
@@ -88,6 +88,14 @@ func (s *cardService) FindById(cardID int) (*response.ApiResponse[*response.Card
 			Message: "Card not found",
 		}
 	}
+
+	if !caller.IsAdmin() && card.UserID != caller.UserID {
+		s.logger.Error("card belongs to another user", zap.Int("card_id", cardID), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Card not found",
+		}
+	}
 	so := s.mapper.ToCardResponse(*card)
 
 	return &response.ApiResponse[*response.CardResponse]{
@@ -165,7 +173,16 @@ func (s *cardService) FindByUsersID(userID int) (*response.ApiResponse[[]*respon
 	}, nil
 }
 
-func (s *cardService) Create(request requests.CreateCardRequest) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse) {
+func (s *cardService) Create(caller requests.Caller, request requests.CreateCardRequest) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse) {
+	// Kartu hanya boleh dibuat atas nama pengguna yang sedang login, kecuali oleh admin
+	if !caller.IsAdmin() && request.UserID != caller.UserID {
+		s.logger.Error("card requested for another user", zap.Int("user_id", caller.UserID), zap.Int("target_user_id", request.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "User not found",
+		}
+	}
+
 	_, err := s.userRepository.Read(request.UserID)
 	if err != nil {
 		s.logger.Error("failed to find user", zap.Error(err))
@@ -194,8 +211,34 @@ func (s *cardService) Create(request requests.CreateCardRequest) (*response.ApiR
 	}, nil
 }
 
-func (s *cardService) Update(request requests.UpdateCardRequest) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse) {
-	_, err := s.userRepository.Read(request.UserID)
+func (s *cardService) Update(caller requests.Caller, request requests.UpdateCardRequest) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse) {
+	card, err := s.cardRepository.Read(request.CardID)
+	if err != nil {
+		s.logger.Error("failed to find card", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Card not found",
+		}
+	}
+
+	if !caller.IsAdmin() && card.UserID != caller.UserID {
+		s.logger.Error("card belongs to another user", zap.Int("card_id", request.CardID), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Card not found",
+		}
+	}
+
+	// Pemilik kartu tidak boleh dipindahkan ke pengguna lain, kecuali oleh admin
+	if !caller.IsAdmin() && request.UserID != caller.UserID {
+		s.logger.Error("card moved to another user", zap.Int("user_id", caller.UserID), zap.Int("target_user_id", request.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "User not found",
+		}
+	}
+
+	_, err = s.userRepository.Read(request.UserID)
 	if err != nil {
 		s.logger.Error("failed to find user", zap.Error(err))
 		return nil, &response.ErrorResponse{
@@ -204,7 +247,7 @@ func (s *cardService) Update(request requests.UpdateCardRequest) (*response.ApiR
 		}
 	}
 
-	card, err := s.cardRepository.Update(request)
+	card, err = s.cardRepository.Update(request)
 
 	if err != nil {
 		s.logger.Error("failed to update card", zap.Error(err))
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/models"
+	"payment-mutex/internal/repository"
+	"payment-mutex/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// checkoutService lets a merchant ask for a payment without handling card
+// numbers. The payment itself is made by the transaction service, from the
+// card the customer picks when confirming the session.
+type checkoutService struct {
+	checkoutSessionRepository repository.CheckoutSessionRepository
+	merchantRepository        repository.MerchantRepository
+	cardRepository            repository.CardRepository
+	transactions              TransactionService
+	pin                       PinService
+	webhooks                  WebhookService
+	logger                    logger.Logger
+	mapper                    responseMapper.CheckoutSessionResponseMapper
+	ttl                       time.Duration
+}
+
+func NewCheckoutService(
+	checkoutSessionRepository repository.CheckoutSessionRepository,
+	merchantRepository repository.MerchantRepository,
+	cardRepository repository.CardRepository,
+	transactions TransactionService,
+	pin PinService,
+	webhooks WebhookService,
+	logger logger.Logger,
+	mapper responseMapper.CheckoutSessionResponseMapper,
+	ttl time.Duration,
+) *checkoutService {
+	return &checkoutService{
+		checkoutSessionRepository: checkoutSessionRepository,
+		merchantRepository:        merchantRepository,
+		cardRepository:            cardRepository,
+		transactions:              transactions,
+		pin:                       pin,
+		webhooks:                  webhooks,
+		logger:                    logger,
+		mapper:                    mapper,
+		ttl:                       ttl,
+	}
+}
+
+func (s *checkoutService) Create(merchantID int, request requests.CreateCheckoutSessionRequest) (*response.ApiResponse[*response.CheckoutSessionResponse], *response.ErrorResponse) {
+	merchant, err := s.merchantRepository.Read(merchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	sessionID, err := newCheckoutSessionID()
+	if err != nil {
+		s.logger.Error("failed to generate checkout session id", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create checkout session",
+		}
+	}
+
+	request.MerchantID = &merchant.MerchantID
+	request.SessionID = sessionID
+	request.ExpiresAt = time.Now().Add(s.ttl)
+
+	session, err := s.checkoutSessionRepository.Create(request)
+	if err != nil {
+		s.logger.Error("failed to create checkout session", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create checkout session",
+		}
+	}
+
+	return &response.ApiResponse[*response.CheckoutSessionResponse]{
+		Status:  "success",
+		Message: "Checkout session created successfully",
+		Data:    s.toResponse(session, merchant),
+	}, nil
+}
+
+// FindByMerchant returns the session when it belongs to the merchant.
+// Sessions of other merchants are reported as not found.
+func (s *checkoutService) FindByMerchant(merchantID int, sessionID string) (*response.ApiResponse[*response.CheckoutSessionResponse], *response.ErrorResponse) {
+	session, err := s.checkoutSessionRepository.ReadBySessionID(sessionID)
+	if err != nil || session.MerchantID != merchantID {
+		s.logger.Error("failed to find merchant checkout session", zap.String("session_id", sessionID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Checkout session not found",
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(merchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	return &response.ApiResponse[*response.CheckoutSessionResponse]{
+		Status:  "success",
+		Message: "Checkout session found",
+		Data:    s.toResponse(session, merchant),
+	}, nil
+}
+
+// Find returns the session to the customer about to pay it. The card it was
+// paid with is only shown to the owner of that card.
+func (s *checkoutService) Find(caller requests.Caller, sessionID string) (*response.ApiResponse[*response.CheckoutSessionResponse], *response.ErrorResponse) {
+	session, err := s.checkoutSessionRepository.ReadBySessionID(sessionID)
+	if err != nil {
+		s.logger.Error("failed to find checkout session", zap.String("session_id", sessionID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Checkout session not found",
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(session.MerchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	so := s.toResponse(session, merchant)
+	if so.CardNumber != "" && !ownsCard(s.cardRepository, caller, so.CardNumber) {
+		so.CardNumber = ""
+	}
+
+	return &response.ApiResponse[*response.CheckoutSessionResponse]{
+		Status:  "success",
+		Message: "Checkout session found",
+		Data:    so,
+	}, nil
+}
+
+// Confirm pays an open session from a card of the caller. The session is
+// moved to processing before the payment is made, so it is paid at most once
+// and cannot expire halfway. A payment that fails, for example on
+// insufficient balance, opens the session again so the customer can try
+// another card.
+func (s *checkoutService) Confirm(caller requests.Caller, request requests.ConfirmCheckoutSessionRequest) (*response.ApiResponse[*response.CheckoutSessionResponse], *response.ErrorResponse) {
+	session, err := s.checkoutSessionRepository.ReadBySessionID(request.SessionID)
+	if err != nil {
+		s.logger.Error("failed to find checkout session", zap.String("session_id", request.SessionID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Checkout session not found",
+		}
+	}
+
+	if session.Status != models.CheckoutSessionOpen {
+		s.logger.Error("checkout session is no longer open", zap.String("status", session.Status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Checkout session is already " + session.Status,
+		}
+	}
+
+	if !time.Now().Before(session.ExpiresAt) {
+		if err := s.expire(session); err != nil {
+			s.logger.Error("failed to expire checkout session", zap.Error(err))
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Checkout session has expired",
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(session.MerchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	// Pembayaran lewat sesi tidak melalui API key, jadi status merchant diperiksa di sini
+	if merchant.Status != models.MerchantStatusActive {
+		s.logger.Error("checkout session of inactive merchant", zap.Int("merchant_id", merchant.MerchantID), zap.String("status", merchant.Status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant is not active",
+		}
+	}
+
+	if !ownsCard(s.cardRepository, caller, request.CardNumber) {
+		s.logger.Error("checkout card belongs to another user", zap.String("card_number", request.CardNumber), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Card not found",
+		}
+	}
+
+	if errRes := s.pin.VerifyAmount(caller, request.Pin, session.Amount); errRes != nil {
+		return nil, errRes
+	}
+
+	// Hanya satu konfirmasi yang bisa memindahkan sesi dari open, yang lain atau kedaluwarsa akan gagal di sini
+	_, err = s.checkoutSessionRepository.UpdateStatus(requests.UpdateCheckoutSessionStatus{
+		CheckoutSessionID: session.CheckoutSessionID,
+		From:              models.CheckoutSessionOpen,
+		Status:            models.CheckoutSessionProcessing,
+		CardNumber:        request.CardNumber,
+		PaymentMethod:     request.PaymentMethod,
+	})
+	if errors.Is(err, repository.ErrCheckoutSessionStatusChanged) {
+		s.logger.Error("checkout session is no longer open", zap.String("session_id", session.SessionID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Checkout session is no longer open",
+		}
+	}
+
+	if err != nil {
+		s.logger.Error("failed to start checkout session payment", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to confirm checkout session",
+		}
+	}
+
+	transaction, errRes := s.transactions.Create(session.MerchantID, requests.CreateTransactionRequest{
+		CardNumber:      request.CardNumber,
+		Amount:          session.Amount,
+		PaymentMethod:   request.PaymentMethod,
+		TransactionTime: time.Now(),
+	})
+	if errRes != nil {
+		if _, err := s.checkoutSessionRepository.UpdateStatus(requests.UpdateCheckoutSessionStatus{
+			CheckoutSessionID: session.CheckoutSessionID,
+			From:              models.CheckoutSessionProcessing,
+			Status:            models.CheckoutSessionOpen,
+		}); err != nil {
+			s.logger.Error("failed to reopen checkout session", zap.Error(err), zap.String("session_id", session.SessionID))
+		}
+
+		return nil, errRes
+	}
+
+	completed, err := s.checkoutSessionRepository.UpdateStatus(requests.UpdateCheckoutSessionStatus{
+		CheckoutSessionID: session.CheckoutSessionID,
+		From:              models.CheckoutSessionProcessing,
+		Status:            models.CheckoutSessionCompleted,
+		CardNumber:        request.CardNumber,
+		PaymentMethod:     request.PaymentMethod,
+		TransactionID:     transaction.Data.ID,
+	})
+	if err != nil {
+		// Transaksi sudah berhasil dan sesi tetap processing, jadi ID-nya dicatat agar bisa dicocokkan secara manual
+		s.logger.Error("failed to complete checkout session", zap.Error(err), zap.Int("transaction_id", transaction.Data.ID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to complete checkout session",
+		}
+	}
+
+	so := s.toResponse(completed, merchant)
+	s.webhooks.Publish(merchant.MerchantID, models.WebhookEventCheckoutCompleted, so)
+
+	return &response.ApiResponse[*response.CheckoutSessionResponse]{
+		Status:  "success",
+		Message: "Checkout session paid successfully",
+		Data:    so,
+	}, nil
+}
+
+// ExpireSessions closes every open session that outlived its expiry and
+// returns how many were closed. A session being paid is left alone.
+func (s *checkoutService) ExpireSessions() int {
+	sessions, err := s.checkoutSessionRepository.ReadExpired(time.Now())
+	if err != nil {
+		s.logger.Error("failed to read expired checkout sessions", zap.Error(err))
+		return 0
+	}
+
+	expired := 0
+
+	for _, session := range sessions {
+		err := s.expire(session)
+		if errors.Is(err, repository.ErrCheckoutSessionStatusChanged) {
+			// Sesi baru saja dikonfirmasi dan sedang dibayar
+			continue
+		}
+
+		if err != nil {
+			s.logger.Error("failed to expire checkout session", zap.Error(err), zap.String("session_id", session.SessionID))
+			continue
+		}
+
+		expired++
+	}
+
+	return expired
+}
+
+// expire marks session as expired and tells the merchant. It fails with
+// repository.ErrCheckoutSessionStatusChanged once the session is no longer
+// open.
+func (s *checkoutService) expire(session *record.CheckoutSessionRecord) error {
+	expired, err := s.checkoutSessionRepository.UpdateStatus(requests.UpdateCheckoutSessionStatus{
+		CheckoutSessionID: session.CheckoutSessionID,
+		From:              models.CheckoutSessionOpen,
+		Status:            models.CheckoutSessionExpired,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.webhooks.Publish(expired.MerchantID, models.WebhookEventCheckoutExpired, s.mapper.ToCheckoutSessionResponse(*expired))
+
+	return nil
+}
+
+func (s *checkoutService) toResponse(session *record.CheckoutSessionRecord, merchant *record.MerchantRecord) *response.CheckoutSessionResponse {
+	so := s.mapper.ToCheckoutSessionResponse(*session)
+	so.MerchantName = merchant.Name
+
+	return so
+}
+
+func newCheckoutSessionID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return "cs_" + hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/ledger"
+	"payment-mutex/internal/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hookedTransactions runs beforeCreate ahead of every payment, so a test can
+// act while a checkout session is being paid.
+type hookedTransactions struct {
+	TransactionService
+	beforeCreate func()
+}
+
+func (h hookedTransactions) Create(merchantID int, request requests.CreateTransactionRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse) {
+	h.beforeCreate()
+	return h.TransactionService.Create(merchantID, request)
+}
+
+// checkoutSession creates an active merchant with a session asking for
+// amount and returns the merchant card and the session ID.
+func (p *testPlatform) checkoutSession(t *testing.T, amount int) (string, string) {
+	t.Helper()
+
+	merchantID, merchantCard := p.merchant(t, 0)
+
+	if _, err := p.repos.Merchant.UpdateStatus(requests.UpdateMerchantStatusRequest{MerchantID: merchantID, Status: models.MerchantStatusActive}); err != nil {
+		t.Fatalf("activate merchant: %v", err)
+	}
+
+	res, errRes := p.services.Checkout.Create(merchantID, requests.CreateCheckoutSessionRequest{Amount: amount, Reference: "order-1"})
+	if errRes != nil {
+		t.Fatalf("create checkout session: %s", errRes.Message)
+	}
+
+	return merchantCard, res.Data.ID
+}
+
+// confirm pays sessionID from cardNumber of userID and returns the error
+// message, or "" when it went through.
+func (p *testPlatform) confirm(userID int, cardNumber string, sessionID string) string {
+	_, errRes := p.services.Checkout.Confirm(requests.Caller{UserID: userID}, requests.ConfirmCheckoutSessionRequest{SessionID: sessionID, CardNumber: cardNumber, PaymentMethod: "dana"})
+	return errorMessage(errRes)
+}
+
+// sessionStatus returns the status of sessionID.
+func (p *testPlatform) sessionStatus(t *testing.T, sessionID string) string {
+	t.Helper()
+
+	session, err := p.repos.CheckoutSession.ReadBySessionID(sessionID)
+	if err != nil {
+		t.Fatalf("read checkout session: %v", err)
+	}
+
+	return session.Status
+}
+
+func TestCheckoutConfirm(t *testing.T) {
+	p := newTestPlatform(t)
+
+	merchantCard, sessionID := p.checkoutSession(t, 30000)
+	userID, customerCard := p.card(t, 20000)
+
+	topup := func() {
+		saldo, err := p.repos.Saldo.ReadByCardNumber(customerCard)
+		if err != nil {
+			t.Fatalf("read saldo: %v", err)
+		}
+
+		if _, err := p.ledger.Post(ledger.TopupEntry(customerCard, 80000, saldo.SaldoID)); err != nil {
+			t.Fatalf("top up card: %v", err)
+		}
+	}
+
+	// Setiap langkah berjalan di atas hasil langkah sebelumnya
+	tests := []struct {
+		name       string
+		before     func()
+		want       string
+		wantStatus string
+	}{
+		{"failed payment", func() {}, "Insufficient balance", models.CheckoutSessionOpen},
+		{"after a top up", topup, "", models.CheckoutSessionCompleted},
+		{"second confirm", func() {}, "Checkout session is already completed", models.CheckoutSessionCompleted},
+	}
+
+	for _, tt := range tests {
+		tt.before()
+
+		if got := p.confirm(userID, customerCard, sessionID); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+
+		if status := p.sessionStatus(t, sessionID); status != tt.wantStatus {
+			t.Errorf("%s: status %q, want %q", tt.name, status, tt.wantStatus)
+		}
+	}
+
+	if customer, merchant := p.balance(t, customerCard), p.balance(t, merchantCard); customer != 70000 || merchant != 30000 {
+		t.Errorf("customer %d, merchant %d; want a single payment of 30000", customer, merchant)
+	}
+
+	p.checkBooks(t, []string{customerCard, merchantCard}, ledger.AccountFeeRevenue)
+}
+
+func TestCheckoutParallelConfirms(t *testing.T) {
+	p := newTestPlatform(t)
+
+	merchantCard, sessionID := p.checkoutSession(t, 10000)
+	userID, customerCard := p.card(t, 100000)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	paid := 0
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if p.confirm(userID, customerCard, sessionID) == "" {
+				mu.Lock()
+				paid++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if paid != 1 {
+		t.Errorf("%d parallel confirms went through, want 1", paid)
+	}
+
+	if balance := p.balance(t, customerCard); balance != 90000 {
+		t.Errorf("customer holds %d, want 90000 after a single payment", balance)
+	}
+
+	p.checkBooks(t, []string{customerCard, merchantCard}, ledger.AccountFeeRevenue)
+}
+
+func TestCheckoutExpiryDuringConfirm(t *testing.T) {
+	p := newTestPlatform(t)
+	s := p.services.Checkout.(*checkoutService)
+	s.ttl = 50 * time.Millisecond
+
+	_, sessionID := p.checkoutSession(t, 10000)
+	userID, customerCard := p.card(t, 100000)
+
+	// Sesi melewati batas waktunya saat pembayaran sedang berjalan
+	expired := -1
+	s.transactions = hookedTransactions{
+		TransactionService: s.transactions,
+		beforeCreate: func() {
+			time.Sleep(2 * s.ttl)
+			expired = s.ExpireSessions()
+		},
+	}
+
+	if got := p.confirm(userID, customerCard, sessionID); got != "" {
+		t.Fatalf("confirm: %s", got)
+	}
+
+	if expired != 0 {
+		t.Errorf("expired %d sessions while one was being paid, want 0", expired)
+	}
+
+	if status := p.sessionStatus(t, sessionID); status != models.CheckoutSessionCompleted {
+		t.Errorf("status %q, want %q", status, models.CheckoutSessionCompleted)
+	}
+
+	// Sesi yang sudah selesai tidak ikut kedaluwarsa
+	if n := s.ExpireSessions(); n != 0 {
+		t.Errorf("expired %d sessions after the payment, want 0", n)
+	}
+}
+
+func TestCheckoutConfirmAfterExpiry(t *testing.T) {
+	p := newTestPlatform(t)
+	p.services.Checkout.(*checkoutService).ttl = -time.Second
+
+	_, sessionID := p.checkoutSession(t, 10000)
+	userID, customerCard := p.card(t, 100000)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"first confirm", "Checkout session has expired"},
+		{"second confirm", "Checkout session is already expired"},
+	}
+
+	for _, tt := range tests {
+		if got := p.confirm(userID, customerCard, sessionID); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if balance := p.balance(t, customerCard); balance != 100000 {
+		t.Errorf("customer holds %d, want the untouched 100000", balance)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/models"
 	"payment-mutex/internal/repository"
 	"payment-mutex/pkg/logger"
 	"time"
@@ -69,7 +70,7 @@ func (s *dashboardService) GetGlobalOverview() (*response.ApiResponse[*response.
 	overview.TotalBalance = totalBalance
 	overview.ActiveCards = activeCards
 
-	totalTransactions, _, err := s.transactionRepository.ReadAll(1, 1000, "")
+	totalTransactions, _, err := s.transactionRepository.ReadAll(1, 1000, "", models.StatusSucceeded)
 	if err != nil {
 		s.logger.Error("failed to fetch transactions", zap.Error(err))
 		return nil, &response.ErrorResponse{
@@ -79,7 +80,7 @@ func (s *dashboardService) GetGlobalOverview() (*response.ApiResponse[*response.
 	}
 	overview.TotalTransaksi = len(totalTransactions)
 
-	topups, _, err := s.topupRepository.ReadAll(1, 1000, "")
+	topups, _, err := s.topupRepository.ReadAll(1, 1000, "", models.StatusSucceeded)
 	if err != nil {
 		s.logger.Error("failed to fetch topups", zap.Error(err))
 		return nil, &response.ErrorResponse{
@@ -97,7 +98,7 @@ func (s *dashboardService) GetGlobalOverview() (*response.ApiResponse[*response.
 	overview.TotalTopup = totalTopup
 	overview.TopupAmount = topupAmount
 
-	withdrawals, _, err := s.withdrawRepository.ReadAll(1, 1000, "")
+	withdrawals, _, err := s.withdrawRepository.ReadAll(1, 1000, "", models.StatusSucceeded)
 	if err != nil {
 		s.logger.Error("failed to fetch withdrawals", zap.Error(err))
 		return nil, &response.ErrorResponse{
@@ -115,7 +116,7 @@ func (s *dashboardService) GetGlobalOverview() (*response.ApiResponse[*response.
 	overview.TotalWithdraw = totalWithdraw
 	overview.WithdrawAmount = withdrawAmount
 
-	transfers, _, err := s.transferRepository.ReadAll(1, 1000, "")
+	transfers, _, err := s.transferRepository.ReadAll(1, 1000, "", models.StatusSucceeded)
 	if err != nil {
 		s.logger.Error("failed to fetch transfers", zap.Error(err))
 		return nil, &response.ErrorResponse{
@@ -149,7 +150,7 @@ func (s *dashboardService) GetGlobalOverview() (*response.ApiResponse[*response.
 	totalMerchants := len(merchants)
 	activeMerchants := 0
 	for _, merchant := range merchants {
-		if merchant.Status == "active" {
+		if merchant.Status == models.MerchantStatusActive {
 			activeMerchants++
 		}
 	}
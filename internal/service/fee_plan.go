@@ -0,0 +1,281 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/repository"
+	"payment-mutex/pkg/logger"
+	methodtopup "payment-mutex/pkg/method_topup"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// feeQuote is the fee a payment pays under the fee plan of its merchant.
+type feeQuote struct {
+	FeePlanID int
+	Rate      int
+	Fixed     int
+	Fee       int
+}
+
+// quoteFee works out the fee of a payment of amount with paymentMethod to
+// merchant. A merchant without a fee plan pays nothing, and the fee is never
+// more than amount.
+func quoteFee(feePlans repository.FeePlanRepository, merchant *record.MerchantRecord, paymentMethod string, amount int) (feeQuote, error) {
+	if merchant.FeePlanID == 0 {
+		return feeQuote{}, nil
+	}
+
+	plan, err := feePlans.Read(merchant.FeePlanID)
+	if err != nil {
+		return feeQuote{}, err
+	}
+
+	quote := feeQuote{
+		FeePlanID: plan.FeePlanID,
+		Rate:      plan.DefaultRate,
+		Fixed:     plan.DefaultFixed,
+	}
+
+	for _, rate := range plan.Rates {
+		if rate.PaymentMethod == strings.ToLower(paymentMethod) {
+			quote.Rate = rate.Rate
+			quote.Fixed = rate.Fixed
+			break
+		}
+	}
+
+	if amount <= 0 {
+		return quote, nil
+	}
+
+	quote.Fee = amount*quote.Rate/10000 + quote.Fixed
+	if quote.Fee > amount {
+		quote.Fee = amount
+	}
+
+	return quote, nil
+}
+
+type feePlanService struct {
+	feePlanRepository  repository.FeePlanRepository
+	merchantRepository repository.MerchantRepository
+	logger             logger.Logger
+	mapper             responseMapper.FeePlanResponseMapper
+	merchantMapper     responseMapper.MerchantResponseMapper
+
+	// mu keeps a plan from being deleted while it is assigned to a merchant.
+	mu sync.Mutex
+}
+
+func NewFeePlanService(
+	feePlanRepository repository.FeePlanRepository,
+	merchantRepository repository.MerchantRepository,
+	logger logger.Logger,
+	mapper responseMapper.FeePlanResponseMapper,
+	merchantMapper responseMapper.MerchantResponseMapper,
+) *feePlanService {
+	return &feePlanService{
+		feePlanRepository:  feePlanRepository,
+		merchantRepository: merchantRepository,
+		logger:             logger,
+		mapper:             mapper,
+		merchantMapper:     merchantMapper,
+	}
+}
+
+func (s *feePlanService) FindAll() (*response.ApiResponse[[]*response.FeePlanResponse], *response.ErrorResponse) {
+	plans, err := s.feePlanRepository.ReadAll()
+	if err != nil {
+		s.logger.Error("failed to fetch fee plans", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to fetch fee plans",
+		}
+	}
+
+	return &response.ApiResponse[[]*response.FeePlanResponse]{
+		Status:  "success",
+		Message: "Fee plans retrieved successfully",
+		Data:    s.mapper.ToFeePlansResponse(plans),
+	}, nil
+}
+
+func (s *feePlanService) FindByID(feePlanID int) (*response.ApiResponse[*response.FeePlanResponse], *response.ErrorResponse) {
+	plan, err := s.feePlanRepository.Read(feePlanID)
+	if err != nil {
+		s.logger.Error("failed to find fee plan", zap.Int("fee_plan_id", feePlanID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Fee plan not found",
+		}
+	}
+
+	return &response.ApiResponse[*response.FeePlanResponse]{
+		Status:  "success",
+		Message: "Fee plan retrieved successfully",
+		Data:    s.mapper.ToFeePlanResponse(*plan),
+	}, nil
+}
+
+func (s *feePlanService) Create(request requests.CreateFeePlanRequest) (*response.ApiResponse[*response.FeePlanResponse], *response.ErrorResponse) {
+	plan, err := s.feePlanRepository.Create(request)
+	if err != nil {
+		s.logger.Error("failed to create fee plan", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create fee plan",
+		}
+	}
+
+	return &response.ApiResponse[*response.FeePlanResponse]{
+		Status:  "success",
+		Message: "Fee plan created successfully",
+		Data:    s.mapper.ToFeePlanResponse(*plan),
+	}, nil
+}
+
+// Update changes the rates of a plan. Transactions already made keep the fee
+// they were charged.
+func (s *feePlanService) Update(request requests.UpdateFeePlanRequest) (*response.ApiResponse[*response.FeePlanResponse], *response.ErrorResponse) {
+	plan, err := s.feePlanRepository.Update(request)
+	if err != nil {
+		s.logger.Error("failed to update fee plan", zap.Int("fee_plan_id", request.FeePlanID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Fee plan not found",
+		}
+	}
+
+	return &response.ApiResponse[*response.FeePlanResponse]{
+		Status:  "success",
+		Message: "Fee plan updated successfully",
+		Data:    s.mapper.ToFeePlanResponse(*plan),
+	}, nil
+}
+
+// Delete removes a plan no merchant is charged by.
+func (s *feePlanService) Delete(feePlanID int) (*response.ApiResponse[string], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count, err := s.merchantRepository.CountByFeePlanID(feePlanID)
+	if err != nil {
+		s.logger.Error("failed to count merchants of fee plan", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to delete fee plan",
+		}
+	}
+
+	if count > 0 {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Fee plan is still assigned to merchants",
+		}
+	}
+
+	if err := s.feePlanRepository.Delete(feePlanID); err != nil {
+		s.logger.Error("failed to delete fee plan", zap.Int("fee_plan_id", feePlanID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Fee plan not found",
+		}
+	}
+
+	return &response.ApiResponse[string]{
+		Status:  "success",
+		Message: "Fee plan deleted successfully",
+		Data:    "Fee plan with ID " + strconv.Itoa(feePlanID) + " has been deleted",
+	}, nil
+}
+
+// Assign charges the payments a merchant receives from now on by a fee plan,
+// or by none when FeePlanID is zero.
+func (s *feePlanService) Assign(request requests.AssignFeePlanRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if request.FeePlanID != 0 {
+		if _, err := s.feePlanRepository.Read(request.FeePlanID); err != nil {
+			s.logger.Error("failed to find fee plan", zap.Int("fee_plan_id", request.FeePlanID), zap.Error(err))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Fee plan not found",
+			}
+		}
+	}
+
+	merchant, err := s.merchantRepository.UpdateFeePlan(request.MerchantID, request.FeePlanID)
+	if err != nil {
+		s.logger.Error("failed to assign fee plan", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	s.logger.Info("fee plan assigned", zap.Int("merchant_id", merchant.MerchantID), zap.Int("fee_plan_id", merchant.FeePlanID))
+
+	return &response.ApiResponse[*response.MerchantResponse]{
+		Status:  "success",
+		Message: "Fee plan assigned successfully",
+		Data:    s.merchantMapper.ToMerchantResponse(*merchant),
+	}, nil
+}
+
+// Preview returns the fee a merchant would pay on a payment of amount with
+// paymentMethod, without making it.
+func (s *feePlanService) Preview(caller requests.Caller, merchantID int, amount int, paymentMethod string) (*response.ApiResponse[*response.FeePreviewResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, merchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	if amount <= 0 || !methodtopup.PaymentMethodValidator(paymentMethod) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid amount or payment method",
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(merchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	quote, err := quoteFee(s.feePlanRepository, merchant, paymentMethod, amount)
+	if err != nil {
+		s.logger.Error("failed to quote fee", zap.Int("merchant_id", merchantID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to calculate fee",
+		}
+	}
+
+	return &response.ApiResponse[*response.FeePreviewResponse]{
+		Status:  "success",
+		Message: "Fee calculated successfully",
+		Data: &response.FeePreviewResponse{
+			MerchantID:    merchantID,
+			FeePlanID:     quote.FeePlanID,
+			PaymentMethod: strings.ToLower(paymentMethod),
+			Amount:        amount,
+			Rate:          quote.Rate,
+			Fixed:         quote.Fixed,
+			Fee:           quote.Fee,
+			NetAmount:     amount - quote.Fee,
+		},
+	}, nil
+}
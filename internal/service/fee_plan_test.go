@@ -0,0 +1,61 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"testing"
+)
+
+func TestQuoteFee(t *testing.T) {
+	p := newTestPlatform(t)
+
+	// Default 1% + 500, dana 2% tanpa biaya tetap, ovo 0% + 3.000
+	plan, err := p.repos.FeePlan.Create(requests.CreateFeePlanRequest{
+		Name:         "Plan",
+		DefaultRate:  100,
+		DefaultFixed: 500,
+		Rates: []requests.FeeRateRequest{
+			{PaymentMethod: "dana", Rate: 200},
+			{PaymentMethod: "ovo", Fixed: 3000},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create fee plan: %v", err)
+	}
+
+	withPlan := &record.MerchantRecord{MerchantID: 1, FeePlanID: plan.FeePlanID}
+	withoutPlan := &record.MerchantRecord{MerchantID: 2}
+
+	tests := []struct {
+		name     string
+		merchant *record.MerchantRecord
+		method   string
+		amount   int
+		wantRate int
+		wantFee  int
+	}{
+		{"rate of the method", withPlan, "dana", 50000, 200, 1000},
+		{"method in another case", withPlan, "DANA", 50000, 200, 1000},
+		{"fixed fee of the method", withPlan, "ovo", 50000, 0, 3000},
+		{"default rate plus fixed for an unknown method", withPlan, "gopay", 50000, 100, 1000},
+		{"capped at the amount", withPlan, "ovo", 2000, 0, 2000},
+		{"rate rounds down", withPlan, "dana", 149, 200, 2},
+		{"no amount", withPlan, "dana", 0, 200, 0},
+		{"merchant without a plan", withoutPlan, "dana", 50000, 0, 0},
+	}
+
+	for _, tt := range tests {
+		quote, err := quoteFee(p.repos.FeePlan, tt.merchant, tt.method, tt.amount)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if quote.Rate != tt.wantRate || quote.Fee != tt.wantFee {
+			t.Errorf("%s: rate %d, fee %d; want %d, %d", tt.name, quote.Rate, quote.Fee, tt.wantRate, tt.wantFee)
+		}
+	}
+
+	if _, err := quoteFee(p.repos.FeePlan, &record.MerchantRecord{FeePlanID: 99}, "dana", 50000); err == nil {
+		t.Error("quote with a missing fee plan succeeded")
+	}
+}
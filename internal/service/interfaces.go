@@ -3,11 +3,26 @@ package service
 import (
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
+	"payment-mutex/pkg/auth"
 )
 
 type AuthService interface {
 	RegisterUser(request *requests.RegisterRequest) (*response.ApiResponse[response.UserResponse], *response.ErrorResponse)
-	Login(request *requests.AuthRequest) (*response.ApiResponse[string], error)
+	Login(request *requests.AuthRequest, clientIP string) (*response.ApiResponse[*response.LoginResponse], *response.ErrorResponse)
+	VerifyLogin(request requests.VerifyLoginRequest) (*response.ApiResponse[*response.TokenResponse], *response.ErrorResponse)
+	EnrollTotp(caller requests.Caller) (*response.ApiResponse[*response.TotpEnrollmentResponse], *response.ErrorResponse)
+	ConfirmTotp(caller requests.Caller, request requests.ConfirmTotpRequest) (*response.ApiResponse[*response.RecoveryCodesResponse], *response.ErrorResponse)
+	Refresh(request requests.RefreshTokenRequest) (*response.ApiResponse[*response.TokenResponse], *response.ErrorResponse)
+	Logout(caller requests.Caller, sessionID int) (*response.ApiResponse[string], *response.ErrorResponse)
+	IsRevoked(claims *auth.Claims) bool
+	EnsureAdmin(email string, password string) error
+}
+
+type AccountService interface {
+	VerifyEmail(request requests.VerifyEmailRequest) (*response.ApiResponse[string], *response.ErrorResponse)
+	ResendVerification(request requests.ResendVerificationRequest) (*response.ApiResponse[string], *response.ErrorResponse)
+	ForgotPassword(request requests.ForgotPasswordRequest) (*response.ApiResponse[string], *response.ErrorResponse)
+	ResetPassword(request requests.ResetPasswordRequest) (*response.ApiResponse[string], *response.ErrorResponse)
 }
 
 type UserService interface {
@@ -15,54 +30,76 @@ type UserService interface {
 	FindByID(id int) (*response.ApiResponse[*response.UserResponse], *response.ErrorResponse)
 	Create(request requests.CreateUserRequest) (*response.ApiResponse[*response.UserResponse], *response.ErrorResponse)
 	Update(request requests.UpdateUserRequest) (*response.ApiResponse[*response.UserResponse], *response.ErrorResponse)
+	UpdateRole(request requests.UpdateUserRoleRequest) (*response.ApiResponse[*response.UserResponse], *response.ErrorResponse)
+	Unlock(request requests.UnlockUserRequest) (*response.ApiResponse[*response.UserResponse], *response.ErrorResponse)
 	Delete(userID int) (*response.ApiResponse[string], *response.ErrorResponse)
 }
 
+type PinService interface {
+	SetPin(caller requests.Caller, request requests.SetPinRequest) (*response.ApiResponse[string], *response.ErrorResponse)
+	ChangePin(caller requests.Caller, request requests.ChangePinRequest) (*response.ApiResponse[string], *response.ErrorResponse)
+	VerifyAmount(caller requests.Caller, pin string, amount int) *response.ErrorResponse
+}
+
 type SaldoService interface {
 	FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.SaldoResponse], *response.ErrorResponse)
-	FindById(saldoID int) (*response.ApiResponse[*response.SaldoResponse], *response.ErrorResponse)
-	Create(requests requests.CreateSaldoRequest) (*response.ApiResponse[*response.SaldoResponse], *response.ErrorResponse)
+	FindById(caller requests.Caller, saldoID int) (*response.ApiResponse[*response.SaldoResponse], *response.ErrorResponse)
+	Create(caller requests.Caller, requests requests.CreateSaldoRequest) (*response.ApiResponse[*response.SaldoResponse], *response.ErrorResponse)
 	Update(requests requests.UpdateSaldoRequest) (*response.ApiResponse[*response.SaldoResponse], *response.ErrorResponse)
 	Delete(saldoID int) (*response.ApiResponse[string], *response.ErrorResponse)
 }
 
 type TopupService interface {
-	FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.TopupResponse], *response.ErrorResponse)
-	FindById(topupID int) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse)
-	Create(requests requests.CreateTopupRequest) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse)
+	FindAll(page int, pageSize int, search string, status string) (*response.APIResponsePagination[[]*response.TopupResponse], *response.ErrorResponse)
+	FindById(caller requests.Caller, topupID int) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse)
+	Create(caller requests.Caller, requests requests.CreateTopupRequest) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse)
 	Update(requests requests.UpdateTopupRequest) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse)
+	UpdateStatus(request requests.UpdateStatusRequest) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse)
 	Delete(topupID int) (*response.ApiResponse[string], *response.ErrorResponse)
 }
 
 type TransferService interface {
-	FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.TransferResponse], *response.ErrorResponse)
-	FindById(transferID int) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse)
-	Create(requests requests.CreateTransferRequest) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse)
+	FindAll(page int, pageSize int, search string, status string) (*response.APIResponsePagination[[]*response.TransferResponse], *response.ErrorResponse)
+	FindById(caller requests.Caller, transferID int) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse)
+	Create(caller requests.Caller, requests requests.CreateTransferRequest) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse)
 	Update(requests requests.UpdateTransferRequest) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse)
+	UpdateStatus(request requests.UpdateStatusRequest) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse)
 	Delete(transferID int) (*response.ApiResponse[string], *response.ErrorResponse)
 }
 
 type CardService interface {
 	FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.CardResponse], *response.ErrorResponse)
-	FindById(cardID int) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse)
-	Create(requests requests.CreateCardRequest) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse)
-	Update(requests requests.UpdateCardRequest) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse)
+	FindById(caller requests.Caller, cardID int) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse)
+	Create(caller requests.Caller, requests requests.CreateCardRequest) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse)
+	Update(caller requests.Caller, requests requests.UpdateCardRequest) (*response.ApiResponse[*response.CardResponse], *response.ErrorResponse)
 	Delete(cardID int) (*response.ApiResponse[string], *response.ErrorResponse)
 }
 
 type WithdrawService interface {
-	FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.WithdrawResponse], *response.ErrorResponse)
-	FindById(withdrawID int) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse)
-	Create(requests requests.CreateWithdrawRequest) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse)
+	FindAll(page int, pageSize int, search string, status string) (*response.APIResponsePagination[[]*response.WithdrawResponse], *response.ErrorResponse)
+	FindById(caller requests.Caller, withdrawID int) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse)
+	Create(caller requests.Caller, requests requests.CreateWithdrawRequest) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse)
 	Update(requests requests.UpdateWithdrawRequest) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse)
+	UpdateStatus(request requests.UpdateStatusRequest) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse)
+	Hold(caller requests.Caller, request requests.CreateWithdrawHoldRequest) (*response.ApiResponse[*response.WithdrawHoldResponse], *response.ErrorResponse)
+	Capture(caller requests.Caller, request requests.CaptureWithdrawHoldRequest) (*response.ApiResponse[*response.WithdrawHoldResponse], *response.ErrorResponse)
+	Void(caller requests.Caller, request requests.VoidWithdrawHoldRequest) (*response.ApiResponse[*response.WithdrawHoldResponse], *response.ErrorResponse)
+	ExpireHolds() int
 	Delete(withdrawID int) (*response.ApiResponse[string], *response.ErrorResponse)
 }
 
 type TransactionService interface {
-	FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.TransactionResponse], *response.ErrorResponse)
-	FindById(transactionID int) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse)
-	Create(apikey string, requests requests.CreateTransactionRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse)
-	Update(apikey string, requests requests.UpdateTransactionRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse)
+	FindAll(page int, pageSize int, search string, status string) (*response.APIResponsePagination[[]*response.TransactionResponse], *response.ErrorResponse)
+	FindById(caller requests.Caller, transactionID int) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse)
+	FindByMerchant(merchantID int, transactionID int) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse)
+	Create(merchantID int, requests requests.CreateTransactionRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse)
+	Update(merchantID int, requests requests.UpdateTransactionRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse)
+	Refund(merchantID int, requests requests.CreateRefundRequest) (*response.ApiResponse[*response.RefundResponse], *response.ErrorResponse)
+	UpdateStatus(merchantID int, request requests.UpdateStatusRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse)
+	Authorize(merchantID int, request requests.CreateAuthorizationRequest) (*response.ApiResponse[*response.AuthorizationResponse], *response.ErrorResponse)
+	Capture(merchantID int, request requests.CaptureAuthorizationRequest) (*response.ApiResponse[*response.AuthorizationResponse], *response.ErrorResponse)
+	Void(merchantID int, request requests.VoidAuthorizationRequest) (*response.ApiResponse[*response.AuthorizationResponse], *response.ErrorResponse)
+	ExpireAuthorizations() int
 	Delete(transactionID int) (*response.ApiResponse[string], *response.ErrorResponse)
 }
 
@@ -73,9 +110,68 @@ type DashboardService interface {
 type MerchantService interface {
 	FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.MerchantResponse], *response.ErrorResponse)
 	FindByName(name string) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
-	FindByApiKey(apiKey string) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
 	FindByID(merchantID int) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
-	Create(requests requests.CreateMerchantRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
-	Update(requests requests.UpdateMerchantRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	Create(caller requests.Caller, requests requests.CreateMerchantRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	Update(caller requests.Caller, requests requests.UpdateMerchantRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	UpdateOwner(requests requests.UpdateMerchantOwnerRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
 	Delete(merchantID int) (*response.ApiResponse[string], *response.ErrorResponse)
+	Authenticate(apiKey string, scope string) (int, *response.ErrorResponse)
+	FindApiKeys(caller requests.Caller, merchantID int) (*response.ApiResponse[[]*response.MerchantApiKeyResponse], *response.ErrorResponse)
+	CreateApiKey(caller requests.Caller, request requests.CreateApiKeyRequest) (*response.ApiResponse[*response.MerchantApiKeyResponse], *response.ErrorResponse)
+	RotateApiKey(caller requests.Caller, request requests.RotateApiKeyRequest) (*response.ApiResponse[*response.MerchantApiKeyResponse], *response.ErrorResponse)
+	RevokeApiKey(caller requests.Caller, request requests.RevokeApiKeyRequest) (*response.ApiResponse[*response.MerchantApiKeyResponse], *response.ErrorResponse)
+	MigrateLegacyApiKeys() (int, error)
+	VerifySignature(merchantID int, request requests.SignedRequest) *response.ErrorResponse
+	RotateSigningSecret(caller requests.Caller, request requests.RotateSigningSecretRequest) (*response.ApiResponse[*response.SigningSecretResponse], *response.ErrorResponse)
+	RequireSignature(caller requests.Caller, request requests.RequireSignatureRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	Submit(caller requests.Caller, request requests.ChangeMerchantStatusRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	Approve(caller requests.Caller, request requests.ChangeMerchantStatusRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	Reject(caller requests.Caller, request requests.ChangeMerchantStatusRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	Suspend(caller requests.Caller, request requests.ChangeMerchantStatusRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	Close(caller requests.Caller, request requests.ChangeMerchantStatusRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	FindStatusHistory(caller requests.Caller, merchantID int) (*response.ApiResponse[[]*response.MerchantStatusChangeResponse], *response.ErrorResponse)
+}
+
+type LedgerService interface {
+	FindPostingsByCardNumber(cardNumber string, page int, pageSize int) (*response.APIResponsePagination[[]*response.PostingResponse], *response.ErrorResponse)
+	RecomputeBalance(cardNumber string) (*response.ApiResponse[*response.LedgerBalanceResponse], *response.ErrorResponse)
+}
+
+type WebhookService interface {
+	Publish(merchantID int, eventType string, data any)
+	DeliverDue() int
+	FindEndpoints(caller requests.Caller, merchantID int) (*response.ApiResponse[[]*response.WebhookEndpointResponse], *response.ErrorResponse)
+	CreateEndpoint(caller requests.Caller, request requests.CreateWebhookRequest) (*response.ApiResponse[*response.WebhookEndpointResponse], *response.ErrorResponse)
+	UpdateEndpoint(caller requests.Caller, request requests.UpdateWebhookRequest) (*response.ApiResponse[*response.WebhookEndpointResponse], *response.ErrorResponse)
+	DeleteEndpoint(caller requests.Caller, endpointID int) (*response.ApiResponse[string], *response.ErrorResponse)
+	FindDeliveries(caller requests.Caller, merchantID int, endpointID int, page int, pageSize int) (*response.APIResponsePagination[[]*response.WebhookDeliveryResponse], *response.ErrorResponse)
+	Redeliver(caller requests.Caller, request requests.RedeliverWebhookRequest) (*response.ApiResponse[*response.WebhookDeliveryResponse], *response.ErrorResponse)
+	RotateSecret(caller requests.Caller, request requests.RotateWebhookSecretRequest) (*response.ApiResponse[*response.WebhookSecretResponse], *response.ErrorResponse)
+}
+
+type SettlementService interface {
+	FindBatches(caller requests.Caller, merchantID int, page int, pageSize int) (*response.APIResponsePagination[[]*response.SettlementBatchResponse], *response.ErrorResponse)
+	FindBatch(caller requests.Caller, batchID int) (*response.ApiResponse[*response.SettlementBatchResponse], *response.ErrorResponse)
+	FindPending(caller requests.Caller, merchantID int) (*response.ApiResponse[*response.PendingSettlementResponse], *response.ErrorResponse)
+	UpdateMode(request requests.UpdateSettlementModeRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	Close(request requests.CloseSettlementRequest) (*response.ApiResponse[*response.SettlementBatchResponse], *response.ErrorResponse)
+	CloseDue() int
+}
+
+type FeePlanService interface {
+	FindAll() (*response.ApiResponse[[]*response.FeePlanResponse], *response.ErrorResponse)
+	FindByID(feePlanID int) (*response.ApiResponse[*response.FeePlanResponse], *response.ErrorResponse)
+	Create(request requests.CreateFeePlanRequest) (*response.ApiResponse[*response.FeePlanResponse], *response.ErrorResponse)
+	Update(request requests.UpdateFeePlanRequest) (*response.ApiResponse[*response.FeePlanResponse], *response.ErrorResponse)
+	Delete(feePlanID int) (*response.ApiResponse[string], *response.ErrorResponse)
+	Assign(request requests.AssignFeePlanRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse)
+	Preview(caller requests.Caller, merchantID int, amount int, paymentMethod string) (*response.ApiResponse[*response.FeePreviewResponse], *response.ErrorResponse)
+}
+
+type CheckoutService interface {
+	Create(merchantID int, request requests.CreateCheckoutSessionRequest) (*response.ApiResponse[*response.CheckoutSessionResponse], *response.ErrorResponse)
+	FindByMerchant(merchantID int, sessionID string) (*response.ApiResponse[*response.CheckoutSessionResponse], *response.ErrorResponse)
+	Find(caller requests.Caller, sessionID string) (*response.ApiResponse[*response.CheckoutSessionResponse], *response.ErrorResponse)
+	Confirm(caller requests.Caller, request requests.ConfirmCheckoutSessionRequest) (*response.ApiResponse[*response.CheckoutSessionResponse], *response.ErrorResponse)
+	ExpireSessions() int
 }
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/ledger"
+	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/repository"
+	"payment-mutex/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+type ledgerService struct {
+	journalRepository repository.JournalRepository
+	ledger            *ledger.Ledger
+	logger            logger.Logger
+	mapper            responseMapper.JournalResponseMapper
+}
+
+func NewLedgerService(
+	journalRepository repository.JournalRepository,
+	ledger *ledger.Ledger,
+	logger logger.Logger,
+	mapper responseMapper.JournalResponseMapper,
+) *ledgerService {
+	return &ledgerService{
+		journalRepository: journalRepository,
+		ledger:            ledger,
+		logger:            logger,
+		mapper:            mapper,
+	}
+}
+
+func (s *ledgerService) FindPostingsByCardNumber(cardNumber string, page int, pageSize int) (*response.APIResponsePagination[[]*response.PostingResponse], *response.ErrorResponse) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	postings, totalRecords, err := s.journalRepository.ReadPostingsByAccount(ledger.CardAccount(cardNumber), page, pageSize)
+	if err != nil {
+		s.logger.Error("failed to fetch postings", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to fetch postings",
+		}
+	}
+
+	if len(postings) == 0 {
+		s.logger.Error("no postings found", zap.String("cardNumber", cardNumber))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "No postings found",
+		}
+	}
+
+	so := s.mapper.ToPostingsResponse(postings)
+
+	totalPages := (totalRecords + pageSize - 1) / pageSize
+
+	return &response.APIResponsePagination[[]*response.PostingResponse]{
+		Status:  "success",
+		Message: "Postings retrieved successfully",
+		Data:    so,
+		Meta: response.PaginationMeta{
+			CurrentPage:  page,
+			PageSize:     pageSize,
+			TotalPages:   totalPages,
+			TotalRecords: totalRecords,
+		},
+	}, nil
+}
+
+func (s *ledgerService) RecomputeBalance(cardNumber string) (*response.ApiResponse[*response.LedgerBalanceResponse], *response.ErrorResponse) {
+	journalBalance, previousBalance, err := s.ledger.Recompute(cardNumber)
+	if err != nil {
+		s.logger.Error("failed to recompute balance", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to recompute balance",
+		}
+	}
+
+	if journalBalance != previousBalance {
+		s.logger.Error("saldo projection was out of sync with journal", zap.String("cardNumber", cardNumber), zap.Int("saldoBalance", previousBalance), zap.Int("journalBalance", journalBalance))
+	}
+
+	return &response.ApiResponse[*response.LedgerBalanceResponse]{
+		Status:  "success",
+		Message: "Balance recomputed successfully",
+		Data: &response.LedgerBalanceResponse{
+			CardNumber:      cardNumber,
+			JournalBalance:  journalBalance,
+			PreviousBalance: previousBalance,
+			Corrected:       journalBalance != previousBalance,
+		},
+	}, nil
+}
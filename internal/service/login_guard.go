@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/repository"
+	"payment-mutex/pkg/logger"
+	"payment-mutex/pkg/throttle"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LoginPolicy decides how hard password guessing is slowed down.
+type LoginPolicy struct {
+	// MaxAttempts wrong passwords in a row lock the account for Lockout.
+	MaxAttempts int
+	Lockout     time.Duration
+	// BackoffBase is the first wait after the free attempts of an email or
+	// IP address are used up. Every further failure doubles it, up to
+	// BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+const (
+	// Satu IP bisa dipakai banyak orang (NAT, kantor), jadi diberi jatah lebih
+	emailFreeAttempts = 2
+	ipFreeAttempts    = 10
+
+	loginThrottleWindow = time.Hour
+)
+
+// loginGuard counts failed logins per email, per IP address and per account.
+// The email and IP counters only slow attempts down and live in memory; the
+// account counter locks the account and is stored with the user.
+type loginGuard struct {
+	userRepository repository.UserRepository
+	logger         logger.Logger
+	policy         LoginPolicy
+
+	byEmail *throttle.Throttle
+	byIP    *throttle.Throttle
+
+	// mu menjaga agar hitungan login gagal tidak saling menimpa
+	mu sync.Mutex
+}
+
+func newLoginGuard(userRepository repository.UserRepository, logger logger.Logger, policy LoginPolicy) *loginGuard {
+	return &loginGuard{
+		userRepository: userRepository,
+		logger:         logger,
+		policy:         policy,
+		byEmail:        throttle.New(emailFreeAttempts, policy.BackoffBase, policy.BackoffMax, loginThrottleWindow),
+		byIP:           throttle.New(ipFreeAttempts, policy.BackoffBase, policy.BackoffMax, loginThrottleWindow),
+	}
+}
+
+// check refuses a login attempt that comes too soon after earlier failures or
+// is for a locked account. user is nil when email belongs to no account.
+func (g *loginGuard) check(email string, ip string, user *record.UserRecord) *response.ErrorResponse {
+	wait := g.byEmail.Wait(normalizeEmail(email))
+	if ipWait := g.byIP.Wait(ip); ipWait > wait {
+		wait = ipWait
+	}
+
+	if wait > 0 {
+		g.logger.SecurityEvent("login_throttled", zap.String("email", email), zap.String("ip", ip), zap.Duration("wait", wait))
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Too many failed login attempts, try again in %d seconds", int(math.Ceil(wait.Seconds()))),
+		}
+	}
+
+	if user != nil && user.LoginLockedUntil != nil && time.Now().Before(*user.LoginLockedUntil) {
+		g.logger.SecurityEvent("login_locked_account", zap.Int("user_id", user.UserID), zap.String("ip", ip))
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Account is temporarily locked, try again later",
+		}
+	}
+
+	return nil
+}
+
+// fail records a wrong password, or an unknown email when user is nil.
+func (g *loginGuard) fail(email string, ip string, user *record.UserRecord) {
+	g.byEmail.Fail(normalizeEmail(email))
+	g.byIP.Fail(ip)
+
+	if user == nil {
+		g.logger.SecurityEvent("login_failed", zap.String("email", email), zap.String("ip", ip), zap.String("reason", "unknown email"))
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Dibaca ulang agar percobaan yang bersamaan ikut terhitung
+	current, err := g.userRepository.Read(user.UserID)
+	if err != nil {
+		g.logger.Error("failed to find user", zap.Error(err))
+		return
+	}
+
+	// Kunci berakhir dengan sendirinya, hitungan dimulai lagi dari nol
+	attempts := current.LoginFailedAttempts + 1
+	var lockedUntil *time.Time
+
+	if attempts >= g.policy.MaxAttempts {
+		until := time.Now().Add(g.policy.Lockout)
+		lockedUntil = &until
+		attempts = 0
+	}
+
+	if _, err := g.userRepository.UpdateLoginAttempts(current.UserID, attempts, lockedUntil); err != nil {
+		g.logger.Error("failed to record failed login", zap.Error(err))
+	}
+
+	g.logger.SecurityEvent("login_failed", zap.Int("user_id", current.UserID), zap.String("ip", ip), zap.String("reason", "wrong password"))
+
+	if lockedUntil != nil {
+		g.logger.SecurityEvent("account_locked", zap.Int("user_id", current.UserID), zap.String("ip", ip), zap.Time("locked_until", *lockedUntil))
+	}
+}
+
+// succeed clears the counters of the account after a correct password. The
+// IP counter is left alone, otherwise signing in to an own account would
+// reset the guessing budget of that address.
+func (g *loginGuard) succeed(email string, user *record.UserRecord) {
+	g.byEmail.Reset(normalizeEmail(email))
+
+	if user.LoginFailedAttempts == 0 && user.LoginLockedUntil == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, err := g.userRepository.UpdateLoginAttempts(user.UserID, 0, nil); err != nil {
+		g.logger.Error("failed to reset failed logins", zap.Error(err))
+	}
+}
+
+// unlock lifts the lockout of an account together with the backoff of its
+// email.
+func (g *loginGuard) unlock(user *record.UserRecord) (*record.UserRecord, error) {
+	g.byEmail.Reset(normalizeEmail(user.Email))
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.userRepository.UpdateLoginAttempts(user.UserID, 0, nil)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
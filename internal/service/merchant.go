@@ -5,28 +5,55 @@ import (
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
 	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/models"
 	"payment-mutex/internal/repository"
 	"payment-mutex/pkg/logger"
+	"payment-mutex/pkg/signature"
 	"strconv"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
 
 type merchantService struct {
 	merchantRepository repository.MerchantRepository
+	apiKeyRepository   repository.MerchantApiKeyRepository
+	userRepository     repository.UserRepository
 	logger             logger.Logger
 	mapper             responseMapper.MerchantResponseMapper
+	apiKeyMapper       responseMapper.MerchantApiKeyResponseMapper
+	rotationOverlap    time.Duration
+	signatureTolerance time.Duration
+	nonces             *signature.NonceCache
+
+	// apiKeyMu menjaga agar rotasi dan pencabutan api key tidak saling mendahului
+	apiKeyMu sync.Mutex
+
+	// statusMu menjaga agar dua perubahan status merchant tidak saling mendahului
+	statusMu sync.Mutex
 }
 
 func NewMerchantService(
 	merchantRepository repository.MerchantRepository,
+	apiKeyRepository repository.MerchantApiKeyRepository,
+	userRepository repository.UserRepository,
 	logger logger.Logger,
 	mapper responseMapper.MerchantResponseMapper,
+	apiKeyMapper responseMapper.MerchantApiKeyResponseMapper,
+	rotationOverlap time.Duration,
+	signatureTolerance time.Duration,
 ) *merchantService {
 	return &merchantService{
 		merchantRepository: merchantRepository,
+		apiKeyRepository:   apiKeyRepository,
+		userRepository:     userRepository,
 		logger:             logger,
 		mapper:             mapper,
+		apiKeyMapper:       apiKeyMapper,
+		rotationOverlap:    rotationOverlap,
+		signatureTolerance: signatureTolerance,
+		nonces:             signature.NewNonceCache(signatureTolerance),
 	}
 }
 
@@ -94,11 +121,10 @@ func (s *merchantService) FindByID(id int) (*response.ApiResponse[*response.Merc
 	}, nil
 }
 
-func (s *merchantService) FindByApiKey(apiKey string) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
-	merchant, err := s.merchantRepository.ReadByApiKey(apiKey)
-
+func (s *merchantService) FindByName(name string) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	merchant, err := s.merchantRepository.ReadByName(name)
 	if err != nil {
-		s.logger.Error("failed to find merchant by api key", zap.Error(err))
+		s.logger.Error("failed to find merchant by name", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
 			Message: "Merchant not found",
@@ -114,26 +140,20 @@ func (s *merchantService) FindByApiKey(apiKey string) (*response.ApiResponse[*re
 	}, nil
 }
 
-func (s *merchantService) FindByName(name string) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
-	merchant, err := s.merchantRepository.ReadByName(name)
-	if err != nil {
-		s.logger.Error("failed to find merchant by name", zap.Error(err))
+func (s *merchantService) Create(caller requests.Caller, request requests.CreateMerchantRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	// Selain admin, merchant selalu dibuat atas nama pemanggil
+	if !caller.IsAdmin() || request.UserID == 0 {
+		request.UserID = caller.UserID
+	}
+
+	if _, err := s.userRepository.Read(request.UserID); err != nil {
+		s.logger.Error("failed to find merchant owner", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Merchant not found",
+			Message: "User not found",
 		}
 	}
 
-	so := s.mapper.ToMerchantResponse(*merchant)
-
-	return &response.ApiResponse[*response.MerchantResponse]{
-		Status:  "success",
-		Message: "Merchant retrieved successfully",
-		Data:    so,
-	}, nil
-}
-
-func (s *merchantService) Create(request requests.CreateMerchantRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
 	merchant, err := s.merchantRepository.Create(request)
 
 	if err != nil {
@@ -147,16 +167,38 @@ func (s *merchantService) Create(request requests.CreateMerchantRequest) (*respo
 
 	}
 
+	// Customer yang membuka merchant menjadi merchant_owner
+	s.promoteOwner(merchant.UserID)
+
+	// Merchant baru langsung mendapat satu api key dengan semua scope
+	_, apiKey, err := s.issueApiKey(merchant.MerchantID, defaultApiKeyName, allApiKeyScopes)
+	if err != nil {
+		s.logger.Error("failed to create merchant api key", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create merchant api key",
+		}
+	}
+
 	so := s.mapper.ToMerchantResponse(*merchant)
+	so.ApiKey = apiKey
 
 	return &response.ApiResponse[*response.MerchantResponse]{
 		Status:  "success",
-		Message: "Merchant created successfully",
+		Message: "Merchant created successfully, store the api key now because it cannot be shown again",
 		Data:    so,
 	}, nil
 }
 
-func (s *merchantService) Update(request requests.UpdateMerchantRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+func (s *merchantService) Update(caller requests.Caller, request requests.UpdateMerchantRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, request.MerchantID) {
+		s.logger.Error("merchant update by non-owner", zap.Int("merchant_id", request.MerchantID), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Forbidden",
+		}
+	}
+
 	merchant, err := s.merchantRepository.Update(request)
 
 	if err != nil {
@@ -177,6 +219,55 @@ func (s *merchantService) Update(request requests.UpdateMerchantRequest) (*respo
 	}, nil
 }
 
+// UpdateOwner hands a merchant to another user, who becomes a merchant
+// owner. It is only reachable by admins.
+func (s *merchantService) UpdateOwner(request requests.UpdateMerchantOwnerRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	if _, err := s.userRepository.Read(request.UserID); err != nil {
+		s.logger.Error("failed to find new merchant owner", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "User not found",
+		}
+	}
+
+	merchant, err := s.merchantRepository.UpdateOwner(request)
+	if err != nil {
+		s.logger.Error("failed to update merchant owner", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update merchant owner",
+		}
+	}
+
+	s.promoteOwner(merchant.UserID)
+
+	s.logger.Info("merchant owner changed", zap.Int("merchant_id", merchant.MerchantID), zap.Int("user_id", merchant.UserID))
+
+	so := s.mapper.ToMerchantResponse(*merchant)
+
+	return &response.ApiResponse[*response.MerchantResponse]{
+		Status:  "success",
+		Message: "Merchant owner updated successfully",
+		Data:    so,
+	}, nil
+}
+
+// promoteOwner makes the user with userID a merchant owner, unless they
+// already are one or are an admin.
+func (s *merchantService) promoteOwner(userID int) {
+	owner, err := s.userRepository.Read(userID)
+	if err != nil || owner.Role == models.RoleAdmin || owner.Role == models.RoleMerchantOwner {
+		return
+	}
+
+	if _, err := s.userRepository.UpdateRole(requests.UpdateUserRoleRequest{
+		UserID: owner.UserID,
+		Role:   models.RoleMerchantOwner,
+	}); err != nil {
+		s.logger.Error("failed to promote merchant owner", zap.Error(err))
+	}
+}
+
 func (s *merchantService) Delete(id int) (*response.ApiResponse[string], *response.ErrorResponse) {
 	err := s.merchantRepository.Delete(id)
 
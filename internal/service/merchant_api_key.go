@@ -0,0 +1,283 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/models"
+	"payment-mutex/pkg/apikey"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultApiKeyName is the name of the key every new merchant gets.
+const defaultApiKeyName = "default"
+
+var allApiKeyScopes = []string{models.ApiKeyScopeCharge, models.ApiKeyScopeRefund, models.ApiKeyScopeRead}
+
+// Authenticate returns the ID of the merchant apiKey belongs to when the key
+// is active and carries scope.
+func (s *merchantService) Authenticate(apiKey string, scope string) (int, *response.ErrorResponse) {
+	invalid := &response.ErrorResponse{
+		Status:  "error",
+		Message: "Invalid API Key",
+	}
+
+	lookup, ok := apikey.Lookup(apiKey)
+	if !ok {
+		return 0, invalid
+	}
+
+	key, err := s.apiKeyRepository.ReadByLookup(lookup)
+	if err != nil || !apikey.Matches(apiKey, key.Hash) {
+		s.logger.SecurityEvent("api_key_invalid", zap.String("prefix", lookup))
+		return 0, invalid
+	}
+
+	now := time.Now()
+
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)) {
+		s.logger.SecurityEvent("api_key_inactive", zap.Int("key_id", key.KeyID), zap.Int("merchant_id", key.MerchantID))
+		return 0, invalid
+	}
+
+	if !slices.Contains(key.Scopes, scope) {
+		s.logger.SecurityEvent("api_key_scope_denied", zap.Int("key_id", key.KeyID), zap.String("scope", scope))
+		return 0, &response.ErrorResponse{
+			Status:  "error",
+			Message: "API Key is not allowed to " + scope,
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(key.MerchantID)
+	if err != nil {
+		s.logger.Error("api key of unknown merchant", zap.Int("key_id", key.KeyID), zap.Error(err))
+		return 0, invalid
+	}
+
+	// Key merchant yang belum disetujui, disuspend, atau ditutup ditolak
+	if merchant.Status != models.MerchantStatusActive {
+		s.logger.SecurityEvent("api_key_merchant_inactive", zap.Int("key_id", key.KeyID), zap.Int("merchant_id", key.MerchantID), zap.String("status", merchant.Status))
+		return 0, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant is not active",
+		}
+	}
+
+	if err := s.apiKeyRepository.Touch(key.KeyID, now); err != nil {
+		s.logger.Error("failed to record api key use", zap.Error(err))
+	}
+
+	return key.MerchantID, nil
+}
+
+func (s *merchantService) FindApiKeys(caller requests.Caller, merchantID int) (*response.ApiResponse[[]*response.MerchantApiKeyResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, merchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	keys, err := s.apiKeyRepository.ReadByMerchantID(merchantID)
+	if err != nil {
+		s.logger.Error("failed to fetch api keys", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to fetch api keys",
+		}
+	}
+
+	return &response.ApiResponse[[]*response.MerchantApiKeyResponse]{
+		Status:  "success",
+		Message: "Api keys retrieved successfully",
+		Data:    s.apiKeyMapper.ToMerchantApiKeysResponse(keys),
+	}, nil
+}
+
+// CreateApiKey issues a new key for the merchant. The key is only part of
+// this response, afterwards just its prefix can be shown.
+func (s *merchantService) CreateApiKey(caller requests.Caller, request requests.CreateApiKeyRequest) (*response.ApiResponse[*response.MerchantApiKeyResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, request.MerchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	key, plain, err := s.issueApiKey(request.MerchantID, request.Name, request.Scopes)
+	if err != nil {
+		s.logger.Error("failed to create api key", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create api key",
+		}
+	}
+
+	s.logger.SecurityEvent("api_key_created", zap.Int("key_id", key.KeyID), zap.Int("merchant_id", key.MerchantID), zap.Int("user_id", caller.UserID))
+
+	so := s.apiKeyMapper.ToMerchantApiKeyResponse(*key)
+	so.ApiKey = plain
+
+	return &response.ApiResponse[*response.MerchantApiKeyResponse]{
+		Status:  "success",
+		Message: "Api key created, store it now because it cannot be shown again",
+		Data:    so,
+	}, nil
+}
+
+// RotateApiKey issues a key with the name and scopes of an existing one. The
+// old key keeps working for the overlap so clients can switch without
+// downtime.
+func (s *merchantService) RotateApiKey(caller requests.Caller, request requests.RotateApiKeyRequest) (*response.ApiResponse[*response.MerchantApiKeyResponse], *response.ErrorResponse) {
+	s.apiKeyMu.Lock()
+	defer s.apiKeyMu.Unlock()
+
+	old, errRes := s.findOwnedApiKey(caller, request.KeyID)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	now := time.Now()
+
+	if old.RevokedAt != nil || (old.ExpiresAt != nil && !now.Before(*old.ExpiresAt)) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Only active api keys can be rotated",
+		}
+	}
+
+	overlap := s.rotationOverlap
+	if request.OverlapSeconds > 0 {
+		overlap = time.Duration(request.OverlapSeconds) * time.Second
+	}
+
+	key, plain, err := s.issueApiKey(old.MerchantID, old.Name, old.Scopes)
+	if err != nil {
+		s.logger.Error("failed to create api key", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to rotate api key",
+		}
+	}
+
+	// Kunci lama tetap berlaku selama masa overlap
+	if _, err := s.apiKeyRepository.Expire(old.KeyID, now.Add(overlap)); err != nil {
+		s.logger.Error("failed to expire rotated api key", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to rotate api key",
+		}
+	}
+
+	s.logger.SecurityEvent("api_key_rotated", zap.Int("key_id", old.KeyID), zap.Int("new_key_id", key.KeyID), zap.Int("merchant_id", key.MerchantID), zap.Duration("overlap", overlap))
+
+	so := s.apiKeyMapper.ToMerchantApiKeyResponse(*key)
+	so.ApiKey = plain
+
+	return &response.ApiResponse[*response.MerchantApiKeyResponse]{
+		Status:  "success",
+		Message: "Api key rotated, the old key stops working at the end of the overlap",
+		Data:    so,
+	}, nil
+}
+
+// RevokeApiKey stops a key at once, also one that is in its rotation overlap.
+func (s *merchantService) RevokeApiKey(caller requests.Caller, request requests.RevokeApiKeyRequest) (*response.ApiResponse[*response.MerchantApiKeyResponse], *response.ErrorResponse) {
+	s.apiKeyMu.Lock()
+	defer s.apiKeyMu.Unlock()
+
+	key, errRes := s.findOwnedApiKey(caller, request.KeyID)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	key, err := s.apiKeyRepository.Revoke(key.KeyID)
+	if err != nil {
+		s.logger.Error("failed to revoke api key", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to revoke api key",
+		}
+	}
+
+	s.logger.SecurityEvent("api_key_revoked", zap.Int("key_id", key.KeyID), zap.Int("merchant_id", key.MerchantID), zap.Int("user_id", caller.UserID))
+
+	return &response.ApiResponse[*response.MerchantApiKeyResponse]{
+		Status:  "success",
+		Message: "Api key revoked successfully",
+		Data:    s.apiKeyMapper.ToMerchantApiKeyResponse(*key),
+	}, nil
+}
+
+// MigrateLegacyApiKeys moves the plain text keys merchants had before into
+// the key table as their default key, so existing integrations keep working.
+func (s *merchantService) MigrateLegacyApiKeys() (int, error) {
+	legacy, err := s.merchantRepository.ReadLegacyApiKeys()
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+
+	for merchantID, plain := range legacy {
+		lookup, ok := apikey.Lookup(plain)
+		if !ok {
+			s.logger.Error("legacy api key too short to migrate", zap.Int("merchant_id", merchantID))
+			continue
+		}
+
+		if _, err := s.apiKeyRepository.Create(requests.CreateMerchantApiKeyRequest{
+			MerchantID: merchantID,
+			Name:       defaultApiKeyName,
+			Lookup:     lookup,
+			Hash:       apikey.Hash(plain),
+			Scopes:     allApiKeyScopes,
+		}); err != nil {
+			return migrated, err
+		}
+
+		if err := s.merchantRepository.ClearLegacyApiKey(merchantID); err != nil {
+			return migrated, err
+		}
+
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+func (s *merchantService) findOwnedApiKey(caller requests.Caller, keyID int) (*record.MerchantApiKeyRecord, *response.ErrorResponse) {
+	key, err := s.apiKeyRepository.Read(keyID)
+	if err != nil || !ownsMerchant(s.merchantRepository, caller, key.MerchantID) {
+		s.logger.Error("failed to find api key", zap.Int("key_id", keyID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Api key not found",
+		}
+	}
+
+	return key, nil
+}
+
+// issueApiKey stores a new key and returns it together with the plain text
+// key, which is not kept.
+func (s *merchantService) issueApiKey(merchantID int, name string, scopes []string) (*record.MerchantApiKeyRecord, string, error) {
+	plain := apikey.GenerateApiKey()
+	lookup, _ := apikey.Lookup(plain)
+
+	key, err := s.apiKeyRepository.Create(requests.CreateMerchantApiKeyRequest{
+		MerchantID: merchantID,
+		Name:       name,
+		Lookup:     lookup,
+		Hash:       apikey.Hash(plain),
+		Scopes:     slices.Clone(scopes),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, plain, nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/pkg/signature"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const maxNonceLength = 128
+
+// VerifySignature checks the signature of a request of the merchant. Unsigned
+// requests pass unless the merchant requires signing. A signature is accepted
+// once, and only while its timestamp is within the tolerance of the clock.
+func (s *merchantService) VerifySignature(merchantID int, request requests.SignedRequest) *response.ErrorResponse {
+	merchant, err := s.merchantRepository.Read(merchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	if request.Signature == "" {
+		if merchant.RequireSignature {
+			s.logger.SecurityEvent("signature_missing", zap.Int("merchant_id", merchantID))
+			return &response.ErrorResponse{
+				Status:  "error",
+				Message: "Request signature is required",
+			}
+		}
+
+		return nil
+	}
+
+	if merchant.SigningSecret == "" {
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant has no signing secret",
+		}
+	}
+
+	if request.Nonce == "" || len(request.Nonce) > maxNonceLength {
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Request nonce is missing or too long",
+		}
+	}
+
+	unix, err := strconv.ParseInt(request.Timestamp, 10, 64)
+	if err != nil {
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request timestamp",
+		}
+	}
+
+	signedAt := time.Unix(unix, 0)
+
+	if skew := time.Since(signedAt); skew > s.signatureTolerance || skew < -s.signatureTolerance {
+		s.logger.SecurityEvent("signature_stale", zap.Int("merchant_id", merchantID), zap.Duration("skew", skew))
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Request timestamp is outside the allowed window",
+		}
+	}
+
+	payload := signature.Payload(request.Method, request.Path, request.Timestamp, request.Nonce, request.Body)
+
+	if !signature.Verify(merchant.SigningSecret, payload, request.Signature) {
+		s.logger.SecurityEvent("signature_invalid", zap.Int("merchant_id", merchantID), zap.String("path", request.Path))
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request signature",
+		}
+	}
+
+	// Nonce baru dicatat setelah tanda tangan valid, agar pihak lain tidak bisa menghabiskannya
+	if !s.nonces.Use(strconv.Itoa(merchantID)+":"+request.Nonce, signedAt.Add(s.signatureTolerance)) {
+		s.logger.SecurityEvent("signature_replayed", zap.Int("merchant_id", merchantID), zap.String("path", request.Path))
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Request was already used",
+		}
+	}
+
+	return nil
+}
+
+// RotateSigningSecret gives the merchant a new signing secret. The old one
+// stops working at once.
+func (s *merchantService) RotateSigningSecret(caller requests.Caller, request requests.RotateSigningSecretRequest) (*response.ApiResponse[*response.SigningSecretResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, request.MerchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(request.MerchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	secret, err := signature.GenerateSecret()
+	if err != nil {
+		s.logger.Error("failed to generate signing secret", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to rotate signing secret",
+		}
+	}
+
+	merchant, err = s.merchantRepository.UpdateSigning(requests.UpdateMerchantSigningRequest{
+		MerchantID:       merchant.MerchantID,
+		SigningSecret:    secret,
+		RequireSignature: merchant.RequireSignature,
+	})
+	if err != nil {
+		s.logger.Error("failed to save signing secret", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to rotate signing secret",
+		}
+	}
+
+	s.logger.SecurityEvent("signing_secret_rotated", zap.Int("merchant_id", merchant.MerchantID), zap.Int("user_id", caller.UserID))
+
+	return &response.ApiResponse[*response.SigningSecretResponse]{
+		Status:  "success",
+		Message: "Signing secret rotated, store it now because it cannot be shown again",
+		Data: &response.SigningSecretResponse{
+			MerchantID:       merchant.MerchantID,
+			SigningSecret:    secret,
+			RequireSignature: merchant.RequireSignature,
+		},
+	}, nil
+}
+
+// RequireSignature turns refusing unsigned requests of the merchant on or off.
+func (s *merchantService) RequireSignature(caller requests.Caller, request requests.RequireSignatureRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, request.MerchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(request.MerchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	if request.Required && merchant.SigningSecret == "" {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Create a signing secret before requiring signatures",
+		}
+	}
+
+	merchant, err = s.merchantRepository.UpdateSigning(requests.UpdateMerchantSigningRequest{
+		MerchantID:       merchant.MerchantID,
+		SigningSecret:    merchant.SigningSecret,
+		RequireSignature: request.Required,
+	})
+	if err != nil {
+		s.logger.Error("failed to update signature requirement", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update signature requirement",
+		}
+	}
+
+	s.logger.SecurityEvent("signature_requirement_changed", zap.Int("merchant_id", merchant.MerchantID), zap.Bool("required", request.Required), zap.Int("user_id", caller.UserID))
+
+	return &response.ApiResponse[*response.MerchantResponse]{
+		Status:  "success",
+		Message: "Signature requirement updated successfully",
+		Data:    s.mapper.ToMerchantResponse(*merchant),
+	}, nil
+}
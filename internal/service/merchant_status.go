@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/models"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// merchantStatusStep is one step of the merchant review workflow.
+type merchantStatusStep struct {
+	to string
+	// from maps each status the step may start from to the reason recorded
+	// when the caller gives none. An empty reason means one must be given.
+	from map[string]string
+	// reasons are the reason codes a caller may give.
+	reasons []string
+}
+
+var (
+	merchantSubmitStep = merchantStatusStep{
+		to:   models.MerchantStatusPendingReview,
+		from: map[string]string{models.MerchantStatusDraft: models.MerchantReasonSubmitted},
+	}
+	merchantApproveStep = merchantStatusStep{
+		to: models.MerchantStatusActive,
+		from: map[string]string{
+			models.MerchantStatusPendingReview: models.MerchantReasonKybVerified,
+			models.MerchantStatusSuspended:     models.MerchantReasonReinstated,
+		},
+		reasons: []string{models.MerchantReasonKybVerified, models.MerchantReasonReinstated, models.MerchantReasonOther},
+	}
+	merchantRejectStep = merchantStatusStep{
+		to:      models.MerchantStatusDraft,
+		from:    map[string]string{models.MerchantStatusPendingReview: ""},
+		reasons: []string{models.MerchantReasonKybIncomplete, models.MerchantReasonKybRejected, models.MerchantReasonOther},
+	}
+	merchantSuspendStep = merchantStatusStep{
+		to:      models.MerchantStatusSuspended,
+		from:    map[string]string{models.MerchantStatusActive: ""},
+		reasons: []string{models.MerchantReasonFraudSuspected, models.MerchantReasonHighChargebacks, models.MerchantReasonComplianceReview, models.MerchantReasonOther},
+	}
+	merchantCloseStep = merchantStatusStep{
+		to: models.MerchantStatusClosed,
+		from: map[string]string{
+			models.MerchantStatusDraft:         "",
+			models.MerchantStatusPendingReview: "",
+			models.MerchantStatusActive:        "",
+			models.MerchantStatusSuspended:     "",
+		},
+		reasons: []string{models.MerchantReasonOwnerRequest, models.MerchantReasonKybRejected, models.MerchantReasonFraudSuspected, models.MerchantReasonOther},
+	}
+)
+
+// Submit sends a draft merchant of the caller to review.
+func (s *merchantService) Submit(caller requests.Caller, request requests.ChangeMerchantStatusRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, request.MerchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	return s.changeStatus(caller, request, merchantSubmitStep, "Merchant submitted for review")
+}
+
+// Approve makes a reviewed or suspended merchant active, so its API keys
+// work again.
+func (s *merchantService) Approve(caller requests.Caller, request requests.ChangeMerchantStatusRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	return s.changeStatus(caller, request, merchantApproveStep, "Merchant approved successfully")
+}
+
+// Reject sends a merchant under review back to draft.
+func (s *merchantService) Reject(caller requests.Caller, request requests.ChangeMerchantStatusRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	return s.changeStatus(caller, request, merchantRejectStep, "Merchant sent back to draft")
+}
+
+func (s *merchantService) Suspend(caller requests.Caller, request requests.ChangeMerchantStatusRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	return s.changeStatus(caller, request, merchantSuspendStep, "Merchant suspended successfully")
+}
+
+// Close ends a merchant for good. Its records are kept.
+func (s *merchantService) Close(caller requests.Caller, request requests.ChangeMerchantStatusRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	return s.changeStatus(caller, request, merchantCloseStep, "Merchant closed successfully")
+}
+
+func (s *merchantService) FindStatusHistory(caller requests.Caller, merchantID int) (*response.ApiResponse[[]*response.MerchantStatusChangeResponse], *response.ErrorResponse) {
+	merchant, err := s.merchantRepository.Read(merchantID)
+	if err != nil || !ownsMerchant(s.merchantRepository, caller, merchantID) {
+		s.logger.Error("failed to find merchant", zap.Int("merchant_id", merchantID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	return &response.ApiResponse[[]*response.MerchantStatusChangeResponse]{
+		Status:  "success",
+		Message: "Merchant status history retrieved successfully",
+		Data:    s.mapper.ToMerchantStatusHistoryResponse(merchant.StatusHistory),
+	}, nil
+}
+
+func (s *merchantService) changeStatus(caller requests.Caller, request requests.ChangeMerchantStatusRequest, step merchantStatusStep, message string) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	merchant, err := s.merchantRepository.Read(request.MerchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Int("merchant_id", request.MerchantID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	defaultReason, ok := step.from[merchant.Status]
+	if !ok {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot change merchant status from %s to %s", merchant.Status, step.to),
+		}
+	}
+
+	// Alasan dari pemanggil harus salah satu kode yang diterima langkah ini
+	reason := request.Reason
+	if reason == "" {
+		reason = defaultReason
+	} else if !slices.Contains(step.reasons, reason) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid reason code",
+		}
+	}
+
+	if reason == "" {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Reason code is required",
+		}
+	}
+
+	updated, err := s.merchantRepository.UpdateStatus(requests.UpdateMerchantStatusRequest{
+		MerchantID: merchant.MerchantID,
+		Status:     step.to,
+		Reason:     reason,
+		Note:       request.Note,
+		ChangedBy:  caller.UserID,
+	})
+	if err != nil {
+		s.logger.Error("failed to update merchant status", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update merchant status",
+		}
+	}
+
+	s.logger.Info("merchant status changed",
+		zap.Int("merchant_id", merchant.MerchantID),
+		zap.String("from", merchant.Status),
+		zap.String("to", updated.Status),
+		zap.String("reason", reason),
+		zap.Int("changed_by", caller.UserID),
+	)
+
+	return &response.ApiResponse[*response.MerchantResponse]{
+		Status:  "success",
+		Message: message,
+		Data:    s.mapper.ToMerchantResponse(*updated),
+	}, nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/repository"
+)
+
+// ownsCard reports whether caller may act on the card with cardNumber. Admins
+// may act on every card, other users only on their own cards. Resources on a
+// card the caller does not own are reported as not found, so their IDs do not
+// leak.
+func ownsCard(cards repository.CardRepository, caller requests.Caller, cardNumber string) bool {
+	if caller.IsAdmin() {
+		return true
+	}
+
+	owned, err := cards.ReadByUsersID(caller.UserID)
+	if err != nil {
+		return false
+	}
+
+	for _, card := range owned {
+		if card.CardNumber == cardNumber {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ownsMerchant reports whether caller may manage the merchant with
+// merchantID. Admins may manage every merchant, other users only the ones
+// they own.
+func ownsMerchant(merchants repository.MerchantRepository, caller requests.Caller, merchantID int) bool {
+	merchant, err := merchants.Read(merchantID)
+	if err != nil {
+		return false
+	}
+
+	return caller.IsAdmin() || merchant.UserID == caller.UserID
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/repository"
+	"payment-mutex/pkg/hash"
+	"payment-mutex/pkg/logger"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PinPolicy decides when money-moving requests need the transaction PIN and
+// how long a user is locked out after too many wrong PINs in a row.
+type PinPolicy struct {
+	// Threshold is the largest amount that can be moved without a PIN.
+	Threshold   int
+	MaxAttempts int
+	Lockout     time.Duration
+}
+
+type pinService struct {
+	userRepository repository.UserRepository
+	hash           hash.Hashing
+	logger         logger.Logger
+	policy         PinPolicy
+
+	// mu menjaga locks; setiap user punya lock sendiri agar bcrypt satu user tidak menahan user lain
+	mu    sync.Mutex
+	locks map[int]*userLock
+}
+
+// userLock serializes the PIN checks of one user. waiters counts the callers
+// holding or waiting for it, so it can be dropped once nobody needs it.
+type userLock struct {
+	mu      sync.Mutex
+	waiters int
+}
+
+func NewPinService(userRepository repository.UserRepository, hash hash.Hashing, logger logger.Logger, policy PinPolicy) *pinService {
+	return &pinService{
+		userRepository: userRepository,
+		hash:           hash,
+		logger:         logger,
+		policy:         policy,
+		locks:          make(map[int]*userLock),
+	}
+}
+
+func (s *pinService) SetPin(caller requests.Caller, request requests.SetPinRequest) (*response.ApiResponse[string], *response.ErrorResponse) {
+	user, err := s.userRepository.Read(caller.UserID)
+	if err != nil {
+		s.logger.Error("failed to find user", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "User not found",
+		}
+	}
+
+	if user.Pin != nil {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transaction PIN is already set, change it instead",
+		}
+	}
+
+	if user.Password == nil || s.hash.ComparePassword(*user.Password, request.Password) != nil {
+		s.logger.Error("wrong password while setting pin", zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid password",
+		}
+	}
+
+	if errRes := s.savePin(caller.UserID, request.Pin); errRes != nil {
+		return nil, errRes
+	}
+
+	return &response.ApiResponse[string]{
+		Status:  "success",
+		Message: "Transaction PIN set successfully",
+		Data:    "Transaction PIN has been set",
+	}, nil
+}
+
+func (s *pinService) ChangePin(caller requests.Caller, request requests.ChangePinRequest) (*response.ApiResponse[string], *response.ErrorResponse) {
+	if errRes := s.Verify(caller, request.OldPin); errRes != nil {
+		return nil, errRes
+	}
+
+	if errRes := s.savePin(caller.UserID, request.Pin); errRes != nil {
+		return nil, errRes
+	}
+
+	return &response.ApiResponse[string]{
+		Status:  "success",
+		Message: "Transaction PIN changed successfully",
+		Data:    "Transaction PIN has been changed",
+	}, nil
+}
+
+// VerifyAmount asks for the PIN when amount is above the threshold of the
+// policy.
+func (s *pinService) VerifyAmount(caller requests.Caller, pin string, amount int) *response.ErrorResponse {
+	if amount <= s.policy.Threshold {
+		return nil
+	}
+
+	return s.Verify(caller, pin)
+}
+
+// Verify checks pin against the transaction PIN of caller. Every wrong PIN
+// counts towards the lockout, and a correct one resets the count. Checks of
+// the same user run one at a time, so wrong PINs sent in parallel are all
+// counted; checks of different users do not wait for each other.
+func (s *pinService) Verify(caller requests.Caller, pin string) *response.ErrorResponse {
+	unlock := s.lockUser(caller.UserID)
+	defer unlock()
+
+	user, err := s.userRepository.Read(caller.UserID)
+	if err != nil {
+		s.logger.Error("failed to find user", zap.Error(err))
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "User not found",
+		}
+	}
+
+	if user.Pin == nil {
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transaction PIN is not set",
+		}
+	}
+
+	now := time.Now()
+
+	if user.PinLockedUntil != nil && now.Before(*user.PinLockedUntil) {
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transaction PIN is locked, try again later",
+		}
+	}
+
+	if pin == "" {
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transaction PIN is required",
+		}
+	}
+
+	if s.hash.ComparePassword(*user.Pin, pin) == nil {
+		if user.PinFailedAttempts > 0 || user.PinLockedUntil != nil {
+			if _, err := s.userRepository.UpdatePinAttempts(user.UserID, 0, nil); err != nil {
+				s.logger.Error("failed to reset pin attempts", zap.Error(err))
+			}
+		}
+
+		return nil
+	}
+
+	// Kunci berakhir dengan sendirinya, hitungan dimulai lagi dari nol
+	attempts := user.PinFailedAttempts + 1
+	var lockedUntil *time.Time
+
+	if attempts >= s.policy.MaxAttempts {
+		until := now.Add(s.policy.Lockout)
+		lockedUntil = &until
+		attempts = 0
+	}
+
+	if _, err := s.userRepository.UpdatePinAttempts(user.UserID, attempts, lockedUntil); err != nil {
+		s.logger.Error("failed to record wrong pin", zap.Error(err))
+	}
+
+	s.logger.Error("wrong transaction pin", zap.Int("user_id", user.UserID), zap.Bool("locked", lockedUntil != nil))
+
+	if lockedUntil != nil {
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Too many wrong PINs, transaction PIN is locked",
+		}
+	}
+
+	return &response.ErrorResponse{
+		Status:  "error",
+		Message: "Invalid transaction PIN",
+	}
+}
+
+// lockUser takes the lock of userID and returns the function that releases
+// it.
+func (s *pinService) lockUser(userID int) func() {
+	s.mu.Lock()
+	lock, ok := s.locks[userID]
+	if !ok {
+		lock = &userLock{}
+		s.locks[userID] = lock
+	}
+	lock.waiters++
+	s.mu.Unlock()
+
+	lock.mu.Lock()
+
+	return func() {
+		lock.mu.Unlock()
+
+		s.mu.Lock()
+		lock.waiters--
+		if lock.waiters == 0 {
+			delete(s.locks, userID)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *pinService) savePin(userID int, pin string) *response.ErrorResponse {
+	hashed, err := s.hash.HashPassword(pin)
+	if err != nil {
+		s.logger.Error("failed to hash pin", zap.Error(err))
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to save transaction PIN",
+		}
+	}
+
+	if _, err := s.userRepository.UpdatePin(userID, hashed); err != nil {
+		s.logger.Error("failed to save pin", zap.Error(err))
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to save transaction PIN",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	recordmapper "payment-mutex/internal/mapper/record"
+	"payment-mutex/internal/repository"
+	"payment-mutex/pkg/hash"
+	"payment-mutex/pkg/logger"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// newTestPinService returns a PIN service and a caller whose PIN is 123456.
+func newTestPinService(t *testing.T, policy PinPolicy) (*pinService, requests.Caller) {
+	t.Helper()
+
+	users := repository.NewUserRepository(recordmapper.NewUserRecordMapper())
+
+	user, err := users.Create(requests.CreateUserRequest{FirstName: "A", LastName: "B", Email: "a@example.com", Password: "x"})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	s := NewPinService(users, *hash.NewHashingPassword(), logger.Logger{Log: zap.NewNop()}, policy)
+
+	if errRes := s.savePin(user.UserID, "123456"); errRes != nil {
+		t.Fatalf("save pin: %s", errRes.Message)
+	}
+
+	return s, requests.Caller{UserID: user.UserID}
+}
+
+func TestPinVerifyLocksAfterWrongPins(t *testing.T) {
+	s, caller := newTestPinService(t, PinPolicy{MaxAttempts: 3, Lockout: time.Hour})
+
+	tests := []struct {
+		pin  string
+		want string
+	}{
+		{"000000", "Invalid transaction PIN"},
+		{"123456", ""},
+		// PIN yang benar mengulang hitungan dari nol
+		{"000000", "Invalid transaction PIN"},
+		{"000000", "Invalid transaction PIN"},
+		{"000000", "Too many wrong PINs, transaction PIN is locked"},
+		{"123456", "Transaction PIN is locked, try again later"},
+	}
+
+	for i, tt := range tests {
+		if got := errorMessage(s.Verify(caller, tt.pin)); got != tt.want {
+			t.Errorf("attempt %d with %s: got %q, want %q", i+1, tt.pin, got, tt.want)
+		}
+	}
+}
+
+func TestPinVerifyCountsParallelWrongPins(t *testing.T) {
+	s, caller := newTestPinService(t, PinPolicy{MaxAttempts: 3, Lockout: time.Hour})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			s.Verify(caller, "000000")
+		}()
+	}
+
+	wg.Wait()
+
+	if got := errorMessage(s.Verify(caller, "123456")); got != "Transaction PIN is locked, try again later" {
+		t.Errorf("after 3 parallel wrong PINs: got %q, want the PIN to be locked", got)
+	}
+
+	if len(s.locks) != 0 {
+		t.Errorf("%d user locks left behind", len(s.locks))
+	}
+}
+
+func errorMessage(errRes *response.ErrorResponse) string {
+	if errRes == nil {
+		return ""
+	}
+
+	return errRes.Message
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/ledger"
 	responseMapper "payment-mutex/internal/mapper/response"
 	"payment-mutex/internal/repository"
 	"payment-mutex/pkg/logger"
@@ -14,6 +15,7 @@ import (
 type saldoService struct {
 	cardRepository  repository.CardRepository
 	saldoRepository repository.SaldoRepository
+	ledger          *ledger.Ledger
 	logger          logger.Logger
 	mapper          responseMapper.SaldoResponseMapper
 }
@@ -21,12 +23,14 @@ type saldoService struct {
 func NewSaldoService(
 	cardRepository repository.CardRepository,
 	saldoRepository repository.SaldoRepository,
+	ledger *ledger.Ledger,
 	logger logger.Logger,
 	mapper responseMapper.SaldoResponseMapper,
 ) *saldoService {
 	return &saldoService{
 		cardRepository:  cardRepository,
 		saldoRepository: saldoRepository,
+		ledger:          ledger,
 		logger:          logger,
 		mapper:          mapper,
 	}
@@ -76,7 +80,7 @@ func (s *saldoService) FindAll(page int, pageSize int, search string) (*response
 	}, nil
 }
 
-func (s *saldoService) FindById(saldoID int) (*response.ApiResponse[*response.SaldoResponse], *response.ErrorResponse) {
+func (s *saldoService) FindById(caller requests.Caller, saldoID int) (*response.ApiResponse[*response.SaldoResponse], *response.ErrorResponse) {
 	saldo, err := s.saldoRepository.Read(saldoID)
 	if err != nil {
 		s.logger.Error("failed find saldo by id", zap.Error(err))
@@ -86,6 +90,14 @@ func (s *saldoService) FindById(saldoID int) (*response.ApiResponse[*response.Sa
 		}
 	}
 
+	if !ownsCard(s.cardRepository, caller, saldo.CardNumber) {
+		s.logger.Error("saldo belongs to another user", zap.Int("saldo_id", saldoID), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Saldo record not found",
+		}
+	}
+
 	so := s.mapper.ToSaldoResponse(*saldo)
 
 	return &response.ApiResponse[*response.SaldoResponse]{
@@ -95,7 +107,7 @@ func (s *saldoService) FindById(saldoID int) (*response.ApiResponse[*response.Sa
 	}, nil
 }
 
-func (s *saldoService) Create(requests requests.CreateSaldoRequest) (*response.ApiResponse[*response.SaldoResponse], *response.ErrorResponse) {
+func (s *saldoService) Create(caller requests.Caller, requests requests.CreateSaldoRequest) (*response.ApiResponse[*response.SaldoResponse], *response.ErrorResponse) {
 	_, err := s.cardRepository.ReadByCardNumber(requests.CardNumber)
 	if err != nil {
 		s.logger.Error("failed to find card", zap.Error(err))
@@ -105,6 +117,18 @@ func (s *saldoService) Create(requests requests.CreateSaldoRequest) (*response.A
 		}
 	}
 
+	if !ownsCard(s.cardRepository, caller, requests.CardNumber) {
+		s.logger.Error("card belongs to another user", zap.String("card_number", requests.CardNumber), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Card not found",
+		}
+	}
+
+	// Saldo awal dicatat sebagai entri jurnal, bukan ditulis langsung
+	openingBalance := requests.TotalBalance
+	requests.TotalBalance = 0
+
 	saldo, err := s.saldoRepository.Create(requests)
 	if err != nil {
 		s.logger.Error("failed to create saldo", zap.Error(err))
@@ -114,6 +138,30 @@ func (s *saldoService) Create(requests requests.CreateSaldoRequest) (*response.A
 		}
 	}
 
+	if openingBalance != 0 {
+		if _, err := s.ledger.Post(ledger.AdjustmentEntry(saldo.CardNumber, openingBalance, saldo.SaldoID)); err != nil {
+			s.logger.Error("failed to post opening balance", zap.Error(err))
+
+			if deleteErr := s.saldoRepository.Delete(saldo.SaldoID); deleteErr != nil {
+				s.logger.Error("failed to remove saldo after posting failure", zap.Error(deleteErr))
+			}
+
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Failed to create saldo record",
+			}
+		}
+
+		saldo, err = s.saldoRepository.Read(saldo.SaldoID)
+		if err != nil {
+			s.logger.Error("failed to read created saldo", zap.Error(err))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Failed to create saldo record",
+			}
+		}
+	}
+
 	so := s.mapper.ToSaldoResponse(*saldo)
 
 	return &response.ApiResponse[*response.SaldoResponse]{
@@ -124,16 +172,34 @@ func (s *saldoService) Create(requests requests.CreateSaldoRequest) (*response.A
 }
 
 func (s *saldoService) Update(requests requests.UpdateSaldoRequest) (*response.ApiResponse[*response.SaldoResponse], *response.ErrorResponse) {
-	_, err := s.cardRepository.ReadByCardNumber(requests.CardNumber)
+	existingSaldo, err := s.saldoRepository.Read(requests.SaldoID)
 	if err != nil {
-		s.logger.Error("failed to find card", zap.Error(err))
+		s.logger.Error("failed to find saldo", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Card not found",
+			Message: "Saldo record not found",
+		}
+	}
+
+	// Saldo terikat pada akun kartu di jurnal, sehingga nomor kartu tidak bisa diganti
+	if existingSaldo.CardNumber != requests.CardNumber {
+		s.logger.Error("card number of saldo cannot be changed", zap.Int("saldoID", requests.SaldoID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Card number of a saldo cannot be changed",
+		}
+	}
+
+	// Selisih dihitung ledger di bawah lock-nya agar tidak menimpa transfer yang berjalan bersamaan
+	if _, err := s.ledger.SetBalance(existingSaldo.SaldoID, requests.TotalBalance); err != nil {
+		s.logger.Error("failed to post balance adjustment", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update saldo record",
 		}
 	}
 
-	saldo, err := s.saldoRepository.Update(requests)
+	saldo, err := s.saldoRepository.Read(requests.SaldoID)
 	if err != nil {
 		s.logger.Error("failed to update saldo", zap.Error(err))
 		return nil, &response.ErrorResponse{
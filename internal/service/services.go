@@ -1,24 +1,34 @@
 package service
 
 import (
+	"payment-mutex/internal/ledger"
 	responseMapper "payment-mutex/internal/mapper/response"
 	"payment-mutex/internal/repository"
 	"payment-mutex/pkg/auth"
 	"payment-mutex/pkg/hash"
 	"payment-mutex/pkg/logger"
+	"payment-mutex/pkg/notifier"
+	"time"
 )
 
 type Services struct {
 	Auth        AuthService
+	Account     AccountService
 	Saldo       SaldoService
 	Topup       TopupService
 	Transfer    TransferService
 	Withdraw    WithdrawService
 	User        UserService
+	Pin         PinService
 	Card        CardService
 	Transaction TransactionService
 	Dashboard   DashboardService
 	Merchant    MerchantService
+	Ledger      LedgerService
+	Webhook     WebhookService
+	Settlement  SettlementService
+	FeePlan     FeePlanService
+	Checkout    CheckoutService
 }
 
 type Deps struct {
@@ -27,27 +37,56 @@ type Deps struct {
 	Hash           *hash.Hashing
 	Token          auth.TokenManager
 	MapperResponse responseMapper.ResponseMapper
+
+	WithdrawHoldTTL  time.Duration
+	AuthorizationTTL time.Duration
+	// CheckoutSessionTTL is how long a customer has to pay a checkout session.
+	CheckoutSessionTTL time.Duration
+	// ApiKeyRotationOverlap is how long a rotated merchant key keeps working.
+	ApiKeyRotationOverlap time.Duration
+	// SignatureTolerance is how far the timestamp of a signed merchant
+	// request may be from the clock.
+	SignatureTolerance time.Duration
+	Pin                PinPolicy
+	Login              LoginPolicy
+	Account            AccountPolicy
+	Notifier           notifier.Notifier
+	Webhook            WebhookPolicy
+	Settlement         SettlementPolicy
+	// TotpIssuer is the name authenticator apps show next to the account.
+	TotpIssuer string
 }
 
 func NewServices(deps Deps) *Services {
+	ledger := ledger.NewLedger(deps.Repository.Journal, deps.Repository.Saldo, deps.Repository)
+	pin := NewPinService(deps.Repository.User, *deps.Hash, deps.Logger, deps.Pin)
+	guard := newLoginGuard(deps.Repository.User, deps.Logger, deps.Login)
+	account := NewAccountService(*deps.Hash, deps.Repository.User, deps.Repository.UserToken, deps.Repository.Session, deps.Token, deps.Notifier, deps.Logger, deps.Account)
+	webhook := NewWebhookService(deps.Repository.WebhookEndpoint, deps.Repository.WebhookDelivery, deps.Repository.Merchant, deps.Logger, deps.MapperResponse.WebhookResponseMapper, deps.Webhook)
+	transaction := NewTransactionService(
+		deps.Repository.Merchant,
+		deps.Repository.Card, deps.Repository.Saldo, deps.Repository.Transaction, deps.Repository.Refund, deps.Repository.Authorization, deps.Repository.SettlementItem, deps.Repository.FeePlan, ledger, deps.Logger, deps.MapperResponse.TransactionResponseMapper, deps.MapperResponse.RefundResponseMapper, deps.MapperResponse.AuthorizationResponseMapper, webhook, deps.AuthorizationTTL)
+
 	return &Services{
-		Auth:  NewAuthService(*deps.Hash, deps.Repository.User, deps.Token, deps.Logger, deps.MapperResponse.UserResponseMapper),
-		Saldo: NewSaldoService(deps.Repository.Card, deps.Repository.Saldo, deps.Logger, deps.MapperResponse.SaldoResponseMapper),
-		Topup: NewTopupService(deps.Repository.Card, deps.Repository.Topup, deps.Repository.Saldo, deps.Logger, deps.MapperResponse.TopupResponseMapper),
+		Auth:    NewAuthService(*deps.Hash, deps.Repository.User, deps.Repository.Session, guard, account, deps.Token, deps.Logger, deps.MapperResponse.UserResponseMapper, deps.TotpIssuer),
+		Account: account,
+		Saldo:   NewSaldoService(deps.Repository.Card, deps.Repository.Saldo, ledger, deps.Logger, deps.MapperResponse.SaldoResponseMapper),
+		Topup:   NewTopupService(deps.Repository.Card, deps.Repository.Topup, deps.Repository.Saldo, ledger, deps.Logger, deps.MapperResponse.TopupResponseMapper),
 		Transfer: NewTransferService(
 			deps.Repository.User,
 			deps.Repository.Card,
 			deps.Repository.Transfer,
 			deps.Repository.Saldo,
+			ledger,
+			pin,
 			deps.Logger,
 			deps.MapperResponse.TransferResponseMapper,
 		),
-		Withdraw: NewWithdrawService(deps.Repository.User, deps.Repository.Withdraw, deps.Repository.Saldo, deps.Logger, deps.MapperResponse.WithdrawResponseMapper),
-		User:     NewUserService(deps.Repository.User, deps.Logger, deps.MapperResponse.UserResponseMapper),
-		Card:     NewCardService(deps.Repository.Card, deps.Repository.User, deps.Repository.Saldo, deps.Logger, deps.MapperResponse.CardResponseMapper),
-		Transaction: NewTransactionService(
-			deps.Repository.Merchant,
-			deps.Repository.Card, deps.Repository.Saldo, deps.Repository.Transaction, deps.Logger, deps.MapperResponse.TransactionResponseMapper),
+		Withdraw:    NewWithdrawService(deps.Repository.User, deps.Repository.Card, deps.Repository.Withdraw, deps.Repository.Saldo, deps.Repository.WithdrawHold, ledger, pin, deps.Logger, deps.MapperResponse.WithdrawResponseMapper, deps.MapperResponse.WithdrawHoldResponseMapper, deps.WithdrawHoldTTL),
+		User:        NewUserService(deps.Repository.User, guard, deps.Logger, deps.MapperResponse.UserResponseMapper),
+		Pin:         pin,
+		Card:        NewCardService(deps.Repository.Card, deps.Repository.User, deps.Repository.Saldo, deps.Logger, deps.MapperResponse.CardResponseMapper),
+		Transaction: transaction,
 		Dashboard: NewDashboardService(
 			deps.Repository.Card, deps.Repository.Saldo, deps.Repository.Transaction, deps.Repository.Topup, deps.Repository.Withdraw, deps.Repository.Transaction,
 			deps.Repository.Merchant,
@@ -55,8 +94,50 @@ func NewServices(deps Deps) *Services {
 		),
 		Merchant: NewMerchantService(
 			deps.Repository.Merchant,
+			deps.Repository.MerchantApiKey,
+			deps.Repository.User,
 			deps.Logger,
 			deps.MapperResponse.MerchantResponseMapper,
+			deps.MapperResponse.MerchantApiKeyResponseMapper,
+			deps.ApiKeyRotationOverlap,
+			deps.SignatureTolerance,
+		),
+		Ledger: NewLedgerService(
+			deps.Repository.Journal,
+			ledger,
+			deps.Logger,
+			deps.MapperResponse.JournalResponseMapper,
+		),
+		Webhook: webhook,
+		Settlement: NewSettlementService(
+			deps.Repository.Merchant,
+			deps.Repository.Card,
+			deps.Repository.SettlementItem,
+			deps.Repository.SettlementBatch,
+			ledger,
+			webhook,
+			deps.Logger,
+			deps.MapperResponse.SettlementResponseMapper,
+			deps.MapperResponse.MerchantResponseMapper,
+			deps.Settlement,
+		),
+		FeePlan: NewFeePlanService(
+			deps.Repository.FeePlan,
+			deps.Repository.Merchant,
+			deps.Logger,
+			deps.MapperResponse.FeePlanResponseMapper,
+			deps.MapperResponse.MerchantResponseMapper,
+		),
+		Checkout: NewCheckoutService(
+			deps.Repository.CheckoutSession,
+			deps.Repository.Merchant,
+			deps.Repository.Card,
+			transaction,
+			pin,
+			webhook,
+			deps.Logger,
+			deps.MapperResponse.CheckoutSessionResponseMapper,
+			deps.CheckoutSessionTTL,
 		),
 	}
 }
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"errors"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/ledger"
+	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/models"
+	"payment-mutex/internal/repository"
+	"payment-mutex/pkg/logger"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SettlementPolicy decides when the daily batches of merchants settled in
+// batches close. The fee kept from each payment comes from the fee plan of
+// the merchant.
+type SettlementPolicy struct {
+	// CutoffHour is the hour of the day, in local time, at which the open
+	// items of the day are closed into batches.
+	CutoffHour int
+}
+
+// Cutoff returns the last cutoff at or before now.
+func (p SettlementPolicy) Cutoff(now time.Time) time.Time {
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), p.CutoffHour, 0, 0, 0, now.Location())
+	if cutoff.After(now) {
+		cutoff = cutoff.AddDate(0, 0, -1)
+	}
+
+	return cutoff
+}
+
+// errNegativeSettlement is returned when the open items of a merchant would
+// pay out less than nothing. They stay open until new payments cover them.
+var errNegativeSettlement = errors.New("open settlement items do not cover their refunds and fees")
+
+type settlementService struct {
+	merchantRepository repository.MerchantRepository
+	cardRepository     repository.CardRepository
+	itemRepository     repository.SettlementItemRepository
+	batchRepository    repository.SettlementBatchRepository
+	ledger             *ledger.Ledger
+	webhooks           WebhookService
+	logger             logger.Logger
+	mapper             responseMapper.SettlementResponseMapper
+	merchantMapper     responseMapper.MerchantResponseMapper
+	policy             SettlementPolicy
+
+	// mu menjaga agar satu item tidak masuk ke dua batch yang ditutup bersamaan
+	mu sync.Mutex
+}
+
+func NewSettlementService(
+	merchantRepository repository.MerchantRepository,
+	cardRepository repository.CardRepository,
+	itemRepository repository.SettlementItemRepository,
+	batchRepository repository.SettlementBatchRepository,
+	ledger *ledger.Ledger,
+	webhooks WebhookService,
+	logger logger.Logger,
+	mapper responseMapper.SettlementResponseMapper,
+	merchantMapper responseMapper.MerchantResponseMapper,
+	policy SettlementPolicy,
+) *settlementService {
+	return &settlementService{
+		merchantRepository: merchantRepository,
+		cardRepository:     cardRepository,
+		itemRepository:     itemRepository,
+		batchRepository:    batchRepository,
+		ledger:             ledger,
+		webhooks:           webhooks,
+		logger:             logger,
+		mapper:             mapper,
+		merchantMapper:     merchantMapper,
+		policy:             policy,
+	}
+}
+
+// CloseDue closes the items created before the last cutoff into one batch
+// per merchant and pays them out. It returns how many batches were paid.
+func (s *settlementService) CloseDue() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.policy.Cutoff(time.Now())
+
+	items, err := s.itemRepository.ReadOpen(0, cutoff)
+	if err != nil {
+		s.logger.Error("failed to find open settlement items", zap.Error(err))
+		return 0
+	}
+
+	byMerchant := make(map[int][]*record.SettlementItemRecord)
+	merchantIDs := make([]int, 0)
+
+	for _, item := range items {
+		if _, ok := byMerchant[item.MerchantID]; !ok {
+			merchantIDs = append(merchantIDs, item.MerchantID)
+		}
+
+		byMerchant[item.MerchantID] = append(byMerchant[item.MerchantID], item)
+	}
+
+	paid := 0
+
+	for _, merchantID := range merchantIDs {
+		batch, err := s.closeBatch(merchantID, byMerchant[merchantID], cutoff)
+		if errors.Is(err, errNegativeSettlement) {
+			continue
+		}
+
+		if err != nil {
+			s.logger.Error("failed to close settlement batch", zap.Int("merchant_id", merchantID), zap.Error(err))
+			continue
+		}
+
+		if batch.Status == models.SettlementBatchPaid {
+			paid++
+		}
+	}
+
+	return paid
+}
+
+// Close pays out every open item of a merchant now instead of at the next
+// cutoff.
+func (s *settlementService) Close(request requests.CloseSettlementRequest) (*response.ApiResponse[*response.SettlementBatchResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	items, err := s.itemRepository.ReadOpen(request.MerchantID, now)
+	if err != nil {
+		s.logger.Error("failed to find open settlement items", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to close settlement batch",
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant has nothing to settle",
+		}
+	}
+
+	batch, err := s.closeBatch(request.MerchantID, items, now)
+	if errors.Is(err, errNegativeSettlement) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Open items do not cover their refunds and fees",
+		}
+	}
+
+	if err != nil {
+		s.logger.Error("failed to close settlement batch", zap.Int("merchant_id", request.MerchantID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to close settlement batch",
+		}
+	}
+
+	if batch.Status != models.SettlementBatchPaid {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to pay out settlement batch: " + batch.Error,
+		}
+	}
+
+	return &response.ApiResponse[*response.SettlementBatchResponse]{
+		Status:  "success",
+		Message: "Settlement batch paid successfully",
+		Data:    s.withItems(batch),
+	}, nil
+}
+
+func (s *settlementService) FindBatches(caller requests.Caller, merchantID int, page int, pageSize int) (*response.APIResponsePagination[[]*response.SettlementBatchResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, merchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	batches, totalRecords, err := s.batchRepository.ReadByMerchantID(merchantID, page, pageSize)
+	if err != nil {
+		s.logger.Error("failed to fetch settlement batches", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to fetch settlement batches",
+		}
+	}
+
+	totalPages := (totalRecords + pageSize - 1) / pageSize
+
+	return &response.APIResponsePagination[[]*response.SettlementBatchResponse]{
+		Status:  "success",
+		Message: "Settlement batches retrieved successfully",
+		Data:    s.mapper.ToSettlementBatchesResponse(batches),
+		Meta: response.PaginationMeta{
+			CurrentPage:  page,
+			PageSize:     pageSize,
+			TotalPages:   totalPages,
+			TotalRecords: totalRecords,
+		},
+	}, nil
+}
+
+// FindBatch returns a batch together with the items it paid out.
+func (s *settlementService) FindBatch(caller requests.Caller, batchID int) (*response.ApiResponse[*response.SettlementBatchResponse], *response.ErrorResponse) {
+	batch, err := s.batchRepository.Read(batchID)
+	if err != nil || !ownsMerchant(s.merchantRepository, caller, batch.MerchantID) {
+		s.logger.Error("failed to find settlement batch", zap.Int("batch_id", batchID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Settlement batch not found",
+		}
+	}
+
+	return &response.ApiResponse[*response.SettlementBatchResponse]{
+		Status:  "success",
+		Message: "Settlement batch retrieved successfully",
+		Data:    s.withItems(batch),
+	}, nil
+}
+
+// FindPending returns the pending balance of a merchant and the open items
+// the next batch will pay out.
+func (s *settlementService) FindPending(caller requests.Caller, merchantID int) (*response.ApiResponse[*response.PendingSettlementResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, merchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(merchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	now := time.Now()
+
+	items, err := s.itemRepository.ReadOpen(merchantID, now)
+	if err != nil {
+		s.logger.Error("failed to find open settlement items", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to fetch pending settlement",
+		}
+	}
+
+	balance, err := s.ledger.PendingBalance(merchantID)
+	if err != nil {
+		s.logger.Error("failed to compute pending balance", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to fetch pending settlement",
+		}
+	}
+
+	fees := 0
+	for _, item := range items {
+		fees += item.Fee
+	}
+
+	return &response.ApiResponse[*response.PendingSettlementResponse]{
+		Status:  "success",
+		Message: "Pending settlement retrieved successfully",
+		Data: &response.PendingSettlementResponse{
+			MerchantID:     merchantID,
+			SettlementMode: merchant.SettlementMode,
+			PendingBalance: balance,
+			FeeAmount:      fees,
+			NetAmount:      balance - fees,
+			NextCutoff:     s.policy.Cutoff(now).AddDate(0, 0, 1),
+			Items:          s.mapper.ToSettlementItemsResponse(items),
+		},
+	}, nil
+}
+
+// UpdateMode switches how new transactions of a merchant are settled.
+// Transactions already made keep the mode they were made in.
+func (s *settlementService) UpdateMode(request requests.UpdateSettlementModeRequest) (*response.ApiResponse[*response.MerchantResponse], *response.ErrorResponse) {
+	merchant, err := s.merchantRepository.UpdateSettlementMode(request.MerchantID, request.Mode)
+	if err != nil {
+		s.logger.Error("failed to update settlement mode", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	s.logger.Info("settlement mode changed", zap.Int("merchant_id", merchant.MerchantID), zap.String("mode", merchant.SettlementMode))
+
+	return &response.ApiResponse[*response.MerchantResponse]{
+		Status:  "success",
+		Message: "Settlement mode updated successfully",
+		Data:    s.merchantMapper.ToMerchantResponse(*merchant),
+	}, nil
+}
+
+// closeBatch puts items into a new batch and pays it out to the merchant
+// card. The items, the payout entry and the paid status are applied together;
+// when that fails the batch is kept as failed and the items stay open for the
+// next run. It must be called with s.mu held.
+func (s *settlementService) closeBatch(merchantID int, items []*record.SettlementItemRecord, cutoff time.Time) (*record.SettlementBatchRecord, error) {
+	request := requests.CreateSettlementBatchRequest{
+		MerchantID: merchantID,
+		Cutoff:     cutoff,
+		ItemCount:  len(items),
+	}
+
+	itemIDs := make([]int, 0, len(items))
+
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ItemID)
+
+		if item.Amount > 0 {
+			request.GrossAmount += item.Amount
+		} else {
+			request.RefundAmount -= item.Amount
+		}
+
+		request.FeeAmount += item.Fee
+	}
+
+	request.NetAmount = request.GrossAmount - request.RefundAmount - request.FeeAmount
+
+	if request.NetAmount < 0 {
+		return nil, errNegativeSettlement
+	}
+
+	merchant, err := s.merchantRepository.Read(merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantCard, err := s.cardRepository.ReadByUserID(merchant.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	request.CardNumber = merchantCard.CardNumber
+
+	batch, err := s.batchRepository.Create(request)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := s.ledger.Begin()
+	unit.SettlementItem.AssignBatch(itemIDs, batch.BatchID)
+
+	// Batch yang isinya saling meniadakan ditutup tanpa entri jurnal
+	if request.NetAmount+request.FeeAmount != 0 {
+		entry := ledger.SettlementPayoutEntry(merchantID, merchantCard.CardNumber, request.NetAmount, request.FeeAmount, batch.BatchID)
+
+		if _, err := s.ledger.Stage(unit, entry); err != nil {
+			unit.Rollback()
+			return s.failBatch(batch, err)
+		}
+	}
+
+	pending := unit.SettlementBatch.UpdateStatus(requests.UpdateSettlementBatchStatus{
+		BatchID: batch.BatchID,
+		Status:  models.SettlementBatchPaid,
+	})
+
+	if err := s.ledger.Commit(unit); err != nil {
+		return s.failBatch(batch, err)
+	}
+
+	batch = pending.Value()
+
+	s.logger.Info("settlement batch paid", zap.Int("batch_id", batch.BatchID), zap.Int("merchant_id", merchantID), zap.Int("net_amount", batch.NetAmount))
+	s.webhooks.Publish(merchantID, models.WebhookEventSettlementPaid, s.withItems(batch))
+
+	return batch, nil
+}
+
+func (s *settlementService) failBatch(batch *record.SettlementBatchRecord, cause error) (*record.SettlementBatchRecord, error) {
+	s.logger.Error("failed to pay out settlement batch", zap.Int("batch_id", batch.BatchID), zap.Error(cause))
+
+	failed, err := s.batchRepository.UpdateStatus(requests.UpdateSettlementBatchStatus{
+		BatchID: batch.BatchID,
+		Status:  models.SettlementBatchFailed,
+		Error:   cause.Error(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return failed, nil
+}
+
+func (s *settlementService) withItems(batch *record.SettlementBatchRecord) *response.SettlementBatchResponse {
+	so := s.mapper.ToSettlementBatchResponse(*batch)
+
+	items, err := s.itemRepository.ReadByBatchID(batch.BatchID)
+	if err != nil {
+		s.logger.Error("failed to find settlement batch items", zap.Int("batch_id", batch.BatchID), zap.Error(err))
+		return so
+	}
+
+	so.Items = s.mapper.ToSettlementItemsResponse(items)
+
+	return so
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/ledger"
+	"payment-mutex/internal/models"
+	"testing"
+	"time"
+)
+
+// batchMerchant creates a merchant settled in batches with a fee of 1% plus
+// 500 on every payment.
+func (p *testPlatform) batchMerchant(t *testing.T) (int, string) {
+	t.Helper()
+
+	merchantID, merchantCard := p.merchant(t, 0)
+	p.feePlan(t, merchantID, 100, 500)
+
+	if _, err := p.repos.Merchant.UpdateSettlementMode(merchantID, models.SettlementModeBatch); err != nil {
+		t.Fatalf("switch to batch settlement: %v", err)
+	}
+
+	return merchantID, merchantCard
+}
+
+func (p *testPlatform) openItems(t *testing.T, merchantID int) int {
+	t.Helper()
+
+	items, err := p.repos.SettlementItem.ReadOpen(merchantID, time.Now())
+	if err != nil {
+		t.Fatalf("read open items: %v", err)
+	}
+
+	return len(items)
+}
+
+func TestSettlementClosePaysOutBatch(t *testing.T) {
+	p := newTestPlatform(t)
+
+	merchantID, merchantCard := p.batchMerchant(t)
+	_, customerCard := p.card(t, 100000)
+
+	first := p.pay(t, merchantID, customerCard, 50000)
+	p.pay(t, merchantID, customerCard, 30000)
+
+	if _, errRes := p.services.Transaction.Refund(merchantID, requests.CreateRefundRequest{TransactionID: first, Amount: 10000}); errRes != nil {
+		t.Fatalf("refund: %s", errRes.Message)
+	}
+
+	// Sebelum batch ditutup hasil penjualan masih di saldo pending
+	if pending, _ := p.ledger.PendingBalance(merchantID); pending != 70000 {
+		t.Fatalf("pending balance %d before closing, want 70000", pending)
+	}
+
+	res, errRes := p.services.Settlement.Close(requests.CloseSettlementRequest{MerchantID: merchantID})
+	if errRes != nil {
+		t.Fatalf("close: %s", errRes.Message)
+	}
+
+	// Fee 1.000 + 800 dipotong dari 80.000 dikurangi refund 10.000
+	batch := res.Data
+	want := struct{ items, gross, refund, fee, net int }{3, 80000, 10000, 1800, 68200}
+	got := struct{ items, gross, refund, fee, net int }{batch.ItemCount, batch.GrossAmount, batch.RefundAmount, batch.FeeAmount, batch.NetAmount}
+
+	if got != want {
+		t.Errorf("batch %+v, want %+v", got, want)
+	}
+
+	if batch.Status != models.SettlementBatchPaid || len(batch.Items) != 3 {
+		t.Errorf("batch is %s with %d items, want paid with 3", batch.Status, len(batch.Items))
+	}
+
+	if pending, _ := p.ledger.PendingBalance(merchantID); pending != 0 {
+		t.Errorf("pending balance %d after the payout, want 0", pending)
+	}
+
+	if balance := p.balance(t, merchantCard); balance != 68200 {
+		t.Errorf("merchant card holds %d, want the net 68200", balance)
+	}
+
+	p.checkBooks(t, []string{customerCard, merchantCard}, ledger.AccountFeeRevenue, ledger.MerchantPendingAccount(merchantID))
+
+	if _, errRes := p.services.Settlement.Close(requests.CloseSettlementRequest{MerchantID: merchantID}); errorMessage(errRes) != "Merchant has nothing to settle" {
+		t.Errorf("second close: got %q, want nothing to settle", errorMessage(errRes))
+	}
+}
+
+func TestSettlementKeepsNegativeBatchOpen(t *testing.T) {
+	p := newTestPlatform(t)
+
+	merchantID, merchantCard := p.batchMerchant(t)
+	_, customerCard := p.card(t, 100000)
+
+	// Refund penuh menyisakan fee 600 yang belum tertutup
+	transactionID := p.pay(t, merchantID, customerCard, 10000)
+
+	if _, errRes := p.services.Transaction.Refund(merchantID, requests.CreateRefundRequest{TransactionID: transactionID}); errRes != nil {
+		t.Fatalf("refund: %s", errRes.Message)
+	}
+
+	_, errRes := p.services.Settlement.Close(requests.CloseSettlementRequest{MerchantID: merchantID})
+	if got := errorMessage(errRes); got != "Open items do not cover their refunds and fees" {
+		t.Fatalf("close: got %q, want the batch to be refused", got)
+	}
+
+	if open := p.openItems(t, merchantID); open != 2 {
+		t.Errorf("%d open items after a refused close, want 2", open)
+	}
+
+	batches, _, err := p.repos.SettlementBatch.ReadByMerchantID(merchantID, 1, 10)
+	if err != nil {
+		t.Fatalf("read batches: %v", err)
+	}
+
+	if len(batches) != 0 {
+		t.Errorf("%d batches created for a refused close", len(batches))
+	}
+
+	// Pembayaran baru menutup fee yang tertunda
+	p.pay(t, merchantID, customerCard, 20000)
+
+	res, errRes := p.services.Settlement.Close(requests.CloseSettlementRequest{MerchantID: merchantID})
+	if errRes != nil {
+		t.Fatalf("close after a new payment: %s", errRes.Message)
+	}
+
+	if res.Data.ItemCount != 3 || res.Data.FeeAmount != 1300 || res.Data.NetAmount != 18700 {
+		t.Errorf("batch of %d items, fee %d, net %d; want 3, 1300, 18700", res.Data.ItemCount, res.Data.FeeAmount, res.Data.NetAmount)
+	}
+
+	if open := p.openItems(t, merchantID); open != 0 {
+		t.Errorf("%d items left open after the payout", open)
+	}
+
+	if pending, _ := p.ledger.PendingBalance(merchantID); pending != 0 {
+		t.Errorf("pending balance %d after the payout, want 0", pending)
+	}
+
+	p.checkBooks(t, []string{customerCard, merchantCard}, ledger.AccountFeeRevenue, ledger.MerchantPendingAccount(merchantID))
+}
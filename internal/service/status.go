@@ -0,0 +1,32 @@
+package service
+
+import "payment-mutex/internal/models"
+
+// Services move their own records from pending through processing to
+// succeeded or failed as the money moves. manualStatusTransitions is what the
+// update_status endpoints may do on top of that: give up on a movement that
+// is stuck in processing, or reverse one that succeeded. Failed and reversed
+// are final.
+var manualStatusTransitions = map[string][]string{
+	models.StatusProcessing: {models.StatusFailed},
+	models.StatusSucceeded:  {models.StatusReversed},
+}
+
+func isKnownStatus(status string) bool {
+	switch status {
+	case models.StatusPending, models.StatusProcessing, models.StatusSucceeded, models.StatusFailed, models.StatusReversed:
+		return true
+	}
+
+	return false
+}
+
+func canTransitionStatus(from string, to string) bool {
+	for _, next := range manualStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,13 +1,17 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/ledger"
 	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/models"
 	"payment-mutex/internal/repository"
 	"payment-mutex/pkg/logger"
 	"strconv"
+	"sync"
 
 	"go.uber.org/zap"
 )
@@ -16,14 +20,19 @@ type topupService struct {
 	cardRepository  repository.CardRepository
 	topupRepository repository.TopupRepository
 	saldoRepository repository.SaldoRepository
+	ledger          *ledger.Ledger
 	logger          logger.Logger
 	mapper          responseMapper.TopupResponseMapper
+
+	// mu menjaga agar perubahan nominal dan status topup tidak saling mendahului
+	mu sync.Mutex
 }
 
 func NewTopupService(
 	cardRepository repository.CardRepository,
 	topupRepository repository.TopupRepository,
 	saldoRepository repository.SaldoRepository,
+	ledger *ledger.Ledger,
 	logger logger.Logger,
 	mapper responseMapper.TopupResponseMapper,
 ) *topupService {
@@ -31,12 +40,13 @@ func NewTopupService(
 		cardRepository:  cardRepository,
 		topupRepository: topupRepository,
 		saldoRepository: saldoRepository,
+		ledger:          ledger,
 		logger:          logger,
 		mapper:          mapper,
 	}
 }
 
-func (s *topupService) FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.TopupResponse], *response.ErrorResponse) {
+func (s *topupService) FindAll(page int, pageSize int, search string, status string) (*response.APIResponsePagination[[]*response.TopupResponse], *response.ErrorResponse) {
 	if page <= 0 {
 		page = 1
 	}
@@ -45,7 +55,15 @@ func (s *topupService) FindAll(page int, pageSize int, search string) (*response
 		pageSize = 10
 	}
 
-	topups, totalRecords, err := s.topupRepository.ReadAll(page, pageSize, search)
+	if status != "" && !isKnownStatus(status) {
+		s.logger.Error("invalid status filter", zap.String("status", status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid status filter",
+		}
+	}
+
+	topups, totalRecords, err := s.topupRepository.ReadAll(page, pageSize, search, status)
 
 	if err != nil {
 		s.logger.Error("failed to fetch topups", zap.Error(err))
@@ -80,7 +98,7 @@ func (s *topupService) FindAll(page int, pageSize int, search string) (*response
 	}, nil
 }
 
-func (s *topupService) FindById(topupID int) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse) {
+func (s *topupService) FindById(caller requests.Caller, topupID int) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse) {
 	topup, err := s.topupRepository.Read(topupID)
 	if err != nil {
 		s.logger.Error("failed to find topup by id", zap.Error(err))
@@ -90,6 +108,14 @@ func (s *topupService) FindById(topupID int) (*response.ApiResponse[*response.To
 		}
 	}
 
+	if !ownsCard(s.cardRepository, caller, topup.CardNumber) {
+		s.logger.Error("topup belongs to another user", zap.Int("topup_id", topupID), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Topup record not found",
+		}
+	}
+
 	so := s.mapper.ToTopupResponse(*topup)
 
 	return &response.ApiResponse[*response.TopupResponse]{
@@ -99,7 +125,7 @@ func (s *topupService) FindById(topupID int) (*response.ApiResponse[*response.To
 	}, nil
 }
 
-func (s *topupService) Create(request requests.CreateTopupRequest) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse) {
+func (s *topupService) Create(caller requests.Caller, request requests.CreateTopupRequest) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse) {
 	_, err := s.cardRepository.ReadByCardNumber(request.CardNumber)
 	if err != nil {
 		s.logger.Error("failed to find card by number", zap.Error(err))
@@ -109,6 +135,14 @@ func (s *topupService) Create(request requests.CreateTopupRequest) (*response.Ap
 		}
 	}
 
+	if !ownsCard(s.cardRepository, caller, request.CardNumber) {
+		s.logger.Error("card belongs to another user", zap.String("card_number", request.CardNumber), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Card not found",
+		}
+	}
+
 	// Create topup
 	topup, err := s.topupRepository.Create(request)
 	if err != nil {
@@ -119,29 +153,35 @@ func (s *topupService) Create(request requests.CreateTopupRequest) (*response.Ap
 		}
 	}
 
-	// Find current saldo
-	saldo, err := s.saldoRepository.ReadByCardNumber(request.CardNumber)
-	if err != nil {
-		s.logger.Error("failed to find saldo by user id", zap.Error(err))
+	if _, err := s.topupRepository.UpdateStatus(topup.TopupID, models.StatusProcessing); err != nil {
+		s.logger.Error("failed to mark topup as processing", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Failed to fetch user's saldo",
+			Message: "Failed to process topup",
 		}
 	}
 
-	newBalance := saldo.TotalBalance + request.TopupAmount
-	_, err = s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-		CardNumber:   request.CardNumber,
-		TotalBalance: newBalance,
-	})
+	// Status succeeded disimpan bersama entri jurnalnya dalam satu unit of work
+	unit := s.ledger.Begin()
+	pending := unit.Topup.UpdateStatus(topup.TopupID, models.StatusSucceeded)
+
+	_, err = s.ledger.PostWith(unit, ledger.TopupEntry(request.CardNumber, request.TopupAmount, topup.TopupID))
 	if err != nil {
-		s.logger.Error("failed to update saldo balance", zap.Error(err))
+		s.logger.Error("failed to post topup entry", zap.Error(err))
+
+		// Topup yang gagal tetap disimpan dengan status failed
+		if _, statusErr := s.topupRepository.UpdateStatus(topup.TopupID, models.StatusFailed); statusErr != nil {
+			s.logger.Error("failed to mark topup as failed", zap.Error(statusErr))
+		}
+
 		return nil, &response.ErrorResponse{
 			Status:  "error",
 			Message: "Failed to update saldo balance",
 		}
 	}
 
+	topup = pending.Value()
+
 	so := s.mapper.ToTopupResponse(*topup)
 
 	return &response.ApiResponse[*response.TopupResponse]{
@@ -152,6 +192,9 @@ func (s *topupService) Create(request requests.CreateTopupRequest) (*response.Ap
 }
 
 func (s *topupService) Update(request requests.UpdateTopupRequest) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	_, err := s.cardRepository.ReadByCardNumber(request.CardNumber)
 	if err != nil {
 		s.logger.Error("failed to find card by number", zap.Error(err))
@@ -171,61 +214,51 @@ func (s *topupService) Update(request requests.UpdateTopupRequest) (*response.Ap
 		}
 	}
 
-	topupDifference := request.TopupAmount - existingTopup.TopupAmount
-
-	// Update the topup amount
-	_, err = s.topupRepository.UpdateAmount(requests.UpdateTopupAmount{
-		TopupID:     request.TopupID,
-		TopupAmount: request.TopupAmount,
-	})
-	if err != nil {
-		s.logger.Error("Failed to update topup amount", zap.Error(err))
+	if existingTopup.Status != models.StatusSucceeded {
+		s.logger.Error("cannot update topup that has not succeeded", zap.String("status", existingTopup.Status))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: fmt.Sprintf("Failed to update topup amount: %v", err),
+			Message: "Only succeeded topups can be updated",
 		}
 	}
 
-	// Retrieve the current balance from saldo
-	currentSaldo, err := s.saldoRepository.ReadByCardNumber(request.CardNumber)
-	if err != nil {
-		s.logger.Error("Failed to retrieve current saldo", zap.Error(err))
-		return nil, &response.ErrorResponse{
-			Status:  "error",
-			Message: fmt.Sprintf("Failed to retrieve current saldo: %v", err),
-		}
-	}
+	// Saldo dan nominal topup disimpan dalam satu unit of work supaya
+	// saldo tidak pernah berubah tanpa record topupnya
+	unit := s.ledger.Begin()
 
-	if currentSaldo == nil {
-		s.logger.Error("No saldo found for card number", zap.String("card_number", request.CardNumber))
+	unit.Topup.UpdateAmount(requests.UpdateTopupAmount{
+		TopupID:     request.TopupID,
+		TopupAmount: request.TopupAmount,
+	})
+
+	_, err = s.ledger.Stage(unit, ledger.Combine(
+		ledger.ReferenceTopup,
+		existingTopup.TopupID,
+		"topup amount update for card "+existingTopup.CardNumber,
+		ledger.Reverse(ledger.TopupEntry(existingTopup.CardNumber, existingTopup.TopupAmount, existingTopup.TopupID)),
+		ledger.TopupEntry(existingTopup.CardNumber, request.TopupAmount, existingTopup.TopupID),
+	))
+	if err != nil {
+		s.logger.Error("Failed to update saldo balance", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "card not found",
+			Message: fmt.Sprintf("Failed to update saldo balance: %v", err),
 		}
 	}
 
-	newBalance := currentSaldo.TotalBalance + topupDifference
-
-	_, err = s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-		CardNumber:   request.CardNumber,
-		TotalBalance: newBalance,
-	})
-
-	if err != nil {
-		s.logger.Error("Failed to update saldo balance", zap.Error(err))
+	if err := s.ledger.Commit(unit); err != nil {
+		s.logger.Error("Failed to update topup amount", zap.Error(err))
 
-		// Rollback the topup update if saldo update fails
-		_, rollbackErr := s.topupRepository.UpdateAmount(requests.UpdateTopupAmount{
-			TopupID:     request.TopupID,
-			TopupAmount: existingTopup.TopupAmount,
-		})
-		if rollbackErr != nil {
-			s.logger.Error("Failed to rollback topup update", zap.Error(rollbackErr))
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient balance to reduce topup amount",
+			}
 		}
 
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: fmt.Sprintf("Failed to update saldo balance: %v", err),
+			Message: fmt.Sprintf("Failed to update topup amount: %v", err),
 		}
 	}
 
@@ -264,3 +297,79 @@ func (s *topupService) Delete(topupID int) (*response.ApiResponse[string], *resp
 		Data:    "Topup record with ID " + strconv.Itoa(topupID) + " has been deleted",
 	}, nil
 }
+
+func (s *topupService) UpdateStatus(request requests.UpdateStatusRequest) (*response.ApiResponse[*response.TopupResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	topup, err := s.topupRepository.Read(request.ID)
+	if err != nil {
+		s.logger.Error("failed to find topup", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Topup not found",
+		}
+	}
+
+	if !canTransitionStatus(topup.Status, request.Status) {
+		s.logger.Error("invalid topup status transition", zap.String("from", topup.Status), zap.String("to", request.Status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot change topup status from %s to %s", topup.Status, request.Status),
+		}
+	}
+
+	// Status reversed membalik perpindahan saldo lewat entri jurnal kompensasi,
+	// disimpan bersama perubahan status dalam satu unit of work
+	unit := s.ledger.Begin()
+
+	if request.Status == models.StatusReversed {
+		// Hanya topup yang benar-benar memindahkan saldo yang bisa dibalik
+		if posted, err := s.ledger.Posted(ledger.ReferenceTopup, topup.TopupID); err != nil || !posted {
+			s.logger.Error("topup has no ledger entry to reverse", zap.Error(err), zap.Int("topupID", topup.TopupID))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Topup has no ledger entry to reverse",
+			}
+		}
+
+		entry := ledger.Reverse(ledger.TopupEntry(topup.CardNumber, topup.TopupAmount, topup.TopupID))
+
+		if _, err := s.ledger.Stage(unit, entry); err != nil {
+			s.logger.Error("failed to post topup reversal", zap.Error(err))
+
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Failed to reverse topup",
+			}
+		}
+	}
+
+	pending := unit.Topup.UpdateStatus(topup.TopupID, request.Status)
+
+	if err := s.ledger.Commit(unit); err != nil {
+		s.logger.Error("failed to update topup status", zap.Error(err))
+
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient balance to reverse topup",
+			}
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update topup status",
+		}
+	}
+
+	updated := pending.Value()
+
+	so := s.mapper.ToTopupResponse(*updated)
+
+	return &response.ApiResponse[*response.TopupResponse]{
+		Status:  "success",
+		Message: "Topup status updated successfully",
+		Data:    so,
+	}, nil
+}
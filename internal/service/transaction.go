@@ -1,23 +1,43 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/ledger"
 	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/models"
 	"payment-mutex/internal/repository"
 	"payment-mutex/pkg/logger"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
 
 type transactionService struct {
-	merchantRepository    repository.MerchantRepository
-	cardRepository        repository.CardRepository
-	saldoRepository       repository.SaldoRepository
-	transactionRepository repository.TransactionRepository
-	logger                logger.Logger
-	mapper                responseMapper.TransactionResponseMapper
+	merchantRepository       repository.MerchantRepository
+	cardRepository           repository.CardRepository
+	saldoRepository          repository.SaldoRepository
+	transactionRepository    repository.TransactionRepository
+	refundRepository         repository.RefundRepository
+	authorizationRepository  repository.AuthorizationRepository
+	settlementItemRepository repository.SettlementItemRepository
+	feePlanRepository        repository.FeePlanRepository
+	ledger                   *ledger.Ledger
+	logger                   logger.Logger
+	mapper                   responseMapper.TransactionResponseMapper
+	refundMapper             responseMapper.RefundResponseMapper
+	authorizationMapper      responseMapper.AuthorizationResponseMapper
+	webhooks                 WebhookService
+	authorizationTTL         time.Duration
+
+	// refundMu menjaga agar refund, pembalikan, dan perubahan nominal transaksi tidak saling mendahului
+	refundMu sync.Mutex
+
+	// authorizationMu menjaga agar capture, void, dan kedaluwarsa otorisasi tidak saling mendahului
+	authorizationMu sync.Mutex
 }
 
 func NewTransactionService(
@@ -25,20 +45,38 @@ func NewTransactionService(
 	cardRepository repository.CardRepository,
 	saldoRepository repository.SaldoRepository,
 	transactionRepository repository.TransactionRepository,
+	refundRepository repository.RefundRepository,
+	authorizationRepository repository.AuthorizationRepository,
+	settlementItemRepository repository.SettlementItemRepository,
+	feePlanRepository repository.FeePlanRepository,
+	ledger *ledger.Ledger,
 	logger logger.Logger,
 	mapper responseMapper.TransactionResponseMapper,
+	refundMapper responseMapper.RefundResponseMapper,
+	authorizationMapper responseMapper.AuthorizationResponseMapper,
+	webhooks WebhookService,
+	authorizationTTL time.Duration,
 ) *transactionService {
 	return &transactionService{
-		merchantRepository:    merchantRepository,
-		cardRepository:        cardRepository,
-		saldoRepository:       saldoRepository,
-		transactionRepository: transactionRepository,
-		logger:                logger,
-		mapper:                mapper,
+		merchantRepository:       merchantRepository,
+		cardRepository:           cardRepository,
+		saldoRepository:          saldoRepository,
+		transactionRepository:    transactionRepository,
+		refundRepository:         refundRepository,
+		authorizationRepository:  authorizationRepository,
+		settlementItemRepository: settlementItemRepository,
+		feePlanRepository:        feePlanRepository,
+		ledger:                   ledger,
+		logger:                   logger,
+		mapper:                   mapper,
+		refundMapper:             refundMapper,
+		authorizationMapper:      authorizationMapper,
+		webhooks:                 webhooks,
+		authorizationTTL:         authorizationTTL,
 	}
 }
 
-func (s *transactionService) FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.TransactionResponse], *response.ErrorResponse) {
+func (s *transactionService) FindAll(page int, pageSize int, search string, status string) (*response.APIResponsePagination[[]*response.TransactionResponse], *response.ErrorResponse) {
 	if page <= 0 {
 		page = 1
 	}
@@ -47,7 +85,15 @@ func (s *transactionService) FindAll(page int, pageSize int, search string) (*re
 		pageSize = 10
 	}
 
-	transactions, totalRecords, err := s.transactionRepository.ReadAll(page, pageSize, search)
+	if status != "" && !isKnownStatus(status) {
+		s.logger.Error("invalid status filter", zap.String("status", status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid status filter",
+		}
+	}
+
+	transactions, totalRecords, err := s.transactionRepository.ReadAll(page, pageSize, search, status)
 
 	if err != nil {
 		s.logger.Error("failed to fetch transactions", zap.Error(err))
@@ -82,7 +128,9 @@ func (s *transactionService) FindAll(page int, pageSize int, search string) (*re
 	}, nil
 }
 
-func (s *transactionService) FindById(transactionID int) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse) {
+// FindById returns the transaction to the owner of the card it was paid
+// with or of the merchant it was paid to. Other callers get not found.
+func (s *transactionService) FindById(caller requests.Caller, transactionID int) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse) {
 	transaction, err := s.transactionRepository.Read(transactionID)
 	if err != nil {
 		s.logger.Error("failed to find transaction", zap.Error(err))
@@ -92,6 +140,14 @@ func (s *transactionService) FindById(transactionID int) (*response.ApiResponse[
 		}
 	}
 
+	if !ownsCard(s.cardRepository, caller, transaction.CardNumber) && !ownsMerchant(s.merchantRepository, caller, transaction.MerchantID) {
+		s.logger.Error("transaction belongs to another user", zap.Int("transaction_id", transactionID), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transaction not found",
+		}
+	}
+
 	so := s.mapper.ToTransactionResponse(*transaction)
 
 	return &response.ApiResponse[*response.TransactionResponse]{
@@ -101,9 +157,30 @@ func (s *transactionService) FindById(transactionID int) (*response.ApiResponse[
 	}, nil
 }
 
-func (s *transactionService) Create(apiKey string, request requests.CreateTransactionRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse) {
-	// Ambil informasi merchant berdasarkan API key
-	merchant, err := s.merchantRepository.ReadByApiKey(apiKey)
+// FindByMerchant returns the transaction when it belongs to the merchant.
+// Transactions of other merchants are reported as not found.
+func (s *transactionService) FindByMerchant(merchantID int, transactionID int) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse) {
+	transaction, err := s.transactionRepository.Read(transactionID)
+	if err != nil || transaction.MerchantID != merchantID {
+		s.logger.Error("failed to find merchant transaction", zap.Int("transaction_id", transactionID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transaction not found",
+		}
+	}
+
+	so := s.mapper.ToTransactionResponse(*transaction)
+
+	return &response.ApiResponse[*response.TransactionResponse]{
+		Status:  "success",
+		Message: "Transaction found",
+		Data:    so,
+	}, nil
+}
+
+func (s *transactionService) Create(merchantID int, request requests.CreateTransactionRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse) {
+	// Ambil informasi merchant dari API key yang sudah diperiksa middleware
+	merchant, err := s.merchantRepository.Read(merchantID)
 	if err != nil {
 		s.logger.Error("failed to find merchant", zap.Error(err))
 		return nil, &response.ErrorResponse{
@@ -122,51 +199,31 @@ func (s *transactionService) Create(apiKey string, request requests.CreateTransa
 		}
 	}
 
-	// Ambil informasi saldo kartu
-	saldo, err := s.saldoRepository.ReadByCardNumber(card.CardNumber)
+	// Ambil informasi kartu merchant
+	merchantCard, err := s.cardRepository.ReadByUserID(merchant.UserID)
 	if err != nil {
-		s.logger.Error("failed to find saldo", zap.Error(err))
-		return nil, &response.ErrorResponse{
-			Status:  "error",
-			Message: "Saldo not found",
-		}
-	}
-
-	// Periksa apakah saldo mencukupi
-	// s.logger.Info("Checking balance", zap.Int("AvailableBalance", saldo.TotalBalance), zap.Int("TransactionAmount", request.Amount))
-	if saldo.TotalBalance < request.Amount {
-		s.logger.Error("insufficient balance", zap.Int("AvailableBalance", saldo.TotalBalance), zap.Int("TransactionAmount", request.Amount))
+		s.logger.Error("failed to find merchant card", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Insufficient balance",
+			Message: "Merchant card not found",
 		}
 	}
 
-	// Proses pengurangan saldo
-	saldo.TotalBalance -= request.Amount
-	// s.logger.Info("Balance after deduction", zap.Int("NewBalance", saldo.TotalBalance))
-	if _, err := s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-		CardNumber:   card.CardNumber,
-		TotalBalance: saldo.TotalBalance,
-	}); err != nil {
-		s.logger.Error("failed to update saldo", zap.Error(err))
+	// Fee dihitung dari fee plan merchant sesuai metode pembayaran
+	quote, err := quoteFee(s.feePlanRepository, merchant, request.PaymentMethod, request.Amount)
+	if err != nil {
+		s.logger.Error("failed to quote fee", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Failed to update saldo",
+			Message: "Failed to calculate fee",
 		}
 	}
 
 	// Buat transaksi
 	request.MerchantID = &merchant.MerchantID
-	// s.logger.Info("Creating transaction", zap.Any("Request", zapcore.Field.A))
+	request.Fee = quote.Fee
 	transaction, err := s.transactionRepository.Create(request)
 	if err != nil {
-		// Rollback saldo jika pembuatan transaksi gagal
-		saldo.TotalBalance += request.Amount
-		s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-			CardNumber:   card.CardNumber,
-			TotalBalance: saldo.TotalBalance,
-		})
 		s.logger.Error("failed to create transaction", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
@@ -174,41 +231,65 @@ func (s *transactionService) Create(apiKey string, request requests.CreateTransa
 		}
 	}
 
-	// Ambil informasi kartu dan saldo merchant
-	merchantCard, err := s.cardRepository.ReadByUserID(merchant.UserID)
-	if err != nil {
-		s.logger.Error("failed to find merchant card", zap.Error(err))
+	if _, err := s.transactionRepository.UpdateStatus(transaction.TransactionID, models.StatusProcessing); err != nil {
+		s.logger.Error("failed to mark transaction as processing", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Merchant card not found",
+			Message: "Failed to process transaction",
 		}
 	}
 
-	merchantSaldo, err := s.saldoRepository.ReadByCardNumber(merchantCard.CardNumber)
-	if err != nil {
-		s.logger.Error("failed to find merchant saldo", zap.Error(err))
-		return nil, &response.ErrorResponse{
-			Status:  "error",
-			Message: "Merchant saldo not found",
+	// Pindahkan saldo dari kartu pelanggan ke kartu merchant, atau ke saldo
+	// pending merchant yang settlement-nya per batch, bersama status succeeded
+	// dalam satu unit of work
+	to := newPayee(merchant, merchantCard)
+
+	unit := s.ledger.Begin()
+	pending := unit.Transaction.UpdateStatusFrom(transaction.TransactionID, models.StatusProcessing, models.StatusSucceeded)
+
+	s.accrue(unit, to, requests.CreateSettlementItemRequest{
+		TransactionID: transaction.TransactionID,
+		Type:          models.SettlementItemPayment,
+		ReferenceID:   transaction.TransactionID,
+		Amount:        request.Amount,
+		Fee:           transaction.Fee,
+	})
+
+	if _, err := s.ledger.PostWith(unit, to.payment(card.CardNumber, request.Amount, transaction.Fee, transaction.TransactionID)); err != nil {
+		s.logger.Error("failed to post transaction entry", zap.Error(err), zap.Int("TransactionAmount", request.Amount))
+
+		// Status diubah admin selama diproses, jurnal tidak diposting dan status itu dipertahankan
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Transaction status changed while it was processed",
+			}
+		}
+
+		// Transaction yang gagal tetap disimpan dengan status failed
+		if _, statusErr := s.transactionRepository.UpdateStatus(transaction.TransactionID, models.StatusFailed); statusErr != nil {
+			s.logger.Error("failed to mark transaction as failed", zap.Error(statusErr))
+		}
+
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient balance",
+			}
 		}
-	}
 
-	// Tambahkan saldo ke merchant
-	merchantSaldo.TotalBalance += request.Amount
-	// s.logger.Info("Updating merchant saldo", zap.Int("NewMerchantBalance", merchantSaldo.TotalBalance))
-	if _, err := s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-		CardNumber:   merchantCard.CardNumber,
-		TotalBalance: merchantSaldo.TotalBalance,
-	}); err != nil {
-		s.logger.Error("failed to update merchant saldo", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Failed to update merchant saldo",
+			Message: "Failed to update saldo",
 		}
 	}
 
+	transaction = pending.Value()
+
 	// Map hasil transaksi ke response
 	so := s.mapper.ToTransactionResponse(*transaction)
+	s.webhooks.Publish(merchantID, models.WebhookEventTransactionCreated, so)
+
 	return &response.ApiResponse[*response.TransactionResponse]{
 		Status:  "success",
 		Message: "Transaction created successfully",
@@ -216,7 +297,10 @@ func (s *transactionService) Create(apiKey string, request requests.CreateTransa
 	}, nil
 }
 
-func (s *transactionService) Update(apiKey string, request requests.UpdateTransactionRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse) {
+func (s *transactionService) Update(merchantID int, request requests.UpdateTransactionRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse) {
+	s.refundMu.Lock()
+	defer s.refundMu.Unlock()
+
 	transaction, err := s.transactionRepository.Read(request.TransactionID)
 	if err != nil {
 		s.logger.Error("failed to find transaction", zap.Error(err))
@@ -226,8 +310,8 @@ func (s *transactionService) Update(apiKey string, request requests.UpdateTransa
 		}
 	}
 
-	// Validasi apakah merchant API key sesuai dengan merchant ID dalam transaksi
-	merchant, err := s.merchantRepository.ReadByApiKey(apiKey)
+	// Validasi apakah merchant pemilik API key sesuai dengan merchant ID dalam transaksi
+	merchant, err := s.merchantRepository.Read(merchantID)
 
 	if err != nil || transaction.MerchantID != merchant.MerchantID {
 		s.logger.Error("unauthorized access to transaction", zap.Error(err))
@@ -237,6 +321,14 @@ func (s *transactionService) Update(apiKey string, request requests.UpdateTransa
 		}
 	}
 
+	if transaction.Status != models.StatusSucceeded {
+		s.logger.Error("cannot update transaction that has not succeeded", zap.String("status", transaction.Status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Only succeeded transactions can be updated",
+		}
+	}
+
 	// Ambil informasi kartu dan saldo berdasarkan kartu yang terkait dengan transaksi
 	card, err := s.cardRepository.ReadByCardNumber(transaction.CardNumber)
 	if err != nil {
@@ -247,46 +339,74 @@ func (s *transactionService) Update(apiKey string, request requests.UpdateTransa
 		}
 	}
 
-	saldo, err := s.saldoRepository.ReadByCardNumber(card.CardNumber)
+	merchantCard, err := s.cardRepository.ReadByUserID(merchant.UserID)
 	if err != nil {
-		s.logger.Error("failed to find saldo", zap.Error(err))
+		s.logger.Error("failed to find merchant card", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Saldo not found",
+			Message: "Merchant card not found",
 		}
 	}
 
-	// Kembalikan saldo ke pengguna untuk jumlah transaksi lama
-	saldo.TotalBalance += transaction.Amount
-	s.logger.Debug("Restoring balance for old transaction amount", zap.Int("RestoredBalance", saldo.TotalBalance))
-	if _, err := s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-		CardNumber:   card.CardNumber,
-		TotalBalance: saldo.TotalBalance,
-	}); err != nil {
-		s.logger.Error("failed to restore balance", zap.Error(err))
+	// Nominal transaksi tidak boleh lebih kecil dari total yang sudah direfund
+	refunded, err := s.refundRepository.TotalByTransactionID(transaction.TransactionID)
+	if err != nil {
+		s.logger.Error("failed to sum refunds", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Failed to restore balance",
+			Message: "Failed to update transaction",
 		}
 	}
 
-	// Validasi saldo untuk jumlah transaksi baru
-	if saldo.TotalBalance < request.Amount {
-		s.logger.Error("insufficient balance for updated amount", zap.Int("AvailableBalance", saldo.TotalBalance), zap.Int("UpdatedAmount", request.Amount))
+	if request.Amount < refunded {
+		s.logger.Error("updated amount is below refunded total", zap.Int("UpdatedAmount", request.Amount), zap.Int("Refunded", refunded))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Insufficient balance for updated transaction",
+			Message: "Transaction amount cannot be lower than the refunded amount",
 		}
 	}
 
-	// Perbarui saldo berdasarkan jumlah transaksi baru
-	saldo.TotalBalance -= request.Amount
-	s.logger.Info("Updating balance for updated transaction amount")
-	if _, err := s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-		CardNumber:   card.CardNumber,
-		TotalBalance: saldo.TotalBalance,
-	}); err != nil {
-		s.logger.Error("failed to update balance", zap.Error(err))
+	to, err := s.payeeOf(merchant, merchantCard, transaction.TransactionID)
+	if err != nil {
+		s.logger.Error("failed to find settlement of transaction", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update transaction",
+		}
+	}
+
+	quote, err := quoteFee(s.feePlanRepository, merchant, request.PaymentMethod, request.Amount)
+	if err != nil {
+		s.logger.Error("failed to quote fee", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to calculate fee",
+		}
+	}
+
+	// Pembayaran lama dibatalkan dan pembayaran baru diterapkan dalam satu entri
+	adjustment := ledger.Combine(
+		ledger.ReferenceTransaction,
+		transaction.TransactionID,
+		"payment update from card "+card.CardNumber+" to merchant card "+merchantCard.CardNumber,
+		ledger.Reverse(to.payment(card.CardNumber, transaction.Amount, transaction.Fee, transaction.TransactionID)),
+		to.payment(card.CardNumber, request.Amount, quote.Fee, transaction.TransactionID),
+	)
+
+	// Saldo dan record transaksi disimpan dalam satu unit of work supaya
+	// saldo tidak pernah berubah tanpa record transaksinya
+	unit := s.ledger.Begin()
+
+	s.accrue(unit, to, requests.CreateSettlementItemRequest{
+		TransactionID: transaction.TransactionID,
+		Type:          models.SettlementItemAdjustment,
+		ReferenceID:   transaction.TransactionID,
+		Amount:        request.Amount - transaction.Amount,
+		Fee:           quote.Fee - transaction.Fee,
+	})
+
+	if _, err := s.ledger.Stage(unit, adjustment); err != nil {
+		s.logger.Error("failed to move transaction difference", zap.Error(err), zap.Int("UpdatedAmount", request.Amount))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
 			Message: "Failed to update balance",
@@ -294,26 +414,40 @@ func (s *transactionService) Update(apiKey string, request requests.UpdateTransa
 	}
 
 	transaction.Amount = request.Amount
+	transaction.Fee = quote.Fee
 	transaction.PaymentMethod = request.PaymentMethod
 
-	res, err := s.transactionRepository.Update(requests.UpdateTransactionRequest{
+	pending := unit.Transaction.Update(requests.UpdateTransactionRequest{
 		TransactionID:   transaction.TransactionID,
 		CardNumber:      transaction.CardNumber,
 		Amount:          transaction.Amount,
 		PaymentMethod:   transaction.PaymentMethod,
 		MerchantID:      &transaction.MerchantID,
 		TransactionTime: transaction.TransactionTime,
+		Fee:             transaction.Fee,
 	})
 
-	if err != nil {
-		s.logger.Error("failed to update transaction", zap.Error(err))
+	if err := s.ledger.Commit(unit); err != nil {
+		s.logger.Error("failed to update transaction", zap.Error(err), zap.Int("UpdatedAmount", request.Amount))
+
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient balance for updated transaction",
+			}
+		}
+
 		return nil, &response.ErrorResponse{
 			Status:  "error",
 			Message: "Failed to update transaction",
 		}
 	}
 
+	res := pending.Value()
+
 	so := s.mapper.ToTransactionResponse(*res)
+	s.webhooks.Publish(merchantID, models.WebhookEventTransactionUpdated, so)
+
 	return &response.ApiResponse[*response.TransactionResponse]{
 		Status:  "success",
 		Message: "Transaction updated successfully",
@@ -321,6 +455,141 @@ func (s *transactionService) Update(apiKey string, request requests.UpdateTransa
 	}, nil
 }
 
+func (s *transactionService) Refund(merchantID int, request requests.CreateRefundRequest) (*response.ApiResponse[*response.RefundResponse], *response.ErrorResponse) {
+	s.refundMu.Lock()
+	defer s.refundMu.Unlock()
+
+	transaction, err := s.transactionRepository.Read(request.TransactionID)
+	if err != nil {
+		s.logger.Error("failed to find transaction", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transaction not found",
+		}
+	}
+
+	// Hanya merchant pemilik transaksi yang boleh melakukan refund
+	merchant, err := s.merchantRepository.Read(merchantID)
+
+	if err != nil || transaction.MerchantID != merchant.MerchantID {
+		s.logger.Error("unauthorized access to transaction", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized access to transaction",
+		}
+	}
+
+	if transaction.Status != models.StatusSucceeded {
+		s.logger.Error("cannot refund transaction that has not succeeded", zap.String("status", transaction.Status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Only succeeded transactions can be refunded",
+		}
+	}
+
+	merchantCard, err := s.cardRepository.ReadByUserID(merchant.UserID)
+	if err != nil {
+		s.logger.Error("failed to find merchant card", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant card not found",
+		}
+	}
+
+	to, err := s.payeeOf(merchant, merchantCard, transaction.TransactionID)
+	if err != nil {
+		s.logger.Error("failed to find settlement of transaction", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create refund",
+		}
+	}
+
+	refunded, err := s.refundRepository.TotalByTransactionID(transaction.TransactionID)
+	if err != nil {
+		s.logger.Error("failed to sum refunds", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create refund",
+		}
+	}
+
+	remaining := transaction.Amount - refunded
+	if remaining <= 0 {
+		s.logger.Error("transaction already fully refunded", zap.Int("TransactionID", transaction.TransactionID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transaction has already been fully refunded",
+		}
+	}
+
+	// Nominal kosong berarti refund penuh atas sisa transaksi
+	if request.Amount == 0 {
+		request.Amount = remaining
+	}
+
+	if request.Amount > remaining {
+		s.logger.Error("refund exceeds remaining amount", zap.Int("RefundAmount", request.Amount), zap.Int("Remaining", remaining))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Refund amount exceeds the refundable amount",
+		}
+	}
+
+	request.MerchantID = &merchant.MerchantID
+	request.CardNumber = transaction.CardNumber
+
+	refund, err := s.refundRepository.Create(request)
+	if err != nil {
+		s.logger.Error("failed to create refund", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create refund",
+		}
+	}
+
+	// Kembalikan dana dari kartu merchant, atau dari saldo pending merchant,
+	// ke kartu pelanggan
+	unit := s.ledger.Begin()
+
+	s.accrue(unit, to, requests.CreateSettlementItemRequest{
+		TransactionID: transaction.TransactionID,
+		Type:          models.SettlementItemRefund,
+		ReferenceID:   refund.RefundID,
+		Amount:        -refund.Amount,
+	})
+
+	if _, err := s.ledger.PostWith(unit, to.refund(transaction.CardNumber, refund.Amount, refund.RefundID)); err != nil {
+		s.logger.Error("failed to post refund entry", zap.Error(err), zap.Int("RefundAmount", refund.Amount))
+
+		if deleteErr := s.refundRepository.Delete(refund.RefundID); deleteErr != nil {
+			s.logger.Error("failed to remove refund after posting failure", zap.Error(deleteErr))
+		}
+
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient merchant balance for refund",
+			}
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update saldo",
+		}
+	}
+
+	so := s.refundMapper.ToRefundResponse(*refund)
+	so.RemainingAmount = remaining - refund.Amount
+	s.webhooks.Publish(merchantID, models.WebhookEventTransactionRefunded, so)
+
+	return &response.ApiResponse[*response.RefundResponse]{
+		Status:  "success",
+		Message: "Refund created successfully",
+		Data:    so,
+	}, nil
+}
+
 func (s *transactionService) Delete(transactionID int) (*response.ApiResponse[string], *response.ErrorResponse) {
 	err := s.transactionRepository.Delete(transactionID)
 	if err != nil {
@@ -337,3 +606,126 @@ func (s *transactionService) Delete(transactionID int) (*response.ApiResponse[st
 		Data:    fmt.Sprintf("Transaction with ID %d has been deleted", transactionID),
 	}, nil
 }
+
+func (s *transactionService) UpdateStatus(merchantID int, request requests.UpdateStatusRequest) (*response.ApiResponse[*response.TransactionResponse], *response.ErrorResponse) {
+	s.refundMu.Lock()
+	defer s.refundMu.Unlock()
+
+	transaction, err := s.transactionRepository.Read(request.ID)
+	if err != nil {
+		s.logger.Error("failed to find transaction", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transaction not found",
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(merchantID)
+
+	if err != nil || transaction.MerchantID != merchant.MerchantID {
+		s.logger.Error("unauthorized access to transaction", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized access to transaction",
+		}
+	}
+
+	if !canTransitionStatus(transaction.Status, request.Status) {
+		s.logger.Error("invalid transaction status transition", zap.String("from", transaction.Status), zap.String("to", request.Status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot change transaction status from %s to %s", transaction.Status, request.Status),
+		}
+	}
+
+	// Status reversed membalik pembayaran lewat entri jurnal kompensasi,
+	// disimpan bersama perubahan status dalam satu unit of work
+	unit := s.ledger.Begin()
+
+	if request.Status == models.StatusReversed {
+		// Hanya transaksi yang benar-benar memindahkan saldo yang bisa dibalik
+		if posted, err := s.ledger.Posted(ledger.ReferenceTransaction, transaction.TransactionID); err != nil || !posted {
+			s.logger.Error("transaction has no ledger entry to reverse", zap.Error(err), zap.Int("transactionID", transaction.TransactionID))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Transaction has no ledger entry to reverse",
+			}
+		}
+
+		// Transaksi yang sudah direfund sebagian harus diselesaikan lewat refund
+		refunded, err := s.refundRepository.TotalByTransactionID(transaction.TransactionID)
+		if err != nil || refunded > 0 {
+			s.logger.Error("cannot reverse refunded transaction", zap.Error(err), zap.Int("Refunded", refunded))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Transaction with refunds cannot be reversed",
+			}
+		}
+
+		merchantCard, err := s.cardRepository.ReadByUserID(merchant.UserID)
+		if err != nil {
+			s.logger.Error("failed to find merchant card", zap.Error(err))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Merchant card not found",
+			}
+		}
+
+		to, err := s.payeeOf(merchant, merchantCard, transaction.TransactionID)
+		if err != nil {
+			s.logger.Error("failed to find settlement of transaction", zap.Error(err))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Failed to reverse transaction",
+			}
+		}
+
+		s.accrue(unit, to, requests.CreateSettlementItemRequest{
+			TransactionID: transaction.TransactionID,
+			Type:          models.SettlementItemReversal,
+			ReferenceID:   transaction.TransactionID,
+			Amount:        -transaction.Amount,
+			Fee:           -transaction.Fee,
+		})
+
+		entry := ledger.Reverse(to.payment(transaction.CardNumber, transaction.Amount, transaction.Fee, transaction.TransactionID))
+
+		if _, err := s.ledger.Stage(unit, entry); err != nil {
+			s.logger.Error("failed to post transaction reversal", zap.Error(err))
+
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Failed to reverse transaction",
+			}
+		}
+	}
+
+	pending := unit.Transaction.UpdateStatus(transaction.TransactionID, request.Status)
+
+	if err := s.ledger.Commit(unit); err != nil {
+		s.logger.Error("failed to update transaction status", zap.Error(err))
+
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient merchant balance to reverse transaction",
+			}
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update transaction status",
+		}
+	}
+
+	updated := pending.Value()
+
+	so := s.mapper.ToTransactionResponse(*updated)
+	s.webhooks.Publish(merchantID, models.WebhookEventTransactionStatusChanged, so)
+
+	return &response.ApiResponse[*response.TransactionResponse]{
+		Status:  "success",
+		Message: "Transaction status updated successfully",
+		Data:    so,
+	}, nil
+}
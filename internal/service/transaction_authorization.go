@@ -0,0 +1,311 @@
+package service
+
+import (
+	"errors"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/models"
+	"payment-mutex/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func (s *transactionService) Authorize(merchantID int, request requests.CreateAuthorizationRequest) (*response.ApiResponse[*response.AuthorizationResponse], *response.ErrorResponse) {
+	merchant, err := s.merchantRepository.Read(merchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	if _, err := s.cardRepository.ReadByCardNumber(request.CardNumber); err != nil {
+		s.logger.Error("failed to find card", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Card not found",
+		}
+	}
+
+	s.authorizationMu.Lock()
+	defer s.authorizationMu.Unlock()
+
+	// Dana pelanggan ditahan dulu, baru dipindahkan ke merchant saat capture
+	if err := s.ledger.Hold(request.CardNumber, request.Amount); err != nil {
+		s.logger.Error("failed to hold balance for authorization", zap.Error(err), zap.String("cardNumber", request.CardNumber), zap.Int("requested", request.Amount))
+
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient balance",
+			}
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to hold balance",
+		}
+	}
+
+	request.MerchantID = &merchant.MerchantID
+	request.ExpiresAt = time.Now().Add(s.authorizationTTL)
+
+	authorization, err := s.authorizationRepository.Create(request)
+	if err != nil {
+		s.logger.Error("failed to create authorization", zap.Error(err))
+
+		if releaseErr := s.ledger.Release(request.CardNumber, request.Amount); releaseErr != nil {
+			s.logger.Error("failed to release balance after authorization failure", zap.Error(releaseErr))
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create authorization",
+		}
+	}
+
+	so := s.authorizationMapper.ToAuthorizationResponse(*authorization)
+	s.webhooks.Publish(merchantID, models.WebhookEventAuthorizationCreated, so)
+
+	return &response.ApiResponse[*response.AuthorizationResponse]{
+		Status:  "success",
+		Message: "Transaction authorized successfully",
+		Data:    so,
+	}, nil
+}
+
+func (s *transactionService) Capture(merchantID int, request requests.CaptureAuthorizationRequest) (*response.ApiResponse[*response.AuthorizationResponse], *response.ErrorResponse) {
+	s.authorizationMu.Lock()
+	defer s.authorizationMu.Unlock()
+
+	authorization, errRes := s.findActiveAuthorization(merchantID, request.AuthorizationID)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	// Nominal kosong berarti seluruh dana yang diotorisasi di-capture
+	amount := request.Amount
+	if amount == 0 {
+		amount = authorization.Amount
+	}
+
+	if amount > authorization.Amount {
+		s.logger.Error("capture exceeds authorized amount", zap.Int("requested", amount), zap.Int("authorized", authorization.Amount))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Capture amount exceeds the authorized amount",
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(authorization.MerchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	merchantCard, err := s.cardRepository.ReadByUserID(merchant.UserID)
+	if err != nil {
+		s.logger.Error("failed to find merchant card", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant card not found",
+		}
+	}
+
+	quote, err := quoteFee(s.feePlanRepository, merchant, authorization.PaymentMethod, amount)
+	if err != nil {
+		s.logger.Error("failed to quote fee", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to calculate fee",
+		}
+	}
+
+	transaction, err := s.transactionRepository.Create(requests.CreateTransactionRequest{
+		CardNumber:      authorization.CardNumber,
+		Amount:          amount,
+		PaymentMethod:   authorization.PaymentMethod,
+		MerchantID:      &authorization.MerchantID,
+		TransactionTime: time.Now(),
+		Fee:             quote.Fee,
+	})
+	if err != nil {
+		s.logger.Error("failed to create transaction for capture", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create transaction record",
+		}
+	}
+
+	if _, err := s.transactionRepository.UpdateStatus(transaction.TransactionID, models.StatusProcessing); err != nil {
+		s.logger.Error("failed to mark transaction as processing", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to process transaction",
+		}
+	}
+
+	to := newPayee(merchant, merchantCard)
+
+	unit := s.ledger.Begin()
+
+	s.accrue(unit, to, requests.CreateSettlementItemRequest{
+		TransactionID: transaction.TransactionID,
+		Type:          models.SettlementItemPayment,
+		ReferenceID:   transaction.TransactionID,
+		Amount:        amount,
+		Fee:           transaction.Fee,
+	})
+
+	unit.Transaction.UpdateStatus(transaction.TransactionID, models.StatusSucceeded)
+	captured := unit.Authorization.UpdateStatus(requests.UpdateAuthorizationStatus{
+		AuthorizationID: authorization.AuthorizationID,
+		Status:          models.AuthorizationCaptured,
+		CapturedAmount:  amount,
+		TransactionID:   transaction.TransactionID,
+	})
+
+	// Hold dilepas, dana dipindahkan ke merchant dan kedua status diubah dalam satu langkah
+	if _, err := s.ledger.CaptureWith(unit, authorization.CardNumber, authorization.Amount, to.payment(authorization.CardNumber, amount, transaction.Fee, transaction.TransactionID)); err != nil {
+		s.logger.Error("failed to capture authorization", zap.Error(err))
+
+		if _, statusErr := s.transactionRepository.UpdateStatus(transaction.TransactionID, models.StatusFailed); statusErr != nil {
+			s.logger.Error("failed to mark transaction as failed", zap.Error(statusErr))
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to capture authorization",
+		}
+	}
+
+	so := s.authorizationMapper.ToAuthorizationResponse(*captured.Value())
+	s.webhooks.Publish(merchantID, models.WebhookEventAuthorizationCaptured, so)
+
+	return &response.ApiResponse[*response.AuthorizationResponse]{
+		Status:  "success",
+		Message: "Authorization captured successfully",
+		Data:    so,
+	}, nil
+}
+
+func (s *transactionService) Void(merchantID int, request requests.VoidAuthorizationRequest) (*response.ApiResponse[*response.AuthorizationResponse], *response.ErrorResponse) {
+	s.authorizationMu.Lock()
+	defer s.authorizationMu.Unlock()
+
+	authorization, errRes := s.findActiveAuthorization(merchantID, request.AuthorizationID)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	voided, err := s.releaseAuthorization(authorization, models.AuthorizationVoided)
+	if err != nil {
+		s.logger.Error("failed to void authorization", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to void authorization",
+		}
+	}
+
+	so := s.authorizationMapper.ToAuthorizationResponse(*voided)
+	s.webhooks.Publish(merchantID, models.WebhookEventAuthorizationVoided, so)
+
+	return &response.ApiResponse[*response.AuthorizationResponse]{
+		Status:  "success",
+		Message: "Authorization voided successfully",
+		Data:    so,
+	}, nil
+}
+
+// ExpireAuthorizations releases every authorization that outlived its expiry
+// and returns how many were released.
+func (s *transactionService) ExpireAuthorizations() int {
+	s.authorizationMu.Lock()
+	defer s.authorizationMu.Unlock()
+
+	authorizations, err := s.authorizationRepository.ReadExpired(time.Now())
+	if err != nil {
+		s.logger.Error("failed to read expired authorizations", zap.Error(err))
+		return 0
+	}
+
+	expired := 0
+
+	for _, authorization := range authorizations {
+		if _, err := s.releaseAuthorization(authorization, models.AuthorizationExpired); err != nil {
+			s.logger.Error("failed to expire authorization", zap.Error(err), zap.Int("authorizationID", authorization.AuthorizationID))
+			continue
+		}
+
+		expired++
+	}
+
+	return expired
+}
+
+// findActiveAuthorization returns the authorization when it belongs to the
+// merchant with merchantID and can still be captured or voided. An authorization
+// found past its expiry is released on the spot. It must be called with
+// s.authorizationMu held.
+func (s *transactionService) findActiveAuthorization(merchantID int, authorizationID int) (*record.AuthorizationRecord, *response.ErrorResponse) {
+	authorization, err := s.authorizationRepository.Read(authorizationID)
+	if err != nil {
+		s.logger.Error("failed to find authorization", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Authorization not found",
+		}
+	}
+
+	merchant, err := s.merchantRepository.Read(merchantID)
+	if err != nil || authorization.MerchantID != merchant.MerchantID {
+		s.logger.Error("unauthorized access to authorization", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Unauthorized access to authorization",
+		}
+	}
+
+	if authorization.Status != models.AuthorizationAuthorized {
+		s.logger.Error("authorization is no longer active", zap.String("status", authorization.Status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Authorization is already " + authorization.Status,
+		}
+	}
+
+	if !time.Now().Before(authorization.ExpiresAt) {
+		if _, err := s.releaseAuthorization(authorization, models.AuthorizationExpired); err != nil {
+			s.logger.Error("failed to expire authorization", zap.Error(err))
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Authorization has expired",
+		}
+	}
+
+	return authorization, nil
+}
+
+// releaseAuthorization returns the held amount to the card and closes the
+// authorization with status in one unit of work.
+func (s *transactionService) releaseAuthorization(authorization *record.AuthorizationRecord, status string) (*record.AuthorizationRecord, error) {
+	unit := s.ledger.Begin()
+	released := unit.Authorization.UpdateStatus(requests.UpdateAuthorizationStatus{
+		AuthorizationID: authorization.AuthorizationID,
+		Status:          status,
+	})
+
+	if err := s.ledger.ReleaseWith(unit, authorization.CardNumber, authorization.Amount); err != nil {
+		return nil, err
+	}
+
+	return released.Value(), nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/ledger"
+	"payment-mutex/internal/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+// authorize holds amount on cardNumber for merchantID and returns the
+// authorization ID.
+func (p *testPlatform) authorize(t *testing.T, merchantID int, cardNumber string, amount int) int {
+	t.Helper()
+
+	res, errRes := p.services.Transaction.Authorize(merchantID, requests.CreateAuthorizationRequest{CardNumber: cardNumber, Amount: amount, PaymentMethod: "dana"})
+	if errRes != nil {
+		t.Fatalf("authorize %d: %s", amount, errRes.Message)
+	}
+
+	return res.Data.ID
+}
+
+// held returns the held balance of cardNumber.
+func (p *testPlatform) held(t *testing.T, cardNumber string) int {
+	t.Helper()
+
+	saldo, err := p.repos.Saldo.ReadByCardNumber(cardNumber)
+	if err != nil {
+		t.Fatalf("read saldo: %v", err)
+	}
+
+	return saldo.HeldBalance
+}
+
+// authorizationStep acts on authorization id of merchantID and returns the
+// error message, or "" when it went through.
+type authorizationStep func(p *testPlatform, merchantID int, id int) string
+
+func TestAuthorizationCaptureAndVoid(t *testing.T) {
+	capture := func(amount int) authorizationStep {
+		return func(p *testPlatform, merchantID int, id int) string {
+			_, errRes := p.services.Transaction.Capture(merchantID, requests.CaptureAuthorizationRequest{AuthorizationID: id, Amount: amount})
+			return errorMessage(errRes)
+		}
+	}
+
+	void := func(p *testPlatform, merchantID int, id int) string {
+		_, errRes := p.services.Transaction.Void(merchantID, requests.VoidAuthorizationRequest{AuthorizationID: id})
+		return errorMessage(errRes)
+	}
+
+	tests := []struct {
+		name string
+		// ttl is how long the authorization is valid, a negative one has
+		// expired by the time it is used.
+		ttl          time.Duration
+		steps        []authorizationStep
+		want         []string
+		wantStatus   string
+		wantCustomer int
+		wantMerchant int
+	}{
+		{
+			name:         "full capture",
+			ttl:          time.Hour,
+			steps:        []authorizationStep{capture(0)},
+			want:         []string{""},
+			wantStatus:   models.AuthorizationCaptured,
+			wantCustomer: 70000,
+			wantMerchant: 30000,
+		},
+		{
+			name:         "partial capture releases the rest",
+			ttl:          time.Hour,
+			steps:        []authorizationStep{capture(10000)},
+			want:         []string{""},
+			wantStatus:   models.AuthorizationCaptured,
+			wantCustomer: 90000,
+			wantMerchant: 10000,
+		},
+		{
+			name:         "capture above the hold",
+			ttl:          time.Hour,
+			steps:        []authorizationStep{capture(30001), void},
+			want:         []string{"Capture amount exceeds the authorized amount", ""},
+			wantStatus:   models.AuthorizationVoided,
+			wantCustomer: 100000,
+		},
+		{
+			name:         "capture after void",
+			ttl:          time.Hour,
+			steps:        []authorizationStep{void, capture(0), void},
+			want:         []string{"", "Authorization is already voided", "Authorization is already voided"},
+			wantStatus:   models.AuthorizationVoided,
+			wantCustomer: 100000,
+		},
+		{
+			name:         "capture after capture",
+			ttl:          time.Hour,
+			steps:        []authorizationStep{capture(0), capture(0), void},
+			want:         []string{"", "Authorization is already captured", "Authorization is already captured"},
+			wantStatus:   models.AuthorizationCaptured,
+			wantCustomer: 70000,
+			wantMerchant: 30000,
+		},
+		{
+			name:         "capture after expiry",
+			ttl:          -time.Second,
+			steps:        []authorizationStep{capture(0), capture(0), void},
+			want:         []string{"Authorization has expired", "Authorization is already expired", "Authorization is already expired"},
+			wantStatus:   models.AuthorizationExpired,
+			wantCustomer: 100000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlatform(t)
+			p.services.Transaction.(*transactionService).authorizationTTL = tt.ttl
+
+			merchantID, merchantCard := p.merchant(t, 0)
+			_, customerCard := p.card(t, 100000)
+			id := p.authorize(t, merchantID, customerCard, 30000)
+
+			if held := p.held(t, customerCard); held != 30000 {
+				t.Fatalf("held %d after authorizing, want 30000", held)
+			}
+
+			for i, step := range tt.steps {
+				if got := step(p, merchantID, id); got != tt.want[i] {
+					t.Errorf("step %d: got %q, want %q", i+1, got, tt.want[i])
+				}
+			}
+
+			authorization, err := p.repos.Authorization.Read(id)
+			if err != nil {
+				t.Fatalf("read authorization: %v", err)
+			}
+
+			if authorization.Status != tt.wantStatus {
+				t.Errorf("status %q, want %q", authorization.Status, tt.wantStatus)
+			}
+
+			if held := p.held(t, customerCard); held != 0 {
+				t.Errorf("held %d after the authorization closed, want 0", held)
+			}
+
+			if customer, merchant := p.balance(t, customerCard), p.balance(t, merchantCard); customer != tt.wantCustomer || merchant != tt.wantMerchant {
+				t.Errorf("customer %d, merchant %d; want %d, %d", customer, merchant, tt.wantCustomer, tt.wantMerchant)
+			}
+
+			p.checkBooks(t, []string{customerCard, merchantCard}, ledger.AccountFeeRevenue)
+		})
+	}
+}
+
+func TestAuthorizationParallelCaptureAndVoid(t *testing.T) {
+	p := newTestPlatform(t)
+
+	merchantID, merchantCard := p.merchant(t, 0)
+	_, customerCard := p.card(t, 100000)
+
+	for round := 0; round < 20; round++ {
+		id := p.authorize(t, merchantID, customerCard, 1000)
+
+		var wg sync.WaitGroup
+		var captured, voided bool
+
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			_, errRes := p.services.Transaction.Capture(merchantID, requests.CaptureAuthorizationRequest{AuthorizationID: id})
+			captured = errRes == nil
+		}()
+
+		go func() {
+			defer wg.Done()
+			_, errRes := p.services.Transaction.Void(merchantID, requests.VoidAuthorizationRequest{AuthorizationID: id})
+			voided = errRes == nil
+		}()
+
+		wg.Wait()
+
+		if captured == voided {
+			t.Fatalf("round %d: captured = %v and voided = %v, want exactly one", round, captured, voided)
+		}
+
+		if held := p.held(t, customerCard); held != 0 {
+			t.Fatalf("round %d: held %d, want 0", round, held)
+		}
+	}
+
+	p.checkBooks(t, []string{customerCard, merchantCard})
+}
+
+func TestExpireAuthorizations(t *testing.T) {
+	p := newTestPlatform(t)
+	s := p.services.Transaction.(*transactionService)
+
+	merchantID, _ := p.merchant(t, 0)
+	_, customerCard := p.card(t, 100000)
+
+	s.authorizationTTL = -time.Second
+	expired := p.authorize(t, merchantID, customerCard, 10000)
+
+	s.authorizationTTL = time.Hour
+	active := p.authorize(t, merchantID, customerCard, 20000)
+
+	if n := s.ExpireAuthorizations(); n != 1 {
+		t.Errorf("expired %d authorizations, want 1", n)
+	}
+
+	for _, tt := range []struct {
+		id     int
+		status string
+	}{
+		{expired, models.AuthorizationExpired},
+		{active, models.AuthorizationAuthorized},
+	} {
+		authorization, err := p.repos.Authorization.Read(tt.id)
+		if err != nil {
+			t.Fatalf("read authorization: %v", err)
+		}
+
+		if authorization.Status != tt.status {
+			t.Errorf("authorization %d: status %q, want %q", tt.id, authorization.Status, tt.status)
+		}
+	}
+
+	if held := p.held(t, customerCard); held != 20000 {
+		t.Errorf("held %d, want only the 20000 of the active authorization", held)
+	}
+}
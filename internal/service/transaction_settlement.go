@@ -0,0 +1,74 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/ledger"
+	"payment-mutex/internal/models"
+	"payment-mutex/internal/repository"
+)
+
+// payee is where the proceeds of a transaction go: the merchant card, or the
+// pending balance of a merchant settled in batches.
+type payee struct {
+	merchantID int
+	cardNumber string
+	deferred   bool
+}
+
+// newPayee returns where a new transaction of merchant is paid to.
+func newPayee(merchant *record.MerchantRecord, merchantCard *record.CardRecord) payee {
+	return payee{
+		merchantID: merchant.MerchantID,
+		cardNumber: merchantCard.CardNumber,
+		deferred:   merchant.SettlementMode == models.SettlementModeBatch,
+	}
+}
+
+// payment moves amount to the payee. An instant payee is paid less fee at
+// once; a deferred payee has the whole amount accrued and the fee is kept
+// when its batch is paid out.
+func (p payee) payment(cardNumber string, amount int, fee int, transactionID int) requests.CreateJournalEntryRequest {
+	if p.deferred {
+		return ledger.DeferredPaymentEntry(cardNumber, p.merchantID, amount, transactionID)
+	}
+
+	return ledger.PaymentEntry(cardNumber, p.cardNumber, amount, fee, transactionID)
+}
+
+func (p payee) refund(cardNumber string, amount int, refundID int) requests.CreateJournalEntryRequest {
+	if p.deferred {
+		return ledger.DeferredRefundEntry(cardNumber, p.merchantID, amount, refundID)
+	}
+
+	return ledger.RefundEntry(cardNumber, p.cardNumber, amount, refundID)
+}
+
+// payeeOf returns where transactionID was paid to. It follows the
+// transaction rather than the merchant, so switching the settlement mode
+// does not move later refunds or reversals to another account.
+func (s *transactionService) payeeOf(merchant *record.MerchantRecord, merchantCard *record.CardRecord, transactionID int) (payee, error) {
+	items, err := s.settlementItemRepository.ReadByTransactionID(transactionID)
+	if err != nil {
+		return payee{}, err
+	}
+
+	return payee{
+		merchantID: merchant.MerchantID,
+		cardNumber: merchantCard.CardNumber,
+		deferred:   len(items) > 0,
+	}, nil
+}
+
+// accrue stages a settlement item on unit when the payee is settled in
+// batches. Nothing is staged for instant payees or for items that move
+// nothing.
+func (s *transactionService) accrue(unit *repository.UnitOfWork, to payee, request requests.CreateSettlementItemRequest) {
+	if !to.deferred || (request.Amount == 0 && request.Fee == 0) {
+		return
+	}
+
+	request.MerchantID = to.merchantID
+
+	unit.SettlementItem.Create(request)
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/ledger"
+	recordmapper "payment-mutex/internal/mapper/record"
+	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/repository"
+	"payment-mutex/pkg/hash"
+	"payment-mutex/pkg/logger"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// testPlatform holds services on in-memory repositories, together with a
+// ledger for setting balances up and reading them back.
+type testPlatform struct {
+	services *Services
+	repos    *repository.Repositories
+	ledger   *ledger.Ledger
+	users    int
+}
+
+func newTestPlatform(t *testing.T) *testPlatform {
+	t.Helper()
+
+	r, err := repository.NewRepositorys(repository.Deps{MapperRecord: *recordmapper.NewRecordMapper(), StorageDriver: repository.StorageDriverMemory})
+	if err != nil {
+		t.Fatalf("open repositories: %v", err)
+	}
+
+	services := NewServices(Deps{
+		Repository:         r,
+		Logger:             logger.Logger{Log: zap.NewNop()},
+		Hash:               hash.NewHashingPassword(),
+		MapperResponse:     *responseMapper.NewResponseMapper(),
+		WithdrawHoldTTL:    time.Hour,
+		AuthorizationTTL:   time.Hour,
+		CheckoutSessionTTL: time.Hour,
+		Pin:                PinPolicy{Threshold: 1 << 30, MaxAttempts: 3, Lockout: time.Hour},
+	})
+
+	return &testPlatform{
+		services: services,
+		repos:    r,
+		ledger:   ledger.NewLedger(r.Journal, r.Saldo, r),
+	}
+}
+
+// card creates a user with a card holding balance and returns the user ID
+// and card number.
+func (p *testPlatform) card(t *testing.T, balance int) (int, string) {
+	t.Helper()
+
+	p.users++
+
+	card, err := p.repos.Card.Create(requests.CreateCardRequest{UserID: p.users, CardType: "debit"})
+	if err != nil {
+		t.Fatalf("create card: %v", err)
+	}
+
+	saldo, err := p.repos.Saldo.Create(requests.CreateSaldoRequest{CardNumber: card.CardNumber})
+	if err != nil {
+		t.Fatalf("create saldo: %v", err)
+	}
+
+	if balance > 0 {
+		if _, err := p.ledger.Post(ledger.TopupEntry(card.CardNumber, balance, saldo.SaldoID)); err != nil {
+			t.Fatalf("top up card: %v", err)
+		}
+	}
+
+	return p.users, card.CardNumber
+}
+
+// merchant creates a merchant whose owner has a card holding balance and
+// returns the merchant ID and its card number.
+func (p *testPlatform) merchant(t *testing.T, balance int) (int, string) {
+	t.Helper()
+
+	userID, cardNumber := p.card(t, balance)
+
+	merchant, err := p.repos.Merchant.Create(requests.CreateMerchantRequest{Name: "Shop", UserID: userID})
+	if err != nil {
+		t.Fatalf("create merchant: %v", err)
+	}
+
+	return merchant.MerchantID, cardNumber
+}
+
+// feePlan charges merchantID rate basis points plus fixed on every payment.
+func (p *testPlatform) feePlan(t *testing.T, merchantID int, rate int, fixed int) {
+	t.Helper()
+
+	plan, err := p.repos.FeePlan.Create(requests.CreateFeePlanRequest{Name: "Plan", DefaultRate: rate, DefaultFixed: fixed})
+	if err != nil {
+		t.Fatalf("create fee plan: %v", err)
+	}
+
+	if _, err := p.repos.Merchant.UpdateFeePlan(merchantID, plan.FeePlanID); err != nil {
+		t.Fatalf("assign fee plan: %v", err)
+	}
+}
+
+// pay pays amount from cardNumber to merchantID and returns the transaction
+// ID.
+func (p *testPlatform) pay(t *testing.T, merchantID int, cardNumber string, amount int) int {
+	t.Helper()
+
+	res, errRes := p.services.Transaction.Create(merchantID, requests.CreateTransactionRequest{CardNumber: cardNumber, Amount: amount, PaymentMethod: "dana"})
+	if errRes != nil {
+		t.Fatalf("pay %d: %s", amount, errRes.Message)
+	}
+
+	return res.Data.ID
+}
+
+// checkBooks fails t unless every card saldo matches the journal and the
+// card accounts together with accounts add up to zero.
+func (p *testPlatform) checkBooks(t *testing.T, cards []string, accounts ...string) {
+	t.Helper()
+
+	sum := 0
+
+	for _, cardNumber := range cards {
+		saldo, err := p.repos.Saldo.ReadByCardNumber(cardNumber)
+		if err != nil {
+			t.Fatalf("read saldo: %v", err)
+		}
+
+		balance, err := p.ledger.Balance(cardNumber)
+		if err != nil {
+			t.Fatalf("journal balance: %v", err)
+		}
+
+		if saldo.TotalBalance != balance {
+			t.Errorf("saldo of %s is %d, journal says %d", cardNumber, saldo.TotalBalance, balance)
+		}
+
+		sum += balance
+	}
+
+	for _, account := range append(accounts, ledger.AccountTopupFunding, ledger.AccountMerchantSettlement) {
+		debit, credit, err := p.repos.Journal.TotalsByAccount(account)
+		if err != nil {
+			t.Fatalf("totals of %s: %v", account, err)
+		}
+
+		sum += credit - debit
+	}
+
+	if sum != 0 {
+		t.Errorf("journal is off by %d", sum)
+	}
+}
+
+// balance returns the saldo of cardNumber.
+func (p *testPlatform) balance(t *testing.T, cardNumber string) int {
+	t.Helper()
+
+	saldo, err := p.repos.Saldo.ReadByCardNumber(cardNumber)
+	if err != nil {
+		t.Fatalf("read saldo: %v", err)
+	}
+
+	return saldo.TotalBalance
+}
+
+func TestTransactionRefund(t *testing.T) {
+	p := newTestPlatform(t)
+
+	merchantID, merchantCard := p.merchant(t, 5000)
+	_, customerCard := p.card(t, 100000)
+
+	// Fee 1% + 500 dari 50.000 adalah 1.000 dan tidak ikut dikembalikan
+	p.feePlan(t, merchantID, 100, 500)
+	transactionID := p.pay(t, merchantID, customerCard, 50000)
+
+	// Setiap langkah berjalan di atas hasil langkah sebelumnya
+	tests := []struct {
+		name          string
+		amount        int
+		want          string
+		wantRemaining int
+		wantCustomer  int
+		wantMerchant  int
+	}{
+		{"partial", 20000, "", 30000, 70000, 34000},
+		{"second partial", 20000, "", 10000, 90000, 14000},
+		{"more than is left", 20000, "Refund amount exceeds the refundable amount", 0, 90000, 14000},
+		{"rest of the amount", 0, "", 0, 100000, 4000},
+		{"after a full refund", 1, "Transaction has already been fully refunded", 0, 100000, 4000},
+	}
+
+	for _, tt := range tests {
+		res, errRes := p.services.Transaction.Refund(merchantID, requests.CreateRefundRequest{TransactionID: transactionID, Amount: tt.amount})
+		if got := errorMessage(errRes); got != tt.want {
+			t.Fatalf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+
+		if errRes == nil && res.Data.RemainingAmount != tt.wantRemaining {
+			t.Errorf("%s: %d left to refund, want %d", tt.name, res.Data.RemainingAmount, tt.wantRemaining)
+		}
+
+		if customer, merchant := p.balance(t, customerCard), p.balance(t, merchantCard); customer != tt.wantCustomer || merchant != tt.wantMerchant {
+			t.Errorf("%s: customer %d, merchant %d; want %d, %d", tt.name, customer, merchant, tt.wantCustomer, tt.wantMerchant)
+		}
+
+		p.checkBooks(t, []string{customerCard, merchantCard}, ledger.AccountFeeRevenue)
+	}
+
+	refunded, err := p.repos.Refund.TotalByTransactionID(transactionID)
+	if err != nil {
+		t.Fatalf("sum refunds: %v", err)
+	}
+
+	if refunded != 50000 {
+		t.Errorf("refunded %d, want the 50000 paid", refunded)
+	}
+}
+
+func TestTransactionRefundWithoutMerchantBalance(t *testing.T) {
+	p := newTestPlatform(t)
+
+	merchantID, merchantCard := p.merchant(t, 0)
+	_, customerCard := p.card(t, 100000)
+	transactionID := p.pay(t, merchantID, customerCard, 50000)
+
+	// Saldo merchant dipakai dulu sehingga refund penuh tidak tertutup
+	if _, err := p.ledger.Post(ledger.WithdrawEntry(merchantCard, 40000, 1)); err != nil {
+		t.Fatalf("withdraw merchant balance: %v", err)
+	}
+
+	_, errRes := p.services.Transaction.Refund(merchantID, requests.CreateRefundRequest{TransactionID: transactionID})
+	if got := errorMessage(errRes); got != "Insufficient merchant balance for refund" {
+		t.Fatalf("refund: got %q, want insufficient merchant balance", got)
+	}
+
+	// Refund yang gagal tidak boleh mengurangi sisa yang bisa direfund
+	refunds, err := p.repos.Refund.ReadByTransactionID(transactionID)
+	if err != nil {
+		t.Fatalf("read refunds: %v", err)
+	}
+
+	if len(refunds) != 0 {
+		t.Errorf("%d refunds left behind by a failed refund", len(refunds))
+	}
+
+	p.checkBooks(t, []string{customerCard, merchantCard}, ledger.AccountWithdrawalPayout)
+}
+
+func TestTransactionParallelRefunds(t *testing.T) {
+	p := newTestPlatform(t)
+
+	merchantID, merchantCard := p.merchant(t, 0)
+	_, customerCard := p.card(t, 100000)
+	transactionID := p.pay(t, merchantID, customerCard, 50000)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+
+	// Hanya dua refund 20.000 yang muat di transaksi 50.000
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, errRes := p.services.Transaction.Refund(merchantID, requests.CreateRefundRequest{TransactionID: transactionID, Amount: 20000}); errRes == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if succeeded != 2 {
+		t.Errorf("%d parallel refunds went through, want 2", succeeded)
+	}
+
+	refunded, err := p.repos.Refund.TotalByTransactionID(transactionID)
+	if err != nil {
+		t.Fatalf("sum refunds: %v", err)
+	}
+
+	if refunded != 40000 {
+		t.Errorf("refunded %d, want 40000", refunded)
+	}
+
+	p.checkBooks(t, []string{customerCard, merchantCard})
+}
@@ -1,12 +1,16 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/ledger"
 	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/models"
 	"payment-mutex/internal/repository"
 	"payment-mutex/pkg/logger"
+	"sync"
 
 	"go.uber.org/zap"
 )
@@ -16,25 +20,33 @@ type transferService struct {
 	cardRepository     repository.CardRepository
 	saldoRepository    repository.SaldoRepository
 	transferRepository repository.TransferRepository
+	ledger             *ledger.Ledger
+	pin                PinService
 	logger             logger.Logger
 	mapper             responseMapper.TransferResponseMapper
+
+	// mu menjaga agar perubahan nominal dan status transfer tidak saling mendahului
+	mu sync.Mutex
 }
 
 func NewTransferService(
 	userRepository repository.UserRepository,
 	cardRepository repository.CardRepository,
 	transferRepository repository.TransferRepository,
-	saldoRepository repository.SaldoRepository, logger logger.Logger, mapper responseMapper.TransferResponseMapper) *transferService {
+	saldoRepository repository.SaldoRepository, ledger *ledger.Ledger, pin PinService, logger logger.Logger, mapper responseMapper.TransferResponseMapper) *transferService {
 	return &transferService{
 		userRepository:     userRepository,
+		cardRepository:     cardRepository,
 		transferRepository: transferRepository,
 		saldoRepository:    saldoRepository,
+		ledger:             ledger,
+		pin:                pin,
 		logger:             logger,
 		mapper:             mapper,
 	}
 }
 
-func (s *transferService) FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.TransferResponse], *response.ErrorResponse) {
+func (s *transferService) FindAll(page int, pageSize int, search string, status string) (*response.APIResponsePagination[[]*response.TransferResponse], *response.ErrorResponse) {
 	if page <= 0 {
 		page = 1
 	}
@@ -43,7 +55,15 @@ func (s *transferService) FindAll(page int, pageSize int, search string) (*respo
 		pageSize = 10
 	}
 
-	transfers, totalRecords, err := s.transferRepository.ReadAll(page, pageSize, search)
+	if status != "" && !isKnownStatus(status) {
+		s.logger.Error("invalid status filter", zap.String("status", status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid status filter",
+		}
+	}
+
+	transfers, totalRecords, err := s.transferRepository.ReadAll(page, pageSize, search, status)
 
 	if err != nil {
 		s.logger.Error("failed to fetch transfers", zap.Error(err))
@@ -78,7 +98,7 @@ func (s *transferService) FindAll(page int, pageSize int, search string) (*respo
 	}, nil
 }
 
-func (s *transferService) FindById(transferID int) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse) {
+func (s *transferService) FindById(caller requests.Caller, transferID int) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse) {
 	transfer, err := s.transferRepository.Read(transferID)
 	if err != nil {
 		s.logger.Error("failed to find transfer by ID", zap.Error(err))
@@ -88,6 +108,15 @@ func (s *transferService) FindById(transferID int) (*response.ApiResponse[*respo
 		}
 	}
 
+	// Transfer boleh dilihat oleh pemilik kartu pengirim maupun penerima
+	if !ownsCard(s.cardRepository, caller, transfer.TransferFrom) && !ownsCard(s.cardRepository, caller, transfer.TransferTo) {
+		s.logger.Error("transfer belongs to another user", zap.Int("transfer_id", transferID), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transfer not found",
+		}
+	}
+
 	so := s.mapper.ToTransferResponse(*transfer)
 
 	return &response.ApiResponse[*response.TransferResponse]{
@@ -97,7 +126,7 @@ func (s *transferService) FindById(transferID int) (*response.ApiResponse[*respo
 	}, nil
 }
 
-func (s *transferService) Create(request requests.CreateTransferRequest) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse) {
+func (s *transferService) Create(caller requests.Caller, request requests.CreateTransferRequest) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse) {
 	_, err := s.cardRepository.ReadByCardNumber(request.TransferFrom)
 	if err != nil {
 		s.logger.Error("failed to find sender card by Number", zap.Error(err))
@@ -107,76 +136,80 @@ func (s *transferService) Create(request requests.CreateTransferRequest) (*respo
 		}
 	}
 
-	_, err = s.cardRepository.ReadByCardNumber(request.TransferTo)
-	if err != nil {
-		s.logger.Error("failed to find receiver card by number", zap.Error(err))
+	if !ownsCard(s.cardRepository, caller, request.TransferFrom) {
+		s.logger.Error("sender card belongs to another user", zap.String("card_number", request.TransferFrom), zap.Int("user_id", caller.UserID))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Receiver card not found",
+			Message: "Sender card not found",
 		}
 	}
 
-	senderSaldo, err := s.saldoRepository.ReadByCardNumber(request.TransferFrom)
+	if errRes := s.pin.VerifyAmount(caller, request.Pin, request.TransferAmount); errRes != nil {
+		return nil, errRes
+	}
+
+	_, err = s.cardRepository.ReadByCardNumber(request.TransferTo)
 	if err != nil {
-		s.logger.Error("failed to find sender saldo by card number", zap.Error(err))
+		s.logger.Error("failed to find receiver card by number", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Failed to find sender saldo",
+			Message: "Receiver card not found",
 		}
 	}
 
-	receiverSaldo, err := s.saldoRepository.ReadByCardNumber(request.TransferTo)
+	transfer, err := s.transferRepository.Create(request)
 	if err != nil {
-		s.logger.Error("failed to find receiver saldo by card number", zap.Error(err))
+		s.logger.Error("failed to create transfer", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Failed to find receiver saldo",
+			Message: "Failed to create transfer",
 		}
 	}
 
-	if senderSaldo.TotalBalance < request.TransferAmount {
+	if _, err := s.transferRepository.UpdateStatus(transfer.TransferID, models.StatusProcessing); err != nil {
+		s.logger.Error("failed to mark transfer as processing", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Insufficient balance for sender",
+			Message: "Failed to process transfer",
 		}
 	}
 
-	senderSaldo.TotalBalance -= request.TransferAmount
-	receiverSaldo.TotalBalance += request.TransferAmount
+	// Status succeeded disimpan bersama entri jurnalnya dalam satu unit of work
+	unit := s.ledger.Begin()
+	pending := unit.Transfer.UpdateStatusFrom(transfer.TransferID, models.StatusProcessing, models.StatusSucceeded)
 
-	_, err = s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-		CardNumber:   senderSaldo.CardNumber,
-		TotalBalance: senderSaldo.TotalBalance,
-	})
+	_, err = s.ledger.PostWith(unit, ledger.TransferEntry(request.TransferFrom, request.TransferTo, request.TransferAmount, transfer.TransferID))
 	if err != nil {
-		s.logger.Error("failed to update sender saldo", zap.Error(err))
-		return nil, &response.ErrorResponse{
-			Status:  "error",
-			Message: "Failed to update sender saldo",
+		s.logger.Error("failed to post transfer entry", zap.Error(err))
+
+		// Status diubah admin selama diproses, jurnal tidak diposting dan status itu dipertahankan
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Transfer status changed while it was processed",
+			}
 		}
-	}
 
-	_, err = s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-		CardNumber:   receiverSaldo.CardNumber,
-		TotalBalance: receiverSaldo.TotalBalance,
-	})
-	if err != nil {
-		s.logger.Error("failed to update receiver saldo", zap.Error(err))
-		return nil, &response.ErrorResponse{
-			Status:  "error",
-			Message: "Failed to update receiver saldo",
+		// Transfer yang gagal tetap disimpan dengan status failed
+		if _, statusErr := s.transferRepository.UpdateStatus(transfer.TransferID, models.StatusFailed); statusErr != nil {
+			s.logger.Error("failed to mark transfer as failed", zap.Error(statusErr))
+		}
+
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient balance for sender",
+			}
 		}
-	}
 
-	transfer, err := s.transferRepository.Create(request)
-	if err != nil {
-		s.logger.Error("failed to create transfer", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Failed to create transfer",
+			Message: "Failed to update saldo",
 		}
 	}
 
+	transfer = pending.Value()
+
 	so := s.mapper.ToTransferResponse(*transfer)
 
 	return &response.ApiResponse[*response.TransferResponse]{
@@ -187,6 +220,9 @@ func (s *transferService) Create(request requests.CreateTransferRequest) (*respo
 }
 
 func (s *transferService) Update(request requests.UpdateTransferRequest) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Retrieve the existing transfer
 	transfer, err := s.transferRepository.Read(request.TransferID)
 	if err != nil {
@@ -197,115 +233,55 @@ func (s *transferService) Update(request requests.UpdateTransferRequest) (*respo
 		}
 	}
 
-	// Calculate the amount difference for the update
-	amountDifference := request.TransferAmount - transfer.TransferAmount
-
-	// Update sender's saldo
-	senderSaldo, err := s.saldoRepository.ReadByCardNumber(transfer.TransferFrom)
-	if err != nil {
-		s.logger.Error("Failed to find sender's saldo by user ID", zap.Error(err))
+	if transfer.Status != models.StatusSucceeded {
+		s.logger.Error("cannot update transfer that has not succeeded", zap.String("status", transfer.Status))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: fmt.Sprintf("Failed to find sender's saldo: %v", err),
+			Message: "Only succeeded transfers can be updated",
 		}
 	}
 
-	newSenderBalance := senderSaldo.TotalBalance - amountDifference
-	if newSenderBalance < 0 {
-		s.logger.Error("Insufficient balance for sender", zap.String("senderID", transfer.TransferFrom))
-		return nil, &response.ErrorResponse{
-			Status:  "error",
-			Message: "Insufficient balance for sender",
-		}
-	}
-
-	senderSaldo.TotalBalance = newSenderBalance
-	_, err = s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-		CardNumber:   senderSaldo.CardNumber,
-		TotalBalance: senderSaldo.TotalBalance,
-	})
-	if err != nil {
-		s.logger.Error("Failed to update sender's saldo", zap.Error(err))
-		return nil, &response.ErrorResponse{
-			Status:  "error",
-			Message: fmt.Sprintf("Failed to update sender's saldo: %v", err),
-		}
-	}
-
-	// Update receiver's saldo
-	receiverSaldo, err := s.saldoRepository.ReadByCardNumber(transfer.TransferTo)
-	if err != nil {
-		s.logger.Error("Failed to find receiver's saldo by user ID", zap.Error(err))
-
-		// Rollback the sender's saldo if the receiver's saldo update fails
-		rollbackSenderBalance := requests.UpdateSaldoBalance{
-			CardNumber:   transfer.TransferFrom,
-			TotalBalance: senderSaldo.TotalBalance,
-		}
-		_, rollbackErr := s.saldoRepository.UpdateBalance(rollbackSenderBalance)
-		if rollbackErr != nil {
-			s.logger.Error("Failed to rollback sender's saldo after receiver lookup failure", zap.Error(rollbackErr))
-		}
-
+	// Transfer lama dibatalkan dan transfer baru diterapkan dalam satu entri
+	adjustment := ledger.Combine(
+		ledger.ReferenceTransfer,
+		transfer.TransferID,
+		"transfer update from card "+request.TransferFrom+" to card "+request.TransferTo,
+		ledger.Reverse(ledger.TransferEntry(transfer.TransferFrom, transfer.TransferTo, transfer.TransferAmount, transfer.TransferID)),
+		ledger.TransferEntry(request.TransferFrom, request.TransferTo, request.TransferAmount, transfer.TransferID),
+	)
+
+	// Saldo dan record transfer disimpan dalam satu unit of work supaya
+	// saldo tidak pernah berubah tanpa record transfernya
+	unit := s.ledger.Begin()
+
+	if _, err := s.ledger.Stage(unit, adjustment); err != nil {
+		s.logger.Error("Failed to move transfer difference", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: fmt.Sprintf("Failed to find receiver's saldo: %v", err),
+			Message: fmt.Sprintf("Failed to update saldo: %v", err),
 		}
 	}
 
-	newReceiverBalance := receiverSaldo.TotalBalance + amountDifference
-	receiverSaldo.TotalBalance = newReceiverBalance
-
-	_, err = s.saldoRepository.UpdateBalance(requests.UpdateSaldoBalance{
-		CardNumber:   receiverSaldo.CardNumber,
-		TotalBalance: receiverSaldo.TotalBalance,
-	})
-	if err != nil {
-		s.logger.Error("Failed to update receiver's saldo", zap.Error(err))
-
-		// Rollback both sender's and receiver's saldos if the update fails
-		rollbackSenderBalance := requests.UpdateSaldoBalance{
-			CardNumber:   transfer.TransferFrom,
-			TotalBalance: senderSaldo.TotalBalance + amountDifference,
-		}
-		rollbackReceiverBalance := requests.UpdateSaldoBalance{
-			CardNumber:   transfer.TransferTo,
-			TotalBalance: receiverSaldo.TotalBalance - amountDifference,
-		}
-
-		s.saldoRepository.UpdateBalance(rollbackSenderBalance)
-		s.saldoRepository.UpdateBalance(rollbackReceiverBalance)
-
-		return nil, &response.ErrorResponse{
-			Status:  "error",
-			Message: fmt.Sprintf("Failed to update receiver's saldo: %v", err),
-		}
-	}
+	pending := unit.Transfer.Update(request)
 
-	// Update transfer record
-	updatedTransfer, err := s.transferRepository.Update(request)
-	if err != nil {
+	if err := s.ledger.Commit(unit); err != nil {
 		s.logger.Error("Failed to update transfer", zap.Error(err))
 
-		// Rollback saldos if the transfer update fails
-		rollbackSenderBalance := requests.UpdateSaldoBalance{
-			CardNumber:   transfer.TransferFrom,
-			TotalBalance: senderSaldo.TotalBalance + amountDifference,
-		}
-		rollbackReceiverBalance := requests.UpdateSaldoBalance{
-			CardNumber:   transfer.TransferTo,
-			TotalBalance: receiverSaldo.TotalBalance - amountDifference,
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient balance for transfer update",
+			}
 		}
 
-		s.saldoRepository.UpdateBalance(rollbackSenderBalance)
-		s.saldoRepository.UpdateBalance(rollbackReceiverBalance)
-
 		return nil, &response.ErrorResponse{
 			Status:  "error",
 			Message: fmt.Sprintf("Failed to update transfer: %v", err),
 		}
 	}
 
+	updatedTransfer := pending.Value()
+
 	so := s.mapper.ToTransferResponse(*updatedTransfer)
 
 	// Return the updated transfer in a successful response
@@ -332,3 +308,79 @@ func (s *transferService) Delete(transferID int) (*response.ApiResponse[string],
 		Data:    fmt.Sprintf("Transfer with ID %d has been deleted", transferID),
 	}, nil
 }
+
+func (s *transferService) UpdateStatus(request requests.UpdateStatusRequest) (*response.ApiResponse[*response.TransferResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, err := s.transferRepository.Read(request.ID)
+	if err != nil {
+		s.logger.Error("failed to find transfer", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Transfer not found",
+		}
+	}
+
+	if !canTransitionStatus(transfer.Status, request.Status) {
+		s.logger.Error("invalid transfer status transition", zap.String("from", transfer.Status), zap.String("to", request.Status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot change transfer status from %s to %s", transfer.Status, request.Status),
+		}
+	}
+
+	// Status reversed membalik perpindahan saldo lewat entri jurnal kompensasi,
+	// disimpan bersama perubahan status dalam satu unit of work
+	unit := s.ledger.Begin()
+
+	if request.Status == models.StatusReversed {
+		// Hanya transfer yang benar-benar memindahkan saldo yang bisa dibalik
+		if posted, err := s.ledger.Posted(ledger.ReferenceTransfer, transfer.TransferID); err != nil || !posted {
+			s.logger.Error("transfer has no ledger entry to reverse", zap.Error(err), zap.Int("transferID", transfer.TransferID))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Transfer has no ledger entry to reverse",
+			}
+		}
+
+		entry := ledger.Reverse(ledger.TransferEntry(transfer.TransferFrom, transfer.TransferTo, transfer.TransferAmount, transfer.TransferID))
+
+		if _, err := s.ledger.Stage(unit, entry); err != nil {
+			s.logger.Error("failed to post transfer reversal", zap.Error(err))
+
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Failed to reverse transfer",
+			}
+		}
+	}
+
+	pending := unit.Transfer.UpdateStatus(transfer.TransferID, request.Status)
+
+	if err := s.ledger.Commit(unit); err != nil {
+		s.logger.Error("failed to update transfer status", zap.Error(err))
+
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient balance on receiver card to reverse transfer",
+			}
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update transfer status",
+		}
+	}
+
+	updated := pending.Value()
+
+	so := s.mapper.ToTransferResponse(*updated)
+
+	return &response.ApiResponse[*response.TransferResponse]{
+		Status:  "success",
+		Message: "Transfer status updated successfully",
+		Data:    so,
+	}, nil
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/pkg/totp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	recoveryCodeCount = 10
+
+	// totpSkew accepts the code of the step before and after the current one
+	// to allow for clock drift of the phone.
+	totpSkew = 1
+
+	totpMaxAttempts = 5
+	totpLockout     = 15 * time.Minute
+)
+
+// EnrollTotp creates a new authenticator secret for the caller. Two-factor
+// login stays off until ConfirmTotp receives a code made with it, so an
+// enrollment that was never finished does not lock anybody out.
+func (s *authService) EnrollTotp(caller requests.Caller) (*response.ApiResponse[*response.TotpEnrollmentResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, err := s.repository.Read(caller.UserID)
+	if err != nil {
+		s.logger.Error("failed to find user", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "User not found",
+		}
+	}
+
+	if user.TotpEnabled {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Two-factor authentication is already enabled",
+		}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.logger.Error("failed to generate totp secret", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to enroll two-factor authentication",
+		}
+	}
+
+	if _, err := s.repository.UpdateTotp(requests.UpdateUserTotpRequest{
+		UserID: user.UserID,
+		Secret: secret,
+	}); err != nil {
+		s.logger.Error("failed to save totp secret", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to enroll two-factor authentication",
+		}
+	}
+
+	return &response.ApiResponse[*response.TotpEnrollmentResponse]{
+		Status:  "success",
+		Message: "Scan the provisioning URI with an authenticator app and confirm a code",
+		Data: &response.TotpEnrollmentResponse{
+			Secret:          secret,
+			ProvisioningURI: totp.ProvisioningURI(secret, s.totpIssuer, user.Email),
+		},
+	}, nil
+}
+
+// ConfirmTotp turns two-factor login on once the caller proves the
+// authenticator app works, and returns the recovery codes. They are only
+// shown here; just their hashes are kept.
+func (s *authService) ConfirmTotp(caller requests.Caller, request requests.ConfirmTotpRequest) (*response.ApiResponse[*response.RecoveryCodesResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, err := s.repository.Read(caller.UserID)
+	if err != nil {
+		s.logger.Error("failed to find user", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "User not found",
+		}
+	}
+
+	if user.TotpEnabled {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Two-factor authentication is already enabled",
+		}
+	}
+
+	if user.TotpSecret == "" {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Two-factor enrollment has not been started",
+		}
+	}
+
+	step, ok := totp.Validate(user.TotpSecret, request.Code, time.Now(), totpSkew)
+	if !ok {
+		s.logger.Error("wrong totp code while confirming enrollment", zap.Int("user_id", user.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid two-factor code",
+		}
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		s.logger.Error("failed to generate recovery codes", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to enable two-factor authentication",
+		}
+	}
+
+	if _, err := s.repository.UpdateTotp(requests.UpdateUserTotpRequest{
+		UserID:        user.UserID,
+		Secret:        user.TotpSecret,
+		Enabled:       true,
+		LastStep:      step,
+		RecoveryCodes: hashes,
+	}); err != nil {
+		s.logger.Error("failed to enable totp", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to enable two-factor authentication",
+		}
+	}
+
+	return &response.ApiResponse[*response.RecoveryCodesResponse]{
+		Status:  "success",
+		Message: "Two-factor authentication enabled, store the recovery codes somewhere safe",
+		Data:    &response.RecoveryCodesResponse{RecoveryCodes: codes},
+	}, nil
+}
+
+// VerifyLogin finishes a login that returned a challenge token. Each code of
+// the authenticator app is accepted once, and a recovery code is used up.
+// Too many wrong codes in a row lock the second step for a while.
+func (s *authService) VerifyLogin(request requests.VerifyLoginRequest) (*response.ApiResponse[*response.TokenResponse], *response.ErrorResponse) {
+	claims, err := s.token.ValidateChallengeToken(request.ChallengeToken)
+	if err != nil {
+		s.logger.Error("invalid challenge token", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid challenge token",
+		}
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid challenge token",
+		}
+	}
+
+	user, errRes := s.checkSecondFactor(userID, request.Code)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	tokens, err := s.startSession(user)
+	if err != nil {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to login",
+		}
+	}
+
+	return &response.ApiResponse[*response.TokenResponse]{
+		Status:  "success",
+		Message: "login success",
+		Data:    tokens,
+	}, nil
+}
+
+func (s *authService) checkSecondFactor(userID int, code string) (*record.UserRecord, *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, err := s.repository.Read(userID)
+	if err != nil || !user.TotpEnabled {
+		s.logger.Error("two-factor login for user without two-factor", zap.Int("user_id", userID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid challenge token",
+		}
+	}
+
+	now := time.Now()
+
+	if user.TotpLockedUntil != nil && now.Before(*user.TotpLockedUntil) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Two-factor login is locked, try again later",
+		}
+	}
+
+	update := requests.UpdateUserTotpRequest{
+		UserID:        user.UserID,
+		Secret:        user.TotpSecret,
+		Enabled:       true,
+		LastStep:      user.TotpLastStep,
+		RecoveryCodes: user.RecoveryCodes,
+	}
+
+	accepted := false
+
+	if step, ok := totp.Validate(user.TotpSecret, code, now, totpSkew); ok && step > user.TotpLastStep {
+		update.LastStep = step
+		accepted = true
+	} else if remaining, ok := useRecoveryCode(user.RecoveryCodes, code); ok {
+		update.RecoveryCodes = remaining
+		accepted = true
+
+		s.logger.Debug("recovery code used", zap.Int("user_id", user.UserID), zap.Int("remaining", len(remaining)))
+	}
+
+	if !accepted {
+		// Kunci berakhir dengan sendirinya, hitungan dimulai lagi dari nol
+		update.FailedAttempts = user.TotpFailedAttempts + 1
+
+		if update.FailedAttempts >= totpMaxAttempts {
+			until := now.Add(totpLockout)
+			update.LockedUntil = &until
+			update.FailedAttempts = 0
+		}
+	}
+
+	if _, err := s.repository.UpdateTotp(update); err != nil {
+		s.logger.Error("failed to save two-factor state", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to login",
+		}
+	}
+
+	if !accepted {
+		s.logger.SecurityEvent("two_factor_failed", zap.Int("user_id", user.UserID))
+
+		if update.LockedUntil != nil {
+			s.logger.SecurityEvent("two_factor_locked", zap.Int("user_id", user.UserID), zap.Time("locked_until", *update.LockedUntil))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Too many wrong codes, two-factor login is locked",
+			}
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid two-factor code",
+		}
+	}
+
+	return user, nil
+}
+
+// generateRecoveryCodes returns new recovery codes in the form xxxxx-xxxxx
+// together with the hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(raw)[:10])
+
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code the way it was typed, ignoring case,
+// dashes and spaces.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// useRecoveryCode returns the hashes without the one of code, or false when
+// code is not one of them.
+func useRecoveryCode(hashes []string, code string) ([]string, bool) {
+	hashed := hashRecoveryCode(code)
+
+	for i, h := range hashes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hashed)) == 1 {
+			remaining := make([]string, 0, len(hashes)-1)
+			remaining = append(remaining, hashes[:i]...)
+			remaining = append(remaining, hashes[i+1:]...)
+
+			return remaining, true
+		}
+	}
+
+	return nil, false
+}
@@ -12,17 +12,20 @@ import (
 
 type userService struct {
 	userRepository repository.UserRepository
+	guard          *loginGuard
 	logger         logger.Logger
 	mapper         responseMapper.UserResponseMapper
 }
 
 func NewUserService(
 	userRepository repository.UserRepository,
+	guard *loginGuard,
 	logger logger.Logger,
 	mapper responseMapper.UserResponseMapper,
 ) *userService {
 	return &userService{
 		userRepository: userRepository,
+		guard:          guard,
 		logger:         logger,
 		mapper:         mapper,
 	}
@@ -145,6 +148,57 @@ func (ds *userService) Update(request requests.UpdateUserRequest) (*response.Api
 	}, nil
 }
 
+func (ds *userService) UpdateRole(request requests.UpdateUserRoleRequest) (*response.ApiResponse[*response.UserResponse], *response.ErrorResponse) {
+	res, err := ds.userRepository.UpdateRole(request)
+	if err != nil {
+		ds.logger.Error("failed to update user role", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update user role",
+		}
+	}
+
+	so := ds.mapper.ToUserResponse(*res)
+
+	return &response.ApiResponse[*response.UserResponse]{
+		Status:  "success",
+		Message: "User role updated successfully",
+		Data:    so,
+	}, nil
+}
+
+// Unlock lifts a login lockout before it runs out, for example once support
+// confirmed the owner of the account.
+func (ds *userService) Unlock(request requests.UnlockUserRequest) (*response.ApiResponse[*response.UserResponse], *response.ErrorResponse) {
+	user, err := ds.userRepository.Read(request.UserID)
+	if err != nil {
+		ds.logger.Error("failed to find user", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "User not found",
+		}
+	}
+
+	res, err := ds.guard.unlock(user)
+	if err != nil {
+		ds.logger.Error("failed to unlock user", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to unlock user",
+		}
+	}
+
+	ds.logger.SecurityEvent("account_unlocked", zap.Int("user_id", user.UserID))
+
+	so := ds.mapper.ToUserResponse(*res)
+
+	return &response.ApiResponse[*response.UserResponse]{
+		Status:  "success",
+		Message: "User unlocked successfully",
+		Data:    so,
+	}, nil
+}
+
 func (ds *userService) Delete(userID int) (*response.ApiResponse[string], *response.ErrorResponse) {
 	err := ds.userRepository.Delete(userID)
 	if err != nil {
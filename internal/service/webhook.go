@@ -0,0 +1,676 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/models"
+	"payment-mutex/internal/repository"
+	"payment-mutex/pkg/logger"
+	"payment-mutex/pkg/signature"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WebhookPolicy decides how webhooks are sent and retried.
+type WebhookPolicy struct {
+	Timeout time.Duration
+	// MaxAttempts failed attempts mark a delivery failed. Attempt n+1 waits
+	// BackoffBase*2^(n-1) after attempt n, up to BackoffMax.
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+
+	// webhookSendConcurrency is how many due deliveries are sent at once.
+	webhookSendConcurrency = 8
+)
+
+type webhookService struct {
+	endpointRepository repository.WebhookEndpointRepository
+	deliveryRepository repository.WebhookDeliveryRepository
+	merchantRepository repository.MerchantRepository
+	logger             logger.Logger
+	mapper             responseMapper.WebhookResponseMapper
+	client             *http.Client
+	policy             WebhookPolicy
+
+	// mu menjaga agar satu delivery tidak dikirim dua kali bersamaan
+	mu      sync.Mutex
+	sending map[int]bool
+}
+
+func NewWebhookService(
+	endpointRepository repository.WebhookEndpointRepository,
+	deliveryRepository repository.WebhookDeliveryRepository,
+	merchantRepository repository.MerchantRepository,
+	logger logger.Logger,
+	mapper responseMapper.WebhookResponseMapper,
+	policy WebhookPolicy,
+) *webhookService {
+	return &webhookService{
+		endpointRepository: endpointRepository,
+		deliveryRepository: deliveryRepository,
+		merchantRepository: merchantRepository,
+		logger:             logger,
+		mapper:             mapper,
+		client: &http.Client{
+			Timeout:   policy.Timeout,
+			Transport: newWebhookTransport(),
+			// Redirect tidak diikuti, endpoint harus membalas langsung
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		policy:  policy,
+		sending: make(map[int]bool),
+	}
+}
+
+// Publish queues eventType for every endpoint of the merchant subscribed to
+// it and sends it in the background. It never fails the caller; problems are
+// logged and the delivery log shows what was sent.
+func (s *webhookService) Publish(merchantID int, eventType string, data any) {
+	endpoints, err := s.endpointRepository.ReadSubscribed(merchantID, eventType)
+	if err != nil {
+		s.logger.Error("failed to find webhook endpoints", zap.Error(err))
+		return
+	}
+
+	if len(endpoints) == 0 {
+		return
+	}
+
+	eventID, err := newEventID()
+	if err != nil {
+		s.logger.Error("failed to create webhook event id", zap.Error(err))
+		return
+	}
+
+	payload, err := json.Marshal(response.WebhookEvent{
+		ID:         eventID,
+		Type:       eventType,
+		MerchantID: merchantID,
+		CreatedAt:  time.Now(),
+		Data:       data,
+	})
+	if err != nil {
+		s.logger.Error("failed to encode webhook event", zap.String("event", eventType), zap.Error(err))
+		return
+	}
+
+	for _, endpoint := range endpoints {
+		delivery, err := s.deliveryRepository.Create(requests.CreateWebhookDeliveryRequest{
+			EndpointID: endpoint.EndpointID,
+			MerchantID: merchantID,
+			EventID:    eventID,
+			EventType:  eventType,
+			Payload:    string(payload),
+		})
+		if err != nil {
+			s.logger.Error("failed to queue webhook delivery", zap.Int("endpoint_id", endpoint.EndpointID), zap.Error(err))
+			continue
+		}
+
+		go s.send(delivery.DeliveryID)
+	}
+}
+
+// DeliverDue sends the deliveries whose next attempt is due and returns how
+// many were attempted.
+func (s *webhookService) DeliverDue() int {
+	due, err := s.deliveryRepository.ReadDue(time.Now())
+	if err != nil {
+		s.logger.Error("failed to find due webhook deliveries", zap.Error(err))
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, webhookSendConcurrency)
+
+	for _, delivery := range due {
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func(deliveryID int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			s.send(deliveryID)
+		}(delivery.DeliveryID)
+	}
+
+	wg.Wait()
+
+	return len(due)
+}
+
+func (s *webhookService) FindEndpoints(caller requests.Caller, merchantID int) (*response.ApiResponse[[]*response.WebhookEndpointResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, merchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	endpoints, err := s.endpointRepository.ReadByMerchantID(merchantID)
+	if err != nil {
+		s.logger.Error("failed to fetch webhook endpoints", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to fetch webhook endpoints",
+		}
+	}
+
+	return &response.ApiResponse[[]*response.WebhookEndpointResponse]{
+		Status:  "success",
+		Message: "Webhook endpoints retrieved successfully",
+		Data:    s.mapper.ToWebhookEndpointsResponse(endpoints),
+	}, nil
+}
+
+// CreateEndpoint registers a URL of the merchant. The first endpoint of a
+// merchant also creates its webhook secret, which is only shown then.
+func (s *webhookService) CreateEndpoint(caller requests.Caller, request requests.CreateWebhookRequest) (*response.ApiResponse[*response.WebhookEndpointResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, request.MerchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	if errRes := validateWebhookURL(request.URL); errRes != nil {
+		return nil, errRes
+	}
+
+	merchant, err := s.merchantRepository.Read(request.MerchantID)
+	if err != nil {
+		s.logger.Error("failed to find merchant", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	secret := ""
+
+	if merchant.WebhookSecret == "" {
+		secret, err = s.newSecret(merchant.MerchantID)
+		if err != nil {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Failed to create webhook endpoint",
+			}
+		}
+	}
+
+	endpoint, err := s.endpointRepository.Create(requests.CreateWebhookEndpointRequest{
+		MerchantID: request.MerchantID,
+		URL:        request.URL,
+		Events:     request.Events,
+	})
+	if err != nil {
+		s.logger.Error("failed to create webhook endpoint", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create webhook endpoint",
+		}
+	}
+
+	so := s.mapper.ToWebhookEndpointResponse(*endpoint)
+	so.WebhookSecret = secret
+
+	return &response.ApiResponse[*response.WebhookEndpointResponse]{
+		Status:  "success",
+		Message: "Webhook endpoint created successfully",
+		Data:    so,
+	}, nil
+}
+
+func (s *webhookService) UpdateEndpoint(caller requests.Caller, request requests.UpdateWebhookRequest) (*response.ApiResponse[*response.WebhookEndpointResponse], *response.ErrorResponse) {
+	if _, errRes := s.findOwnedEndpoint(caller, request.EndpointID); errRes != nil {
+		return nil, errRes
+	}
+
+	if errRes := validateWebhookURL(request.URL); errRes != nil {
+		return nil, errRes
+	}
+
+	endpoint, err := s.endpointRepository.Update(requests.UpdateWebhookEndpointRequest{
+		EndpointID: request.EndpointID,
+		URL:        request.URL,
+		Events:     request.Events,
+		Active:     request.Active,
+	})
+	if err != nil {
+		s.logger.Error("failed to update webhook endpoint", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update webhook endpoint",
+		}
+	}
+
+	return &response.ApiResponse[*response.WebhookEndpointResponse]{
+		Status:  "success",
+		Message: "Webhook endpoint updated successfully",
+		Data:    s.mapper.ToWebhookEndpointResponse(*endpoint),
+	}, nil
+}
+
+// DeleteEndpoint removes an endpoint. Its pending deliveries fail on their
+// next attempt.
+func (s *webhookService) DeleteEndpoint(caller requests.Caller, endpointID int) (*response.ApiResponse[string], *response.ErrorResponse) {
+	if _, errRes := s.findOwnedEndpoint(caller, endpointID); errRes != nil {
+		return nil, errRes
+	}
+
+	if err := s.endpointRepository.Delete(endpointID); err != nil {
+		s.logger.Error("failed to delete webhook endpoint", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to delete webhook endpoint",
+		}
+	}
+
+	return &response.ApiResponse[string]{
+		Status:  "success",
+		Message: "Webhook endpoint deleted successfully",
+		Data:    "Webhook endpoint with ID " + strconv.Itoa(endpointID) + " has been deleted",
+	}, nil
+}
+
+// FindDeliveries returns the delivery log of the merchant, newest first. When
+// endpointID is not zero only deliveries to that endpoint are returned.
+func (s *webhookService) FindDeliveries(caller requests.Caller, merchantID int, endpointID int, page int, pageSize int) (*response.APIResponsePagination[[]*response.WebhookDeliveryResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, merchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	deliveries, totalRecords, err := s.deliveryRepository.ReadByMerchantID(merchantID, endpointID, page, pageSize)
+	if err != nil {
+		s.logger.Error("failed to fetch webhook deliveries", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to fetch webhook deliveries",
+		}
+	}
+
+	totalPages := (totalRecords + pageSize - 1) / pageSize
+
+	return &response.APIResponsePagination[[]*response.WebhookDeliveryResponse]{
+		Status:  "success",
+		Message: "Webhook deliveries retrieved successfully",
+		Data:    s.mapper.ToWebhookDeliveriesResponse(deliveries),
+		Meta: response.PaginationMeta{
+			CurrentPage:  page,
+			PageSize:     pageSize,
+			TotalPages:   totalPages,
+			TotalRecords: totalRecords,
+		},
+	}, nil
+}
+
+// Redeliver sends the event of a delivery again as a new delivery to the same
+// endpoint, whatever became of the original one.
+func (s *webhookService) Redeliver(caller requests.Caller, request requests.RedeliverWebhookRequest) (*response.ApiResponse[*response.WebhookDeliveryResponse], *response.ErrorResponse) {
+	original, err := s.deliveryRepository.Read(request.DeliveryID)
+	if err != nil || !ownsMerchant(s.merchantRepository, caller, original.MerchantID) {
+		s.logger.Error("failed to find webhook delivery", zap.Int("delivery_id", request.DeliveryID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Webhook delivery not found",
+		}
+	}
+
+	if _, err := s.endpointRepository.Read(original.EndpointID); err != nil {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Webhook endpoint no longer exists",
+		}
+	}
+
+	delivery, err := s.deliveryRepository.Create(requests.CreateWebhookDeliveryRequest{
+		EndpointID:   original.EndpointID,
+		MerchantID:   original.MerchantID,
+		EventID:      original.EventID,
+		EventType:    original.EventType,
+		Payload:      original.Payload,
+		RedeliveryOf: original.DeliveryID,
+	})
+	if err != nil {
+		s.logger.Error("failed to queue webhook redelivery", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to redeliver webhook",
+		}
+	}
+
+	// Dikirim langsung agar hasilnya terlihat di response
+	s.send(delivery.DeliveryID)
+
+	if sent, err := s.deliveryRepository.Read(delivery.DeliveryID); err == nil {
+		delivery = sent
+	}
+
+	return &response.ApiResponse[*response.WebhookDeliveryResponse]{
+		Status:  "success",
+		Message: "Webhook redelivered",
+		Data:    s.mapper.ToWebhookDeliveryResponse(*delivery),
+	}, nil
+}
+
+// RotateSecret replaces the webhook secret of the merchant. Deliveries that
+// are retried afterwards are signed with the new secret.
+func (s *webhookService) RotateSecret(caller requests.Caller, request requests.RotateWebhookSecretRequest) (*response.ApiResponse[*response.WebhookSecretResponse], *response.ErrorResponse) {
+	if !ownsMerchant(s.merchantRepository, caller, request.MerchantID) {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Merchant not found",
+		}
+	}
+
+	secret, err := s.newSecret(request.MerchantID)
+	if err != nil {
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to rotate webhook secret",
+		}
+	}
+
+	s.logger.SecurityEvent("webhook_secret_rotated", zap.Int("merchant_id", request.MerchantID), zap.Int("user_id", caller.UserID))
+
+	return &response.ApiResponse[*response.WebhookSecretResponse]{
+		Status:  "success",
+		Message: "Webhook secret rotated, store it now because it cannot be shown again",
+		Data: &response.WebhookSecretResponse{
+			MerchantID:    request.MerchantID,
+			WebhookSecret: secret,
+		},
+	}, nil
+}
+
+// send makes one attempt at a pending delivery and plans the next one when it
+// fails.
+func (s *webhookService) send(deliveryID int) {
+	s.mu.Lock()
+	if s.sending[deliveryID] {
+		s.mu.Unlock()
+		return
+	}
+	s.sending[deliveryID] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.sending, deliveryID)
+		s.mu.Unlock()
+	}()
+
+	// Dibaca ulang karena sweep bisa memegang data lama dari delivery yang baru saja dikirim
+	delivery, err := s.deliveryRepository.Read(deliveryID)
+	if err != nil || delivery.Status != models.WebhookDeliveryPending {
+		return
+	}
+
+	if delivery.NextAttemptAt != nil && time.Now().Before(*delivery.NextAttemptAt) {
+		return
+	}
+
+	attempt := requests.RecordWebhookAttemptRequest{
+		DeliveryID:  deliveryID,
+		AttemptedAt: time.Now(),
+	}
+
+	attempt.StatusCode, err = s.post(delivery)
+	attempt.Duration = time.Since(attempt.AttemptedAt)
+
+	switch {
+	case err == nil:
+		attempt.Status = models.WebhookDeliverySucceeded
+	case len(delivery.Attempts)+1 >= s.policy.MaxAttempts:
+		attempt.Error = err.Error()
+		attempt.Status = models.WebhookDeliveryFailed
+	default:
+		attempt.Error = err.Error()
+		attempt.Status = models.WebhookDeliveryPending
+		next := time.Now().Add(s.backoff(len(delivery.Attempts) + 1))
+		attempt.NextAttemptAt = &next
+	}
+
+	if _, err := s.deliveryRepository.RecordAttempt(attempt); err != nil {
+		s.logger.Error("failed to record webhook attempt", zap.Int("delivery_id", deliveryID), zap.Error(err))
+		return
+	}
+
+	if attempt.Status == models.WebhookDeliveryFailed {
+		s.logger.Warn("webhook delivery failed", zap.Int("delivery_id", deliveryID), zap.Int("endpoint_id", delivery.EndpointID), zap.String("error", attempt.Error))
+	}
+}
+
+// post sends the payload of delivery to its endpoint. Any answer outside 2xx
+// is an error.
+func (s *webhookService) post(delivery *record.WebhookDeliveryRecord) (int, error) {
+	endpoint, err := s.endpointRepository.Read(delivery.EndpointID)
+	if err != nil {
+		return 0, fmt.Errorf("endpoint was deleted")
+	}
+
+	if !endpoint.Active {
+		return 0, fmt.Errorf("endpoint is disabled")
+	}
+
+	merchant, err := s.merchantRepository.Read(delivery.MerchantID)
+	if err != nil || merchant.WebhookSecret == "" {
+		return 0, fmt.Errorf("merchant has no webhook secret")
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "payment-mutex-webhooks")
+	req.Header.Set(WebhookIDHeader, delivery.EventID)
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(delivery.DeliveryID))
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, signature.Sign(merchant.WebhookSecret, []byte(timestamp+"."+delivery.Payload)))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("endpoint answered %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// backoff returns the wait after the given number of failed attempts.
+func (s *webhookService) backoff(failures int) time.Duration {
+	delay := s.policy.BackoffBase
+	for i := 1; i < failures && delay < s.policy.BackoffMax; i++ {
+		delay *= 2
+	}
+
+	if delay > s.policy.BackoffMax {
+		delay = s.policy.BackoffMax
+	}
+
+	return delay
+}
+
+func (s *webhookService) newSecret(merchantID int) (string, error) {
+	secret, err := signature.GenerateSecret()
+	if err != nil {
+		s.logger.Error("failed to generate webhook secret", zap.Error(err))
+		return "", err
+	}
+
+	if _, err := s.merchantRepository.UpdateWebhookSecret(merchantID, secret); err != nil {
+		s.logger.Error("failed to save webhook secret", zap.Error(err))
+		return "", err
+	}
+
+	return secret, nil
+}
+
+func (s *webhookService) findOwnedEndpoint(caller requests.Caller, endpointID int) (*record.WebhookEndpointRecord, *response.ErrorResponse) {
+	endpoint, err := s.endpointRepository.Read(endpointID)
+	if err != nil || !ownsMerchant(s.merchantRepository, caller, endpoint.MerchantID) {
+		s.logger.Error("failed to find webhook endpoint", zap.Int("endpoint_id", endpointID), zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Webhook endpoint not found",
+		}
+	}
+
+	return endpoint, nil
+}
+
+// errWebhookAddress is returned when a webhook would reach an address inside
+// the network the service runs in.
+var errWebhookAddress = errors.New("webhook URL resolves to a private or reserved address")
+
+// webhookBlockedNetworks are reserved ranges the net.IP checks do not cover.
+var webhookBlockedNetworks = mustParseCIDRs("0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4")
+
+// validateWebhookURL accepts https URLs that do not name a private or
+// reserved address. Host names are checked again on every delivery, when they
+// are resolved, because they can point somewhere else later.
+func validateWebhookURL(raw string) *response.ErrorResponse {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" || parsed.User != nil {
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Webhook URL must be an https URL",
+		}
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	ip := net.ParseIP(host)
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || (ip != nil && !isPublicIP(ip)) {
+		return &response.ErrorResponse{
+			Status:  "error",
+			Message: "Webhook URL must not point to a private or reserved address",
+		}
+	}
+
+	return nil
+}
+
+// newWebhookTransport returns a transport that refuses to connect to private,
+// loopback, link-local and other reserved addresses. The check runs on the
+// address actually dialed, after the host name was resolved, so a name that
+// resolves to an internal address is refused as well. Proxies from the
+// environment are not used, since they would dial on our behalf.
+func newWebhookTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network string, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return errWebhookAddress
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Transport{
+		Proxy:               nil,
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// isPublicIP reports whether ip is an address on the public internet.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, network := range webhookBlockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+func newEventID() (string, error) {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return "evt_" + hex.EncodeToString(raw), nil
+}
@@ -1,36 +1,58 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"payment-mutex/internal/domain/requests"
 	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/ledger"
 	responseMapper "payment-mutex/internal/mapper/response"
+	"payment-mutex/internal/models"
 	"payment-mutex/internal/repository"
 	"payment-mutex/pkg/logger"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
 
 type withdrawService struct {
-	userRepository     repository.UserRepository
-	saldoRepository    repository.SaldoRepository
-	withdrawRepository repository.WithdrawRepository
-	logger             logger.Logger
-	mapper             responseMapper.WithdrawResponseMapper
+	userRepository         repository.UserRepository
+	cardRepository         repository.CardRepository
+	saldoRepository        repository.SaldoRepository
+	withdrawRepository     repository.WithdrawRepository
+	withdrawHoldRepository repository.WithdrawHoldRepository
+	ledger                 *ledger.Ledger
+	pin                    PinService
+	logger                 logger.Logger
+	mapper                 responseMapper.WithdrawResponseMapper
+	holdMapper             responseMapper.WithdrawHoldResponseMapper
+	holdTTL                time.Duration
+
+	// mu menjaga agar perubahan nominal, status, dan hold withdraw tidak saling mendahului
+	mu sync.Mutex
 }
 
 func NewWithdrawService(
 	userRepository repository.UserRepository,
-	withdrawRepository repository.WithdrawRepository, saldoRepository repository.SaldoRepository, logger logger.Logger, mapper responseMapper.WithdrawResponseMapper) *withdrawService {
+	cardRepository repository.CardRepository,
+	withdrawRepository repository.WithdrawRepository, saldoRepository repository.SaldoRepository, withdrawHoldRepository repository.WithdrawHoldRepository, ledger *ledger.Ledger, pin PinService, logger logger.Logger, mapper responseMapper.WithdrawResponseMapper, holdMapper responseMapper.WithdrawHoldResponseMapper, holdTTL time.Duration) *withdrawService {
 	return &withdrawService{
-		userRepository:     userRepository,
-		saldoRepository:    saldoRepository,
-		withdrawRepository: withdrawRepository,
-		logger:             logger,
-		mapper:             mapper,
+		userRepository:         userRepository,
+		cardRepository:         cardRepository,
+		saldoRepository:        saldoRepository,
+		withdrawRepository:     withdrawRepository,
+		withdrawHoldRepository: withdrawHoldRepository,
+		ledger:                 ledger,
+		pin:                    pin,
+		logger:                 logger,
+		mapper:                 mapper,
+		holdMapper:             holdMapper,
+		holdTTL:                holdTTL,
 	}
 }
 
-func (s *withdrawService) FindAll(page int, pageSize int, search string) (*response.APIResponsePagination[[]*response.WithdrawResponse], *response.ErrorResponse) {
+func (s *withdrawService) FindAll(page int, pageSize int, search string, status string) (*response.APIResponsePagination[[]*response.WithdrawResponse], *response.ErrorResponse) {
 	if page <= 0 {
 		page = 1
 	}
@@ -39,7 +61,15 @@ func (s *withdrawService) FindAll(page int, pageSize int, search string) (*respo
 		pageSize = 10
 	}
 
-	withdraws, totalRecords, err := s.withdrawRepository.ReadAll(page, pageSize, search)
+	if status != "" && !isKnownStatus(status) {
+		s.logger.Error("invalid status filter", zap.String("status", status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Invalid status filter",
+		}
+	}
+
+	withdraws, totalRecords, err := s.withdrawRepository.ReadAll(page, pageSize, search, status)
 
 	if err != nil {
 		s.logger.Error("failed to fetch withdraws", zap.Error(err))
@@ -74,7 +104,7 @@ func (s *withdrawService) FindAll(page int, pageSize int, search string) (*respo
 	}, nil
 }
 
-func (s *withdrawService) FindById(withdrawID int) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse) {
+func (s *withdrawService) FindById(caller requests.Caller, withdrawID int) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse) {
 	withdraw, err := s.withdrawRepository.Read(withdrawID)
 	if err != nil {
 		s.logger.Error("failed to find withdraw by id", zap.Error(err))
@@ -83,6 +113,14 @@ func (s *withdrawService) FindById(withdrawID int) (*response.ApiResponse[*respo
 			Message: "Failed to fetch withdraw record by ID.",
 		}
 	}
+
+	if !ownsCard(s.cardRepository, caller, withdraw.CardNumber) {
+		s.logger.Error("withdraw belongs to another user", zap.Int("withdraw_id", withdrawID), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to fetch withdraw record by ID.",
+		}
+	}
 	so := s.mapper.ToWithdrawResponse(*withdraw)
 
 	return &response.ApiResponse[*response.WithdrawResponse]{
@@ -92,61 +130,83 @@ func (s *withdrawService) FindById(withdrawID int) (*response.ApiResponse[*respo
 	}, nil
 }
 
-func (s *withdrawService) Create(request requests.CreateWithdrawRequest) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse) {
-	saldo, err := s.saldoRepository.ReadByCardNumber(request.CardNumber)
-	if err != nil {
-		s.logger.Error("Failed to find saldo by user ID", zap.Error(err))
+func (s *withdrawService) Create(caller requests.Caller, request requests.CreateWithdrawRequest) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse) {
+	if !ownsCard(s.cardRepository, caller, request.CardNumber) {
+		s.logger.Error("card belongs to another user", zap.String("card_number", request.CardNumber), zap.Int("user_id", caller.UserID))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Failed to fetch saldo for the user.",
+			Message: "Card not found.",
 		}
 	}
 
-	if saldo == nil {
-		s.logger.Error("Saldo not found for user", zap.String("cardNumber", request.CardNumber))
+	if errRes := s.pin.VerifyAmount(caller, request.Pin, request.WithdrawAmount); errRes != nil {
+		return nil, errRes
+	}
+
+	// Buat catatan withdraw
+	withdrawRecord, err := s.withdrawRepository.Create(request)
+	if err != nil {
+		s.logger.Error("Failed to create withdraw record", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Saldo not found for the specified user ID.",
+			Message: "Failed to create withdraw record.",
 		}
 	}
 
-	// Periksa saldo mencukupi
-	if saldo.TotalBalance < request.WithdrawAmount {
-		s.logger.Error("Insufficient balance for user", zap.String("cardNumber", request.CardNumber), zap.Int("requested", request.WithdrawAmount))
+	if _, err := s.withdrawRepository.UpdateStatus(withdrawRecord.WithdrawID, models.StatusProcessing); err != nil {
+		s.logger.Error("failed to mark withdraw as processing", zap.Error(err))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Insufficient balance for withdrawal.",
+			Message: "Failed to process withdraw",
 		}
 	}
 
-	// Update saldo setelah penarikan
-	newTotalBalance := saldo.TotalBalance - request.WithdrawAmount
-	updateData := requests.UpdateSaldoWithdraw{
-		CardNumber:     request.CardNumber,
-		TotalBalance:   newTotalBalance,
-		WithdrawAmount: &request.WithdrawAmount,
-		WithdrawTime:   &request.WithdrawTime,
-	}
+	// Saldo diperiksa dan dikurangi lewat ledger bersama status succeeded dalam
+	// satu unit of work
+	unit := s.ledger.Begin()
+	pending := unit.Withdraw.UpdateStatusFrom(withdrawRecord.WithdrawID, models.StatusProcessing, models.StatusSucceeded)
 
-	_, err = s.saldoRepository.UpdateSaldoWithdraw(updateData)
+	_, err = s.ledger.PostWith(unit, ledger.WithdrawEntry(request.CardNumber, request.WithdrawAmount, withdrawRecord.WithdrawID))
 	if err != nil {
-		s.logger.Error("Failed to update saldo after withdrawal", zap.Error(err))
+		s.logger.Error("Failed to post withdraw entry", zap.Error(err), zap.String("cardNumber", request.CardNumber), zap.Int("requested", request.WithdrawAmount))
+
+		// Status diubah admin selama diproses, jurnal tidak diposting dan status itu dipertahankan
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Withdraw status changed while it was processed",
+			}
+		}
+
+		// Withdraw yang gagal tetap disimpan dengan status failed
+		if _, statusErr := s.withdrawRepository.UpdateStatus(withdrawRecord.WithdrawID, models.StatusFailed); statusErr != nil {
+			s.logger.Error("failed to mark withdraw as failed", zap.Error(statusErr))
+		}
+
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient balance for withdrawal.",
+			}
+		}
+
 		return nil, &response.ErrorResponse{
 			Status:  "error",
 			Message: "Failed to update saldo after withdrawal.",
 		}
 	}
 
-	// Buat catatan withdraw
-	withdrawRecord, err := s.withdrawRepository.Create(request)
+	_, err = s.saldoRepository.UpdateSaldoWithdraw(requests.UpdateSaldoWithdraw{
+		CardNumber:     request.CardNumber,
+		WithdrawAmount: &request.WithdrawAmount,
+		WithdrawTime:   &request.WithdrawTime,
+	})
 	if err != nil {
-		s.logger.Error("Failed to create withdraw record", zap.Error(err))
-		return nil, &response.ErrorResponse{
-			Status:  "error",
-			Message: "Failed to create withdraw record.",
-		}
+		s.logger.Error("Failed to record withdrawal on saldo", zap.Error(err))
 	}
 
+	withdrawRecord = pending.Value()
+
 	so := s.mapper.ToWithdrawResponse(*withdrawRecord)
 
 	return &response.ApiResponse[*response.WithdrawResponse]{
@@ -157,7 +217,10 @@ func (s *withdrawService) Create(request requests.CreateWithdrawRequest) (*respo
 }
 
 func (s *withdrawService) Update(request requests.UpdateWithdrawRequest) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse) {
-	_, err := s.withdrawRepository.Read(request.WithdrawID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existingWithdraw, err := s.withdrawRepository.Read(request.WithdrawID)
 	if err != nil {
 		s.logger.Error("Failed to find withdraw record by ID", zap.Error(err))
 		return nil, &response.ErrorResponse{
@@ -166,59 +229,55 @@ func (s *withdrawService) Update(request requests.UpdateWithdrawRequest) (*respo
 		}
 	}
 
-	// Ambil saldo pengguna
-	saldo, err := s.saldoRepository.ReadByCardNumber(request.CardNumber)
-	if err != nil {
-		s.logger.Error("Failed to fetch saldo by user ID", zap.Error(err))
+	if existingWithdraw.Status != models.StatusSucceeded {
+		s.logger.Error("cannot update withdraw that has not succeeded", zap.String("status", existingWithdraw.Status))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Failed to fetch saldo for the user.",
+			Message: "Only succeeded withdraws can be updated",
 		}
 	}
 
-	if saldo.TotalBalance < request.WithdrawAmount {
-		s.logger.Error("Insufficient balance for user", zap.String("cardNumber", request.CardNumber))
+	// Penarikan lama dikembalikan dan penarikan baru dikurangi dalam satu entri
+	adjustment := ledger.Combine(
+		ledger.ReferenceWithdraw,
+		existingWithdraw.WithdrawID,
+		"withdrawal update for card "+request.CardNumber,
+		ledger.Reverse(ledger.WithdrawEntry(existingWithdraw.CardNumber, existingWithdraw.WithdrawAmount, existingWithdraw.WithdrawID)),
+		ledger.WithdrawEntry(request.CardNumber, request.WithdrawAmount, existingWithdraw.WithdrawID),
+	)
+
+	// Saldo dan record penarikan disimpan dalam satu unit of work supaya
+	// saldo tidak pernah berubah tanpa record penarikannya
+	unit := s.ledger.Begin()
+
+	if _, err := s.ledger.Stage(unit, adjustment); err != nil {
+		s.logger.Error("Failed to update saldo balance", zap.Error(err), zap.String("cardNumber", request.CardNumber))
 		return nil, &response.ErrorResponse{
 			Status:  "error",
-			Message: "Insufficient balance for withdrawal update.",
+			Message: "Failed to update saldo balance.",
 		}
 	}
 
-	// Update saldo baru
-	newTotalBalance := saldo.TotalBalance - request.WithdrawAmount
-	updateSaldoData := requests.UpdateSaldoWithdraw{
-		CardNumber:     saldo.CardNumber,
-		TotalBalance:   newTotalBalance,
-		WithdrawAmount: &request.WithdrawAmount,
-		WithdrawTime:   &request.WithdrawTime,
-	}
+	pending := unit.Withdraw.Update(request)
 
-	_, err = s.saldoRepository.UpdateSaldoWithdraw(updateSaldoData)
-	if err != nil {
-		s.logger.Error("Failed to update saldo balance", zap.Error(err))
-		return nil, &response.ErrorResponse{
-			Status:  "error",
-			Message: "Failed to update saldo balance.",
-		}
-	}
+	if err := s.ledger.Commit(unit); err != nil {
+		s.logger.Error("Failed to update withdraw record", zap.Error(err), zap.String("cardNumber", request.CardNumber))
 
-	updatedWithdraw, err := s.withdrawRepository.Update(request)
-	if err != nil {
-		rollbackData := requests.UpdateSaldoBalance{
-			CardNumber:   saldo.CardNumber,
-			TotalBalance: saldo.TotalBalance,
-		}
-		_, rollbackErr := s.saldoRepository.UpdateBalance(rollbackData)
-		if rollbackErr != nil {
-			s.logger.Error("Failed to rollback saldo after withdraw update failure", zap.Error(rollbackErr))
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient balance for withdrawal update.",
+			}
 		}
-		s.logger.Error("Failed to update withdraw record", zap.Error(err))
+
 		return nil, &response.ErrorResponse{
 			Status:  "error",
 			Message: "Failed to update withdraw record.",
 		}
 	}
 
+	updatedWithdraw := pending.Value()
+
 	so := s.mapper.ToWithdrawResponse(*updatedWithdraw)
 
 	return &response.ApiResponse[*response.WithdrawResponse]{
@@ -244,3 +303,72 @@ func (s *withdrawService) Delete(withdrawID int) (*response.ApiResponse[string],
 		Data:    "Record deleted",
 	}, nil
 }
+
+func (s *withdrawService) UpdateStatus(request requests.UpdateStatusRequest) (*response.ApiResponse[*response.WithdrawResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	withdraw, err := s.withdrawRepository.Read(request.ID)
+	if err != nil {
+		s.logger.Error("failed to find withdraw", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Withdraw not found",
+		}
+	}
+
+	if !canTransitionStatus(withdraw.Status, request.Status) {
+		s.logger.Error("invalid withdraw status transition", zap.String("from", withdraw.Status), zap.String("to", request.Status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot change withdraw status from %s to %s", withdraw.Status, request.Status),
+		}
+	}
+
+	// Status reversed membalik perpindahan saldo lewat entri jurnal kompensasi,
+	// disimpan bersama perubahan status dalam satu unit of work
+	unit := s.ledger.Begin()
+
+	if request.Status == models.StatusReversed {
+		// Hanya withdraw yang benar-benar memindahkan saldo yang bisa dibalik
+		if posted, err := s.ledger.Posted(ledger.ReferenceWithdraw, withdraw.WithdrawID); err != nil || !posted {
+			s.logger.Error("withdraw has no ledger entry to reverse", zap.Error(err), zap.Int("withdrawID", withdraw.WithdrawID))
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Withdraw has no ledger entry to reverse",
+			}
+		}
+
+		entry := ledger.Reverse(ledger.WithdrawEntry(withdraw.CardNumber, withdraw.WithdrawAmount, withdraw.WithdrawID))
+
+		if _, err := s.ledger.Stage(unit, entry); err != nil {
+			s.logger.Error("failed to post withdraw reversal", zap.Error(err))
+
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Failed to reverse withdraw",
+			}
+		}
+	}
+
+	pending := unit.Withdraw.UpdateStatus(withdraw.WithdrawID, request.Status)
+
+	if err := s.ledger.Commit(unit); err != nil {
+		s.logger.Error("failed to update withdraw status", zap.Error(err))
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update withdraw status",
+		}
+	}
+
+	updated := pending.Value()
+
+	so := s.mapper.ToWithdrawResponse(*updated)
+
+	return &response.ApiResponse[*response.WithdrawResponse]{
+		Status:  "success",
+		Message: "Withdraw status updated successfully",
+		Data:    so,
+	}, nil
+}
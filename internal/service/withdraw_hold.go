@@ -0,0 +1,279 @@
+package service
+
+import (
+	"errors"
+	"payment-mutex/internal/domain/record"
+	"payment-mutex/internal/domain/requests"
+	"payment-mutex/internal/domain/response"
+	"payment-mutex/internal/ledger"
+	"payment-mutex/internal/models"
+	"payment-mutex/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func (s *withdrawService) Hold(caller requests.Caller, request requests.CreateWithdrawHoldRequest) (*response.ApiResponse[*response.WithdrawHoldResponse], *response.ErrorResponse) {
+	_, err := s.saldoRepository.ReadByCardNumber(request.CardNumber)
+	if err != nil {
+		s.logger.Error("failed to find saldo for hold", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Saldo not found for card.",
+		}
+	}
+
+	if !ownsCard(s.cardRepository, caller, request.CardNumber) {
+		s.logger.Error("card belongs to another user", zap.String("card_number", request.CardNumber), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Saldo not found for card.",
+		}
+	}
+
+	if errRes := s.pin.VerifyAmount(caller, request.Pin, request.Amount); errRes != nil {
+		return nil, errRes
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Dana ditahan lebih dulu sehingga tidak bisa dipakai debit lain
+	if err := s.ledger.Hold(request.CardNumber, request.Amount); err != nil {
+		s.logger.Error("failed to hold balance", zap.Error(err), zap.String("cardNumber", request.CardNumber), zap.Int("requested", request.Amount))
+
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return nil, &response.ErrorResponse{
+				Status:  "error",
+				Message: "Insufficient available balance for hold.",
+			}
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to hold balance.",
+		}
+	}
+
+	request.ExpiresAt = time.Now().Add(s.holdTTL)
+
+	hold, err := s.withdrawHoldRepository.Create(request)
+	if err != nil {
+		s.logger.Error("failed to create withdraw hold", zap.Error(err))
+
+		if releaseErr := s.ledger.Release(request.CardNumber, request.Amount); releaseErr != nil {
+			s.logger.Error("failed to release balance after hold failure", zap.Error(releaseErr))
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create withdraw hold.",
+		}
+	}
+
+	so := s.holdMapper.ToWithdrawHoldResponse(*hold)
+
+	return &response.ApiResponse[*response.WithdrawHoldResponse]{
+		Status:  "success",
+		Message: "Withdraw hold created successfully.",
+		Data:    so,
+	}, nil
+}
+
+func (s *withdrawService) Capture(caller requests.Caller, request requests.CaptureWithdrawHoldRequest) (*response.ApiResponse[*response.WithdrawHoldResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold, errRes := s.findActiveHold(caller, request.HoldID)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	// Nominal kosong berarti seluruh dana yang ditahan ditarik
+	amount := request.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+
+	if amount > hold.Amount {
+		s.logger.Error("capture exceeds held amount", zap.Int("requested", amount), zap.Int("held", hold.Amount))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Capture amount exceeds the held amount.",
+		}
+	}
+
+	now := time.Now()
+
+	withdrawRecord, err := s.withdrawRepository.Create(requests.CreateWithdrawRequest{
+		CardNumber:     hold.CardNumber,
+		WithdrawAmount: amount,
+		WithdrawTime:   now,
+	})
+	if err != nil {
+		s.logger.Error("failed to create withdraw record for capture", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create withdraw record.",
+		}
+	}
+
+	if _, err := s.withdrawRepository.UpdateStatus(withdrawRecord.WithdrawID, models.StatusProcessing); err != nil {
+		s.logger.Error("failed to mark withdraw as processing", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to process withdraw",
+		}
+	}
+
+	// Seluruh hold dilepas, nominal capture didebit dan status withdraw serta hold diubah dalam satu unit
+	unit := s.ledger.Begin()
+	unit.Withdraw.UpdateStatus(withdrawRecord.WithdrawID, models.StatusSucceeded)
+	captured := unit.WithdrawHold.UpdateStatus(requests.UpdateWithdrawHoldStatus{
+		HoldID:         hold.HoldID,
+		Status:         models.WithdrawHoldCaptured,
+		CapturedAmount: amount,
+		WithdrawID:     withdrawRecord.WithdrawID,
+	})
+
+	if _, err := s.ledger.CaptureWith(unit, hold.CardNumber, hold.Amount, ledger.WithdrawEntry(hold.CardNumber, amount, withdrawRecord.WithdrawID)); err != nil {
+		s.logger.Error("failed to capture withdraw hold", zap.Error(err))
+
+		if _, statusErr := s.withdrawRepository.UpdateStatus(withdrawRecord.WithdrawID, models.StatusFailed); statusErr != nil {
+			s.logger.Error("failed to mark withdraw as failed", zap.Error(statusErr))
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to capture withdraw hold.",
+		}
+	}
+
+	if _, err := s.saldoRepository.UpdateSaldoWithdraw(requests.UpdateSaldoWithdraw{
+		CardNumber:     hold.CardNumber,
+		WithdrawAmount: &amount,
+		WithdrawTime:   &now,
+	}); err != nil {
+		s.logger.Error("Failed to record withdrawal on saldo", zap.Error(err))
+	}
+
+	so := s.holdMapper.ToWithdrawHoldResponse(*captured.Value())
+
+	return &response.ApiResponse[*response.WithdrawHoldResponse]{
+		Status:  "success",
+		Message: "Withdraw hold captured successfully.",
+		Data:    so,
+	}, nil
+}
+
+func (s *withdrawService) Void(caller requests.Caller, request requests.VoidWithdrawHoldRequest) (*response.ApiResponse[*response.WithdrawHoldResponse], *response.ErrorResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold, errRes := s.findActiveHold(caller, request.HoldID)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	voided, err := s.releaseHold(hold, models.WithdrawHoldVoided)
+	if err != nil {
+		s.logger.Error("failed to void withdraw hold", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Failed to void withdraw hold.",
+		}
+	}
+
+	so := s.holdMapper.ToWithdrawHoldResponse(*voided)
+
+	return &response.ApiResponse[*response.WithdrawHoldResponse]{
+		Status:  "success",
+		Message: "Withdraw hold voided successfully.",
+		Data:    so,
+	}, nil
+}
+
+// ExpireHolds releases every hold that outlived its expiry and returns how
+// many were released.
+func (s *withdrawService) ExpireHolds() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	holds, err := s.withdrawHoldRepository.ReadExpired(time.Now())
+	if err != nil {
+		s.logger.Error("failed to read expired withdraw holds", zap.Error(err))
+		return 0
+	}
+
+	expired := 0
+
+	for _, hold := range holds {
+		if _, err := s.releaseHold(hold, models.WithdrawHoldExpired); err != nil {
+			s.logger.Error("failed to expire withdraw hold", zap.Error(err), zap.Int("holdID", hold.HoldID))
+			continue
+		}
+
+		expired++
+	}
+
+	return expired
+}
+
+// findActiveHold returns the hold when it can still be captured or voided by
+// caller. A hold found past its expiry is released on the spot. It must be
+// called with s.mu held.
+func (s *withdrawService) findActiveHold(caller requests.Caller, holdID int) (*record.WithdrawHoldRecord, *response.ErrorResponse) {
+	hold, err := s.withdrawHoldRepository.Read(holdID)
+	if err != nil {
+		s.logger.Error("failed to find withdraw hold", zap.Error(err))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Withdraw hold not found.",
+		}
+	}
+
+	if !ownsCard(s.cardRepository, caller, hold.CardNumber) {
+		s.logger.Error("withdraw hold belongs to another user", zap.Int("hold_id", holdID), zap.Int("user_id", caller.UserID))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Withdraw hold not found.",
+		}
+	}
+
+	if hold.Status != models.WithdrawHoldHeld {
+		s.logger.Error("withdraw hold is no longer held", zap.String("status", hold.Status))
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Withdraw hold is already " + hold.Status + ".",
+		}
+	}
+
+	if !time.Now().Before(hold.ExpiresAt) {
+		if _, err := s.releaseHold(hold, models.WithdrawHoldExpired); err != nil {
+			s.logger.Error("failed to expire withdraw hold", zap.Error(err))
+		}
+
+		return nil, &response.ErrorResponse{
+			Status:  "error",
+			Message: "Withdraw hold has expired.",
+		}
+	}
+
+	return hold, nil
+}
+
+// releaseHold returns the held amount to the card and closes the hold with
+// status in one unit of work.
+func (s *withdrawService) releaseHold(hold *record.WithdrawHoldRecord, status string) (*record.WithdrawHoldRecord, error) {
+	unit := s.ledger.Begin()
+	released := unit.WithdrawHold.UpdateStatus(requests.UpdateWithdrawHoldStatus{
+		HoldID: hold.HoldID,
+		Status: status,
+	})
+
+	if err := s.ledger.ReleaseWith(unit, hold.CardNumber, hold.Amount); err != nil {
+		return nil, err
+	}
+
+	return released.Value(), nil
+}
@@ -2,14 +2,48 @@ package apikey
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 )
 
+const (
+	// keyPrefix marks keys issued since keys are stored hashed.
+	keyPrefix = "pmk_"
+	// LookupLength is how many leading characters of a key are stored in
+	// plain text to find its hash without comparing against every key.
+	LookupLength = 16
+)
+
+// GenerateApiKey returns a new random key.
 func GenerateApiKey() string {
-	key := make([]byte, 32)
+	key := make([]byte, 30)
 	_, err := rand.Read(key)
 	if err != nil {
 		panic(err)
 	}
-	return hex.EncodeToString(key)
+	return keyPrefix + hex.EncodeToString(key)
+}
+
+// Lookup returns the part of key that is stored in plain text, or false when
+// key is too short to be one.
+func Lookup(key string) (string, bool) {
+	if len(key) < LookupLength*2 {
+		return "", false
+	}
+
+	return key[:LookupLength], true
+}
+
+// Hash returns the hash a key is stored as. Keys are long random strings,
+// so a fast hash is enough to keep them from being read back.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches reports whether key hashes to hash, in constant time.
+func Matches(key string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(key)), []byte(hash)) == 1
 }
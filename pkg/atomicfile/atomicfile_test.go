@@ -0,0 +1,40 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	for _, content := range []string{`{"v":1}`, `{"v":2}`} {
+		if err := Write(path, []byte(content)); err != nil {
+			t.Fatalf("write %s: %v", content, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+
+		if string(data) != content {
+			t.Errorf("file holds %s, want %s", data, content)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("%d files in the directory, want only the written one", len(entries))
+	}
+
+	if err := Write(filepath.Join(dir, "missing", "state.json"), nil); err == nil {
+		t.Error("write into a missing directory succeeded")
+	}
+}
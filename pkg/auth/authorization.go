@@ -5,20 +5,34 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-
-	"github.com/spf13/viper"
 )
 
 type contextKey string
 
-const UserIDKey contextKey = "userId"
+const (
+	UserIDKey    contextKey = "userId"
+	RoleKey      contextKey = "role"
+	SessionIDKey contextKey = "sessionId"
+)
+
+// Revocations tells whether an access token was revoked before it expired.
+type Revocations interface {
+	IsRevoked(claims *Claims) bool
+}
+
+// Verifier checks the access token of a request: its signature and expiry
+// with Tokens and whether its session is still alive with Revocations.
+type Verifier struct {
+	Tokens      TokenManager
+	Revocations Revocations
+}
 
-func Authorization(r *http.Request) (string, error) {
+func (v *Verifier) Authorization(r *http.Request) (*Claims, error) {
 	keys := r.URL.Query()
 	token := keys.Get("token")
 
 	if token != "" {
-		return token, errors.New("token not found")
+		return nil, errors.New("token not found")
 	}
 
 	bearerToken := r.Header.Get("Authorization")
@@ -26,21 +40,19 @@ func Authorization(r *http.Request) (string, error) {
 	if len(strings.Split(bearerToken, " ")) == 2 {
 		tokenString := strings.Split(bearerToken, " ")[1]
 
-		manager, err := NewManager(viper.GetString("JWT_SECRET"))
-		if err != nil {
-			fmt.Println("Error loading .env file:", err)
-			return "", err
-		}
-
-		userId, err := manager.ValidateToken(tokenString)
+		claims, err := v.Tokens.ValidateToken(tokenString)
 
 		if err != nil {
 			fmt.Println("Error validating token:", err)
-			return "", err
+			return nil, err
+		}
+
+		if v.Revocations.IsRevoked(claims) {
+			return nil, errors.New("token revoked")
 		}
 
-		return userId, nil
+		return claims, nil
 	}
 
-	return "", errors.New("invalid token")
+	return nil, errors.New("invalid token")
 }
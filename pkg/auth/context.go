@@ -16,3 +16,27 @@ func GetContextUserId(r *http.Request) string {
 	}
 	return userId.(string)
 }
+
+func SetContextRole(r *http.Request, role string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), RoleKey, role))
+}
+
+func GetContextRole(r *http.Request) string {
+	role := r.Context().Value(RoleKey)
+	if role == nil {
+		return ""
+	}
+	return role.(string)
+}
+
+func SetContextSessionId(r *http.Request, sessionID int) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), SessionIDKey, sessionID))
+}
+
+func GetContextSessionId(r *http.Request) int {
+	sessionID := r.Context().Value(SessionIDKey)
+	if sessionID == nil {
+		return 0
+	}
+	return sessionID.(int)
+}
@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"payment-mutex/pkg/atomicfile"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+)
+
+// SigningKey is a key tokens are signed with. Its ID is sent in the kid header
+// so a verifier knows which public key to check a token against.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	// RetiredAt is set once a newer key took over signing. A retired key
+	// only verifies tokens it signed before.
+	RetiredAt *time.Time
+
+	private crypto.Signer
+}
+
+// GenerateSigningKey creates a new key for algorithm.
+func GenerateSigningKey(algorithm string) (*SigningKey, error) {
+	var private crypto.Signer
+
+	switch algorithm {
+	case AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+
+		private = key
+	case AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		private = key
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	key := &SigningKey{
+		Algorithm: algorithm,
+		CreatedAt: time.Now(),
+		private:   private,
+	}
+
+	key.ID = key.JWK().thumbprint()
+
+	return key, nil
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+
+	return jwt.SigningMethodRS256
+}
+
+// signingKey returns the private key in the form the jwt package signs with.
+func (k *SigningKey) signingKey() interface{} {
+	if key, ok := k.private.(*rsa.PrivateKey); ok {
+		return key
+	}
+
+	return k.private
+}
+
+// JWK is a public key in the JSON Web Key format of RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public half of the key.
+func (k *SigningKey) JWK() JWK {
+	jwk := JWK{
+		Kid: k.ID,
+		Use: "sig",
+		Alg: k.Algorithm,
+	}
+
+	switch public := k.private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+
+	return jwk
+}
+
+// thumbprint is the RFC 7638 thumbprint of the key, used as its ID so the same
+// key always gets the same kid.
+func (j JWK) thumbprint() string {
+	var members string
+
+	if j.Kty == "RSA" {
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, j.E, j.N)
+	} else {
+		members = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, j.Crv, j.X)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// storedKey is the form a key is saved in.
+type storedKey struct {
+	ID         string     `json:"kid"`
+	Algorithm  string     `json:"alg"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+	PrivateKey string     `json:"private_key"`
+}
+
+// loadKeys reads the keys saved at path, or returns nil when nothing was saved
+// yet.
+func loadKeys(path string) ([]*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var stored []storedKey
+
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	keys := make([]*SigningKey, 0, len(stored))
+
+	for _, s := range stored {
+		block, _ := pem.Decode([]byte(s.PrivateKey))
+		if block == nil {
+			return nil, fmt.Errorf("key %s in %s is not PEM encoded", s.ID, path)
+		}
+
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %s in %s: %w", s.ID, path, err)
+		}
+
+		private, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("key %s in %s cannot sign", s.ID, path)
+		}
+
+		keys = append(keys, &SigningKey{
+			ID:        s.ID,
+			Algorithm: s.Algorithm,
+			CreatedAt: s.CreatedAt,
+			RetiredAt: s.RetiredAt,
+			private:   private,
+		})
+	}
+
+	return keys, nil
+}
+
+// saveKeys writes keys to path, replacing the file in one step so a crash
+// never leaves a half written key file behind.
+func saveKeys(path string, keys []*SigningKey) error {
+	stored := make([]storedKey, 0, len(keys))
+
+	for _, k := range keys {
+		der, err := x509.MarshalPKCS8PrivateKey(k.private)
+		if err != nil {
+			return fmt.Errorf("failed to encode key %s: %w", k.ID, err)
+		}
+
+		stored = append(stored, storedKey{
+			ID:         k.ID,
+			Algorithm:  k.Algorithm,
+			CreatedAt:  k.CreatedAt,
+			RetiredAt:  k.RetiredAt,
+			PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		})
+	}
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	return atomicfile.Write(path, data)
+}
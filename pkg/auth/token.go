@@ -1,58 +1,302 @@
 package auth
 
 import (
-	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// TokenTypeChallenge is handed out by a login that still needs a second
+	// factor. It only proves the password was right.
+	TokenTypeChallenge = "mfa_challenge"
+	// Link tokens are sent by email. Their ID claim names the stored token
+	// that makes them single use.
+	TokenTypeEmailVerification = "email_verification"
+	TokenTypePasswordReset     = "password_reset"
+
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	ChallengeTokenTTL      = 5 * time.Minute
+)
+
 type TokenManager interface {
-	NewJwtToken(userId int) (string, error)
-	ValidateToken(token string) (string, error)
+	NewJwtToken(userId int, role string, sessionID int) (string, error)
+	NewRefreshToken(userId int, sessionID int, generation int) (string, error)
+	NewChallengeToken(userId int) (string, error)
+	NewLinkToken(userId int, tokenType string, tokenID int, ttl time.Duration) (string, error)
+	ValidateToken(token string) (*Claims, error)
+	ValidateRefreshToken(token string) (*Claims, error)
+	ValidateChallengeToken(token string) (*Claims, error)
+	ValidateLinkToken(token string, tokenType string) (*Claims, error)
+	AccessTokenTTL() time.Duration
+	JWKS() JWKSet
 }
 
+// Claims are the claims carried by access and refresh tokens. The subject is
+// the user ID and SessionID the login the token belongs to. Generation is
+// only set on refresh tokens.
+type Claims struct {
+	Role       string `json:"role,omitempty"`
+	Type       string `json:"typ"`
+	SessionID  int    `json:"sid"`
+	Generation int    `json:"gen,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Manager signs tokens with the newest of its keys and verifies them with
+// whichever key the kid header names. Keys that were rotated out stay until
+// every token they signed has expired.
 type Manager struct {
-	secretKey string
+	mu         sync.RWMutex
+	algorithm  string
+	keysPath   string
+	keys       []*SigningKey // newest first, keys[0] signs
+	accessTTL  time.Duration
+	refreshTTL time.Duration
 }
 
-func NewManager(secretKey string) (*Manager, error) {
-	if secretKey == "" {
-		return nil, errors.New("empty secret key")
+// NewManager loads the keys saved at keysPath and makes sure there is a key
+// for algorithm to sign with. With an empty keysPath the keys only live in
+// memory, so tokens do not survive a restart.
+func NewManager(algorithm string, keysPath string) (*Manager, error) {
+	if algorithm != AlgorithmRS256 && algorithm != AlgorithmEdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	m := &Manager{
+		algorithm:  algorithm,
+		keysPath:   keysPath,
+		accessTTL:  DefaultAccessTokenTTL,
+		refreshTTL: DefaultRefreshTokenTTL,
+	}
+
+	if keysPath != "" {
+		keys, err := loadKeys(keysPath)
+		if err != nil {
+			return nil, err
+		}
+
+		m.keys = keys
 	}
-	return &Manager{secretKey: secretKey}, nil
+
+	// A changed algorithm takes over signing right away
+	if len(m.keys) == 0 || m.keys[0].RetiredAt != nil || m.keys[0].Algorithm != algorithm {
+		if err := m.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
 }
 
-func (m *Manager) NewJwtToken(userId int) (string, error) {
-	nowTime := time.Now()
-	expireTime := nowTime.Add(12 * time.Hour)
+// WithTTL sets how long access and refresh tokens stay valid. A zero or
+// negative duration keeps the current one.
+func (m *Manager) WithTTL(accessTTL time.Duration, refreshTTL time.Duration) *Manager {
+	if accessTTL > 0 {
+		m.accessTTL = accessTTL
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(expireTime),
-		Subject:   strconv.Itoa(userId),
-	})
+	if refreshTTL > 0 {
+		m.refreshTTL = refreshTTL
+	}
 
-	return token.SignedString([]byte(m.secretKey))
+	return m
 }
 
-func (m *Manager) ValidateToken(accessToken string) (string, error) {
-	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (i interface{}, err error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+func (m *Manager) AccessTokenTTL() time.Duration {
+	return m.accessTTL
+}
+
+// Rotate makes a new key the signing key and retires the current one.
+func (m *Manager) Rotate() error {
+	key, err := GenerateSigningKey(m.algorithm)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	keys := []*SigningKey{key}
+
+	for _, k := range m.keys {
+		retired := *k
+		if retired.RetiredAt == nil {
+			retired.RetiredAt = &now
+		}
+
+		keys = append(keys, &retired)
+	}
+
+	keys = m.unexpired(keys, now)
+
+	if m.keysPath != "" {
+		if err := saveKeys(m.keysPath, keys); err != nil {
+			return err
 		}
+	}
+
+	m.keys = keys
+
+	return nil
+}
+
+// RotateIfDue rotates when the signing key is older than maxAge and reports
+// whether it did.
+func (m *Manager) RotateIfDue(maxAge time.Duration) (bool, error) {
+	m.mu.RLock()
+	due := time.Since(m.keys[0].CreatedAt) >= maxAge
+	m.mu.RUnlock()
+
+	if !due {
+		return false, nil
+	}
+
+	return true, m.Rotate()
+}
+
+// unexpired drops retired keys whose tokens have all expired. It must be
+// called with m.mu held.
+func (m *Manager) unexpired(keys []*SigningKey, now time.Time) []*SigningKey {
+	lifetime := m.accessTTL
+	if m.refreshTTL > lifetime {
+		lifetime = m.refreshTTL
+	}
+
+	kept := keys[:0]
+
+	for _, k := range keys {
+		if k.RetiredAt == nil || now.Before(k.RetiredAt.Add(lifetime)) {
+			kept = append(kept, k)
+		}
+	}
 
-		return []byte(m.secretKey), nil
-	})
+	return kept
+}
+
+// JWKS returns the public keys of every key that may have signed a token
+// that is still valid.
+func (m *Manager) JWKS() JWKSet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(m.keys))}
+
+	for _, k := range m.keys {
+		set.Keys = append(set.Keys, k.JWK())
+	}
+
+	return set
+}
+
+func (m *Manager) NewJwtToken(userId int, role string, sessionID int) (string, error) {
+	return m.sign(Claims{
+		Role:      role,
+		Type:      TokenTypeAccess,
+		SessionID: sessionID,
+	}, userId, m.accessTTL)
+}
+
+func (m *Manager) NewRefreshToken(userId int, sessionID int, generation int) (string, error) {
+	return m.sign(Claims{
+		Type:       TokenTypeRefresh,
+		SessionID:  sessionID,
+		Generation: generation,
+	}, userId, m.refreshTTL)
+}
+
+func (m *Manager) NewChallengeToken(userId int) (string, error) {
+	return m.sign(Claims{
+		Type: TokenTypeChallenge,
+	}, userId, ChallengeTokenTTL)
+}
+
+func (m *Manager) NewLinkToken(userId int, tokenType string, tokenID int, ttl time.Duration) (string, error) {
+	claims := Claims{Type: tokenType}
+	claims.ID = strconv.Itoa(tokenID)
+
+	return m.sign(claims, userId, ttl)
+}
+
+func (m *Manager) ValidateToken(accessToken string) (*Claims, error) {
+	return m.parse(accessToken, TokenTypeAccess)
+}
+
+func (m *Manager) ValidateRefreshToken(refreshToken string) (*Claims, error) {
+	return m.parse(refreshToken, TokenTypeRefresh)
+}
+
+func (m *Manager) ValidateChallengeToken(challengeToken string) (*Claims, error) {
+	return m.parse(challengeToken, TokenTypeChallenge)
+}
+
+func (m *Manager) ValidateLinkToken(linkToken string, tokenType string) (*Claims, error) {
+	return m.parse(linkToken, tokenType)
+}
+
+func (m *Manager) sign(claims Claims, userId int, ttl time.Duration) (string, error) {
+	nowTime := time.Now()
+
+	claims.IssuedAt = jwt.NewNumericDate(nowTime)
+	claims.ExpiresAt = jwt.NewNumericDate(nowTime.Add(ttl))
+	claims.Subject = strconv.Itoa(userId)
+
+	m.mu.RLock()
+	key := m.keys[0]
+	m.mu.RUnlock()
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.signingKey())
+}
+
+// parse validates a token and makes sure it is of tokenType, so a refresh
+// token cannot be sent as an access token or the other way around.
+func (m *Manager) parse(tokenString string, tokenType string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.verificationKey, jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}))
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || claims.Subject == "" {
+		return nil, fmt.Errorf("error get user claims from token")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", fmt.Errorf("error get user claims from token")
+	if claims.Type != tokenType {
+		return nil, fmt.Errorf("expected %s token, got %q", tokenType, claims.Type)
+	}
+
+	return claims, nil
+}
+
+// verificationKey returns the public key named by the kid header of token,
+// as long as it is a key of the algorithm the token claims.
+func (m *Manager) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, k := range m.keys {
+		if k.ID != kid {
+			continue
+		}
+
+		if token.Method.Alg() != k.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return k.private.Public(), nil
 	}
 
-	return claims["sub"].(string), nil
+	return nil, fmt.Errorf("unknown signing key %q", kid)
 }
@@ -0,0 +1,117 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrKeyReused  = errors.New("idempotency key reused with a different request")
+	ErrInProgress = errors.New("request with this idempotency key is still being processed")
+)
+
+// Response is what gets replayed when a client retries with the same key.
+type Response struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+type entry struct {
+	fingerprint string
+	response    *Response
+	expiresAt   time.Time
+}
+
+type Store struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	ttl       time.Duration
+	lastSweep time.Time
+}
+
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		entries:   make(map[string]*entry),
+		ttl:       ttl,
+		lastSweep: time.Now(),
+	}
+}
+
+func Fingerprint(parts ...[]byte) string {
+	hash := sha256.New()
+
+	for _, part := range parts {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Begin claims key for a new request. When the key was already completed for
+// the same fingerprint, the stored response is returned so it can be replayed.
+func (s *Store) Begin(key string, fingerprint string) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if existing, ok := s.entries[key]; ok && now.Before(existing.expiresAt) {
+		if existing.fingerprint != fingerprint {
+			return nil, ErrKeyReused
+		}
+
+		if existing.response == nil {
+			return nil, ErrInProgress
+		}
+
+		return existing.response, nil
+	}
+
+	s.entries[key] = &entry{
+		fingerprint: fingerprint,
+		expiresAt:   now.Add(s.ttl),
+	}
+
+	return nil, nil
+}
+
+func (s *Store) Complete(key string, response Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.entries[key]
+	if !ok {
+		return
+	}
+
+	existing.response = &response
+	existing.expiresAt = time.Now().Add(s.ttl)
+}
+
+// Release drops the claim on key so a failed request can be retried.
+func (s *Store) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+}
+
+func (s *Store) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+
+	for key, existing := range s.entries {
+		if !now.Before(existing.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+
+	s.lastSweep = now
+}
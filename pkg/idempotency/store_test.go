@@ -0,0 +1,67 @@
+package idempotency
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStoreBegin(t *testing.T) {
+	s := NewStore(time.Hour)
+	first := Fingerprint([]byte("POST"), []byte(`{"amount":100}`))
+	other := Fingerprint([]byte("POST"), []byte(`{"amount":200}`))
+	stored := Response{StatusCode: 201, ContentType: "application/json", Body: []byte(`{"id":1}`)}
+
+	// Setiap langkah berjalan di atas hasil langkah sebelumnya
+	tests := []struct {
+		name        string
+		before      func()
+		key         string
+		fingerprint string
+		want        *Response
+		wantErr     error
+	}{
+		{name: "new key", key: "k1", fingerprint: first},
+		{name: "same key still running", key: "k1", fingerprint: first, wantErr: ErrInProgress},
+		{name: "same key with another request", key: "k1", fingerprint: other, wantErr: ErrKeyReused},
+		{name: "completed key", before: func() { s.Complete("k1", stored) }, key: "k1", fingerprint: first, want: &stored},
+		{name: "completed key with another request", key: "k1", fingerprint: other, wantErr: ErrKeyReused},
+		{name: "released key", before: func() { s.Begin("k2", first); s.Release("k2") }, key: "k2", fingerprint: other},
+	}
+
+	for _, tt := range tests {
+		if tt.before != nil {
+			tt.before()
+		}
+
+		got, err := s.Begin(tt.key, tt.fingerprint)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: error %v, want %v", tt.name, err, tt.wantErr)
+		}
+
+		if (got == nil) != (tt.want == nil) || got != nil && string(got.Body) != string(tt.want.Body) {
+			t.Errorf("%s: response %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStoreKeysExpire(t *testing.T) {
+	s := NewStore(10 * time.Millisecond)
+	fingerprint := Fingerprint([]byte("a"))
+
+	s.Begin("k", fingerprint)
+	s.Complete("k", Response{StatusCode: 200})
+
+	time.Sleep(20 * time.Millisecond)
+
+	got, err := s.Begin("k", Fingerprint([]byte("b")))
+	if err != nil || got != nil {
+		t.Errorf("expired key: response %+v, error %v, want a fresh claim", got, err)
+	}
+}
+
+func TestFingerprintSeparatesParts(t *testing.T) {
+	if Fingerprint([]byte("ab"), []byte("c")) == Fingerprint([]byte("a"), []byte("bc")) {
+		t.Error("fingerprint does not depend on where the parts split")
+	}
+}
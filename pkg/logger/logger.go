@@ -31,9 +31,9 @@ func NewLogger() (*Logger, error) {
 	}, nil
 }
 
-func (Logger *Logger) Info(message string) {
+func (Logger *Logger) Info(message string, fields ...zap.Field) {
 
-	Logger.Log.Info(message)
+	Logger.Log.Info(message, fields...)
 }
 
 func (Logger *Logger) Fatal(message string, fields ...zap.Field) {
@@ -45,6 +45,17 @@ func (Logger *Logger) Debug(message string, fields ...zap.Field) {
 
 }
 
+func (Logger *Logger) Warn(message string, fields ...zap.Field) {
+	Logger.Log.Warn(message, fields...)
+}
+
+// SecurityEvent logs something that matters for the security of an account,
+// like failed logins or a lockout, under a stable event name so these lines
+// are easy to find and alert on.
+func (Logger *Logger) SecurityEvent(event string, fields ...zap.Field) {
+	Logger.Log.Warn("security event", append([]zap.Field{zap.String("event", event)}, fields...)...)
+}
+
 func (Logger *Logger) Error(message string, fields ...zap.Field) {
 	Logger.Log.Error(message, fields...)
 }
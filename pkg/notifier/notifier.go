@@ -0,0 +1,96 @@
+// Package notifier delivers messages to users, like the links of email
+// verification and password reset. The drivers here need no mail server and
+// are meant for local development: one writes messages to the log, the
+// other to files.
+package notifier
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"payment-mutex/pkg/logger"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Notifier interface {
+	Send(message Message) error
+}
+
+// New returns the notifier of driver. DriverFile writes to the directory
+// path, DriverLog is used when driver is empty.
+func New(driver string, path string, logger logger.Logger) (Notifier, error) {
+	switch driver {
+	case "", DriverLog:
+		return NewLogNotifier(logger), nil
+	case DriverFile:
+		return NewFileNotifier(path)
+	default:
+		return nil, fmt.Errorf("unknown notifier driver %q", driver)
+	}
+}
+
+// LogNotifier writes messages to the application log.
+type LogNotifier struct {
+	logger logger.Logger
+}
+
+func NewLogNotifier(logger logger.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Send(message Message) error {
+	n.logger.Info("notification", zap.String("to", message.To), zap.String("subject", message.Subject), zap.String("body", message.Body))
+
+	return nil
+}
+
+// FileNotifier writes every message to its own file in a directory, named so
+// that listing the directory shows them oldest first.
+type FileNotifier struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func NewFileNotifier(dir string) (*FileNotifier, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("notifier path is required for the %s driver", DriverFile)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	return &FileNotifier{dir: dir}, nil
+}
+
+func (n *FileNotifier) Send(message Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	recipient := strings.NewReplacer("/", "_", "\\", "_", string(filepath.Separator), "_").Replace(message.To)
+	name := fmt.Sprintf("%s-%s.txt", now.UTC().Format("20060102T150405.000000000"), recipient)
+
+	content := fmt.Sprintf("To: %s\nSubject: %s\nDate: %s\n\n%s\n", message.To, message.Subject, now.Format(time.RFC1123Z), message.Body)
+
+	if err := os.WriteFile(filepath.Join(n.dir, name), []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package signature
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("generate secret: %v", err)
+	}
+
+	payload := Payload("post", "/api/transaction/create?x=1", "1700000000", "n1", []byte(`{"amount":100}`))
+	signed := Sign(secret, payload)
+
+	tests := []struct {
+		name      string
+		secret    string
+		payload   []byte
+		signature string
+		ok        bool
+	}{
+		{"same request", secret, payload, signed, true},
+		{"upper case signature", secret, payload, strings.ToUpper(signed), true},
+		{"other secret", secret + "x", payload, signed, false},
+		{"other body", secret, Payload("POST", "/api/transaction/create?x=1", "1700000000", "n1", []byte(`{"amount":999}`)), signed, false},
+		{"other path", secret, Payload("POST", "/api/transaction/create", "1700000000", "n1", []byte(`{"amount":100}`)), signed, false},
+		{"other timestamp", secret, Payload("POST", "/api/transaction/create?x=1", "1700000001", "n1", []byte(`{"amount":100}`)), signed, false},
+		{"other nonce", secret, Payload("POST", "/api/transaction/create?x=1", "1700000000", "n2", []byte(`{"amount":100}`)), signed, false},
+		{"empty signature", secret, payload, "", false},
+	}
+
+	for _, tt := range tests {
+		if ok := Verify(tt.secret, tt.payload, tt.signature); ok != tt.ok {
+			t.Errorf("%s: verified = %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+}
+
+func TestNonceCacheUse(t *testing.T) {
+	c := NewNonceCache(time.Hour)
+	now := time.Now()
+
+	tests := []struct {
+		nonce     string
+		expiresAt time.Time
+		ok        bool
+	}{
+		{"a", now.Add(time.Minute), true},
+		{"a", now.Add(time.Minute), false},
+		{"b", now.Add(time.Minute), true},
+		// Nonce yang sudah kedaluwarsa boleh dipakai lagi
+		{"c", now.Add(-time.Second), true},
+		{"c", now.Add(time.Minute), true},
+		{"c", now.Add(time.Minute), false},
+	}
+
+	for i, tt := range tests {
+		if ok := c.Use(tt.nonce, tt.expiresAt); ok != tt.ok {
+			t.Errorf("use %d of %q = %v, want %v", i+1, tt.nonce, ok, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Package throttle slows down repeated failures. Every key gets a number of
+// free failures, after which each further failure makes it wait twice as long
+// before the next attempt.
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+type entry struct {
+	failures     int
+	blockedUntil time.Time
+	lastFailure  time.Time
+}
+
+type Throttle struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	free      int
+	base      time.Duration
+	max       time.Duration
+	window    time.Duration
+	lastSweep time.Time
+}
+
+// New returns a throttle that lets a key fail free times without waiting,
+// then waits base, 2*base, 4*base and so on up to max. Failures are forgotten
+// once a key did not fail for window.
+func New(free int, base time.Duration, max time.Duration, window time.Duration) *Throttle {
+	return &Throttle{
+		entries:   make(map[string]*entry),
+		free:      free,
+		base:      base,
+		max:       max,
+		window:    window,
+		lastSweep: time.Now(),
+	}
+}
+
+// Wait returns how long key has to wait before its next attempt, or zero when
+// it may try now.
+func (t *Throttle) Wait(key string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.sweep(now)
+
+	e, ok := t.entries[key]
+	if !ok || !now.Before(e.blockedUntil) {
+		return 0
+	}
+
+	return e.blockedUntil.Sub(now)
+}
+
+// Fail records a failed attempt of key and returns how long it has to wait
+// now.
+func (t *Throttle) Fail(key string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.sweep(now)
+
+	e, ok := t.entries[key]
+	if !ok || t.expired(e, now) {
+		e = &entry{}
+		t.entries[key] = e
+	}
+
+	e.failures++
+	e.lastFailure = now
+
+	if e.failures <= t.free {
+		return 0
+	}
+
+	delay := t.base
+	for i := t.free + 1; i < e.failures && delay < t.max; i++ {
+		delay *= 2
+	}
+
+	if delay > t.max {
+		delay = t.max
+	}
+
+	e.blockedUntil = now.Add(delay)
+
+	return delay
+}
+
+// Reset forgets the failures of key.
+func (t *Throttle) Reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.entries, key)
+}
+
+// sweep drops keys that stopped failing, at most once per window. It must be
+// called with t.mu held.
+func (t *Throttle) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.window {
+		return
+	}
+
+	for key, e := range t.entries {
+		if t.expired(e, now) {
+			delete(t.entries, key)
+		}
+	}
+
+	t.lastSweep = now
+}
+
+func (t *Throttle) expired(e *entry, now time.Time) bool {
+	return now.Sub(e.lastFailure) >= t.window && !now.Before(e.blockedUntil)
+}
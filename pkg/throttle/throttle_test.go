@@ -0,0 +1,60 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailDoublesDelay(t *testing.T) {
+	th := New(2, time.Second, 5*time.Second, time.Hour)
+
+	tests := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+
+	for i, want := range tests {
+		if got := th.Fail("key"); got != want {
+			t.Errorf("failure %d: delay %v, want %v", i+1, got, want)
+		}
+	}
+
+	if wait := th.Wait("key"); wait <= 0 || wait > 5*time.Second {
+		t.Errorf("wait = %v, want up to 5s", wait)
+	}
+
+	if wait := th.Wait("other"); wait != 0 {
+		t.Errorf("wait of another key = %v, want 0", wait)
+	}
+}
+
+func TestResetForgetsFailures(t *testing.T) {
+	th := New(1, time.Minute, time.Hour, time.Hour)
+
+	th.Fail("key")
+	th.Fail("key")
+
+	if wait := th.Wait("key"); wait == 0 {
+		t.Fatal("key is not throttled after failing twice")
+	}
+
+	th.Reset("key")
+
+	if wait := th.Wait("key"); wait != 0 {
+		t.Errorf("wait after reset = %v, want 0", wait)
+	}
+
+	if delay := th.Fail("key"); delay != 0 {
+		t.Errorf("first failure after reset waits %v, want 0", delay)
+	}
+}
+
+func TestFailuresExpireAfterWindow(t *testing.T) {
+	th := New(1, time.Millisecond, time.Millisecond, 20*time.Millisecond)
+
+	th.Fail("key")
+	th.Fail("key")
+
+	time.Sleep(30 * time.Millisecond)
+
+	if delay := th.Fail("key"); delay != 0 {
+		t.Errorf("failure after the window waits %v, want 0", delay)
+	}
+}
@@ -0,0 +1,76 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 secret of the RFC 6238 test vectors, base32 encoded.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// Kode 8 digit dari RFC 6238, dipotong ke 6 digit terakhir
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("code at %d: %v", tt.unix, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+
+	tests := []struct {
+		name string
+		code func() string
+		skew int
+		ok   bool
+	}{
+		{"current step", codeAt(t, step), 1, true},
+		{"previous step within skew", codeAt(t, step-1), 1, true},
+		{"next step within skew", codeAt(t, step+1), 1, true},
+		{"step outside skew", codeAt(t, step-2), 1, false},
+		{"previous step without skew", codeAt(t, step-1), 0, false},
+		{"wrong code", func() string { return "000000" }, 1, false},
+		{"short code", func() string { return "05047" }, 1, false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := Validate(rfcSecret, tt.code(), now, tt.skew); ok != tt.ok {
+			t.Errorf("%s: valid = %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+
+	if _, ok := Validate("not base32!", "050471", now, 1); ok {
+		t.Error("code accepted for an invalid secret")
+	}
+}
+
+func codeAt(t *testing.T, step int64) func() string {
+	return func() string {
+		code, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("code at step %d: %v", step, err)
+		}
+
+		return code
+	}
+}